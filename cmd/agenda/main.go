@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	colorspkg "github.com/vinayprograms/karya/internal/colors"
@@ -65,6 +68,9 @@ type colorScheme struct {
 	schedInfo   lipgloss.Style
 	dimText     lipgloss.Style
 	clockActive lipgloss.Style
+	priority    lipgloss.Style
+	blocked     lipgloss.Style
+	event       lipgloss.Style
 }
 
 var colors colorScheme
@@ -87,6 +93,9 @@ func initColors(cfg *config.Config) {
 		schedInfo:   lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
 		dimText:     lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
 		clockActive: lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.ClockActiveColor)).Bold(true),
+		priority:    lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.PriorityColor)).Background(lipgloss.Color(cfg.Colors.PriorityBgColor)).Bold(true),
+		blocked:     lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.OverdueColor)).Bold(true),
+		event:       lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.CalendarEventColor)).Italic(true),
 	}
 }
 
@@ -112,6 +121,9 @@ type model struct {
 	err               error
 	statusMessage     string
 
+	// Pomodoro timer, started with p
+	pomodoro task.PomodoroSession
+
 	// Clock table view state
 	clockTable        *task.ClockTable
 	clockCursor       int
@@ -161,18 +173,27 @@ func initialModel(cfg *config.Config) model {
 		focusDate: time.Now(),
 	}
 
-	switch cfg.Schedule.DefaultView {
+	m.mode, _ = parseViewMode(cfg.Schedule.DefaultView)
+
+	return m
+}
+
+// parseViewMode maps a view name to its mode, falling back to the week view
+// for unknown names.
+func parseViewMode(name string) (viewMode, bool) {
+	switch name {
 	case "day":
-		m.mode = viewDay
+		return viewDay, true
+	case "week":
+		return viewWeek, true
 	case "fortnight":
-		m.mode = viewFortnight
+		return viewFortnight, true
 	case "month":
-		m.mode = viewMonth
-	default:
-		m.mode = viewWeek
+		return viewMonth, true
+	case "year":
+		return viewYear, true
 	}
-
-	return m
+	return viewWeek, false
 }
 
 func (m model) Init() tea.Cmd {
@@ -219,6 +240,178 @@ func loadAgendaCmd(cfg *config.Config, focus time.Time, mode viewMode) tea.Cmd {
 	}
 }
 
+const exportUsage = "usage: agenda --format json|csv|tsv|markdown [--view day|week|fortnight|month|year] [--date YYYY-MM-DD]"
+
+// exportAgenda writes the agenda of one view to stdout instead of starting
+// the TUI. The view defaults to schedule.default_view around today.
+func exportAgenda(cfg *config.Config, args []string) error {
+	format := ""
+	mode, _ := parseViewMode(cfg.Schedule.DefaultView)
+	focus := time.Now()
+	for i := 0; i < len(args); i++ {
+		if i+1 >= len(args) {
+			return fmt.Errorf("%s", exportUsage)
+		}
+		switch args[i] {
+		case "--format":
+			format = args[i+1]
+		case "--view":
+			m, ok := parseViewMode(args[i+1])
+			if !ok {
+				return fmt.Errorf("unknown view %q\n%s", args[i+1], exportUsage)
+			}
+			mode = m
+		case "--date":
+			d, err := time.ParseInLocation("2006-01-02", args[i+1], time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid date %q\n%s", args[i+1], exportUsage)
+			}
+			focus = d
+		default:
+			return fmt.Errorf("%s", exportUsage)
+		}
+		i++
+	}
+	if format == "" {
+		return fmt.Errorf("%s", exportUsage)
+	}
+
+	start, end := viewRange(focus, mode, cfg)
+	today := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 0, 0, 0, time.Local)
+	includeOverdue := !today.Before(start) && !today.After(end)
+	days, err := task.QueryAgenda(cfg, start, end, includeOverdue)
+	if err != nil {
+		return err
+	}
+	return task.WriteAgenda(os.Stdout, cfg, days, format)
+}
+
+const icsUsage = "usage: agenda export --ics [--from YYYY-MM-DD] [--to YYYY-MM-DD]"
+
+// exportICS writes the scheduled items and deadlines between --from
+// (default today) and --to (default a month later) as an iCalendar file.
+func exportICS(cfg *config.Config, args []string) error {
+	ics := false
+	from := time.Now()
+	var to time.Time
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--ics":
+			ics = true
+			continue
+		case "--from", "--to":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s", icsUsage)
+			}
+			d, err := time.ParseInLocation("2006-01-02", args[i+1], time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid date %q\n%s", args[i+1], icsUsage)
+			}
+			if args[i] == "--from" {
+				from = d
+			} else {
+				to = d
+			}
+			i++
+		default:
+			return fmt.Errorf("%s", icsUsage)
+		}
+	}
+	if !ics {
+		return fmt.Errorf("%s", icsUsage)
+	}
+	if to.IsZero() {
+		to = from.AddDate(0, 1, 0)
+	}
+	if to.Before(from) {
+		return fmt.Errorf("--to %s is before --from %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	days, err := task.QueryAgenda(cfg, from, to, false)
+	if err != nil {
+		return err
+	}
+	return task.WriteICS(os.Stdout, cfg, days, time.Now())
+}
+
+const notifyUsage = "usage: agenda notify [--once]"
+
+// runNotify sends notifications for timed items, opening warning windows
+// and long clocks (see task.DueNotifications) until interrupted, checking
+// every 30 seconds and whenever a task file changes. With --once it checks
+// once and exits, for running from cron.
+func runNotify(cfg *config.Config, args []string) error {
+	once := false
+	for _, arg := range args {
+		if arg != "--once" {
+			return fmt.Errorf("%s", notifyUsage)
+		}
+		once = true
+	}
+	sinks, err := task.NotifySinks(cfg, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if task.NotifyStatePath(cfg) == "" {
+		log.Printf("Warning: no karya directory is set, so notifications are sent again after a restart")
+	}
+	notifier := task.NewNotifier(cfg, sinks)
+	check := func() error {
+		sent, err := notifier.Check(time.Now())
+		for _, n := range sent {
+			log.Printf("notified %s: %s: %s", n.Kind, n.Title, n.Body)
+		}
+		return err
+	}
+	if once {
+		return check()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var changes <-chan fsnotify.Event
+	var watchErrs <-chan error
+	if watcher := setupWatcher(cfg); watcher != nil {
+		defer watcher.Close()
+		changes, watchErrs = watcher.Events, watcher.Errors
+	}
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	// next waits for the next tick or task file change, and returns false
+	// once interrupted
+	next := func() bool {
+		for {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-ticker.C:
+				return true
+			case event, ok := <-changes:
+				if !ok {
+					changes = nil
+				} else if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove) != 0 {
+					// Let the editor finish writing
+					time.Sleep(100 * time.Millisecond)
+					return true
+				}
+			case _, ok := <-watchErrs:
+				if !ok {
+					watchErrs = nil
+				}
+			}
+		}
+	}
+	for {
+		if err := check(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		if !next() {
+			return nil
+		}
+	}
+}
+
 func loadClockTableCmd(cfg *config.Config, focus time.Time, mode viewMode) tea.Cmd {
 	return func() tea.Msg {
 		start, end := viewRange(focus, mode, cfg)
@@ -269,6 +462,13 @@ type datePickerResultMsg struct {
 
 type clearStatusMsg struct{}
 
+// pomodoroTickMsg advances the pomodoro timer of generation gen.
+type pomodoroTickMsg struct{ gen int }
+
+func pomodoroTickCmd(gen int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return pomodoroTickMsg{gen} })
+}
+
 type statusUpdateMsg struct {
 	message string
 	err     error
@@ -281,6 +481,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Advance the pomodoro timer regardless of UI state
+	if msg, ok := msg.(pomodoroTickMsg); ok {
+		return m.tickPomodoro(msg.gen)
+	}
+
 	// Help overlay
 	if m.showingHelp {
 		switch msg := msg.(type) {
@@ -588,6 +793,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, clockOutAgendaCmd(m.clockTasks[m.clockCursor])
 				}
 
+			// Pomodoro
+			case "p":
+				if m.pomodoro.Running() {
+					return m.stopPomodoro()
+				}
+				if m.clockCursor < len(m.clockTasks) {
+					return m.startPomodoro(m.clockTasks[m.clockCursor])
+				}
+
 			// Status change
 			case "t":
 				if m.clockCursor < len(m.clockTasks) {
@@ -750,7 +964,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Detail view
 		case "v":
-			if m.cursor < len(m.flatItems) {
+			if m.editableItem() {
 				m.selectedTask = m.flatItems[m.cursor].Task
 				m.showingDetailView = true
 			}
@@ -758,7 +972,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Schedule/Due date picker
 		case "S":
-			if m.cursor < len(m.flatItems) {
+			if m.editableItem() {
 				t := m.flatItems[m.cursor].Task
 				m.selectedTask = t
 				m.datePicker = task.NewDatePicker(t, task.FieldScheduled)
@@ -766,7 +980,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		case "D":
-			if m.cursor < len(m.flatItems) {
+			if m.editableItem() {
 				t := m.flatItems[m.cursor].Task
 				m.selectedTask = t
 				m.datePicker = task.NewDatePicker(t, task.FieldDue)
@@ -776,17 +990,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Clock in/out
 		case "i":
-			if m.cursor < len(m.flatItems) {
+			if m.editableItem() {
 				return m, clockInAgendaCmd(m.flatItems[m.cursor].Task)
 			}
 		case "o":
-			if m.cursor < len(m.flatItems) {
+			if m.editableItem() {
 				return m, clockOutAgendaCmd(m.flatItems[m.cursor].Task)
 			}
 
+		// Pomodoro
+		case "p":
+			if m.pomodoro.Running() {
+				return m.stopPomodoro()
+			}
+			if m.editableItem() {
+				return m.startPomodoro(m.flatItems[m.cursor].Task)
+			}
+
 		// Status change
 		case "t":
-			if m.cursor < len(m.flatItems) {
+			if m.editableItem() {
 				t := m.flatItems[m.cursor].Task
 				m.statusPickerTask = t
 				m.showingStatusPicker = true
@@ -794,6 +1017,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
+		// Undo / redo
+		case "u":
+			return m, undoCmd(m.config, false)
+		case "ctrl+r":
+			return m, undoCmd(m.config, true)
+
 		// Help
 		case "?":
 			m.showingHelp = !m.showingHelp
@@ -801,7 +1030,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Open editor
 		case "enter":
-			if m.cursor < len(m.flatItems) {
+			if m.editableItem() {
 				item := m.flatItems[m.cursor]
 				return m, openEditorCmd(m.config, item.Task)
 			}
@@ -918,15 +1147,28 @@ func updateTaskStatusCmd(cfg *config.Config, t *task.Task, newKeyword string) te
 			}
 		}
 
-		if err := task.UpdateTaskStatus(t, newKeyword, cfg); err != nil {
+		// Journaled so the status and its LOG line are undone together
+		summary := fmt.Sprintf("Status %s -> %s: %s", oldKeyword, newKeyword, t.Title)
+		var unblocked []*task.Task
+		err := task.Journaled(t, summary, func() error {
+			if err := task.UpdateTaskStatus(t, newKeyword, cfg); err != nil {
+				return err
+			}
+			// Record state transition for all status changes
+			if err := task.RecordStateTransition(t, oldKeyword, newKeyword); err != nil {
+				return fmt.Errorf("status updated but failed to record transition: %w", err)
+			}
+			// Move dependents waiting on this task to the ready keyword
+			var err error
+			if unblocked, err = task.UnblockDependents(cfg, t); err != nil {
+				return fmt.Errorf("status updated but failed to unblock dependents: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
 			return statusUpdateMsg{err: err}
 		}
 
-		// Record state transition for all status changes
-		if err := task.RecordStateTransition(t, oldKeyword, newKeyword); err != nil {
-			return statusUpdateMsg{err: fmt.Errorf("status updated but failed to record transition: %w", err)}
-		}
-
 		commitMsg := fmt.Sprintf("Update task status: %s -> %s", oldKeyword, newKeyword)
 		if err := kgit.CommitFile(t.FilePath, commitMsg, true); err != nil {
 			return statusUpdateMsg{
@@ -934,9 +1176,34 @@ func updateTaskStatusCmd(cfg *config.Config, t *task.Task, newKeyword string) te
 			}
 		}
 
-		return statusUpdateMsg{
-			message: fmt.Sprintf("Status updated: %s → %s", oldKeyword, newKeyword),
+		message := fmt.Sprintf("Status updated: %s → %s", oldKeyword, newKeyword)
+		if len(unblocked) > 0 {
+			var files []string
+			for _, u := range unblocked {
+				files = append(files, u.FilePath)
+			}
+			kgit.CommitFilesByRepo(files, fmt.Sprintf("Unblock %d task(s) after: %s", len(unblocked), t.Title), true)
+			message += fmt.Sprintf(" (%d dependent task(s) ready)", len(unblocked))
 		}
+		return statusUpdateMsg{message: message}
+	}
+}
+
+// undoCmd reverts the most recent journaled task edit, or re-applies the most
+// recently undone one when redo is set.
+func undoCmd(cfg *config.Config, redo bool) tea.Cmd {
+	return func() tea.Msg {
+		undo, verb, done := task.Undo, "Undo", "Undone"
+		if redo {
+			undo, verb, done = task.Redo, "Redo", "Redone"
+		}
+		entries, err := undo(cfg, 1)
+		if err != nil {
+			return statusUpdateMsg{err: err}
+		}
+		e := entries[0]
+		kgit.CommitFilesByRepo(e.Files(), fmt.Sprintf("%s: %s", verb, e.Summary), true)
+		return statusUpdateMsg{message: fmt.Sprintf("%s: %s", done, e.Summary)}
 	}
 }
 
@@ -1019,12 +1286,15 @@ func (m model) renderHelp() string {
 		{"D", "set due date"},
 		{"i", "clock in"},
 		{"o", "clock out"},
+		{"p", "start/stop a pomodoro"},
 		{"?", "toggle this help"},
 		{"q", "quit"},
 	}
 
 	agenda := []binding{
 		{"c", "switch to clock view"},
+		{"u", "undo last task edit"},
+		{"C-r", "redo last undone edit"},
 	}
 
 	clock := []binding{
@@ -1135,6 +1405,19 @@ func totalItems(days []task.AgendaDay) int {
 	return n
 }
 
+// editableItem reports whether the cursor is on a task. Calendar events are
+// read-only; for them it explains so in the status line.
+func (m *model) editableItem() bool {
+	if m.cursor >= len(m.flatItems) {
+		return false
+	}
+	if ev := m.flatItems[m.cursor].Event; ev != nil {
+		m.statusMessage = fmt.Sprintf("%q is a read-only event from the %s calendar", ev.Summary, ev.Calendar)
+		return false
+	}
+	return true
+}
+
 func flattenItems(days []task.AgendaDay) []task.AgendaItem {
 	var items []task.AgendaItem
 	for _, day := range days {
@@ -1295,33 +1578,14 @@ func (m *model) buildDayGridLineMapping(items []task.AgendaItem, cursorToLine *[
 	}
 
 	// Mirror gap computation from renderDayTimeGrid
-	gapAfter := make(map[int]bool)
-	hoursInGap := make(map[int]bool)
-	for i := 0; i < len(timed)-1; i++ {
-		if !timed[i].HasEnd || timed[i].IsCompleted {
-			continue
-		}
-		if timed[i+1].IsCompleted {
-			continue
-		}
-		endTime := timed[i].EndTime
-		nextStart := timed[i+1].Date
-		if nextStart.Sub(endTime) >= 15*time.Minute {
-			gapAfter[i] = true
-			for h := endTime.Hour(); h < nextStart.Hour(); h++ {
-				if _, occupied := hourItemIndices[h]; !occupied {
-					hoursInGap[h] = true
-				}
-			}
-		}
-	}
+	gapAfter, hoursInGap := freeGaps(timed, hourItemIndices)
 
 	for hour := gridStart; hour < gridEnd; hour++ {
 		if indices, ok := hourItemIndices[hour]; ok {
 			for _, idx := range indices {
 				*cursorToLine = append(*cursorToLine, lineIdx)
 				lineIdx++
-				if gapAfter[idx] {
+				if _, hasGap := gapAfter[idx]; hasGap {
 					lineIdx++ // gap indicator line
 				}
 			}
@@ -1409,6 +1673,51 @@ func clockOutAgendaCmd(t *task.Task) tea.Cmd {
 	}
 }
 
+// startPomodoro clocks in to t for a pomodoro work interval.
+func (m model) startPomodoro(t *task.Task) (tea.Model, tea.Cmd) {
+	gen, err := m.pomodoro.Start(m.config, t, time.Now())
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Pomodoro: %v", err)
+		return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} })
+	}
+	return m, pomodoroTickCmd(gen)
+}
+
+// stopPomodoro ends the running pomodoro, clocking out of an unfinished
+// work interval.
+func (m model) stopPomodoro() (tea.Model, tea.Cmd) {
+	m.statusMessage = "Pomodoro stopped"
+	if err := m.pomodoro.Stop(time.Now()); err != nil {
+		m.statusMessage = fmt.Sprintf("Pomodoro: %v", err)
+	}
+	return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} })
+}
+
+// tickPomodoro advances the pomodoro timer of generation gen, showing what
+// it announces.
+func (m model) tickPomodoro(gen int) (tea.Model, tea.Cmd) {
+	msg, again := m.pomodoro.Tick(gen, time.Now())
+	var cmds []tea.Cmd
+	if again {
+		cmds = append(cmds, pomodoroTickCmd(gen))
+	}
+	if msg != "" {
+		m.statusMessage = msg
+		cmds = append(cmds, tea.Tick(5*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} }))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// pomodoroStatus renders the running pomodoro for the line above the
+// footer, or "" when none is running.
+func (m model) pomodoroStatus() string {
+	status := m.pomodoro.Status(time.Now())
+	if status == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true).Render(status)
+}
+
 func (m model) View() string {
 	if m.quitting {
 		return ""
@@ -1526,21 +1835,28 @@ func (m model) View() string {
 		b.WriteString("\n")
 	}
 
-	// Status message
+	// Status message, after the running pomodoro
+	status := m.pomodoroStatus()
 	if m.statusMessage != "" {
 		statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-		b.WriteString(statusStyle.Render(m.statusMessage))
+		status = strings.TrimSpace(status + "  " + statusStyle.Render(m.statusMessage))
+	}
+	if status != "" {
+		b.WriteString(status)
 		b.WriteString("\n")
 	}
 
 	// Footer (anchored to bottom)
-	footer := colors.dimText.Render("t: status • S/D: schedule/due • c: clock • i/o: in/out • v: detail • enter: edit • ?: help • q: quit")
+	footer := colors.dimText.Render("t: status • S/D: schedule/due • c: clock • i/o: in/out • p: pomodoro • u: undo • v: detail • enter: edit • ?: help • q: quit")
 	b.WriteString(footer)
 
 	return b.String()
 }
 
 func (m model) renderItem(item task.AgendaItem, selected bool) string {
+	if item.Event != nil {
+		return m.renderEventItem(item, selected)
+	}
 	var parts []string
 	t := item.Task
 
@@ -1625,6 +1941,9 @@ func (m model) renderItem(item task.AgendaItem, selected bool) string {
 	if t.ID != "" {
 		displayTitle = fmt.Sprintf("[%s] %s", t.ID, t.Title)
 	}
+	if item.Span != nil {
+		displayTitle += " " + item.Span.Label()
+	}
 	if item.IsCompleted {
 		displayTitle = "✓ " + displayTitle
 	} else if item.ClockActive {
@@ -1632,6 +1951,14 @@ func (m model) renderItem(item task.AgendaItem, selected bool) string {
 		titleStyle = colors.clockActive
 	}
 	formattedTitle := titleStyle.Render(task.RenderMarkdownDescription(displayTitle, titleStyle))
+	// Priority cookie badge ahead of the title; completed items don't need it
+	if t.PriorityCookie != "" && !item.IsCompleted {
+		formattedTitle = colors.priority.Render(" "+t.PriorityCookie+" ") + " " + formattedTitle
+	}
+	// Blocked marker: a dependency (^id) isn't completed yet
+	if t.Blocked && !item.IsCompleted {
+		formattedTitle = colors.blocked.Render("⊘") + " " + formattedTitle
+	}
 	// Fixed columns: selector(2) + project(10) + schedule(14) + keyword(12) + right-side(30)
 	maxTitle := m.termWidth - 2 - 10 - 14 - 12 - 30
 	if maxTitle < 20 {
@@ -1664,6 +1991,37 @@ func (m model) renderItem(item task.AgendaItem, selected bool) string {
 	return strings.Join(parts, "")
 }
 
+// renderEventItem renders a calendar event in the same columns as tasks:
+// the calendar's name in the project column and no keyword.
+func (m model) renderEventItem(item task.AgendaItem, selected bool) string {
+	ev := item.Event
+	var parts []string
+	if selected {
+		parts = append(parts, lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true).Render("█ "))
+	} else {
+		parts = append(parts, "  ")
+	}
+
+	cal := ev.Calendar
+	if len(cal) > 9 {
+		cal = cal[:9]
+	}
+	parts = append(parts, colors.event.Render(fmt.Sprintf("%-10s", cal+":")))
+	parts = append(parts, colors.event.Render(fmt.Sprintf("%-14s", m.formatScheduleInfo(item))))
+	parts = append(parts, fmt.Sprintf("%-12s", ""))
+
+	title := "▣ " + ev.Summary
+	if ev.Location != "" {
+		title += " @ " + ev.Location
+	}
+	maxTitle := m.termWidth - 2 - 10 - 14 - 12 - 30
+	if maxTitle < 20 {
+		maxTitle = 20
+	}
+	parts = append(parts, colors.event.Render(task.TruncateString(title, maxTitle)))
+	return strings.Join(parts, "")
+}
+
 // renderDayTimeGrid renders day view with hour-resolution time grid.
 // Timed items are placed chronologically; empty hour slots fill the gaps.
 // Two padding slots are added before the first and after the last timed task,
@@ -1742,30 +2100,7 @@ func (m model) renderDayTimeGrid(items []task.AgendaItem, startIdx int, isToday
 		hourItems[item.Date.Hour()] = append(hourItems[item.Date.Hour()], i)
 	}
 
-	// Pre-compute gaps between consecutive timed items (≥15 min free).
-	// Only consider items that occupy real calendar slots (have an end time,
-	// not overdue, not completed).
-	gapAfter := make(map[int]time.Duration) // index into timed -> gap duration
-	hoursInGap := make(map[int]bool)        // hours covered by a gap (suppress empty-hour markers)
-	for i := 0; i < len(timed)-1; i++ {
-		if !timed[i].HasEnd || timed[i].IsCompleted {
-			continue
-		}
-		if timed[i+1].IsCompleted {
-			continue
-		}
-		endTime := timed[i].EndTime
-		nextStart := timed[i+1].Date
-		gap := nextStart.Sub(endTime)
-		if gap >= 15*time.Minute {
-			gapAfter[i] = gap
-			for h := endTime.Hour(); h < nextStart.Hour(); h++ {
-				if _, occupied := hourItems[h]; !occupied {
-					hoursInGap[h] = true
-				}
-			}
-		}
-	}
+	gapAfter, hoursInGap := freeGaps(timed, hourItems)
 
 	// Render overdue items first
 	for _, item := range overdue {
@@ -1832,6 +2167,50 @@ func (m model) renderDayTimeGrid(items []task.AgendaItem, startIdx int, isToday
 	return lines, itemIdx
 }
 
+// freeGaps finds the free time (≥15 min) after each of the chronologically
+// sorted timed items, keyed by index. Items with an end time that aren't
+// completed, calendar events included, count as busy until they end, so a
+// gap only opens once every earlier busy item has ended. It also returns the
+// hours a gap covers that have no item starting in them (see hourItems),
+// whose empty-hour markers the gap line replaces.
+func freeGaps(timed []task.AgendaItem, hourItems map[int][]int) (map[int]time.Duration, map[int]bool) {
+	gapAfter := make(map[int]time.Duration)
+	hoursInGap := make(map[int]bool)
+	var busyUntil time.Time
+	for i := 0; i < len(timed)-1; i++ {
+		if !timed[i].HasEnd || timed[i].IsCompleted {
+			continue
+		}
+		if end := itemEnd(timed[i]); end.After(busyUntil) {
+			busyUntil = end
+		}
+		if timed[i+1].IsCompleted {
+			continue
+		}
+		nextStart := timed[i+1].Date
+		if gap := nextStart.Sub(busyUntil); gap >= 15*time.Minute {
+			gapAfter[i] = gap
+			for h := busyUntil.Hour(); h < nextStart.Hour(); h++ {
+				if _, occupied := hourItems[h]; !occupied {
+					hoursInGap[h] = true
+				}
+			}
+		}
+	}
+	return gapAfter, hoursInGap
+}
+
+// itemEnd returns when a timed item ends. A recurring task's items all carry
+// the end time of its first occurrence, so for tasks only the time of day is
+// taken; events carry their own.
+func itemEnd(item task.AgendaItem) time.Time {
+	if item.Event != nil {
+		return item.EndTime
+	}
+	d := item.Date
+	return time.Date(d.Year(), d.Month(), d.Day(), item.EndTime.Hour(), item.EndTime.Minute(), 0, 0, d.Location())
+}
+
 func formatGapDuration(d time.Duration) string {
 	h := int(d.Hours())
 	m := int(d.Minutes()) % 60
@@ -1854,6 +2233,14 @@ func (m model) formatScheduleInfo(item task.AgendaItem) string {
 		return "Done " + item.CompletedAt.Format("15:04")
 	}
 
+	if item.Event != nil && !item.HasTime {
+		return "All day"
+	}
+
+	if item.Span != nil {
+		return spanBar(item)
+	}
+
 	if item.IsOverdue {
 		daysAgo := int(math.Round(today.Sub(itemDay).Hours() / 24))
 		if daysAgo == 1 {
@@ -1885,7 +2272,11 @@ func (m model) formatScheduleInfo(item task.AgendaItem) string {
 			count := 0
 			current := orig
 			for current.Before(item.Date) {
-				current = task.AddInterval(current, r.Interval, r.Unit)
+				next, ok := r.Next(current)
+				if !ok {
+					break
+				}
+				current = next
 				count++
 			}
 			if count > 1 {
@@ -1901,6 +2292,26 @@ func (m model) formatScheduleInfo(item task.AgendaItem) string {
 	return item.Date.Format("Jan 02")
 }
 
+// spanBar draws where an item's day falls in its range spanning days, one
+// cell per day (scaled down for long ranges), followed on the first and last
+// day of a timed range by its start and end time. Listed under consecutive
+// days, the bars line up into one.
+func spanBar(item task.AgendaItem) string {
+	span := item.Span
+	width := min(span.Days, 7)
+	pos := (span.Day - 1) * width / span.Days
+	bar := strings.Repeat("━", pos) + "█" + strings.Repeat("━", width-pos-1)
+	if item.Schedule != nil && item.Schedule.HasTime {
+		switch span.Day {
+		case 1:
+			return bar + " " + span.Start.Format("15:04")
+		case span.Days:
+			return bar + " →" + span.End.Format("15:04")
+		}
+	}
+	return bar
+}
+
 func (m model) renderClockResolveView() string {
 	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")).
 		Render("Multiple active clocks detected")
@@ -2045,7 +2456,10 @@ func (m model) renderClockView() string {
 		for i := 1; i < contentHeight-1; i++ {
 			b.WriteString("\n")
 		}
-		footer := colors.dimText.Render("t: status • i/o: in/out • v: detail • enter: edit • esc/a: agenda • ?: help • q: quit")
+		if status := m.pomodoroStatus(); status != "" {
+			b.WriteString(status + "\n")
+		}
+		footer := colors.dimText.Render("t: status • i/o: in/out • p: pomodoro • v: detail • enter: edit • esc/a: agenda • ?: help • q: quit")
 		b.WriteString(footer)
 		return b.String()
 	}
@@ -2055,10 +2469,49 @@ func (m model) renderClockView() string {
 	timeColWidth := 7
 	kwColWidth := 9
 	treeWidth := 3 // "╰─ "
-	titleWidth := m.termWidth - projColWidth - treeWidth - kwColWidth - timeColWidth - 4
+	// Clocked/estimate column, only shown when some task has an estimate
+	budgetColWidth := 0
+	for _, proj := range m.clockTable.Projects {
+		if proj.Estimate > 0 {
+			budgetColWidth = 14
+			break
+		}
+	}
+	// Pomodoro column, only shown when some task has completed pomodoros
+	pomodoroColWidth := 0
+	for _, proj := range m.clockTable.Projects {
+		if proj.Pomodoros > 0 {
+			pomodoroColWidth = 6
+			break
+		}
+	}
+	titleWidth := m.termWidth - projColWidth - treeWidth - kwColWidth - timeColWidth - budgetColWidth - pomodoroColWidth - 4
 	if titleWidth < 20 {
 		titleWidth = 20
 	}
+	pomodoros := func(n int) string {
+		if pomodoroColWidth == 0 {
+			return ""
+		}
+		if n == 0 {
+			return strings.Repeat(" ", pomodoroColWidth)
+		}
+		// 🍅 is two cells wide
+		return colors.dimText.Render(fmt.Sprintf("%*d🍅", pomodoroColWidth-2, n))
+	}
+	budget := func(actual, estimate time.Duration, over bool) string {
+		if budgetColWidth == 0 {
+			return ""
+		}
+		if estimate == 0 {
+			return strings.Repeat(" ", budgetColWidth)
+		}
+		s := fmt.Sprintf("%*s", budgetColWidth, task.FormatDuration(actual)+"/"+task.FormatDuration(estimate))
+		if over {
+			return colors.overdue.Render(s)
+		}
+		return colors.dimText.Render(s)
+	}
 
 	var lines []string
 	cursorIdx := 0
@@ -2086,7 +2539,7 @@ func (m model) renderClockView() string {
 			projColWidth, proj.Project+":",
 			treeWidth+kwColWidth+titleWidth, "Project time",
 			timeColWidth, task.FormatDuration(proj.Total))
-		lines = append(lines, colors.project.Render(projLine))
+		lines = append(lines, colors.project.Render(projLine)+pomodoros(proj.Pomodoros)+budget(proj.Actual, proj.Estimate, proj.OverBudget()))
 
 		// Task entries
 		for _, entry := range proj.Entries {
@@ -2135,13 +2588,15 @@ func (m model) renderClockView() string {
 				durationStr = fmt.Sprintf("%*s", timeColWidth, durationStr)
 			}
 
-			taskLine := fmt.Sprintf("%s%-*s %s %s %-*s %s",
+			taskLine := fmt.Sprintf("%s%-*s %s %s %-*s %s%s%s",
 				indicator,
 				projColWidth, "",
 				colors.dimText.Render("╰─"),
 				kwStyle.Render(fmt.Sprintf("%-*s", kwColWidth-1, displayKeyword)),
 				titleWidth, formattedTitle,
-				durationStr)
+				durationStr,
+				pomodoros(entry.Pomodoros),
+				budget(entry.Actual, entry.Estimate, entry.OverBudget()))
 			lines = append(lines, taskLine)
 			cursorIdx++
 		}
@@ -2171,7 +2626,10 @@ func (m model) renderClockView() string {
 	}
 
 	// Footer
-	footer := colors.dimText.Render("t: status • i/o: in/out • v: detail • enter: edit • esc/a: agenda • ?: help • q: quit")
+	if status := m.pomodoroStatus(); status != "" {
+		b.WriteString(status + "\n")
+	}
+	footer := colors.dimText.Render("t: status • i/o: in/out • p: pomodoro • v: detail • enter: edit • esc/a: agenda • ?: help • q: quit")
 	b.WriteString(footer)
 
 	return b.String()
@@ -2190,7 +2648,8 @@ func setupWatcher(cfg *config.Config) *fsnotify.Watcher {
 	entries, err := os.ReadDir(prjDir)
 	if err == nil {
 		for _, e := range entries {
-			if e.IsDir() {
+			// Hidden directories (.git, the task index cache) never hold tasks
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
 				projectDir := filepath.Join(prjDir, e.Name())
 				notesDir := filepath.Join(projectDir, "notes")
 				watcher.Add(projectDir)
@@ -2210,6 +2669,14 @@ func setupWatcher(cfg *config.Config) *fsnotify.Watcher {
 		watcher.Add(filepath.Dir(inboxPath))
 	}
 
+	// Calendar files are rewritten by the tools that sync them
+	for _, path := range cfg.Schedule.Calendars {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			path = filepath.Dir(path)
+		}
+		watcher.Add(path)
+	}
+
 	return watcher
 }
 
@@ -2228,7 +2695,29 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "notify" {
+		if err := runNotify(cfg, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if args := os.Args[1:]; len(args) > 0 {
+		export := exportAgenda
+		if args[0] == "export" {
+			export, args = exportICS, args[1:]
+		}
+		if err := export(cfg, args); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	initColors(cfg)
+	task.EnableJournal(cfg)
+	task.UseTimeZone(cfg)
 
 	watcher := setupWatcher(cfg)
 	if watcher != nil {
@@ -2238,8 +2727,16 @@ func main() {
 	m := initialModel(cfg)
 	m.watcher = watcher
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	finalModel, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Clock out of a pomodoro left running
+	if fm, ok := finalModel.(model); ok {
+		if err := fm.pomodoro.Stop(time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Pomodoro: %v\n", err)
+		}
+	}
 }
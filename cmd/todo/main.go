@@ -8,10 +8,14 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	colorspkg "github.com/vinayprograms/karya/internal/colors"
@@ -45,8 +49,10 @@ type ColorScheme struct {
 	todayDateColor       lipgloss.Style
 	assigneeColor        lipgloss.Style
 	cycleColor           lipgloss.Style
+	priorityColor        lipgloss.Style // [#A]/[#B]/[#C] priority cookie badge
 	childConnectorColor  lipgloss.Style // ⌊ connector for child tasks
 	pendingChildColor    lipgloss.Style // ◑ indicator for parents with pending children
+	blockedColor         lipgloss.Style // ⊘ marker for tasks waiting on an open dependency
 }
 
 // Global color scheme (will be initialized from config)
@@ -69,8 +75,10 @@ func InitializeColors(cfg *configpkg.Config) {
 		todayDateColor:     lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.TodayDateColor)).Background(lipgloss.Color(cfg.Colors.TodayDateBgColor)).Bold(true),
 		assigneeColor:       lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.AssigneeColor)).Background(lipgloss.Color(cfg.Colors.AssigneeBgColor)).Bold(true),
 		cycleColor:          lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.CycleColor)).Background(lipgloss.Color(cfg.Colors.CycleBgColor)).Bold(true),
+		priorityColor:       lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.PriorityColor)).Background(lipgloss.Color(cfg.Colors.PriorityBgColor)).Bold(true),
 		childConnectorColor: lipgloss.NewStyle().Foreground(lipgloss.Color("8")),
 		pendingChildColor:   lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.InProgressColor)),
+		blockedColor:        lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Colors.PastDateColor)).Bold(true),
 	}
 }
 
@@ -162,6 +170,12 @@ func (i taskItem) renderWithSelection(isSelected bool) string {
 
 	// Render task title with markdown formatting, then truncate (no padding)
 	formattedTitle := task.RenderMarkdownDescription(displayTitle, titleStyle)
+	if i.task.PriorityCookie != "" {
+		formattedTitle = colors.priorityColor.Render(" "+i.task.PriorityCookie+" ") + " " + formattedTitle
+	}
+	if i.task.Blocked {
+		formattedTitle = colors.blockedColor.Render("⊘") + " " + formattedTitle
+	}
 	titleWidth := i.maxTitleWidth
 	if titleWidth <= 0 {
 		titleWidth = 40
@@ -287,6 +301,12 @@ func (i taskItem) Title() string {
 
 	// Render task title with markdown formatting, then truncate (no padding)
 	formattedTitle := task.RenderMarkdownDescription(displayTitle, titleStyle)
+	if i.task.PriorityCookie != "" {
+		formattedTitle = colors.priorityColor.Render(" "+i.task.PriorityCookie+" ") + " " + formattedTitle
+	}
+	if i.task.Blocked {
+		formattedTitle = colors.blockedColor.Render("⊘") + " " + formattedTitle
+	}
 	titleWidth := i.maxTitleWidth
 	if titleWidth <= 0 {
 		titleWidth = 40
@@ -420,6 +440,7 @@ type model struct {
 	customFilter    string
 	filterCursor    int
 	filtering       bool
+	filterErr       string // Parse error in customFilter, shown instead of the match count
 	allItems        []list.Item
 	structuredMode  bool
 	loading         bool
@@ -443,6 +464,32 @@ type model struct {
 	showingDatePicker bool
 	datePicker        *task.DatePicker
 
+	// Saved view state
+	showingViewPicker bool
+	viewPicker        *task.ViewPicker
+	activeView        *configpkg.View
+
+	// Property prompt state
+	showingPropertyPrompt bool
+	propertyInput         string
+	propertyErr           string
+
+	// Line edit prompt state (title, tag or assignee)
+	showingEditPrompt bool
+	editField         string
+	editInput         string
+
+	// Add task form state
+	showingAddForm bool
+	addForm        *task.AddForm
+
+	// Refile picker state
+	showingRefilePicker bool
+	refilePicker        *task.RefilePicker
+
+	// Pomodoro timer, started with p
+	pomodoro task.PomodoroSession
+
 	// Terminal dimensions
 	termWidth  int
 	termHeight int
@@ -465,6 +512,23 @@ func (m model) calcMaxTitleWidth() int {
 	return available
 }
 
+// showCompleted reports whether completed tasks should be loaded, either
+// because of the global setting or because the active view asks for them.
+func (m model) showCompleted() bool {
+	return m.config.Todo.ShowCompleted || (m.activeView != nil && m.activeView.ShowCompleted)
+}
+
+// listTitle returns the list title for the current mode and view.
+func (m model) listTitle() string {
+	if m.activeView != nil {
+		return "View: " + m.activeView.Name
+	}
+	if m.structuredMode {
+		return "Tasks (Zettelkasten)"
+	}
+	return "Tasks (All)"
+}
+
 func (m model) Init() tea.Cmd {
 	cmds := []tea.Cmd{waitForFileChange(m.watcher)}
 	if m.config.HasJira() {
@@ -537,6 +601,13 @@ type statusUpdateMsg struct {
 
 type clearStatusMsg struct{}
 
+// pomodoroTickMsg advances the pomodoro timer of generation gen.
+type pomodoroTickMsg struct{ gen int }
+
+func pomodoroTickCmd(gen int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return pomodoroTickMsg{gen} })
+}
+
 func waitForFileChange(watcher *fsnotify.Watcher) tea.Cmd {
 	return func() tea.Msg {
 		if watcher == nil {
@@ -572,6 +643,11 @@ func waitForFileChange(watcher *fsnotify.Watcher) tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Advance the pomodoro timer regardless of UI state
+	if msg, ok := msg.(pomodoroTickMsg); ok {
+		return m.tickPomodoro(msg.gen)
+	}
+
 	// Handle pending-child warning — informational only, completion is
 	// blocked outright (see task.ErrPendingChildren), so there's no override.
 	if m.showingPendingChildWarning {
@@ -716,6 +792,248 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Handle view picker mode
+	if m.showingViewPicker {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			if msg.String() == "ctrl+c" {
+				m.quitting = true
+				if m.watcher != nil {
+					m.watcher.Close()
+				}
+				return m, tea.Quit
+			}
+			m.viewPicker.Update(msg.String())
+			if m.viewPicker.Cancelled {
+				m.showingViewPicker = false
+				m.viewPicker = nil
+				return m, nil
+			}
+			if m.viewPicker.Confirmed {
+				m.showingViewPicker = false
+				m.activeView = m.viewPicker.Selected
+				m.viewPicker = nil
+				m.filtering = false
+				m.customFilter = ""
+				if m.activeView != nil {
+					m.customFilter = m.activeView.Filter
+				}
+				m.list.Title = m.listTitle()
+				// Reload: the view may include completed tasks
+				return m, reloadTasksCmd()
+			}
+			return m, nil
+		case tea.WindowSizeMsg:
+			m.termWidth = msg.Width
+			m.termHeight = msg.Height
+			return m, nil
+		case fileChangedMsg:
+			return m, waitForFileChange(m.watcher)
+		}
+		return m, nil
+	}
+
+	// Handle refile picker mode
+	if m.showingRefilePicker {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			if msg.String() == "ctrl+c" {
+				m.quitting = true
+				if m.watcher != nil {
+					m.watcher.Close()
+				}
+				return m, tea.Quit
+			}
+			m.refilePicker.Update(msg.String())
+			if m.refilePicker.Cancelled {
+				m.showingRefilePicker = false
+				m.refilePicker = nil
+				m.selectedTask = nil
+				return m, nil
+			}
+			if m.refilePicker.Confirmed {
+				return m, refileCmd(m.config, m.selectedTask, *m.refilePicker.Selected)
+			}
+			return m, nil
+		case statusUpdateMsg:
+			m.showingRefilePicker = false
+			m.refilePicker = nil
+			m.selectedTask = nil
+			if msg.err != nil {
+				m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			} else {
+				m.statusMessage = msg.message
+			}
+			return m, tea.Batch(
+				tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} }),
+				waitForFileChange(m.watcher),
+			)
+		case tea.WindowSizeMsg:
+			m.termWidth = msg.Width
+			m.termHeight = msg.Height
+			return m, nil
+		case fileChangedMsg:
+			return m, waitForFileChange(m.watcher)
+		}
+		return m, nil
+	}
+
+	// Handle add task form mode
+	if m.showingAddForm {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			if msg.String() == "ctrl+c" {
+				m.quitting = true
+				if m.watcher != nil {
+					m.watcher.Close()
+				}
+				return m, tea.Quit
+			}
+			m.addForm.Update(msg.String())
+			if m.addForm.Cancelled {
+				m.showingAddForm = false
+				m.addForm = nil
+				return m, nil
+			}
+			if m.addForm.Confirmed {
+				return m, addTaskCmd(m.config, m.addForm.Project(), m.addForm.Line())
+			}
+			return m, nil
+		case statusUpdateMsg:
+			m.showingAddForm = false
+			m.addForm = nil
+			if msg.err != nil {
+				m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			} else {
+				m.statusMessage = msg.message
+			}
+			return m, tea.Batch(
+				tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} }),
+				waitForFileChange(m.watcher),
+			)
+		case tea.WindowSizeMsg:
+			m.termWidth = msg.Width
+			m.termHeight = msg.Height
+			return m, nil
+		case fileChangedMsg:
+			return m, waitForFileChange(m.watcher)
+		}
+		return m, nil
+	}
+
+	// Handle property prompt mode
+	if m.showingPropertyPrompt {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			switch msg.String() {
+			case "ctrl+c":
+				m.quitting = true
+				if m.watcher != nil {
+					m.watcher.Close()
+				}
+				return m, tea.Quit
+			case "esc":
+				m.showingPropertyPrompt = false
+				m.propertyInput = ""
+				m.propertyErr = ""
+				m.selectedTask = nil
+				return m, nil
+			case "enter":
+				key, value, err := task.ParsePropertyAssignment(m.propertyInput)
+				if err != nil {
+					m.propertyErr = err.Error()
+					return m, nil
+				}
+				return m, setPropertyCmd(m.selectedTask, key, value)
+			case "backspace":
+				if runes := []rune(m.propertyInput); len(runes) > 0 {
+					m.propertyInput = string(runes[:len(runes)-1])
+				}
+				m.propertyErr = ""
+				return m, nil
+			default:
+				if len(msg.Runes) > 0 && msg.Runes[0] >= 32 && msg.Runes[0] <= 126 {
+					m.propertyInput += string(msg.Runes)
+					m.propertyErr = ""
+				}
+				return m, nil
+			}
+		case statusUpdateMsg:
+			m.showingPropertyPrompt = false
+			m.propertyInput = ""
+			m.propertyErr = ""
+			m.selectedTask = nil
+			if msg.err != nil {
+				m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			} else {
+				m.statusMessage = msg.message
+			}
+			return m, tea.Batch(
+				tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} }),
+				waitForFileChange(m.watcher),
+			)
+		case tea.WindowSizeMsg:
+			m.termWidth = msg.Width
+			m.termHeight = msg.Height
+			return m, nil
+		case fileChangedMsg:
+			return m, waitForFileChange(m.watcher)
+		}
+		return m, nil
+	}
+
+	// Handle line edit prompt mode
+	if m.showingEditPrompt {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			switch msg.String() {
+			case "ctrl+c":
+				m.quitting = true
+				if m.watcher != nil {
+					m.watcher.Close()
+				}
+				return m, tea.Quit
+			case "esc":
+				m.showingEditPrompt = false
+				m.editInput = ""
+				m.selectedTask = nil
+				return m, nil
+			case "enter":
+				return m, editLineCmd(m.selectedTask, m.editField, strings.TrimSpace(m.editInput))
+			case "backspace":
+				if runes := []rune(m.editInput); len(runes) > 0 {
+					m.editInput = string(runes[:len(runes)-1])
+				}
+				return m, nil
+			default:
+				if len(msg.Runes) > 0 && msg.Runes[0] >= 32 && msg.Runes[0] <= 126 {
+					m.editInput += string(msg.Runes)
+				}
+				return m, nil
+			}
+		case statusUpdateMsg:
+			m.showingEditPrompt = false
+			m.editInput = ""
+			m.selectedTask = nil
+			if msg.err != nil {
+				m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			} else {
+				m.statusMessage = msg.message
+			}
+			return m, tea.Batch(
+				tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} }),
+				waitForFileChange(m.watcher),
+			)
+		case tea.WindowSizeMsg:
+			m.termWidth = msg.Width
+			m.termHeight = msg.Height
+			return m, nil
+		case fileChangedMsg:
+			return m, waitForFileChange(m.watcher)
+		}
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Handle quit keys before list processes them
@@ -735,6 +1053,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.filtering = false
 				m.customFilter = ""
 				m.filterCursor = 0
+				if m.activeView != nil {
+					m.activeView = nil
+					m.list.Title = m.listTitle()
+					return m, reloadTasksCmd()
+				}
 				m.list.SetItems(m.allItems)
 				return m, nil
 			case "enter":
@@ -785,6 +1108,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			// Handle esc: quit if not filtering
 			if msg.String() == "esc" {
+				if m.activeView != nil {
+					// Leave the view along with its filter
+					m.activeView = nil
+					m.customFilter = ""
+					m.list.Title = m.listTitle()
+					return m, reloadTasksCmd()
+				}
 				if m.customFilter != "" {
 					// Clear filter
 					m.customFilter = ""
@@ -819,26 +1149,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			// Switch to structured mode
+			// Toggle structured/unstructured mode
 			if msg.String() == "s" {
-				if !m.structuredMode {
-					m.structuredMode = true
-					m.config.Todo.Structured = true
-					m.list.Title = "Tasks (Zettelkasten)"
-					return m, reloadTasksCmd()
-				}
-				return m, nil
+				m.structuredMode = !m.structuredMode
+				m.config.Todo.Structured = m.structuredMode
+				m.list.Title = m.listTitle()
+				return m, reloadTasksCmd()
 			}
 
-			// Switch to unstructured mode
+			// Undo / redo the last task file edit
 			if msg.String() == "u" {
-				if m.structuredMode {
-					m.structuredMode = false
-					m.config.Todo.Structured = false
-					m.list.Title = "Tasks (All)"
-					return m, reloadTasksCmd()
-				}
-				return m, nil
+				return m, undoCmd(m.config, false)
+			}
+			if msg.String() == "ctrl+r" {
+				return m, undoCmd(m.config, true)
 			}
 
 			switch msg.String() {
@@ -903,11 +1227,95 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return m, clockOutCmd(i.task)
 					}
 				}
+			case "p":
+				// Start a pomodoro on the current task, or stop the running one
+				if !m.filtering {
+					if m.pomodoro.Running() {
+						return m.stopPomodoro()
+					}
+					if i, ok := m.list.SelectedItem().(taskItem); ok {
+						return m.startPomodoro(i.task)
+					}
+				}
+			case "+", "-":
+				// Raise or lower the priority cookie of the current task
+				if !m.filtering {
+					if i, ok := m.list.SelectedItem().(taskItem); ok {
+						return m, shiftPriorityCmd(i.task, msg.String() == "+")
+					}
+				}
+			case "P":
+				// Prompt for a property to set on the current task
+				if !m.filtering {
+					if i, ok := m.list.SelectedItem().(taskItem); ok {
+						m.selectedTask = i.task
+						m.propertyInput = ""
+						m.propertyErr = ""
+						m.showingPropertyPrompt = true
+						return m, nil
+					}
+				}
+			case "e", "#", ">":
+				// Prompt to retitle the current task, toggle a tag or set its assignee
+				if !m.filtering {
+					if i, ok := m.list.SelectedItem().(taskItem); ok {
+						m.selectedTask = i.task
+						switch msg.String() {
+						case "e":
+							m.editField, m.editInput = "title", i.task.Title
+						case "#":
+							m.editField, m.editInput = "tag", ""
+						case ">":
+							m.editField, m.editInput = "assignee", i.task.Assignee
+						}
+						m.showingEditPrompt = true
+						return m, nil
+					}
+				}
+			case "r":
+				// Refile the current task to another project, note or the inbox
+				if !m.filtering {
+					if i, ok := m.list.SelectedItem().(taskItem); ok {
+						targets, err := task.ListRefileTargets(m.config)
+						if err != nil {
+							m.statusMessage = fmt.Sprintf("Error: %v", err)
+							return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} })
+						}
+						m.selectedTask = i.task
+						m.refilePicker = task.NewRefilePicker(i.task, targets)
+						m.showingRefilePicker = true
+						return m, nil
+					}
+				}
+			case "a":
+				// Open the add task form
+				if !m.filtering {
+					projects, err := task.ListProjectNames(m.config)
+					if err != nil {
+						m.statusMessage = fmt.Sprintf("Error: %v", err)
+						return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} })
+					}
+					// Default to the TUI's project, else the selected task's
+					project := m.project
+					if i, ok := m.list.SelectedItem().(taskItem); ok && project == "" {
+						project = i.task.Project
+					}
+					m.addForm = task.NewAddForm(m.config, projects, project)
+					m.showingAddForm = true
+					return m, nil
+				}
+			case "V":
+				// Open saved view switcher
+				if !m.filtering {
+					m.viewPicker = task.NewViewPicker(m.config, m.activeView)
+					m.showingViewPicker = true
+					return m, nil
+				}
 			}
 		}
 	case fileChangedMsg:
 		// Reload tasks when files change
-		tasks, err := task.ListTasks(m.config, m.project, m.config.Todo.ShowCompleted)
+		tasks, err := task.ListTasks(m.config, m.project, m.showCompleted())
 		if err == nil {
 			task.DetectCycles(tasks)
 			// Save current selection to restore after update
@@ -923,6 +1331,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				task.SortByPriority(m.tasks, m.config)
 				sort.SliceStable(m.tasks, func(i, j int) bool {
 					if m.tasks[i].Priority(m.config) == m.tasks[j].Priority(m.config) {
+						if m.tasks[i].CookieRank() != m.tasks[j].CookieRank() {
+							return m.tasks[i].CookieRank() < m.tasks[j].CookieRank()
+						}
 						if m.tasks[i].Project != m.tasks[j].Project {
 							return m.tasks[i].Project < m.tasks[j].Project
 						}
@@ -942,7 +1353,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					items[i] = NewTaskItem(m.config, t, m.projectColWidth, m.keywordColWidth, m.fractionColWidth, m.calcMaxTitleWidth(), m.config.GeneralConfig.Verbose)
 				}
 				m.allItems = items
-				if m.customFilter != "" {
+				if m.customFilter != "" || m.activeView != nil {
 					m.applyCustomFilter()
 				} else {
 					m.list.SetItems(items)
@@ -959,7 +1370,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					items[i] = NewTaskItem(m.config, t, m.projectColWidth, m.keywordColWidth, m.fractionColWidth, m.calcMaxTitleWidth(), m.config.GeneralConfig.Verbose)
 				}
 				m.allItems = items
-				if m.customFilter != "" {
+				if m.customFilter != "" || m.activeView != nil {
 					m.applyCustomFilter()
 				} else {
 					m.list.SetItems(items)
@@ -989,7 +1400,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case loadingStartMsg:
 		// Start loading
 		m.loading = true
-		return m, loadTasksCmd(m.config, m.project)
+		return m, loadTasksCmd(m.config, m.project, m.showCompleted())
 	case loadingDoneMsg:
 		// Finish loading
 		m.loading = false
@@ -1000,6 +1411,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Secondary sort by project, then title, then file path for deterministic order
 			sort.SliceStable(m.tasks, func(i, j int) bool {
 				if m.tasks[i].Priority(m.config) == m.tasks[j].Priority(m.config) {
+					if m.tasks[i].CookieRank() != m.tasks[j].CookieRank() {
+						return m.tasks[i].CookieRank() < m.tasks[j].CookieRank()
+					}
 					if m.tasks[i].Project != m.tasks[j].Project {
 						return m.tasks[i].Project < m.tasks[j].Project
 					}
@@ -1039,7 +1453,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Reload tasks after editing (including inbox)
-		tasks, err := task.ListTasks(m.config, m.project, m.config.Todo.ShowCompleted)
+		tasks, err := task.ListTasks(m.config, m.project, m.showCompleted())
 		if err != nil {
 			return m, tea.Quit
 		}
@@ -1051,6 +1465,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			task.SortByPriority(m.tasks, m.config)
 			sort.SliceStable(m.tasks, func(i, j int) bool {
 				if m.tasks[i].Priority(m.config) == m.tasks[j].Priority(m.config) {
+					if m.tasks[i].CookieRank() != m.tasks[j].CookieRank() {
+						return m.tasks[i].CookieRank() < m.tasks[j].CookieRank()
+					}
 					if m.tasks[i].Project != m.tasks[j].Project {
 						return m.tasks[i].Project < m.tasks[j].Project
 					}
@@ -1077,7 +1494,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.allItems = items
 
 		// Restore previous filter if there was one
-		if m.savedFilter != "" {
+		if m.savedFilter != "" || m.activeView != nil {
 			m.customFilter = m.savedFilter
 			m.applyCustomFilter()
 		} else {
@@ -1134,7 +1551,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) applyCustomFilter() {
-	if m.customFilter == "" {
+	m.filterErr = ""
+	if m.customFilter == "" && m.activeView == nil {
 		m.list.SetItems(m.allItems)
 		m.searchTerm = ""  // Clear search term
 		return
@@ -1190,6 +1608,36 @@ func (m *model) applyCustomFilter() {
 		}
 	}
 
+	// A saved view applies its own completed handling, sort and grouping;
+	// the filter box can refine it further
+	if m.activeView != nil {
+		v := *m.activeView
+		v.Filter = m.customFilter
+		groups, err := task.ApplyView(m.config, v, allTasks)
+		if err != nil {
+			m.filterErr = err.Error()
+			m.list.SetItems([]list.Item{list.Item(&noResultsItem{})})
+			return
+		}
+		// Tasks can appear in several groups (group_by = "tag"); list each once
+		var viewItems []list.Item
+		seen := make(map[*task.Task]bool)
+		for _, g := range groups {
+			for _, t := range g.Tasks {
+				if item, exists := itemToTask[t]; exists && !seen[t] {
+					seen[t] = true
+					viewItems = append(viewItems, item)
+				}
+			}
+		}
+		if len(viewItems) == 0 {
+			m.list.SetItems([]list.Item{list.Item(&noResultsItem{})})
+		} else {
+			m.list.SetItems(viewItems)
+		}
+		return
+	}
+
 	// For date filters, exclude completed tasks unless SHOW_COMPLETED is set
 	filterInput := allTasks
 	if strings.HasPrefix(m.customFilter, "@") && !m.config.Todo.ShowCompleted {
@@ -1201,8 +1649,14 @@ func (m *model) applyCustomFilter() {
 		}
 	}
 
-	// Apply field-specific filtering
-	filteredTasks := task.FilterTasks(filterInput, m.customFilter)
+	// Apply the filter query
+	filteredTasks, err := task.QueryTasks(m.config, filterInput, m.customFilter)
+	if err != nil {
+		m.filterErr = err.Error()
+		m.list.SetItems([]list.Item{list.Item(&noResultsItem{})})
+		return
+	}
+	m.filterErr = ""
 
 	// Convert back to list items
 	var filteredItems []list.Item
@@ -1244,6 +1698,31 @@ func (m model) View() string {
 		return m.renderStatusSelector()
 	}
 
+	// Show view picker overlay if active
+	if m.showingViewPicker && m.viewPicker != nil {
+		return m.viewPicker.View(m.termWidth)
+	}
+
+	// Show property prompt overlay if active
+	if m.showingPropertyPrompt && m.selectedTask != nil {
+		return m.renderPropertyPrompt()
+	}
+
+	// Show line edit prompt overlay if active
+	if m.showingEditPrompt && m.selectedTask != nil {
+		return m.renderEditPrompt()
+	}
+
+	// Show refile picker overlay if active
+	if m.showingRefilePicker && m.refilePicker != nil {
+		return m.refilePicker.View(m.termWidth)
+	}
+
+	// Show add task form overlay if active
+	if m.showingAddForm && m.addForm != nil {
+		return m.addForm.View(m.termWidth)
+	}
+
 	view := m.list.View()
 
 	// Add custom pagination/count info at the top
@@ -1266,10 +1745,15 @@ func (m model) View() string {
 		} else if m.customFilter != "" {
 			paginationText = fmt.Sprintf("%d matches", totalItems)
 		}
+		paginationColor := lipgloss.Color("240")
+		if m.filterErr != "" {
+			paginationText = "Invalid filter: " + m.filterErr
+			paginationColor = lipgloss.Color("9")
+		}
 
 		if paginationText != "" {
 			paginationInfo := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("240")).
+				Foreground(paginationColor).
 				Render(paginationText)
 
 			lines := strings.Split(view, "\n")
@@ -1356,6 +1840,17 @@ func (m model) View() string {
 		view = strings.Join(lines, "\n")
 	}
 
+	// Show the running pomodoro ahead of the help bar
+	if status := m.pomodoro.Status(time.Now()); status != "" {
+		lines := strings.Split(view, "\n")
+		status = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("13")).
+			Bold(true).
+			Render(status)
+		lines[len(lines)-1] = ansi.Truncate(status+"  "+lines[len(lines)-1], m.termWidth, "")
+		view = strings.Join(lines, "\n")
+	}
+
 	return view
 }
 
@@ -1397,6 +1892,76 @@ func (m model) renderPendingChildWarning() string {
 	return boxStyle.Render(content.String())
 }
 
+// renderPropertyPrompt renders the "key:: value" input for the selected task,
+// listing the properties it already has.
+func (m model) renderPropertyPrompt() string {
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+	t := m.selectedTask
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("Set property: " + t.Title))
+	content.WriteString("\n\n")
+
+	for _, k := range t.PropertyKeys() {
+		content.WriteString(dimStyle.Render(fmt.Sprintf("%s:: %s", k, t.Properties[k])))
+		content.WriteString("\n")
+	}
+	if len(t.Properties) > 0 {
+		content.WriteString("\n")
+	}
+
+	content.WriteString("> " + m.propertyInput + "▓")
+	content.WriteString("\n")
+	if m.propertyErr != "" {
+		content.WriteString(errStyle.Render(m.propertyErr))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("key:: value • key:: (empty) removes • enter: save • esc: cancel"))
+
+	return boxStyle.Render(content.String())
+}
+
+// renderEditPrompt renders the input for the selected task's new title, a
+// tag to toggle or its new assignee.
+func (m model) renderEditPrompt() string {
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	t := m.selectedTask
+	header, hint := "Retitle: ", "enter: save • esc: cancel"
+	switch m.editField {
+	case "tag":
+		header, hint = "Toggle tag: ", "adds the tag, or removes it if present • enter: save • esc: cancel"
+	case "assignee":
+		header, hint = "Assign: ", "empty removes the assignee • enter: save • esc: cancel"
+	}
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render(header + t.Title))
+	content.WriteString("\n\n")
+	if m.editField == "tag" && len(t.Tags) > 0 {
+		content.WriteString(dimStyle.Render("#" + strings.Join(t.Tags, " #")))
+		content.WriteString("\n\n")
+	}
+	content.WriteString("> " + m.editInput + "▓")
+	content.WriteString("\n\n")
+	content.WriteString(dimStyle.Render(hint))
+
+	return boxStyle.Render(content.String())
+}
+
 // renderDetailView renders the task detail overlay showing full raw content from file
 func (m model) renderDetailView() string {
 	if m.selectedTask == nil {
@@ -1456,6 +2021,15 @@ func (m model) renderDetailView() string {
 	// Metadata footer
 	metaStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	wrapped = append(wrapped, "")
+	if est := t.EstimateDuration(); est > 0 {
+		clocked := task.ClockedTotal(t)
+		budget := fmt.Sprintf("Estimate %s • Clocked %s", task.FormatDuration(est), task.FormatDuration(clocked))
+		if clocked > est {
+			wrapped = append(wrapped, colors.pastDateColor.Render(fmt.Sprintf("%s • over by %s", budget, task.FormatDuration(clocked-est))))
+		} else {
+			wrapped = append(wrapped, metaStyle.Render(fmt.Sprintf("%s • %s left", budget, task.FormatDuration(est-clocked))))
+		}
+	}
 	wrapped = append(wrapped, metaStyle.Render(fmt.Sprintf("── %s", t.FilePath)))
 
 	// Help
@@ -1579,6 +2153,122 @@ func applyDatePickerCmd(_ *configpkg.Config, dp *task.DatePicker) tea.Cmd {
 	}
 }
 
+func shiftPriorityCmd(t *task.Task, raise bool) tea.Cmd {
+	return func() tea.Msg {
+		cookie := task.ShiftPriority(t.PriorityCookie, raise)
+		if cookie == t.PriorityCookie {
+			return statusUpdateMsg{message: fmt.Sprintf("Priority already [#%s]", cookie)}
+		}
+		if err := task.SetTaskPriority(t, cookie); err != nil {
+			return statusUpdateMsg{err: err}
+		}
+
+		commitMsg := fmt.Sprintf("Set task priority: [#%s] %s", cookie, t.Title)
+		kgit.CommitFile(t.FilePath, commitMsg, true)
+
+		return statusUpdateMsg{message: fmt.Sprintf("Priority: [#%s]", cookie)}
+	}
+}
+
+func setPropertyCmd(t *task.Task, key, value string) tea.Cmd {
+	return func() tea.Msg {
+		if err := task.SetTaskProperty(t, key, value); err != nil {
+			return statusUpdateMsg{err: err}
+		}
+
+		if value == "" {
+			kgit.CommitFile(t.FilePath, fmt.Sprintf("Remove task property: %s (%s)", key, t.Title), true)
+			return statusUpdateMsg{message: fmt.Sprintf("Removed %s", key)}
+		}
+		kgit.CommitFile(t.FilePath, fmt.Sprintf("Set task property: %s:: %s (%s)", key, value, t.Title), true)
+		return statusUpdateMsg{message: fmt.Sprintf("%s:: %s", key, value)}
+	}
+}
+
+// editLineCmd applies the line edit prompt's input to the task: field is
+// "title", "tag" (toggled) or "assignee" (empty removes it).
+func editLineCmd(t *task.Task, field, input string) tea.Cmd {
+	return func() tea.Msg {
+		var edit task.LineEdit
+		switch field {
+		case "title":
+			edit.Title = input
+		case "tag":
+			tag := strings.TrimPrefix(input, "#")
+			if slices.Contains(t.Tags, tag) {
+				edit.RemoveTags = []string{tag}
+			} else if tag != "" {
+				edit.AddTags = []string{tag}
+			}
+		case "assignee":
+			if input == "" && t.Assignee != "" {
+				edit.RemoveAssignee = true
+			} else if input != t.Assignee {
+				edit.Assignee = input
+			}
+		}
+		if err := edit.Validate(); err != nil {
+			return statusUpdateMsg{err: err}
+		}
+		if err := task.EditLine(t, edit.Apply); err != nil {
+			return statusUpdateMsg{err: err}
+		}
+
+		kgit.CommitFile(t.FilePath, fmt.Sprintf("Edit task: %s", t.Title), true)
+		return statusUpdateMsg{message: fmt.Sprintf("Edited: %s", t.Title)}
+	}
+}
+
+func addTaskCmd(cfg *configpkg.Config, project, line string) tea.Cmd {
+	return func() tea.Msg {
+		t, files, err := task.AddTask(cfg, project, "", line)
+		if err != nil {
+			return statusUpdateMsg{err: err}
+		}
+		kgit.CommitFiles(files, fmt.Sprintf("Add task: %s: %s", t.Keyword, t.Title), true)
+		return statusUpdateMsg{message: fmt.Sprintf("Added to %s: %s", t.Project, t.Title)}
+	}
+}
+
+func refileCmd(cfg *configpkg.Config, t *task.Task, target task.RefileTarget) tea.Cmd {
+	return func() tea.Msg {
+		dest, created, err := target.Path, []string(nil), error(nil)
+		if dest == "" {
+			if dest, created, err = task.ResolveRefileTarget(cfg, target.Project); err != nil {
+				return statusUpdateMsg{err: err}
+			}
+		}
+
+		src := t.FilePath
+		if err := task.RefileTask(t, dest, task.RefileAppend); err != nil {
+			return statusUpdateMsg{err: err}
+		}
+
+		commitMsg := fmt.Sprintf("Refile task: %s -> %s", t.Title, target.Label)
+		kgit.CommitFile(src, commitMsg, true)
+		kgit.CommitFiles(append(created, dest), commitMsg, true)
+		return statusUpdateMsg{message: fmt.Sprintf("Refiled to %s", target.Label)}
+	}
+}
+
+// undoCmd reverts the most recent journaled edit, or re-applies the most
+// recently undone one when redo is set, and commits the files it touched.
+func undoCmd(cfg *configpkg.Config, redo bool) tea.Cmd {
+	return func() tea.Msg {
+		undo, verb, done := task.Undo, "Undo", "Undone"
+		if redo {
+			undo, verb, done = task.Redo, "Redo", "Redone"
+		}
+		entries, err := undo(cfg, 1)
+		if err != nil {
+			return statusUpdateMsg{err: err}
+		}
+		e := entries[0]
+		kgit.CommitFilesByRepo(e.Files(), fmt.Sprintf("%s: %s", verb, e.Summary), true)
+		return statusUpdateMsg{message: fmt.Sprintf("%s: %s", done, e.Summary)}
+	}
+}
+
 type clockResultMsg struct {
 	message string
 	err     error
@@ -1604,6 +2294,41 @@ func clockOutCmd(t *task.Task) tea.Cmd {
 	}
 }
 
+// startPomodoro clocks in to t for a pomodoro work interval.
+func (m model) startPomodoro(t *task.Task) (tea.Model, tea.Cmd) {
+	gen, err := m.pomodoro.Start(m.config, t, time.Now())
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Pomodoro: %v", err)
+		return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} })
+	}
+	return m, pomodoroTickCmd(gen)
+}
+
+// stopPomodoro ends the running pomodoro, clocking out of an unfinished
+// work interval.
+func (m model) stopPomodoro() (tea.Model, tea.Cmd) {
+	m.statusMessage = "Pomodoro stopped"
+	if err := m.pomodoro.Stop(time.Now()); err != nil {
+		m.statusMessage = fmt.Sprintf("Pomodoro: %v", err)
+	}
+	return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} })
+}
+
+// tickPomodoro advances the pomodoro timer of generation gen, showing what
+// it announces.
+func (m model) tickPomodoro(gen int) (tea.Model, tea.Cmd) {
+	msg, again := m.pomodoro.Tick(gen, time.Now())
+	var cmds []tea.Cmd
+	if again {
+		cmds = append(cmds, pomodoroTickCmd(gen))
+	}
+	if msg != "" {
+		m.statusMessage = msg
+		cmds = append(cmds, tea.Tick(5*time.Second, func(t time.Time) tea.Msg { return clearStatusMsg{} }))
+	}
+	return m, tea.Batch(cmds...)
+}
+
 // updateTaskStatusCmd creates a command that updates the task status.
 // For recurring tasks being marked complete, it advances the date instead.
 func updateTaskStatusCmd(cfg *configpkg.Config, t *task.Task, newKeyword string) tea.Cmd {
@@ -1630,15 +2355,28 @@ func updateTaskStatusCmd(cfg *configpkg.Config, t *task.Task, newKeyword string)
 		}
 
 		// Normal (non-recurring) status update
-		if err := task.UpdateTaskStatus(t, newKeyword, cfg); err != nil {
+		// Journaled so the status and its LOG line are undone together
+		summary := fmt.Sprintf("Status %s -> %s: %s", oldKeyword, newKeyword, t.Title)
+		var unblocked []*task.Task
+		err := task.Journaled(t, summary, func() error {
+			if err := task.UpdateTaskStatus(t, newKeyword, cfg); err != nil {
+				return err
+			}
+			// Record state transition for all status changes
+			if err := task.RecordStateTransition(t, oldKeyword, newKeyword); err != nil {
+				return fmt.Errorf("status updated but failed to record transition: %w", err)
+			}
+			// Move dependents waiting on this task to the ready keyword
+			var err error
+			if unblocked, err = task.UnblockDependents(cfg, t); err != nil {
+				return fmt.Errorf("status updated but failed to unblock dependents: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
 			return statusUpdateMsg{err: err}
 		}
 
-		// Record state transition for all status changes
-		if err := task.RecordStateTransition(t, oldKeyword, newKeyword); err != nil {
-			return statusUpdateMsg{err: fmt.Errorf("status updated but failed to record transition: %w", err)}
-		}
-
 		// Commit the change if in a git repo
 		commitMsg := fmt.Sprintf("Update task status: %s -> %s", oldKeyword, newKeyword)
 		if err := kgit.CommitFile(t.FilePath, commitMsg, true); err != nil {
@@ -1647,9 +2385,16 @@ func updateTaskStatusCmd(cfg *configpkg.Config, t *task.Task, newKeyword string)
 			}
 		}
 
-		return statusUpdateMsg{
-			message: fmt.Sprintf("Status updated: %s → %s", oldKeyword, newKeyword),
+		message := fmt.Sprintf("Status updated: %s → %s", oldKeyword, newKeyword)
+		if len(unblocked) > 0 {
+			var files []string
+			for _, u := range unblocked {
+				files = append(files, u.FilePath)
+			}
+			kgit.CommitFilesByRepo(files, fmt.Sprintf("Unblock %d task(s) after: %s", len(unblocked), t.Title), true)
+			message += fmt.Sprintf(" (%d dependent task(s) ready)", len(unblocked))
 		}
+		return statusUpdateMsg{message: message}
 	}
 }
 
@@ -1659,9 +2404,9 @@ func reloadTasksCmd() tea.Cmd {
 	}
 }
 
-func loadTasksCmd(cfg *configpkg.Config, project string) tea.Cmd {
+func loadTasksCmd(cfg *configpkg.Config, project string, showCompleted bool) tea.Cmd {
 	return func() tea.Msg {
-		tasks, err := task.ListTasks(cfg, project, cfg.Todo.ShowCompleted)
+		tasks, err := task.ListTasks(cfg, project, showCompleted)
 		if err == nil {
 			task.DetectCycles(tasks)
 		}
@@ -1896,7 +2641,8 @@ func getStructuredWatchDirs(config *configpkg.Config, project string) []string {
 		entries, err := os.ReadDir(prjDir)
 		if err == nil {
 			for _, e := range entries {
-				if e.IsDir() {
+				// Hidden directories (.git, the task index cache) never hold tasks
+				if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
 					projectDir := filepath.Join(prjDir, e.Name())
 					notesDir := filepath.Join(projectDir, "notes")
 					dirs = append(dirs, projectDir, notesDir)
@@ -1938,6 +2684,10 @@ func getUnstructuredWatchDirs(config *configpkg.Config, project string) []string
 
 	filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
 		if err == nil && info.IsDir() {
+			// Skip hidden directories (.git, the task index cache)
+			if path != rootDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
 			depth := strings.Count(path, string(filepath.Separator)) - rootDepth
 			allDirs = append(allDirs, dirInfo{path: path, depth: depth})
 		}
@@ -2151,6 +2901,86 @@ func mergeTasksPreservingOrder(existing, incoming []*task.Task, cfg *configpkg.C
 	return result
 }
 
+// setNaturalDates sets a task line's scheduled and due dates from dates in
+// words (see task.ParseNaturalDate), printing the token each resolves to.
+func setNaturalDates(line, scheduled, due string, now time.Time) (string, error) {
+	m, ok := task.ParseLineModel(line)
+	if !ok {
+		return "", fmt.Errorf("not a task line: %q (want KEYWORD: title)", line)
+	}
+	for _, d := range []struct {
+		label, text string
+		set         func(string)
+	}{
+		{"Scheduled", scheduled, m.SetScheduled},
+		{"Due", due, m.SetDue},
+	} {
+		if d.text == "" {
+			continue
+		}
+		token, err := task.ParseNaturalDate(d.text, now)
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("%s: %s → %s\n", d.label, d.text, token)
+		d.set(token)
+	}
+	return m.String(), nil
+}
+
+// runPomodoro runs count pomodoros on the task with the given ID, showing a
+// countdown until they and their breaks are over. Interrupting it clocks out
+// of an unfinished work interval.
+func runPomodoro(config *configpkg.Config, id string, count int) error {
+	tasks, err := task.ListTasks(config, "", false)
+	if err != nil {
+		return err
+	}
+	matches := task.FindTasksByID(tasks, id)
+	switch {
+	case len(matches) == 0:
+		return fmt.Errorf("no active task with ID [%s]", id)
+	case len(matches) > 1:
+		return fmt.Errorf("%d tasks share ID [%s] (see 'todo ids')", len(matches), id)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	p := task.NewPomodoro(config, matches[0])
+	for p.Completed < count {
+		if err := p.Start(time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("Clocked in: %s\n", p.Task.Title)
+		for p.Phase != task.PomodoroIdle {
+			fmt.Printf("\r\033[K%s", p.Status(time.Now()))
+			select {
+			case <-ctx.Done():
+				fmt.Println()
+				if err := p.Stop(time.Now()); err != nil {
+					return err
+				}
+				fmt.Printf("Stopped after %d pomodoro(s); clocked out\n", p.Completed)
+				return nil
+			case now := <-ticker.C:
+				changed, err := p.Tick(now)
+				if err != nil {
+					fmt.Println()
+					return err
+				}
+				if changed && p.Phase == task.PomodoroBreak {
+					fmt.Printf("\r\033[K\aPomodoro done, clocked out (%d on this task)\n", task.PomodoroCount(p.Task))
+				}
+			}
+		}
+		fmt.Printf("\r\033[K\aBreak over\n")
+	}
+	return nil
+}
+
 func main() {
 	config, err := configpkg.Load()
 	if err != nil {
@@ -2160,6 +2990,10 @@ func main() {
 	// Initialize colors from config
 	InitializeColors(config)
 
+	// Journal task file edits so they can be undone
+	task.EnableJournal(config)
+	task.UseTimeZone(config)
+
 	// Parse flags
 	args := os.Args[1:]
 	for i := 0; i < len(args); i++ {
@@ -2169,6 +3003,10 @@ func main() {
 			// Remove this flag from args
 			args = append(args[:i], args[i+1:]...)
 			i--
+		} else if arg == "--archived" {
+			config.Todo.IncludeArchive = true
+			args = append(args[:i], args[i+1:]...)
+			i--
 		}
 	}
 
@@ -2190,30 +3028,88 @@ func main() {
 	case "-h", "--help", "help":
 		printHelp()
 	case "ls", "list":
-		project := ""
-		if len(args) > 1 {
-			project = args[1]
+		project, filter, format := "", "", ""
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "-f", "--filter", "--format":
+				if i+1 >= len(args) {
+					fmt.Fprintln(os.Stderr, "Usage: todo ls [project] [--filter <query>] [--format json|csv|tsv|markdown]")
+					os.Exit(1)
+				}
+				if args[i] == "--format" {
+					format = args[i+1]
+				} else {
+					filter = args[i+1]
+				}
+				i++
+			default:
+				project = args[i]
+			}
 		}
 		tasks, err := task.ListTasks(config, project, config.Todo.ShowCompleted)
 		if err != nil {
 			log.Fatal(err)
 		}
 		task.DetectCycles(tasks)
+		if filter != "" {
+			tasks, err = task.QueryTasks(config, tasks, filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid filter: %v\n", err)
+				os.Exit(1)
+			}
+		}
 		// Sort tasks by priority: InProgress -> Active -> Someday -> Completed
 		task.SortByPriority(tasks, config)
 		// Secondary sort by project name within same priority
 		sort.SliceStable(tasks, func(i, j int) bool {
 			if tasks[i].Priority(config) == tasks[j].Priority(config) {
+				if tasks[i].CookieRank() != tasks[j].CookieRank() {
+					return tasks[i].CookieRank() < tasks[j].CookieRank()
+				}
 				return tasks[i].Project < tasks[j].Project
 			}
 			return false
 		})
+		if format != "" {
+			if err := task.WriteTasks(os.Stdout, config, tasks, format); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 		printTasksPlain(config, tasks)
+	case "view":
+		if len(args) < 2 {
+			printViewsList(config)
+			return
+		}
+		v := config.FindView(args[1])
+		if v == nil {
+			fmt.Fprintf(os.Stderr, "View %q not found. Define it as [[views]] in ~/.config/karya/config.toml.\n", args[1])
+			os.Exit(1)
+		}
+		groups, err := task.RunView(config, *v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		printViewGroups(config, groups)
 	case "projects":
 		summary, err := task.SummarizeProjects(config)
 		if err != nil {
 			log.Fatal(err)
 		}
+		if len(args) > 1 {
+			if len(args) != 3 || args[1] != "--format" {
+				fmt.Fprintln(os.Stderr, "Usage: todo projects [--format json|csv|tsv|markdown]")
+				os.Exit(1)
+			}
+			if err := task.WriteProjects(os.Stdout, summary, args[2]); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 		showProjectsTable(summary)
 	case "pl":
 		summary, err := task.SummarizeProjects(config)
@@ -2221,6 +3117,363 @@ func main() {
 			log.Fatal(err)
 		}
 		printProjectsList(summary)
+	case "add":
+		const addUsage = "Usage: todo add <project> [--note <zettelID>] [--scheduled <date>] [--due <date>] [--dry-run] [--no-commit] \"<KEYWORD>: title\""
+		var project, noteID, scheduled, due string
+		var words []string
+		commit, dryRun := true, false
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--note", "--scheduled", "--due":
+				if i+1 >= len(args) {
+					fmt.Fprintln(os.Stderr, addUsage)
+					os.Exit(1)
+				}
+				switch args[i] {
+				case "--note":
+					noteID = args[i+1]
+				case "--scheduled":
+					scheduled = args[i+1]
+				default:
+					due = args[i+1]
+				}
+				i++
+			case "--no-commit":
+				commit = false
+			case "--dry-run":
+				dryRun = true
+			default:
+				if project == "" {
+					project = args[i]
+				} else {
+					words = append(words, args[i])
+				}
+			}
+		}
+		if project == "" || len(words) == 0 {
+			fmt.Fprintln(os.Stderr, addUsage)
+			os.Exit(1)
+		}
+		line := strings.Join(words, " ")
+		if scheduled != "" || due != "" {
+			if line, err = setNaturalDates(line, scheduled, due, time.Now()); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if dryRun {
+			fmt.Println(line)
+			return
+		}
+		t, files, err := task.AddTask(config, project, noteID, line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if commit {
+			if err := kgit.CommitFiles(files, fmt.Sprintf("Add task: %s: %s", t.Keyword, t.Title), true); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: git commit failed: %v\n", err)
+			}
+		}
+		fmt.Printf("Added %s: %s (%s:%d)\n", t.Keyword, t.Title, t.FilePath, t.LineNum)
+	case "refile":
+		// todo refile <project> <keyword> <title> --to <destination> [--at <line>]
+		var positional []string
+		dest, position := "", task.RefileAppend
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--to", "--at":
+				if i+1 >= len(args) {
+					fmt.Fprintln(os.Stderr, "Usage: todo refile <project> <keyword> <title> --to <destination> [--at <line>]")
+					os.Exit(1)
+				}
+				if args[i] == "--to" {
+					dest = args[i+1]
+				} else if position, err = strconv.Atoi(args[i+1]); err != nil || position < 1 {
+					fmt.Fprintf(os.Stderr, "invalid line number %q\n", args[i+1])
+					os.Exit(1)
+				}
+				i++
+			default:
+				positional = append(positional, args[i])
+			}
+		}
+		if len(positional) < 3 || dest == "" {
+			fmt.Fprintln(os.Stderr, "Usage: todo refile <project> <keyword> <title> --to <destination> [--at <line>]")
+			os.Exit(1)
+		}
+		project, keyword, title := positional[0], positional[1], strings.Join(positional[2:], " ")
+		tasks, err := task.ListTasks(config, project, true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var target *task.Task
+		for _, t := range tasks {
+			if t.Keyword == keyword && strings.Contains(strings.ToLower(t.Title), strings.ToLower(title)) {
+				target = t
+				break
+			}
+		}
+		if target == nil {
+			fmt.Fprintln(os.Stderr, "task not found")
+			os.Exit(1)
+		}
+		destFile, created, err := task.ResolveRefileTarget(config, dest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		src := target.FilePath
+		if err := task.RefileTask(target, destFile, position); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		commitMsg := fmt.Sprintf("Refile task: %s -> %s", target.Title, dest)
+		kgit.CommitFile(src, commitMsg, true)
+		kgit.CommitFiles(append(created, destFile), commitMsg, true)
+		fmt.Printf("Refiled %s: %s to %s:%d\n", target.Keyword, target.Title, target.FilePath, target.LineNum)
+	case "edit":
+		// todo edit <project> <keyword> <title> [--title T] [--tag TAG]... [--untag TAG]... [--assign NAME | --unassign]
+		const usage = "Usage: todo edit <project> <keyword> <title> [--title <new title>] [--tag <tag>]... [--untag <tag>]... [--assign <name> | --unassign]"
+		var positional []string
+		var edit task.LineEdit
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--title", "--tag", "--untag", "--assign":
+				if i+1 >= len(args) {
+					fmt.Fprintln(os.Stderr, usage)
+					os.Exit(1)
+				}
+				switch value := args[i+1]; args[i] {
+				case "--title":
+					edit.Title = value
+				case "--tag":
+					edit.AddTags = append(edit.AddTags, value)
+				case "--untag":
+					edit.RemoveTags = append(edit.RemoveTags, value)
+				case "--assign":
+					edit.Assignee = value
+				}
+				i++
+			case "--unassign":
+				edit.RemoveAssignee = true
+			default:
+				positional = append(positional, args[i])
+			}
+		}
+		if len(positional) < 3 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(1)
+		}
+		if err := edit.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		project, keyword, title := positional[0], positional[1], strings.Join(positional[2:], " ")
+		tasks, err := task.ListTasks(config, project, true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var target *task.Task
+		for _, t := range tasks {
+			if t.Keyword == keyword && strings.Contains(strings.ToLower(t.Title), strings.ToLower(title)) {
+				target = t
+				break
+			}
+		}
+		if target == nil {
+			fmt.Fprintln(os.Stderr, "task not found")
+			os.Exit(1)
+		}
+		if err := task.EditLine(target, edit.Apply); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		kgit.CommitFile(target.FilePath, fmt.Sprintf("Edit task: %s", target.Title), true)
+		fmt.Printf("Edited %s: %s\n", target.Keyword, target.Title)
+	case "archive":
+		var project string
+		var opts task.ArchiveOptions
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--older-than":
+				if i+1 >= len(args) {
+					fmt.Fprintln(os.Stderr, "Usage: todo archive [project] [--older-than <age>] [--per-year]")
+					os.Exit(1)
+				}
+				if opts.OlderThan, err = task.ParseAge(args[i+1]); err != nil {
+					fmt.Fprintf(os.Stderr, "error: %v\n", err)
+					os.Exit(1)
+				}
+				i++
+			case "--per-year":
+				opts.PerYear = true
+			default:
+				project = args[i]
+			}
+		}
+		archived, files, err := task.ArchiveTasks(config, project, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(archived) == 0 {
+			fmt.Println("Nothing to archive")
+			return
+		}
+		for _, a := range archived {
+			fmt.Printf("%s: %s: %s -> %s\n", a.Task.Project, a.Task.Keyword, a.Task.Title, a.Archive)
+		}
+		if err := kgit.CommitFilesByRepo(files, fmt.Sprintf("Archive %d completed task(s)", len(archived)), true); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: git commit failed: %v\n", err)
+		}
+		fmt.Printf("Archived %d task(s)\n", len(archived))
+	case "graph":
+		const usage = "Usage: todo graph [project] [--format dot|mermaid|json]"
+		project, format := "", "dot"
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--format":
+				if i+1 >= len(args) {
+					fmt.Fprintln(os.Stderr, usage)
+					os.Exit(1)
+				}
+				format = args[i+1]
+				i++
+			default:
+				project = args[i]
+			}
+		}
+		// Load the whole workspace so dependencies on other projects show
+		tasks, err := task.ListTasks(config, "", true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g := task.BuildGraph(config, tasks, project)
+		switch format {
+		case "dot":
+			fmt.Print(g.DOT())
+		case "mermaid":
+			fmt.Print(g.Mermaid())
+		case "json":
+			out, err := g.JSON()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Print(out)
+		default:
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(1)
+		}
+	case "ids":
+		var project string
+		assign := false
+		for _, arg := range args[1:] {
+			if arg == "--assign" {
+				assign = true
+			} else {
+				project = arg
+			}
+		}
+		if assign {
+			assigned, files, err := task.AssignIDs(config, project)
+			for _, t := range assigned {
+				fmt.Printf("[%s] %s: %s: %s\n", t.ID, t.Project, t.Keyword, t.Title)
+			}
+			if len(files) > 0 {
+				if cerr := kgit.CommitFilesByRepo(files, fmt.Sprintf("Assign IDs to %d task(s)", len(assigned)), true); cerr != nil {
+					fmt.Fprintf(os.Stderr, "warning: git commit failed: %v\n", cerr)
+				}
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Assigned %d ID(s)\n", len(assigned))
+			return
+		}
+		tasks, err := task.ListTasks(config, project, true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// IDs must be unique across the workspace, not just the project
+		dups, err := task.WorkspaceDuplicateIDs(config, project)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ids := make([]string, 0, len(dups))
+		for id := range dups {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			fmt.Printf("duplicate ID [%s]:\n", id)
+			for _, t := range dups[id] {
+				fmt.Printf("    %s:%d  %s: %s\n", t.FilePath, t.LineNum, t.Keyword, t.Title)
+			}
+		}
+		missing := 0
+		for _, t := range tasks {
+			if t.ID == "" {
+				missing++
+			}
+		}
+		fmt.Printf("%d task(s), %d without an ID, %d duplicated ID(s)\n", len(tasks), missing, len(dups))
+		if len(dups) > 0 {
+			os.Exit(1)
+		}
+	case "undo", "redo":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "Usage: todo %s [n]\n", subcommand)
+				os.Exit(1)
+			}
+		}
+		undo, verb := task.Undo, "Undo"
+		if subcommand == "redo" {
+			undo, verb = task.Redo, "Redo"
+		}
+		entries, err := undo(config, n)
+		for _, e := range entries {
+			fmt.Printf("%s: %s\n", verb, e.Summary)
+			if cerr := kgit.CommitFilesByRepo(e.Files(), fmt.Sprintf("%s: %s", verb, e.Summary), true); cerr != nil {
+				fmt.Fprintf(os.Stderr, "warning: git commit failed: %v\n", cerr)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "pomodoro":
+		const pomodoroUsage = "Usage: todo pomodoro <task-id> [--count N]"
+		var id string
+		count := 1
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--count":
+				if i+1 >= len(args) {
+					fmt.Fprintln(os.Stderr, pomodoroUsage)
+					os.Exit(1)
+				}
+				if count, err = strconv.Atoi(args[i+1]); err != nil || count < 1 {
+					fmt.Fprintln(os.Stderr, pomodoroUsage)
+					os.Exit(1)
+				}
+				i++
+			default:
+				id = strings.Trim(args[i], "[]")
+			}
+		}
+		if id == "" {
+			fmt.Fprintln(os.Stderr, pomodoroUsage)
+			os.Exit(1)
+		}
+		if err := runPomodoro(config, id, count); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
 	case "clock-in":
 		if len(args) < 4 {
 			fmt.Fprintln(os.Stderr, "Usage: todo clock-in <project> <keyword> <title>")
@@ -2319,14 +3572,49 @@ USAGE:
 
 OPTIONS:
     -v, --verbose       Show additional details like Zettel ID column
+    --archived          Include ARCHIVE.md files written by 'todo archive'
 
 COMMANDS:
     (no command)        Show interactive TUI with all tasks
-    ls [PROJECT]        List tasks in plain text format (for scripting)
-    projects            Show project summary table with task counts
+    ls [PROJECT] [-f QUERY] [--format FORMAT]
+                        List tasks in plain text format (for scripting),
+                        optionally filtered by QUERY (see FILTERING below);
+                        FORMAT is json, csv, tsv or markdown (see docs/todo.md)
+    view [NAME]         Run a saved view from config.toml ([[views]]);
+                        with no NAME, list the configured views
+    projects [--format FORMAT]
+                        Show project summary table with task counts
     pl                  Show project list in plain text format
+    add <project> [--note ID] [--scheduled DATE] [--due DATE] [--dry-run] [--no-commit] "<KEYWORD>: title ..."
+                        Add a task to the project's most recent note (or
+                        note ID); creates a note if the project has none.
+                        Dates may be words ("tomorrow 3pm", "every mon");
+                        --dry-run prints the line without adding it
+    edit <p> <k> <t> [--title NEW] [--tag TAG] [--untag TAG] [--assign NAME | --unassign]
+                        Retitle a task, add or remove tags, or change its
+                        assignee; the rest of the line is left as written
+    archive [PROJECT] [--older-than AGE] [--per-year]
+                        Move completed tasks (with children and sub-lines)
+                        into ARCHIVE.md (or ARCHIVE-<year>.md); AGE is e.g. 30d
+    refile <p> <k> <t> --to DEST [--at LINE]
+                        Move a task with its children and sub-lines to DEST:
+                        inbox, a project (latest note) or project/zettelID
+    graph [PROJECT] [--format dot|mermaid|json]
+                        Print the ^id dependency and nesting graph with
+                        cycles and the critical path highlighted
+    ids [PROJECT] [--assign]
+                        Report tasks sharing an [id] (exit 1 if any) and
+                        count tasks without one; --assign gives each of them
+                        a short ID unique across the workspace
+    undo [N]            Revert the last N task edits (default 1) made by todo,
+                        agenda or the MCP server, even if the file changed since
+    redo [N]            Re-apply the last N undone edits
     clock-in <p> <k> <t> Clock in on a task (project, keyword, title)
     clock-out <p> <k> <t> Clock out of a task (project, keyword, title)
+    pomodoro <id> [--count N]
+                        Clock in to the task with ID for a pomodoro work
+                        interval, clock out at its end and count it on the
+                        task, then time the break; repeat N times (default 1)
     mcp                 Start MCP server (stdio) for AI agent integration
     jira-auth           Authenticate with JIRA (OAuth browser flow, one-time setup)
     <project-name>      Show interactive TUI filtered to specific project
@@ -2349,10 +3637,17 @@ INTERACTIVE MODE:
     Type '/' to filter  Filter tasks list (See FILTERING below)
     Enter               Edit selected task at specific line / Exit filter mode
     t                   Change task status (opens keyword selector)
-    s                   Switch to structured mode (zettelkasten format)
-    u                   Switch to unstructured mode (all .md files)
+    + / -               Raise / lower task priority ([#A] > [#B] > [#C])
+    P                   Set a task property (key:: value; empty value removes)
+    e / # / >           Retitle the task / toggle a tag / set or clear its assignee
+    a                   Add a task (keyword and project pickers, live preview)
+    r                   Refile task to another project, note or the inbox
+    p                   Start or stop a pomodoro on the task (see 'todo pomodoro')
+    u / Ctrl+r          Undo / redo the last task edit (see 'todo undo')
+    s                   Toggle structured (zettelkasten) / unstructured mode
+    V                   Switch to a saved view
     q                   Quit
-    Esc                 Exit filter mode or clear filter
+    Esc                 Exit filter mode, clear filter or leave view
     Ctrl+C              Quit
 
 FILTERING:
@@ -2362,15 +3657,41 @@ FILTERING:
     @date               Filter by scheduled date (e.g., "@2025-01-15")
     @s:date             Filter by scheduled date (e.g., "@s:2025-01-15")
     @d:date             Filter by due date (e.g., "@d:2025-01-20")
+    @d:<date, @d:a..b   Compare dates (<, <=, >, >=, a..b, overdue)
+
+    Terms can be combined with AND, OR, NOT and parentheses; adjacent terms
+    are ANDed. Field terms: project:NAME, keyword:KW, category:CAT (active,
+    inprogress, completed, someday), tag:TAG, assignee:NAME, id:ID,
+    scheduled:EXPR, due:EXPR, priority:A|B|C, prop:KEY=VALUE,
+    has:deps, has:estimate, over:budget, in:cycle, is:ready, is:blocked.
+    Quote phrases: "fix bug".
 
 EXAMPLES:
     todo                           # Show all tasks in interactive TUI
     todo -v                        # Show all tasks with Zettel ID column
     todo --verbose ls              # List all tasks in verbose mode
     todo ls myproject              # List tasks for specific project
+    todo ls -f '#urgent AND NOT project:infra'
+                                   # List tasks matching a filter query
     todo -v myproject              # Show tasks for myproject with details
+    todo ls web --format json      # Export web's tasks as JSON
+    todo view urgent               # Run the saved view named "urgent"
     todo projects                  # Show project summary table
     todo pl                        # Show project list (plain text)
+    todo add web "TODO: Fix login #urgent @d:2025-07-01"
+                                   # Add a task to web's latest note
+    todo add web --due "next fri 5pm" "TODO: Ship release"
+                                   # Add a task due next Friday at 17:00
+    todo refile web TODO "Fix login" --to inbox
+                                   # Move a task (and its children) to the inbox
+    todo archive web --older-than 30d
+                                   # Archive tasks completed over 30 days ago
+    SHOW_COMPLETED=true todo --archived ls web
+                                   # List web's tasks, including archived ones
+    todo graph web | dot -Tsvg > web.svg
+                                   # Render web's dependency graph
+    todo ids --assign web          # Give every task in web a unique ID
+    todo pomodoro api-7 --count 4  # Work four pomodoros on task [api-7]
     todo mcp                       # Start MCP server for AI agents
     SHOW_COMPLETED=true todo       # Show completed tasks in TUI
     STRUCTURED=false todo          # Use unstructured mode (all .md files)
@@ -2380,6 +3701,8 @@ EXAMPLES:
     #   #urgent                    # Show tasks with #urgent tag
     #   @2025-01-15               # Show tasks scheduled for Jan 15, 2025
     #   @d:2025-01-20             # Show tasks due on Jan 20, 2025
+    #   #urgent AND >>alice AND @d:<2025-07-01 AND NOT project:infra
+    #   (category:active OR category:inprogress) has:deps
 
 ENVIRONMENT VARIABLES:
     EDITOR              Editor to use (supports vim, nvim, emacs, nano, code)
@@ -2433,6 +3756,9 @@ func showInteractiveTUI(config *configpkg.Config, project string) {
 	// Secondary sort by project name within same priority
 	sort.SliceStable(tasks, func(i, j int) bool {
 		if tasks[i].Priority(config) == tasks[j].Priority(config) {
+			if tasks[i].CookieRank() != tasks[j].CookieRank() {
+				return tasks[i].CookieRank() < tasks[j].CookieRank()
+			}
 			return tasks[i].Project < tasks[j].Project
 		}
 		return false
@@ -2486,11 +3812,11 @@ func showInteractiveTUI(config *configpkg.Config, project string) {
 			),
 			key.NewBinding(
 				key.WithKeys("s"),
-				key.WithHelp("s", "structured"),
+				key.WithHelp("s", "structured/unstructured"),
 			),
 			key.NewBinding(
 				key.WithKeys("u"),
-				key.WithHelp("u", "unstructured"),
+				key.WithHelp("u", "undo"),
 			),
 			key.NewBinding(
 				key.WithKeys("v"),
@@ -2512,6 +3838,34 @@ func showInteractiveTUI(config *configpkg.Config, project string) {
 				key.WithKeys("o"),
 				key.WithHelp("o", "clock out"),
 			),
+			key.NewBinding(
+				key.WithKeys("p"),
+				key.WithHelp("p", "pomodoro"),
+			),
+			key.NewBinding(
+				key.WithKeys("+", "-"),
+				key.WithHelp("+/-", "priority"),
+			),
+			key.NewBinding(
+				key.WithKeys("P"),
+				key.WithHelp("P", "property"),
+			),
+			key.NewBinding(
+				key.WithKeys("e"),
+				key.WithHelp("e", "retitle"),
+			),
+			key.NewBinding(
+				key.WithKeys("a"),
+				key.WithHelp("a", "add task"),
+			),
+			key.NewBinding(
+				key.WithKeys("r"),
+				key.WithHelp("r", "refile"),
+			),
+			key.NewBinding(
+				key.WithKeys("V"),
+				key.WithHelp("V", "views"),
+			),
 		}
 	}
 
@@ -2545,6 +3899,42 @@ func showInteractiveTUI(config *configpkg.Config, project string) {
 				key.WithKeys("o"),
 				key.WithHelp("o", "clock out"),
 			),
+			key.NewBinding(
+				key.WithKeys("p"),
+				key.WithHelp("p", "start/stop a pomodoro (clocks in for a work interval)"),
+			),
+			key.NewBinding(
+				key.WithKeys("+", "-"),
+				key.WithHelp("+/-", "raise/lower priority ([#A]..[#C])"),
+			),
+			key.NewBinding(
+				key.WithKeys("P"),
+				key.WithHelp("P", "set task property (key:: value)"),
+			),
+			key.NewBinding(
+				key.WithKeys("e"),
+				key.WithHelp("e", "retitle task"),
+			),
+			key.NewBinding(
+				key.WithKeys("#"),
+				key.WithHelp("#", "add or remove a tag"),
+			),
+			key.NewBinding(
+				key.WithKeys(">"),
+				key.WithHelp(">", "set or remove assignee"),
+			),
+			key.NewBinding(
+				key.WithKeys("a"),
+				key.WithHelp("a", "add a task"),
+			),
+			key.NewBinding(
+				key.WithKeys("r"),
+				key.WithHelp("r", "refile task to another project/note"),
+			),
+			key.NewBinding(
+				key.WithKeys("V"),
+				key.WithHelp("V", "switch saved view"),
+			),
 			key.NewBinding(
 				key.WithKeys("/"),
 				key.WithHelp("/", "start filtering"),
@@ -2559,11 +3949,15 @@ func showInteractiveTUI(config *configpkg.Config, project string) {
 			),
 			key.NewBinding(
 				key.WithKeys("s"),
-				key.WithHelp("s", "switch to structured mode (zettelkasten)"),
+				key.WithHelp("s", "toggle structured (zettelkasten) / unstructured mode"),
 			),
 			key.NewBinding(
 				key.WithKeys("u"),
-				key.WithHelp("u", "switch to unstructured mode (all .md files)"),
+				key.WithHelp("u", "undo last task edit"),
+			),
+			key.NewBinding(
+				key.WithKeys("ctrl+r"),
+				key.WithHelp("ctrl+r", "redo last undone edit"),
 			),
 			key.NewBinding(
 				key.WithKeys("g"),
@@ -2616,6 +4010,13 @@ func showInteractiveTUI(config *configpkg.Config, project string) {
 		log.Fatal(err)
 	}
 
+	// Clock out of a pomodoro left running
+	if fm, ok := finalModel.(model); ok {
+		if err := fm.pomodoro.Stop(time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Pomodoro: %v\n", err)
+		}
+	}
+
 	// Print JIRA sync log from the session
 	if fm, ok := finalModel.(model); ok && len(fm.jiraSyncLog) > 0 {
 		fmt.Fprintf(os.Stderr, "\nJIRA sync log:\n")
@@ -2644,7 +4045,11 @@ func printTasksPlain(config *configpkg.Config, tasks []*task.Task) {
 		}
 		
 		// Render task title with markdown formatting
-		formattedTitle := task.RenderMarkdownDescription(t.Title, titleStyle)
+		displayTitle := t.Title
+		if t.PriorityCookie != "" {
+			displayTitle = fmt.Sprintf("[#%s] %s", t.PriorityCookie, t.Title)
+		}
+		formattedTitle := task.RenderMarkdownDescription(displayTitle, titleStyle)
 		
 		if config.GeneralConfig.Verbose {
 			fmt.Printf("%-*s %-16s %-12s %-40s",
@@ -2669,6 +4074,29 @@ func printTasksPlain(config *configpkg.Config, tasks []*task.Task) {
 	}
 }
 
+func printViewsList(config *configpkg.Config) {
+	if len(config.Views) == 0 {
+		fmt.Println("No views defined. Add [[views]] to ~/.config/karya/config.toml.")
+		return
+	}
+	for _, v := range config.Views {
+		fmt.Printf("%-20s %s\n", v.Name, v.Filter)
+	}
+}
+
+func printViewGroups(config *configpkg.Config, groups []task.ViewGroup) {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	for i, g := range groups {
+		if g.Name != "" {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(headerStyle.Render(fmt.Sprintf("%s (%d)", g.Name, len(g.Tasks))))
+		}
+		printTasksPlain(config, g.Tasks)
+	}
+}
+
 func showProjectsTable(summary map[string]int) {
 	var projects []string
 	for p := range summary {
@@ -95,10 +95,12 @@ func Print(cfg *config.Config) error {
 		"today-date":            {Fg: normalizeHex(c.TodayDateColor), Bg: normalizeHex(c.TodayDateBgColor)},
 		"assignee":              {Fg: normalizeHex(c.AssigneeColor), Bg: normalizeHex(c.AssigneeBgColor)},
 		"cycle":                 {Fg: normalizeHex(c.CycleColor), Bg: normalizeHex(c.CycleBgColor)},
+		"priority":              {Fg: normalizeHex(c.PriorityColor), Bg: normalizeHex(c.PriorityBgColor)},
 		"overdue":               {Fg: normalizeHex(c.OverdueColor), Bg: normalizeHex(c.OverdueBgColor)},
 		"deadline":              {Fg: normalizeHex(c.DeadlineColor)},
 		"clock-active":          {Fg: normalizeHex(c.ClockActiveColor)},
 		"agenda-header":         {Fg: normalizeHex(c.AgendaHeaderColor)},
+		"calendar-event":        {Fg: normalizeHex(c.CalendarEventColor)},
 	}
 
 	for name, val := range elements {
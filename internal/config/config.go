@@ -6,6 +6,10 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+	// Zone names must resolve the same on every machine, including those
+	// without a system zoneinfo database, both when the [schedule]
+	// timezone is checked and in task files
+	_ "time/tzdata"
 
 	"github.com/BurntSushi/toml"
 	"github.com/charmbracelet/lipgloss"
@@ -75,11 +79,14 @@ type ColorScheme struct {
 	AssigneeBgColor    string `toml:"assignee-bg"`
 	CycleColor         string `toml:"cycle"`
 	CycleBgColor       string `toml:"cycle-bg"`
+	PriorityColor      string `toml:"priority"`
+	PriorityBgColor    string `toml:"priority-bg"`
 	OverdueColor       string `toml:"overdue"`
 	OverdueBgColor     string `toml:"overdue-bg"`
 	DeadlineColor      string `toml:"deadline"`
 	ClockActiveColor   string `toml:"clock-active"`
 	AgendaHeaderColor  string `toml:"agenda-header"`
+	CalendarEventColor string `toml:"calendar-event"`
 }
 
 type Directories struct {
@@ -96,6 +103,26 @@ type Todo struct {
 	Completed     []string `toml:"completed"`
 	Someday       []string `toml:"someday"`
 	SpecialTags   []string `toml:"special-tags"`
+
+	// Checkboxes treats GitHub-style "- [ ] item" / "- [x] item" list items as
+	// tasks, using CheckboxActive and CheckboxCompleted as their keywords.
+	Checkboxes        bool   `toml:"checkboxes"`
+	CheckboxActive    string `toml:"checkbox_active"`
+	CheckboxCompleted string `toml:"checkbox_completed"`
+
+	// IncludeArchive includes ARCHIVE.md files written by "todo archive"
+	// when finding task files. Set by the --archived flag.
+	IncludeArchive bool `toml:"include_archive"`
+
+	// AutoIDs gives tasks added with "todo add" or the TUI add form a short
+	// generated [id] when they don't have one.
+	AutoIDs bool `toml:"auto_ids"`
+
+	// WaitingKeyword marks tasks waiting on their ^id dependencies. When the
+	// last open dependency of such a task is completed, the task is moved to
+	// ReadyKeyword. Empty disables this.
+	WaitingKeyword string `toml:"waiting_keyword"`
+	ReadyKeyword   string `toml:"ready_keyword"`
 }
 
 type Goals struct {
@@ -106,6 +133,38 @@ type Schedule struct {
 	WeekStart          string `toml:"week_start"`
 	DefaultWarningDays int    `toml:"default_warning_days"`
 	DefaultView        string `toml:"default_view"`
+	// Calendars lists .ics files, or directories of them, whose events the
+	// agenda shows read-only next to tasks
+	Calendars []string `toml:"calendars"`
+	// TimeZone is the IANA zone (e.g. "Europe/Berlin") timed dates and
+	// CLOCK/LOG stamps are written in, with a zone suffix. Empty writes
+	// floating local times.
+	TimeZone string `toml:"timezone"`
+	// Pomodoro intervals, as durations ("25m"). A long break replaces the
+	// short one after every PomodoroLongBreakAfter work intervals.
+	PomodoroWork           string `toml:"pomodoro_work"`
+	PomodoroBreak          string `toml:"pomodoro_break"`
+	PomodoroLongBreak      string `toml:"pomodoro_long_break"`
+	PomodoroLongBreakAfter int    `toml:"pomodoro_long_break_after"`
+}
+
+// Notify configures `agenda notify`.
+type Notify struct {
+	// LeadTime is how long before a timed item starts it is notified, as
+	// a duration ("10m", "1h")
+	LeadTime string `toml:"lead_time"`
+	// ClockHours is how long a clock runs before it is notified; negative
+	// turns clock notifications off
+	ClockHours float64 `toml:"clock_hours"`
+	// Sinks lists where notifications go: "command", "exec" and "stdout".
+	// Empty uses command and exec when they are set, or else stdout.
+	Sinks []string `toml:"sinks"`
+	// Command is run by the shell for each notification, with it in
+	// KARYA_NOTIFY_* variables and as JSON on stdin
+	Command string `toml:"command"`
+	// Exec is a notify-send style program and its first arguments; the
+	// title and body are passed after them
+	Exec []string `toml:"exec"`
 }
 
 type GeneralConfig struct {
@@ -125,6 +184,15 @@ type Jira struct {
 	ExcludeProjects []string          `toml:"exclude_projects"`
 }
 
+// View is a saved task query defined as a [[views]] entry.
+type View struct {
+	Name          string `toml:"name"`
+	Filter        string `toml:"filter"`         // Query expression (same syntax as the todo filter)
+	Sort          string `toml:"sort"`           // priority (default), project, title, scheduled, due
+	GroupBy       string `toml:"group_by"`       // project, assignee, tag, category (empty for no grouping)
+	ShowCompleted bool   `toml:"show_completed"` // Include completed tasks
+}
+
 type Config struct {
 	GeneralConfig GeneralConfig `toml:"general"`
 	Directories   Directories   `toml:"directories"`
@@ -133,6 +201,8 @@ type Config struct {
 	Schedule      Schedule      `toml:"schedule"`
 	Colors        ColorScheme   `toml:"colors"`
 	Jira          Jira          `toml:"jira"`
+	Views         []View        `toml:"views"`
+	Notify        Notify        `toml:"notify"`
 }
 
 func Load() (*Config, error) {
@@ -156,6 +226,9 @@ func Load() (*Config, error) {
 			cfg.Directories.Projects = expandEnv(cfg.Directories.Projects)
 			cfg.Directories.Zettelkasten = expandEnv(cfg.Directories.Zettelkasten)
 			cfg.Directories.Karya = expandEnv(cfg.Directories.Karya)
+			for i, path := range cfg.Schedule.Calendars {
+				cfg.Schedule.Calendars[i] = expandEnv(path)
+			}
 		}
 	}
 
@@ -225,6 +298,49 @@ func Load() (*Config, error) {
 	if cfg.Schedule.DefaultView == "" {
 		cfg.Schedule.DefaultView = "day"
 	}
+	if tz := cfg.Schedule.TimeZone; tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil || tz == "Local" {
+			return nil, fmt.Errorf("invalid [schedule] timezone %q: want an IANA zone name such as \"Europe/Berlin\"", tz)
+		}
+	}
+	for _, p := range []struct {
+		key   string
+		value *string
+		def   string
+	}{
+		{"pomodoro_work", &cfg.Schedule.PomodoroWork, "25m"},
+		{"pomodoro_break", &cfg.Schedule.PomodoroBreak, "5m"},
+		{"pomodoro_long_break", &cfg.Schedule.PomodoroLongBreak, "15m"},
+	} {
+		if *p.value == "" {
+			*p.value = p.def
+		}
+		if d, err := time.ParseDuration(*p.value); err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid [schedule] %s %q: want a duration such as %q", p.key, *p.value, p.def)
+		}
+	}
+	if cfg.Schedule.PomodoroLongBreakAfter == 0 {
+		cfg.Schedule.PomodoroLongBreakAfter = 4
+	}
+	if cfg.Schedule.PomodoroLongBreakAfter < 0 {
+		return nil, fmt.Errorf("invalid [schedule] pomodoro_long_break_after %d: want a positive count", cfg.Schedule.PomodoroLongBreakAfter)
+	}
+
+	// Notify defaults
+	if cfg.Notify.LeadTime == "" {
+		cfg.Notify.LeadTime = "10m"
+	}
+	if d, err := time.ParseDuration(cfg.Notify.LeadTime); err != nil || d < 0 {
+		return nil, fmt.Errorf("invalid [notify] lead_time %q: want a duration such as \"10m\"", cfg.Notify.LeadTime)
+	}
+	if cfg.Notify.ClockHours == 0 {
+		cfg.Notify.ClockHours = 4
+	}
+	for _, sink := range cfg.Notify.Sinks {
+		if sink != "command" && sink != "exec" && sink != "stdout" {
+			return nil, fmt.Errorf("invalid [notify] sink %q: want command, exec or stdout", sink)
+		}
+	}
 
 	// JIRA defaults
 	if cfg.HasJira() && len(cfg.Jira.StatusMap) == 0 {
@@ -256,11 +372,14 @@ func Load() (*Config, error) {
 	cfg.Colors.AssigneeBgColor = resolveColorValue(cfg.Colors.AssigneeBgColor)
 	cfg.Colors.CycleColor = resolveColorValue(cfg.Colors.CycleColor)
 	cfg.Colors.CycleBgColor = resolveColorValue(cfg.Colors.CycleBgColor)
+	cfg.Colors.PriorityColor = resolveColorValue(cfg.Colors.PriorityColor)
+	cfg.Colors.PriorityBgColor = resolveColorValue(cfg.Colors.PriorityBgColor)
 	cfg.Colors.OverdueColor = resolveColorValue(cfg.Colors.OverdueColor)
 	cfg.Colors.OverdueBgColor = resolveColorValue(cfg.Colors.OverdueBgColor)
 	cfg.Colors.DeadlineColor = resolveColorValue(cfg.Colors.DeadlineColor)
 	cfg.Colors.ClockActiveColor = resolveColorValue(cfg.Colors.ClockActiveColor)
 	cfg.Colors.AgendaHeaderColor = resolveColorValue(cfg.Colors.AgendaHeaderColor)
+	cfg.Colors.CalendarEventColor = resolveColorValue(cfg.Colors.CalendarEventColor)
 
 	return cfg, nil
 }
@@ -277,6 +396,43 @@ func (c *Config) GetInboxFilePath() string {
 	return filepath.Join(home, "inbox.md")
 }
 
+// FindView returns the saved view with the given name (case-insensitive), or nil.
+func (c *Config) FindView(name string) *View {
+	for i := range c.Views {
+		if strings.EqualFold(c.Views[i].Name, name) {
+			return &c.Views[i]
+		}
+	}
+	return nil
+}
+
+// CheckboxKeywords returns the keywords used for unchecked and checked
+// checkbox items, defaulting to TODO and DONE.
+func (c *Config) CheckboxKeywords() (active, completed string) {
+	active, completed = c.Todo.CheckboxActive, c.Todo.CheckboxCompleted
+	if active == "" {
+		active = "TODO"
+	}
+	if completed == "" {
+		completed = "DONE"
+	}
+	return active, completed
+}
+
+// WaitingKeywords returns the keyword of tasks waiting on dependencies ("" if
+// unset) and the keyword they move to once unblocked, defaulting to the first
+// active keyword.
+func (c *Config) WaitingKeywords() (waiting, ready string) {
+	waiting, ready = c.Todo.WaitingKeyword, c.Todo.ReadyKeyword
+	if ready == "" {
+		ready = "TODO"
+		if len(c.Todo.Active) > 0 {
+			ready = c.Todo.Active[0]
+		}
+	}
+	return waiting, ready
+}
+
 // HasJira returns true if JIRA integration is configured.
 func (c *Config) HasJira() bool {
 	return len(c.Jira.Connections) > 0
@@ -294,6 +450,25 @@ func (c *Config) JiraSyncInterval() time.Duration {
 	return d
 }
 
+// NotifyLeadTime returns how long before a timed item starts `agenda notify`
+// notifies it.
+func (c *Config) NotifyLeadTime() time.Duration {
+	d, err := time.ParseDuration(c.Notify.LeadTime)
+	if err != nil {
+		return 10 * time.Minute
+	}
+	return d
+}
+
+// PomodoroIntervals returns the pomodoro work, break and long break
+// durations, as validated by Load.
+func (c *Config) PomodoroIntervals() (work, shortBreak, longBreak time.Duration) {
+	work, _ = time.ParseDuration(c.Schedule.PomodoroWork)
+	shortBreak, _ = time.ParseDuration(c.Schedule.PomodoroBreak)
+	longBreak, _ = time.ParseDuration(c.Schedule.PomodoroLongBreak)
+	return work, shortBreak, longBreak
+}
+
 // JiraStatusToKeyword maps a JIRA status name to a karya keyword using the configured
 // status map. Falls back to TODO for non-done categories and DONE for done categories.
 func (c *Config) JiraStatusToKeyword(jiraStatus string, isDoneCategory bool) string {
@@ -409,6 +584,15 @@ func (c *Config) initializeColors() {
 		if c.Colors.CycleBgColor == "" {
 			c.Colors.CycleBgColor = string(themeColorCache["red"])
 		}
+		if c.Colors.PriorityColor == "" {
+			c.Colors.PriorityColor = string(themeColorCache["black"])
+		}
+		if c.Colors.PriorityBgColor == "" {
+			c.Colors.PriorityBgColor = string(themeColorCache["bright-red"])
+		}
+		if c.Colors.CalendarEventColor == "" {
+			c.Colors.CalendarEventColor = string(themeColorCache["bright-blue"])
+		}
 	} else {
 		// No theme set - use terminal's native ANSI colors by setting color names only
 		// This allows the terminal emulator to use its own color scheme (light/dark)
@@ -475,6 +659,12 @@ func (c *Config) initializeColors() {
 		if c.Colors.CycleBgColor == "" {
 			c.Colors.CycleBgColor = "1" // ANSI red - warning color for circular deps
 		}
+		if c.Colors.PriorityColor == "" {
+			c.Colors.PriorityColor = "0" // ANSI black
+		}
+		if c.Colors.PriorityBgColor == "" {
+			c.Colors.PriorityBgColor = "9" // ANSI bright red
+		}
 		if c.Colors.OverdueColor == "" {
 			c.Colors.OverdueColor = "9" // ANSI bright red
 		}
@@ -490,6 +680,9 @@ func (c *Config) initializeColors() {
 		if c.Colors.AgendaHeaderColor == "" {
 			c.Colors.AgendaHeaderColor = "4" // ANSI blue
 		}
+		if c.Colors.CalendarEventColor == "" {
+			c.Colors.CalendarEventColor = "12" // ANSI bright blue
+		}
 	}
 
 }
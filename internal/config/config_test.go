@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadConfigWithDirectories(t *testing.T) {
@@ -139,3 +140,185 @@ zettelkasten = "/config/zet"
 		t.Errorf("Expected EDITOR from env = nvim, got %s", cfg.GeneralConfig.EDITOR)
 	}
 }
+
+func TestLoadConfigWithViews(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configContent := `[directories]
+projects = "/test/projects"
+
+[[views]]
+name = "urgent"
+filter = "#urgent AND NOT category:someday"
+sort = "due"
+group_by = "project"
+
+[[views]]
+name = "Alice"
+filter = ">>alice"
+show_completed = true
+`
+
+	origHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", origHome)
+
+	configDir := filepath.Join(tmpDir, ".config", "karya")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(configContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("HOME", tmpDir)
+	os.Unsetenv("PROJECTS")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(cfg.Views) != 2 {
+		t.Fatalf("Expected 2 views, got %d", len(cfg.Views))
+	}
+	v := cfg.FindView("URGENT")
+	if v == nil {
+		t.Fatal("FindView should match names case-insensitively")
+	}
+	if v.Filter != "#urgent AND NOT category:someday" || v.Sort != "due" || v.GroupBy != "project" || v.ShowCompleted {
+		t.Errorf("Unexpected view: %+v", *v)
+	}
+	if v := cfg.FindView("alice"); v == nil || !v.ShowCompleted {
+		t.Errorf("Expected alice view with show_completed, got %+v", v)
+	}
+	if cfg.FindView("missing") != nil {
+		t.Error("FindView should return nil for unknown views")
+	}
+}
+
+func TestLoadConfigTimeZone(t *testing.T) {
+	tests := []struct {
+		zone    string
+		wantErr bool
+	}{
+		{"Europe/Berlin", false},
+		{"UTC", false},
+		{"Mars/Olympus", true},
+		{"Local", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configDir := filepath.Join(tmpDir, ".config", "karya")
+			if err := os.MkdirAll(configDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			content := "[schedule]\ntimezone = \"" + tt.zone + "\"\n"
+			if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("HOME", tmpDir)
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Schedule.TimeZone != tt.zone {
+				t.Errorf("TimeZone = %q, want %q", cfg.Schedule.TimeZone, tt.zone)
+			}
+		})
+	}
+}
+
+func TestLoadConfigNotify(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantLead     time.Duration
+		wantClockHrs float64
+		wantErr      bool
+	}{
+		{"defaults", "", 10 * time.Minute, 4, false},
+		{"set", "[notify]\nlead_time = \"1h30m\"\nclock_hours = -1\nsinks = [\"exec\", \"stdout\"]\n", 90 * time.Minute, -1, false},
+		{"bad lead time", "[notify]\nlead_time = \"soon\"\n", 0, 0, true},
+		{"negative lead time", "[notify]\nlead_time = \"-5m\"\n", 0, 0, true},
+		{"unknown sink", "[notify]\nsinks = [\"email\"]\n", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configDir := filepath.Join(tmpDir, ".config", "karya")
+			if err := os.MkdirAll(configDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("HOME", tmpDir)
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := cfg.NotifyLeadTime(); got != tt.wantLead {
+				t.Errorf("NotifyLeadTime() = %v, want %v", got, tt.wantLead)
+			}
+			if cfg.Notify.ClockHours != tt.wantClockHrs {
+				t.Errorf("ClockHours = %v, want %v", cfg.Notify.ClockHours, tt.wantClockHrs)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPomodoro(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantWork  time.Duration
+		wantBreak time.Duration
+		wantLong  time.Duration
+		wantAfter int
+		wantErr   bool
+	}{
+		{"defaults", "", 25 * time.Minute, 5 * time.Minute, 15 * time.Minute, 4, false},
+		{"set", "[schedule]\npomodoro_work = \"50m\"\npomodoro_break = \"10m\"\npomodoro_long_break = \"30m\"\npomodoro_long_break_after = 2\n",
+			50 * time.Minute, 10 * time.Minute, 30 * time.Minute, 2, false},
+		{"bad work", "[schedule]\npomodoro_work = \"long\"\n", 0, 0, 0, 0, true},
+		{"zero break", "[schedule]\npomodoro_break = \"0s\"\n", 0, 0, 0, 0, true},
+		{"negative count", "[schedule]\npomodoro_long_break_after = -1\n", 0, 0, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configDir := filepath.Join(tmpDir, ".config", "karya")
+			if err := os.MkdirAll(configDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("HOME", tmpDir)
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			work, shortBreak, longBreak := cfg.PomodoroIntervals()
+			if work != tt.wantWork || shortBreak != tt.wantBreak || longBreak != tt.wantLong {
+				t.Errorf("PomodoroIntervals() = %v, %v, %v; want %v, %v, %v", work, shortBreak, longBreak, tt.wantWork, tt.wantBreak, tt.wantLong)
+			}
+			if cfg.Schedule.PomodoroLongBreakAfter != tt.wantAfter {
+				t.Errorf("PomodoroLongBreakAfter = %d, want %d", cfg.Schedule.PomodoroLongBreakAfter, tt.wantAfter)
+			}
+		})
+	}
+}
@@ -366,6 +366,29 @@ func CommitFiles(filePaths []string, message string, push bool) error {
 	return nil
 }
 
+// CommitFilesByRepo commits the files together, one commit per git repository
+// they belong to. Files outside a repository are skipped.
+func CommitFilesByRepo(filePaths []string, message string, push bool) error {
+	byRoot := make(map[string][]string)
+	var roots []string
+	for _, f := range filePaths {
+		root, err := FindRepoRoot(f)
+		if err != nil {
+			continue
+		}
+		if _, ok := byRoot[root]; !ok {
+			roots = append(roots, root)
+		}
+		byRoot[root] = append(byRoot[root], f)
+	}
+	for _, root := range roots {
+		if err := CommitFiles(byRoot[root], message, push); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Init initializes a git repository at the given path
 func Init(path string) error {
 	_, err := git.PlainInit(path, false)
@@ -906,6 +906,14 @@ func (s *MCPServer) addTask(ctx context.Context, req *mcp.CallToolRequest, args
 	for _, ref := range args.References {
 		taskLine += fmt.Sprintf(" ^%s", ref)
 	}
+	taskLine, err = task.AssignTaskID(s.config, taskLine)
+	if err != nil {
+		return nil, AddTaskResult{
+			Success:       false,
+			Message:       err.Error(),
+			ValidKeywords: validKeywords,
+		}, nil
+	}
 	if !strings.HasSuffix(content, "\n") {
 		content += "\n"
 	}
@@ -929,7 +937,7 @@ func (s *MCPServer) addTask(ctx context.Context, req *mcp.CallToolRequest, args
 
 	return nil, AddTaskResult{
 		Success:       true,
-		Message:       fmt.Sprintf("Added task '%s: %s' to note %s", args.Keyword, args.Title, args.NoteID),
+		Message:       fmt.Sprintf("Added task '%s' to note %s", taskLine, args.NoteID),
 		ValidKeywords: validKeywords,
 	}, nil
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/vinayprograms/karya/internal/config"
@@ -389,3 +391,49 @@ Todo: Mixed case task`
 		t.Errorf("expected 3 case-insensitive matches, got %d", result.Count)
 	}
 }
+
+func TestAddTask_IDs(t *testing.T) {
+	server, tmpDir, cleanup := setupTestMCP(t)
+	defer cleanup()
+	server.config.Todo.AutoIDs = true
+
+	projectDir := filepath.Join(tmpDir, "test-project", "notes")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("failed to create project dir: %v", err)
+	}
+	noteID := zet.GenerateZettelID()
+	if err := zet.CreateZettel(projectDir, noteID, "Test Note"); err != nil {
+		t.Fatalf("failed to create zettel: %v", err)
+	}
+
+	add := func(title, id string) AddTaskResult {
+		_, result, err := server.addTask(context.Background(), nil, AddTaskArgs{
+			Project: "test-project", NoteID: noteID, Keyword: "TODO", Title: title, ID: id,
+		})
+		if err != nil {
+			t.Fatalf("addTask(%q) error: %v", title, err)
+		}
+		return result
+	}
+
+	if result := add("Write docs", ""); !result.Success {
+		t.Fatalf("addTask without ID failed: %s", result.Message)
+	}
+	if result := add("Review docs", "docs"); !result.Success {
+		t.Fatalf("addTask with ID failed: %s", result.Message)
+	}
+	if result := add("Publish docs", "docs"); result.Success {
+		t.Errorf("addTask with a used ID succeeded: %s", result.Message)
+	}
+
+	content, err := zet.ReadZettelContent(projectDir, noteID)
+	if err != nil {
+		t.Fatalf("failed to read note: %v", err)
+	}
+	if !regexp.MustCompile(`(?m)^TODO: \[[a-z0-9]+\] Write docs$`).MatchString(content) {
+		t.Errorf("task without ID was not given one:\n%s", content)
+	}
+	if !strings.Contains(content, "TODO: [docs] Review docs\n") || strings.Contains(content, "Publish docs") {
+		t.Errorf("unexpected note content:\n%s", content)
+	}
+}
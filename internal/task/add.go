@@ -0,0 +1,209 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vinayprograms/karya/internal/config"
+	"github.com/vinayprograms/karya/internal/zet"
+)
+
+// taskLikeRe matches lines that look like a task or checkbox item, so a new
+// task can be appended directly below an existing list.
+var taskLikeRe = regexp.MustCompile(`^(?:[-*+]\s+)?(?:\[[ xX]\]\s|[A-Z]+:)`)
+
+// newNoteTitle is the title of the note created when a project has none.
+const newNoteTitle = "Tasks"
+
+// AddTask appends a task line to a project and returns the parsed task along
+// with every file it wrote (for committing). The line must parse as a task.
+//
+// The task goes to the note with the given zettel ID, or to the most recent
+// note in the project when noteID is empty. In structured mode a new note is
+// created if the project has none; in unstructured mode the project's
+// README.md is used. The "inbox" project appends to the inbox file.
+//
+// An [id] already used by another task is rejected; with todo.auto_ids a
+// task without one is given a generated ID.
+func AddTask(c *config.Config, project, noteID, line string) (*Task, []string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.ContainsAny(line, "\r\n") {
+		return nil, nil, fmt.Errorf("task must be a single non-empty line")
+	}
+	if project == "" {
+		return nil, nil, fmt.Errorf("project name is required")
+	}
+	parsed := ParseLine(c, line, project, "", "")
+	if parsed == nil {
+		return nil, nil, fmt.Errorf("not a task line: %q (want KEYWORD: title)", line)
+	}
+
+	line, err := AssignTaskID(c, line)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path, files, err := addTarget(c, project, noteID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lineNum, err := appendTaskLine(path, line)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Re-read the file so the task carries the same project, zettel and
+	// location as it would when listed
+	var tasks []*Task
+	if project == "inbox" {
+		tasks, err = readInboxFile(path, c)
+	} else {
+		tasks, err = ProcessFile(c, path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, t := range tasks {
+		if t.LineNum == lineNum {
+			return t, append(files, path), nil
+		}
+	}
+	return nil, nil, fmt.Errorf("task not found in %s after writing", path)
+}
+
+// AssignTaskID checks a task line's [id] against every ID in the workspace,
+// since IDs must stay unique for ^references to work. With todo.auto_ids a
+// line without an ID is returned with a generated one.
+func AssignTaskID(c *config.Config, line string) (string, error) {
+	m, ok := ParseLineModel(line)
+	if !ok || (m.ID() == "" && !c.Todo.AutoIDs) {
+		return line, nil
+	}
+	used, err := workspaceIDs(c)
+	if err != nil {
+		return "", err
+	}
+	if used[m.ID()] {
+		return "", fmt.Errorf("task ID %q is already in use", m.ID())
+	}
+	if m.ID() == "" {
+		m.SetID(newTaskID(used))
+		line = m.String()
+	}
+	return line, nil
+}
+
+// addTarget resolves the file a new task is appended to. Any files created
+// on the way (such as the notes index) are returned for committing.
+func addTarget(c *config.Config, project, noteID string) (string, []string, error) {
+	if project == "inbox" {
+		if noteID != "" {
+			return "", nil, fmt.Errorf("--note cannot be used with the inbox")
+		}
+		return c.GetInboxFilePath(), nil, nil
+	}
+
+	prjDir := filepath.Join(c.Directories.Projects, project)
+	if info, err := os.Stat(prjDir); err != nil || !info.IsDir() {
+		return "", nil, fmt.Errorf("project %q does not exist", project)
+	}
+	notesDir := filepath.Join(prjDir, "notes")
+
+	if noteID != "" {
+		if !zet.IsValidZettelID(noteID) {
+			return "", nil, fmt.Errorf("invalid note ID: %s", noteID)
+		}
+		path := filepath.Join(notesDir, noteID, "README.md")
+		if _, err := os.Stat(path); err != nil {
+			return "", nil, fmt.Errorf("note %s not found in project %q", noteID, project)
+		}
+		return path, nil, nil
+	}
+
+	if path := latestNote(notesDir); path != "" {
+		return path, nil, nil
+	}
+
+	if !c.Todo.Structured {
+		return filepath.Join(prjDir, "README.md"), nil, nil
+	}
+
+	// Structured project without notes: start one
+	if err := os.MkdirAll(notesDir, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create notes directory: %w", err)
+	}
+	id := zet.GenerateZettelID()
+	if err := zet.CreateZettel(notesDir, id, newNoteTitle); err != nil {
+		return "", nil, fmt.Errorf("failed to create note: %w", err)
+	}
+	var files []string
+	if err := zet.UpdateReadme(notesDir); err == nil {
+		files = append(files, filepath.Join(notesDir, "README.md"))
+	}
+	return filepath.Join(notesDir, id, "README.md"), files, nil
+}
+
+// latestNote returns the README.md of the most recent zettel in notesDir, or
+// "" if there is none. Zettel IDs are timestamps, so the largest is newest.
+func latestNote(notesDir string) string {
+	matches, _ := filepath.Glob(filepath.Join(notesDir, "??????????????", "README.md"))
+	var latest string
+	for _, m := range matches {
+		if zet.IsValidZettelID(filepath.Base(filepath.Dir(m))) && m > latest {
+			latest = m
+		}
+	}
+	return latest
+}
+
+// appendTaskLine appends line to the file (creating it if needed) and returns
+// its 1-based line number. Consecutive tasks stay together; otherwise the task
+// is separated from preceding text by a blank line.
+func appendTaskLine(path, line string) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+
+	var lineNum int
+	err := mutateFile(path, "Add task: "+line, func(content []byte) ([]byte, error) {
+		text := string(content)
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if last := lastNonEmptyLine(text); last != "" && !taskLikeRe.MatchString(last) {
+			if !strings.HasSuffix(text, "\n\n") {
+				text += "\n"
+			}
+		}
+		text += line + "\n"
+		lineNum = strings.Count(text, "\n")
+		return []byte(text), nil
+	})
+	return lineNum, err
+}
+
+func lastNonEmptyLine(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// ListProjectNames returns the names of all project directories, sorted.
+func ListProjectNames(c *config.Config) ([]string, error) {
+	entries, err := os.ReadDir(c.Directories.Projects)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
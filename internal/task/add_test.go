@@ -0,0 +1,167 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddTask(t *testing.T) {
+	older := "# Old\n\nTODO: Existing task\n"
+	newer := "# Planning\n\nSome notes.\n"
+
+	tests := []struct {
+		name       string
+		structured bool
+		project    string
+		noteID     string
+		line       string
+		wantErr    string
+		wantFile   string // relative to the projects dir
+		wantBody   string // expected file content after the add
+		wantTitle  string
+	}{
+		{
+			name: "latest note", structured: true, project: "web",
+			line:     "TODO: Fix login #urgent @d:2025-07-01",
+			wantFile: "web/notes/20250102000000/README.md",
+			wantBody: newer + "\nTODO: Fix login #urgent @d:2025-07-01\n", wantTitle: "Fix login",
+		},
+		{
+			name: "explicit note joins task list", structured: true, project: "web", noteID: "20250101000000",
+			line:     "DOING: Write docs",
+			wantFile: "web/notes/20250101000000/README.md",
+			wantBody: older + "DOING: Write docs\n", wantTitle: "Write docs",
+		},
+		{
+			name: "unstructured uses latest note", project: "web",
+			line:     "TODO: Plan",
+			wantFile: "web/notes/20250102000000/README.md",
+			wantBody: newer + "\nTODO: Plan\n", wantTitle: "Plan",
+		},
+		{
+			name: "unstructured project without notes", project: "empty",
+			line:     "TODO: First",
+			wantFile: "empty/README.md",
+			wantBody: "TODO: First\n", wantTitle: "First",
+		},
+		{name: "not a task", structured: true, project: "web", line: "Fix login", wantErr: "not a task line"},
+		{name: "unknown keyword", structured: true, project: "web", line: "FOO: Fix login", wantErr: "not a task line"},
+		{name: "multiple lines", structured: true, project: "web", line: "TODO: a\nTODO: b", wantErr: "single"},
+		{name: "missing project", structured: true, project: "nope", line: "TODO: x", wantErr: "does not exist"},
+		{name: "invalid note", structured: true, project: "web", noteID: "123", line: "TODO: x", wantErr: "invalid note ID"},
+		{name: "unknown note", structured: true, project: "web", noteID: "20240101000000", line: "TODO: x", wantErr: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeNote := func(rel, content string) {
+				path := filepath.Join(dir, rel)
+				os.MkdirAll(filepath.Dir(path), 0755)
+				os.WriteFile(path, []byte(content), 0644)
+			}
+			writeNote("web/notes/20250101000000/README.md", older)
+			writeNote("web/notes/20250102000000/README.md", newer)
+			os.MkdirAll(filepath.Join(dir, "empty"), 0755)
+
+			cfg := createTestConfig()
+			cfg.Directories.Projects = dir
+			cfg.Todo.Structured = tt.structured
+
+			got, _, err := AddTask(cfg, tt.project, tt.noteID, tt.line)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AddTask() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddTask() error: %v", err)
+			}
+
+			path := filepath.Join(dir, tt.wantFile)
+			if got.FilePath != path {
+				t.Errorf("FilePath = %q, want %q", got.FilePath, path)
+			}
+			if got.Title != tt.wantTitle || got.Project != tt.project {
+				t.Errorf("task = %q in %q, want %q in %q", got.Title, got.Project, tt.wantTitle, tt.project)
+			}
+			content, _ := os.ReadFile(path)
+			if string(content) != tt.wantBody {
+				t.Errorf("file content = %q, want %q", content, tt.wantBody)
+			}
+			if lines := strings.Split(string(content), "\n"); lines[got.LineNum-1] != strings.TrimSpace(tt.line) {
+				t.Errorf("LineNum %d points at %q", got.LineNum, lines[got.LineNum-1])
+			}
+		})
+	}
+}
+
+func TestAddTask_CreatesNote(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "fresh"), 0755)
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+	cfg.Todo.Structured = true
+
+	got, files, err := AddTask(cfg, "fresh", "", "TODO: Kick off")
+	if err != nil {
+		t.Fatalf("AddTask() error: %v", err)
+	}
+	if !strings.HasPrefix(got.FilePath, filepath.Join(dir, "fresh", "notes")+string(filepath.Separator)) {
+		t.Fatalf("FilePath = %q, want a new note under fresh/notes", got.FilePath)
+	}
+	if got.Zettel != filepath.Base(filepath.Dir(got.FilePath)) {
+		t.Errorf("Zettel = %q, want the new note's ID", got.Zettel)
+	}
+	content, _ := os.ReadFile(got.FilePath)
+	if !strings.HasPrefix(string(content), "# "+newNoteTitle+"\n") || !strings.HasSuffix(string(content), "\nTODO: Kick off\n") {
+		t.Errorf("new note content = %q", content)
+	}
+	if len(files) != 2 || files[len(files)-1] != got.FilePath {
+		t.Errorf("files = %v, want notes index and the new note", files)
+	}
+}
+
+func TestAddForm(t *testing.T) {
+	cfg := createTestConfig()
+
+	tests := []struct {
+		name        string
+		keys        []string
+		wantLine    string
+		wantProject string
+		wantConfirm bool
+		wantErr     bool
+	}{
+		{"type and confirm", []string{"F", "i", "x", " ", "#", "a", "enter"}, "TODO: Fix #a", "web", true, false},
+		{"empty input is rejected", []string{"enter"}, "TODO: ", "web", false, true},
+		{"backspace", []string{"a", "b", "backspace", "enter"}, "TODO: a", "web", true, false},
+		{"keyword picker", []string{"shift+tab", "shift+tab", "right", "tab", "tab", "x", "enter"}, "TASK: x", "web", true, false},
+		{"project picker wraps", []string{"shift+tab", "right", "right", "shift+tab", "shift+tab", "x"}, "TODO: x", "api", false, false},
+		{"picker keys type in text field", []string{"j", "k"}, "TODO: jk", "web", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			af := NewAddForm(cfg, []string{"api", "web"}, "web")
+			for _, k := range tt.keys {
+				af.Update(k)
+			}
+			if got := af.Line(); got != tt.wantLine {
+				t.Errorf("Line() = %q, want %q", got, tt.wantLine)
+			}
+			if got := af.Project(); got != tt.wantProject {
+				t.Errorf("Project() = %q, want %q", got, tt.wantProject)
+			}
+			if af.Confirmed != tt.wantConfirm {
+				t.Errorf("Confirmed = %v, want %v", af.Confirmed, tt.wantConfirm)
+			}
+			if (af.Err != "") != tt.wantErr {
+				t.Errorf("Err = %q, wantErr %v", af.Err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// Fields of the add-task form, in tab order.
+const (
+	addFieldText = iota
+	addFieldKeyword
+	addFieldProject
+	addFieldCount
+)
+
+// AddForm is an inline form for creating a task: a keyword picker, a project
+// picker and the rest of the task line, with a live preview of how the line
+// parses.
+type AddForm struct {
+	Config     *config.Config
+	Keywords   []string
+	Projects   []string
+	KeywordIdx int
+	ProjectIdx int
+	Input      string
+	Focus      int
+	Err        string
+	Confirmed  bool
+	Cancelled  bool
+}
+
+// NewAddForm creates a form defaulting to the first active keyword and the
+// given project. The inbox is always offered as a project.
+func NewAddForm(c *config.Config, projects []string, project string) *AddForm {
+	af := &AddForm{Config: c}
+	for _, e := range GetAllKeywordsFlat(c) {
+		af.Keywords = append(af.Keywords, e.Keyword)
+	}
+	af.Projects = append(af.Projects, projects...)
+	af.Projects = append(af.Projects, "inbox")
+	for i, p := range af.Projects {
+		if p == project {
+			af.ProjectIdx = i
+			break
+		}
+	}
+	return af
+}
+
+// Keyword returns the selected keyword.
+func (af *AddForm) Keyword() string {
+	if len(af.Keywords) == 0 {
+		return ""
+	}
+	return af.Keywords[af.KeywordIdx]
+}
+
+// Project returns the selected project.
+func (af *AddForm) Project() string {
+	return af.Projects[af.ProjectIdx]
+}
+
+// Line returns the task line the form will write.
+func (af *AddForm) Line() string {
+	return af.Keyword() + ": " + strings.TrimSpace(af.Input)
+}
+
+// Preview parses the current line, returning nil if it is not a valid task.
+func (af *AddForm) Preview() *Task {
+	if strings.TrimSpace(af.Input) == "" {
+		return nil
+	}
+	return ParseLine(af.Config, af.Line(), af.Project(), "", "")
+}
+
+func (af *AddForm) Update(key string) {
+	if af.Confirmed || af.Cancelled {
+		return
+	}
+
+	switch key {
+	case "esc":
+		af.Cancelled = true
+		return
+	case "enter":
+		if af.Preview() == nil {
+			af.Err = "enter a title (metadata such as #tag @d:2025-07-01 is optional)"
+			return
+		}
+		af.Confirmed = true
+		return
+	case "tab":
+		af.Focus = (af.Focus + 1) % addFieldCount
+		return
+	case "shift+tab":
+		af.Focus = (af.Focus - 1 + addFieldCount) % addFieldCount
+		return
+	}
+
+	af.Err = ""
+	switch af.Focus {
+	case addFieldKeyword:
+		af.KeywordIdx = cycleIndex(af.KeywordIdx, len(af.Keywords), key)
+	case addFieldProject:
+		af.ProjectIdx = cycleIndex(af.ProjectIdx, len(af.Projects), key)
+	default:
+		switch {
+		case key == "backspace":
+			if runes := []rune(af.Input); len(runes) > 0 {
+				af.Input = string(runes[:len(runes)-1])
+			}
+		case key == "ctrl+u":
+			af.Input = ""
+		case len(key) == 1 && key[0] >= 32 && key[0] <= 126:
+			af.Input += key
+		}
+	}
+}
+
+// cycleIndex moves idx through n choices for left/right and up/down keys.
+func cycleIndex(idx, n int, key string) int {
+	if n == 0 {
+		return idx
+	}
+	switch key {
+	case "right", "down", "l", "j":
+		return (idx + 1) % n
+	case "left", "up", "h", "k":
+		return (idx - 1 + n) % n
+	}
+	return idx
+}
+
+func (af *AddForm) View(width int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+	focusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+	label := func(field int, name string) string {
+		if af.Focus == field {
+			return focusStyle.Render(fmt.Sprintf("▸ %-8s", name))
+		}
+		return fmt.Sprintf("  %-8s", name)
+	}
+	choice := func(field int, value string) string {
+		if af.Focus == field {
+			return focusStyle.Render("◀ " + value + " ▶")
+		}
+		return "  " + value
+	}
+
+	var view strings.Builder
+	view.WriteString(headerStyle.Render("New task"))
+	view.WriteString("\n\n")
+
+	view.WriteString(label(addFieldKeyword, "Keyword") + " " + choice(addFieldKeyword, af.Keyword()) + "\n")
+	view.WriteString(label(addFieldProject, "Project") + " " + choice(addFieldProject, af.Project()) + "\n")
+	input := af.Input
+	if af.Focus == addFieldText {
+		input += "▓"
+	}
+	view.WriteString(label(addFieldText, "Task") + " " + af.Keyword() + ": " + input + "\n")
+
+	view.WriteString("\n")
+	if t := af.Preview(); t != nil {
+		for _, row := range previewRows(t) {
+			line := fmt.Sprintf("  %-10s %s", row[0], row[1])
+			if runes := []rune(line); width > 8 && len(runes) > width-8 {
+				line = string(runes[:width-8]) + "…"
+			}
+			view.WriteString(dimStyle.Render(line) + "\n")
+		}
+	} else {
+		view.WriteString(dimStyle.Render("  KEYWORD: title #tag @s:date @d:date ~estimate >> assignee") + "\n")
+	}
+
+	if af.Err != "" {
+		view.WriteString("\n")
+		view.WriteString(errStyle.Render(af.Err))
+		view.WriteString("\n")
+	}
+
+	view.WriteString("\n")
+	view.WriteString(dimStyle.Render("tab: next field • ←/→: change keyword/project • enter: add • esc: cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2)
+
+	return boxStyle.Render(view.String())
+}
+
+// previewRows lists the parsed fields of a task for the form preview.
+func previewRows(t *Task) [][2]string {
+	rows := [][2]string{{"title", t.Title}}
+	add := func(name, value string) {
+		if value != "" {
+			rows = append(rows, [2]string{name, value})
+		}
+	}
+	add("id", t.ID)
+	add("priority", t.PriorityCookie)
+	add("tags", strings.Join(t.Tags, ", "))
+	add("scheduled", t.ScheduledAt)
+	add("due", t.DueAt)
+	add("estimate", t.Estimate)
+	add("assignee", t.Assignee)
+	add("refs", strings.Join(t.References, ", "))
+	return rows
+}
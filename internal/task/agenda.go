@@ -1,15 +1,18 @@
 package task
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
 	"github.com/vinayprograms/karya/internal/config"
 )
 
-// AgendaItem represents a single entry in the agenda view.
+// AgendaItem represents a single entry in the agenda view. Items from the
+// configured calendars have an Event and no Task; they are read-only.
 type AgendaItem struct {
 	Task        *Task
+	Event       *CalendarEvent
 	Date        time.Time
 	HasTime     bool
 	HasEnd      bool
@@ -22,6 +25,22 @@ type AgendaItem struct {
 	IsCompleted bool
 	CompletedAt time.Time
 	TargetState string
+	// Span is set on the items of a range spanning days (DATE--DATE),
+	// one for each day it covers.
+	Span *AgendaSpan
+}
+
+// AgendaSpan places an agenda item within a range spanning days: the item
+// is day Day of Days of the occurrence running from Start to End. The first
+// day's item starts at Start; the others are all-day.
+type AgendaSpan struct {
+	Start, End time.Time
+	Day, Days  int
+}
+
+// Label returns the span's marker for titles, such as "(day 2/5)".
+func (s *AgendaSpan) Label() string {
+	return fmt.Sprintf("(day %d/%d)", s.Day, s.Days)
 }
 
 // AgendaDay groups agenda items appearing on a single date.
@@ -33,6 +52,8 @@ type AgendaDay struct {
 // QueryAgenda loads all tasks and returns agenda items within [start, end],
 // grouped by day. Only tasks with scheduled or due dates are included.
 // When includeOverdue is true, past-due items appear on today's date.
+// Events of the calendars in schedule.calendars are merged in: timed events
+// on their start day, all-day events on every day they cover.
 func QueryAgenda(c *config.Config, start, end time.Time, includeOverdue bool) ([]AgendaDay, error) {
 	tasks, err := ListTasks(c, "", true)
 	if err != nil {
@@ -106,11 +127,22 @@ func QueryAgenda(c *config.Config, start, end time.Time, includeOverdue bool) ([
 		}
 	}
 
+	events, err := CalendarEvents(c, startDay, endDay)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		addEventEntries(&events[i], startDay, endDay, dayMap)
+	}
+
 	// Mark items with active clocks only on today's occurrence
 	clockCache := make(map[*Task]bool)
 	for date, items := range dayMap {
 		for i := range items {
 			t := items[i].Task
+			if t == nil {
+				continue
+			}
 			if _, ok := clockCache[t]; !ok {
 				clockCache[t] = IsClockActive(t)
 			}
@@ -146,11 +178,17 @@ func addAgendaEntries(c *config.Config, t *Task, dateToken string, isDeadline bo
 	}
 
 	if sched.Recurrence != nil {
-		// Recurring: expand occurrences in range
-		occurrences := sched.ExpandOccurrences(start, end)
+		// Recurring: expand occurrences in range, including those of a range
+		// token that start earlier but still cover its first days
+		length := len(sched.SpanDays(sched.Date))
+		occurrences := sched.ExpandOccurrences(start.AddDate(0, 0, 1-length), end)
 		for _, occ := range occurrences {
 			day := truncateToDay(occ)
-			overdue := day.Before(today)
+			lastDay := lastOf(sched.SpanDays(occ))
+			if lastDay.Before(start) {
+				continue
+			}
+			overdue := lastDay.Before(today)
 			if overdue && !includeOverdue {
 				continue
 			}
@@ -159,7 +197,7 @@ func addAgendaEntries(c *config.Config, t *Task, dateToken string, isDeadline bo
 				Date:       occ,
 				HasTime:    sched.HasTime,
 				HasEnd:     sched.HasEnd,
-				EndTime:    sched.EndTime,
+				EndTime:    sched.EndOn(occ),
 				IsOverdue:  overdue && includeOverdue,
 				IsDeadline: isDeadline,
 				Schedule:   sched,
@@ -168,16 +206,19 @@ func addAgendaEntries(c *config.Config, t *Task, dateToken string, isDeadline bo
 				warningStart := day.AddDate(0, 0, -warningDays)
 				item.Warning = !today.Before(warningStart) && today.Before(day)
 			}
-			targetDay := day
-			if item.IsOverdue {
-				targetDay = today
+			switch {
+			case item.IsOverdue:
+				dayMap[today] = append(dayMap[today], item)
+			case sched.IsRange():
+				addSpanItems(item, sched, start, end, dayMap)
+			default:
+				dayMap[day] = append(dayMap[day], item)
 			}
-			dayMap[targetDay] = append(dayMap[targetDay], item)
 		}
 
 		// For recurring tasks, also check if overdue (most recent missed)
 		if includeOverdue {
-			schedDay := truncateToDay(sched.Date)
+			schedDay := lastOf(sched.SpanDays(sched.Date))
 			if schedDay.Before(today) && schedDay.Before(start) {
 				if sched.Recurrence.Mode == RecurrenceFromDone {
 					// .+ mode: only the stored date matters (no predictable series)
@@ -197,21 +238,29 @@ func addAgendaEntries(c *config.Config, t *Task, dateToken string, isDeadline bo
 					dayMap[today] = append(dayMap[today], item)
 				} else {
 					// + and ++ modes: walk forward to find most recent missed occurrence
-					current := sched.Date
+					current := sched.Date.In(sched.loc())
 					var lastMissed time.Time
-					for !truncateToDay(current).After(today) {
-						if truncateToDay(current).Before(today) {
-							lastMissed = current
+					for n := 1; ; n++ {
+						local := current.In(time.Local)
+						if truncateToDay(local).After(today) || !sched.Recurrence.Continues(n, local) {
+							break
+						}
+						if lastOf(sched.SpanDays(local)).Before(today) {
+							lastMissed = local
 						}
-						current = addInterval(current, sched.Recurrence.Interval, sched.Recurrence.Unit)
+						next, ok := sched.Recurrence.Next(current)
+						if !ok {
+							break
+						}
+						current = next
 					}
-					if !lastMissed.IsZero() && truncateToDay(lastMissed).Before(start) {
+					if !lastMissed.IsZero() && lastOf(sched.SpanDays(lastMissed)).Before(start) {
 						item := AgendaItem{
 							Task:       t,
 							Date:       lastMissed,
 							HasTime:    sched.HasTime,
 							HasEnd:     sched.HasEnd,
-							EndTime:    sched.EndTime,
+							EndTime:    sched.EndOn(lastMissed),
 							IsOverdue:  true,
 							IsDeadline: isDeadline,
 							Schedule:   sched,
@@ -222,12 +271,13 @@ func addAgendaEntries(c *config.Config, t *Task, dateToken string, isDeadline bo
 			}
 		}
 	} else {
-		// Non-recurring: single date
+		// Non-recurring: single date, or the days of a range
 		schedDay := truncateToDay(sched.Date)
+		lastDay := lastOf(sched.SpanDays(sched.Date))
 		completed := t.IsCompleted(c)
-		isOverdue := schedDay.Before(today) && !completed
+		isOverdue := lastDay.Before(today) && !completed
 
-		inRange := (schedDay.Before(end) || schedDay.Equal(end)) && (schedDay.After(start) || schedDay.Equal(start))
+		inRange := !schedDay.After(end) && !lastDay.Before(start)
 
 		if inRange {
 			displaced := isOverdue && includeOverdue
@@ -242,7 +292,7 @@ func addAgendaEntries(c *config.Config, t *Task, dateToken string, isDeadline bo
 				IsCompleted: completed,
 				Schedule:    sched,
 			}
-			if completed {
+			if completed && !sched.IsRange() {
 				item.CompletedAt = lastClockOut(t, schedDay)
 				if !item.CompletedAt.IsZero() && item.HasTime {
 					item.Date = item.CompletedAt
@@ -253,11 +303,14 @@ func addAgendaEntries(c *config.Config, t *Task, dateToken string, isDeadline bo
 				warningStart := schedDay.AddDate(0, 0, -warningDays)
 				item.Warning = !today.Before(warningStart) && today.Before(schedDay)
 			}
-			targetDay := schedDay
-			if displaced {
-				targetDay = today
+			switch {
+			case displaced:
+				dayMap[today] = append(dayMap[today], item)
+			case sched.IsRange():
+				addSpanItems(item, sched, start, end, dayMap)
+			default:
+				dayMap[schedDay] = append(dayMap[schedDay], item)
 			}
-			dayMap[targetDay] = append(dayMap[targetDay], item)
 		} else if isOverdue && includeOverdue {
 			item := AgendaItem{
 				Task:       t,
@@ -277,6 +330,61 @@ func addAgendaEntries(c *config.Config, t *Task, dateToken string, isDeadline bo
 	}
 }
 
+// addSpanItems adds item, an occurrence of a range spanning days, on each
+// day it covers within [start, end].
+func addSpanItems(item AgendaItem, sched *Schedule, start, end time.Time, dayMap map[time.Time][]AgendaItem) {
+	days := sched.SpanDays(item.Date)
+	spanEnd := sched.EndDateOn(item.Date)
+	if len(days) == 1 {
+		// A timed range within one day, or ending at midnight, is an item
+		// with an end time
+		item.HasEnd, item.EndTime = true, spanEnd
+		dayMap[days[0]] = append(dayMap[days[0]], item)
+		return
+	}
+	for i, day := range days {
+		if day.Before(start) || day.After(end) {
+			continue
+		}
+		dayItem := item
+		dayItem.Span = &AgendaSpan{Start: item.Date, End: spanEnd, Day: i + 1, Days: len(days)}
+		if i > 0 {
+			dayItem.Date = day
+			dayItem.HasTime = false
+			dayItem.Warning = false
+		}
+		dayMap[day] = append(dayMap[day], dayItem)
+	}
+}
+
+// lastOf returns the last of a non-empty list of days.
+func lastOf(days []time.Time) time.Time {
+	return days[len(days)-1]
+}
+
+func addEventEntries(ev *CalendarEvent, start, end time.Time, dayMap map[time.Time][]AgendaItem) {
+	if !ev.AllDay {
+		day := truncateToDay(ev.Start)
+		if day.Before(start) || day.After(end) {
+			return
+		}
+		dayMap[day] = append(dayMap[day], AgendaItem{
+			Event:   ev,
+			Date:    ev.Start,
+			HasTime: true,
+			HasEnd:  ev.End.After(ev.Start),
+			EndTime: ev.End,
+		})
+		return
+	}
+	for day := truncateToDay(ev.Start); day.Before(ev.End); day = day.AddDate(0, 0, 1) {
+		if day.Before(start) || day.After(end) {
+			continue
+		}
+		dayMap[day] = append(dayMap[day], AgendaItem{Event: ev, Date: day})
+	}
+}
+
 // lastClockOut returns the end time of the last closed clock entry on the given day.
 // Falls back to the day at 00:00 if no clock entries exist.
 func lastClockOut(t *Task, day time.Time) time.Time {
@@ -285,7 +393,7 @@ func lastClockOut(t *Task, day time.Time) time.Time {
 		return day
 	}
 	dayStart := truncateToDay(day)
-	dayEnd := dayStart.Add(24 * time.Hour)
+	dayEnd := dayStart.AddDate(0, 0, 1)
 	var latest time.Time
 	for _, e := range entries {
 		if e.Open || e.End.IsZero() {
@@ -303,7 +411,7 @@ func lastClockOut(t *Task, day time.Time) time.Time {
 	return latest
 }
 
-// sortAgendaItems sorts items within a day: overdue first, then timed (by time), then untimed (by priority), completed last.
+// sortAgendaItems sorts items within a day: overdue first, then timed (by time), then untimed (all-day events, then tasks by priority and priority cookie), completed last.
 func sortAgendaItems(items []AgendaItem, c *config.Config) {
 	sort.SliceStable(items, func(i, j int) bool {
 		// Completed items last
@@ -326,8 +434,17 @@ func sortAgendaItems(items []AgendaItem, c *config.Config) {
 		if items[i].HasTime && items[j].HasTime {
 			return items[i].Date.Before(items[j].Date)
 		}
-		// Among untimed, sort by priority
-		return items[i].Task.Priority(c) < items[j].Task.Priority(c)
+		// Among untimed, all-day events first, then tasks by priority and priority cookie
+		if (items[i].Event != nil) != (items[j].Event != nil) {
+			return items[i].Event != nil
+		}
+		if items[i].Event != nil {
+			return items[i].Event.Summary < items[j].Event.Summary
+		}
+		if items[i].Task.Priority(c) != items[j].Task.Priority(c) {
+			return items[i].Task.Priority(c) < items[j].Task.Priority(c)
+		}
+		return items[i].Task.CookieRank() < items[j].Task.CookieRank()
 	})
 }
 
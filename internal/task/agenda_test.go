@@ -0,0 +1,70 @@
+package task
+
+import (
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestAddAgendaEntries_Range(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.Local) }
+	tests := []struct {
+		name           string
+		token          string
+		keyword        string
+		today          time.Time
+		includeOverdue bool
+		want           []string // day, then the item's span marker or "overdue", and its time
+	}{
+		{"covers the view's first days", "2025-06-30--2025-07-03", "TODO", day(6, 1), false,
+			[]string{"07-01 (day 2/4)", "07-02 (day 3/4)", "07-03 (day 4/4)"}},
+		{"timed", "2025-07-01T18:00--2025-07-03T09:00", "TODO", day(6, 1), false,
+			[]string{"07-01 (day 1/3) 18:00", "07-02 (day 2/3)", "07-03 (day 3/3)"}},
+		{"ending at midnight", "2025-07-01T22:00--2025-07-02T00:00", "TODO", day(6, 1), false,
+			[]string{"07-01 22:00-00:00"}},
+		{"recurring", "2025-06-23--2025-06-29+1w", "TODO", day(6, 1), false,
+			[]string{"07-01 (day 2/7)", "07-02 (day 3/7)", "07-03 (day 4/7)", "07-04 (day 5/7)", "07-05 (day 6/7)", "07-06 (day 7/7)", "07-07 (day 1/7)"}},
+		{"in progress isn't overdue", "2025-06-28--2025-07-03", "TODO", day(7, 2), true,
+			[]string{"07-01 (day 4/6)", "07-02 (day 5/6)", "07-03 (day 6/6)"}},
+		{"ended shows once on today", "2025-06-20--2025-06-25", "TODO", day(7, 2), true,
+			[]string{"07-02 overdue"}},
+		{"ended and done", "2025-06-30--2025-07-01", "DONE", day(7, 2), true,
+			[]string{"07-01 (day 2/2)"}},
+		{"recurring ended moves to today", "2025-06-30--2025-07-01+1w", "TODO", day(7, 5), true,
+			[]string{"07-05 overdue", "07-07 (day 1/2)"}},
+	}
+
+	c := createTestConfig()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{Keyword: tc.keyword, Title: "Offsite", ScheduledAt: tc.token}
+			dayMap := make(map[time.Time][]AgendaItem)
+			addAgendaEntries(c, task, tc.token, false, day(7, 1), day(7, 7), tc.today, tc.includeOverdue, dayMap)
+
+			var got []string
+			for d, items := range dayMap {
+				for _, item := range items {
+					entry := d.Format("01-02")
+					switch {
+					case item.IsOverdue:
+						entry += " overdue"
+					case item.Span != nil:
+						entry += " " + item.Span.Label()
+					}
+					if item.HasTime {
+						entry += " " + item.Date.Format("15:04")
+					}
+					if item.HasEnd {
+						entry += "-" + item.EndTime.Format("15:04")
+					}
+					got = append(got, entry)
+				}
+			}
+			sort.Strings(got)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("agenda = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// archiveFileRe matches archive file names: ARCHIVE.md or ARCHIVE-2025.md.
+var archiveFileRe = regexp.MustCompile(`^ARCHIVE(?:-\d{4})?\.md$`)
+
+var ageRe = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// IsArchiveFile reports whether the path is a task archive written by
+// ArchiveTasks.
+func IsArchiveFile(path string) bool {
+	return archiveFileRe.MatchString(filepath.Base(path))
+}
+
+// ArchiveOptions controls which tasks ArchiveTasks moves and where.
+type ArchiveOptions struct {
+	OlderThan time.Duration // only archive tasks completed at least this long ago
+	PerYear   bool          // write to ARCHIVE-<year>.md by completion year
+	Now       time.Time     // archive date; zero means time.Now()
+}
+
+// ArchivedTask records one task moved by ArchiveTasks.
+type ArchivedTask struct {
+	Task    *Task  // the task as it was in its source file
+	Archive string // archive file it was moved to
+}
+
+// ArchiveTasks moves completed top-level task blocks (with their children,
+// CLOCK/LOG lines and properties) out of the project's notes into an archive
+// file next to them. Only blocks whose tasks are all completed are moved.
+// Each archived task gets "archived_from:: <zettel>" and "archived_on::
+// <date>" properties. An empty project archives every project.
+//
+// It returns the archived tasks and every file it wrote, for committing.
+func ArchiveTasks(c *config.Config, project string, opts ArchiveOptions) ([]ArchivedTask, []string, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	files, err := FindFiles(c, project)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(files)
+
+	var archived []ArchivedTask
+	archiveBlocks := make(map[string][]string)
+	var archiveOrder []string
+	sourceContent := make(map[string][]byte)
+	sourceLines := make(map[string][]string)
+
+	for _, file := range files {
+		if IsArchiveFile(file) {
+			continue
+		}
+		tasks, err := ProcessFile(c, file)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var candidates []*Task
+		for _, t := range tasks {
+			if t.Parent != nil || !t.IsCompleted(c) || !allCompleted(t, c) {
+				continue
+			}
+			if opts.OlderThan > 0 && now.Sub(completedAt(c, t)) < opts.OlderThan {
+				continue
+			}
+			candidates = append(candidates, t)
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		lines := strings.Split(string(content), "\n")
+
+		// Take every block from the content read here, then cut them out
+		// bottom-up so earlier line numbers stay valid
+		blocks := make([][]string, len(candidates))
+		for i, t := range candidates {
+			if t.LineNum > len(lines) || !isTaskLine(lines[t.LineNum-1], t) {
+				return nil, nil, &ConflictError{Path: file, Task: t.Keyword + ": " + t.Title, Reason: "file changed while archiving"}
+			}
+			blocks[i] = trimBlock(blockLines(lines, t.LineNum, t.IndentLevel))
+		}
+		for i := len(candidates) - 1; i >= 0; i-- {
+			start := candidates[i].LineNum - 1
+			lines = append(lines[:start:start], lines[start+len(blocks[i]):]...)
+			// Don't leave two blank lines where the block was
+			if start > 0 && start < len(lines)-1 && strings.TrimSpace(lines[start-1]) == "" && strings.TrimSpace(lines[start]) == "" {
+				lines = append(lines[:start], lines[start+1:]...)
+			}
+		}
+		sourceContent[file] = content
+		sourceLines[file] = lines
+
+		for i, t := range candidates {
+			dest := archivePath(c, t, opts.PerYear)
+			if _, ok := archiveBlocks[dest]; !ok {
+				archiveOrder = append(archiveOrder, dest)
+			}
+			archiveBlocks[dest] = append(archiveBlocks[dest], archiveBlock(blocks[i], t, now)...)
+			archived = append(archived, ArchivedTask{Task: t, Archive: dest})
+		}
+	}
+
+	// Hold every lock while writing, and check the sources are unchanged
+	// before touching the archives so a conflict leaves everything as it was
+	locked := slices.Clone(archiveOrder)
+	for file := range sourceLines {
+		locked = append(locked, file)
+	}
+	unlock, err := lockFiles(locked...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+	for file, content := range sourceContent {
+		if current, err := os.ReadFile(file); err != nil || string(current) != string(content) {
+			return nil, nil, &ConflictError{Path: file, Reason: "file changed while archiving"}
+		}
+	}
+
+	// Write archives before trimming the sources so a failure never loses tasks
+	var written []string
+	journal := &JournalEntry{Kind: JournalEdit, Summary: fmt.Sprintf("Archive %d task(s)", len(archived))}
+	defer appendJournal(journal)
+	for _, dest := range archiveOrder {
+		before, err := os.ReadFile(dest)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+		after := appendToArchive(before, archiveBlocks[dest])
+		if err := writeFileAtomic(dest, after); err != nil {
+			return nil, nil, err
+		}
+		if change, ok := diffChange(dest, before, after); ok {
+			journal.Changes = append(journal.Changes, change)
+		}
+		written = append(written, dest)
+	}
+	for _, file := range files {
+		lines, ok := sourceLines[file]
+		if !ok {
+			continue
+		}
+		after := []byte(strings.Join(lines, "\n"))
+		if err := writeIfUnchanged(file, sourceContent[file], after); err != nil {
+			return nil, nil, err
+		}
+		if change, ok := diffChange(file, sourceContent[file], after); ok {
+			journal.Changes = append(journal.Changes, change)
+		}
+		written = append(written, file)
+	}
+
+	return archived, written, nil
+}
+
+// allCompleted reports whether every descendant of the task is completed.
+func allCompleted(t *Task, c *config.Config) bool {
+	for _, child := range t.Children {
+		if !child.IsCompleted(c) || !allCompleted(child, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// completedAt returns when the task was last moved to a completed state,
+// falling back to its file's modification time when no LOG entry records it.
+func completedAt(c *config.Config, t *Task) time.Time {
+	var latest time.Time
+	if transitions, err := ParseStateTransitions(t); err == nil {
+		for _, tr := range transitions {
+			if (tr.To == "COMPLETED" || IsCompletedKeyword(c, tr.To)) && tr.Timestamp.After(latest) {
+				latest = tr.Timestamp
+			}
+		}
+	}
+	if latest.IsZero() {
+		if info, err := os.Stat(t.FilePath); err == nil {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// archivePath returns the archive file for a task: inside the project's
+// notes directory when it has one (so the archive is committed with the
+// notes), otherwise in the project directory.
+func archivePath(c *config.Config, t *Task, perYear bool) string {
+	dir := filepath.Join(c.Directories.Projects, t.Project)
+	if info, err := os.Stat(filepath.Join(dir, "notes")); err == nil && info.IsDir() {
+		dir = filepath.Join(dir, "notes")
+	}
+	name := "ARCHIVE.md"
+	if perYear {
+		year := completedAt(c, t).Year()
+		name = fmt.Sprintf("ARCHIVE-%d.md", year)
+	}
+	return filepath.Join(dir, name)
+}
+
+// archiveBlock re-indents a task block to the left margin and adds the
+// archive properties after the task line.
+func archiveBlock(block []string, t *Task, now time.Time) []string {
+	block = reindentBlock(block, "")
+	indent := strings.Repeat(" ", subItemWriteIndent(block))
+	props := []string{
+		fmt.Sprintf("%s- archived_from:: %s", indent, t.Zettel),
+		fmt.Sprintf("%s- archived_on:: %s", indent, now.Format("2006-01-02")),
+	}
+	out := make([]string, 0, len(block)+len(props))
+	out = append(out, block[0])
+	out = append(out, props...)
+	return append(out, block[1:]...)
+}
+
+// appendToArchive returns the content of an archive file with blocks
+// appended, starting it with a heading if it is empty.
+func appendToArchive(content []byte, blocks []string) []byte {
+	text := string(content)
+	if text == "" {
+		text = "# Archive\n\n"
+	} else if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	text += strings.Join(blocks, "\n") + "\n"
+	return []byte(text)
+}
+
+// ParseAge parses an age such as "30d" or "2w", or any time.ParseDuration
+// value such as "36h".
+func ParseAge(s string) (time.Duration, error) {
+	if m := ageRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := 24 * time.Hour
+		if m[2] == "w" {
+			unit *= 7
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (e.g. 30d, 2w, 36h)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,173 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveTasks(t *testing.T) {
+	note := strings.Join([]string{
+		"# Sprint",
+		"",
+		"TODO: Keep me",
+		"DONE: Old release",
+		"  * LOG(TODO -> DONE): 2025-05-01T10:00",
+		"  * CLOCK: 2025-04-30T09:00--2025-04-30T11:00",
+		"  - DONE: Old subtask",
+		"",
+		"DONE: Parent with open child",
+		"  - TODO: Still open",
+		"DONE: Recent",
+		"  * LOG(DOING -> DONE): 2025-06-28T10:00",
+		"",
+		"Closing notes.",
+		"",
+	}, "\n")
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name        string
+		opts        ArchiveOptions
+		wantTitles  string
+		wantArchive string // archive file name inside notes/
+		wantSource  string
+	}{
+		{
+			name:        "all completed blocks",
+			opts:        ArchiveOptions{Now: now},
+			wantTitles:  "Old release|Recent",
+			wantArchive: "ARCHIVE.md",
+			wantSource:  "# Sprint\n\nTODO: Keep me\n\nDONE: Parent with open child\n  - TODO: Still open\n\nClosing notes.\n",
+		},
+		{
+			name:        "older than",
+			opts:        ArchiveOptions{Now: now, OlderThan: 30 * 24 * time.Hour},
+			wantTitles:  "Old release",
+			wantArchive: "ARCHIVE.md",
+			wantSource:  "# Sprint\n\nTODO: Keep me\n\nDONE: Parent with open child\n  - TODO: Still open\nDONE: Recent\n  * LOG(DOING -> DONE): 2025-06-28T10:00\n\nClosing notes.\n",
+		},
+		{
+			name:        "per year",
+			opts:        ArchiveOptions{Now: now, OlderThan: 30 * 24 * time.Hour, PerYear: true},
+			wantTitles:  "Old release",
+			wantArchive: "ARCHIVE-2025.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			notePath := filepath.Join(dir, "web", "notes", "20250101000000", "README.md")
+			os.MkdirAll(filepath.Dir(notePath), 0755)
+			os.WriteFile(notePath, []byte(note), 0644)
+
+			cfg := createTestConfig()
+			cfg.Directories.Projects = dir
+			cfg.Todo.Structured = true
+
+			archived, files, err := ArchiveTasks(cfg, "web", tt.opts)
+			if err != nil {
+				t.Fatalf("ArchiveTasks() error: %v", err)
+			}
+			var titles []string
+			for _, a := range archived {
+				titles = append(titles, a.Task.Title)
+			}
+			if got := strings.Join(titles, "|"); got != tt.wantTitles {
+				t.Errorf("archived %q, want %q", got, tt.wantTitles)
+			}
+
+			archivePath := filepath.Join(dir, "web", "notes", tt.wantArchive)
+			if len(files) != 2 || files[0] != archivePath || files[1] != notePath {
+				t.Errorf("files = %v, want [%s %s]", files, archivePath, notePath)
+			}
+			if tt.wantSource != "" {
+				if got, _ := os.ReadFile(notePath); string(got) != tt.wantSource {
+					t.Errorf("source = %q, want %q", got, tt.wantSource)
+				}
+			}
+
+			content, err := os.ReadFile(archivePath)
+			if err != nil {
+				t.Fatalf("archive not written: %v", err)
+			}
+			wantBlock := strings.Join([]string{
+				"DONE: Old release",
+				"  - archived_from:: 20250101000000",
+				"  - archived_on:: 2025-07-01",
+				"  * LOG(TODO -> DONE): 2025-05-01T10:00",
+				"  * CLOCK: 2025-04-30T09:00--2025-04-30T11:00",
+				"  - DONE: Old subtask",
+			}, "\n")
+			if !strings.HasPrefix(string(content), "# Archive\n\n"+wantBlock+"\n") {
+				t.Errorf("archive = %q, want it to start with the archived block", content)
+			}
+
+			// Archives are hidden from FindFiles unless asked for
+			found, _ := FindFiles(cfg, "web")
+			if len(found) != 1 {
+				t.Errorf("FindFiles() = %v, want only the note", found)
+			}
+			cfg.Todo.IncludeArchive = true
+			found, _ = FindFiles(cfg, "web")
+			if len(found) != 2 || found[1] != archivePath {
+				t.Fatalf("FindFiles() with IncludeArchive = %v, want the archive too", found)
+			}
+			tasks, _ := ProcessFile(cfg, archivePath)
+			if len(tasks) == 0 || tasks[0].Project != "web" || tasks[0].Properties["archived_from"] != "20250101000000" {
+				t.Errorf("archived task parsed as %+v", tasks)
+			}
+		})
+	}
+}
+
+func TestArchiveTasks_Unstructured(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "home"), 0755)
+	os.WriteFile(filepath.Join(dir, "home", "chores.md"), []byte("TODO: Dishes\nDONE: Laundry\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+
+	if _, _, err := ArchiveTasks(cfg, "home", ArchiveOptions{}); err != nil {
+		t.Fatalf("ArchiveTasks() error: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "home", "chores.md")); string(got) != "TODO: Dishes\n" {
+		t.Errorf("source = %q", got)
+	}
+	tasks, _ := ListTasks(cfg, "home", true)
+	if len(tasks) != 1 || tasks[0].Title != "Dishes" {
+		t.Errorf("ListTasks() should skip ARCHIVE.md, got %d tasks", len(tasks))
+	}
+	cfg.Todo.IncludeArchive = true
+	tasks, _ = ListTasks(cfg, "home", true)
+	if len(tasks) != 2 {
+		t.Errorf("ListTasks() with IncludeArchive got %d tasks, want 2", len(tasks))
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"1y", 0, true},
+		{"-5h", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseAge(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseAge(%q) = %v, %v; want %v, err %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// MarkBlocked sets Blocked on every task of tasks that isn't completed and
+// references (^id) a task of tasks that isn't completed either. References
+// to tasks outside tasks don't block, so tasks should hold the whole
+// workspace, completed tasks included; ListTasks marks a project's tasks
+// against every task of the workspace before dropping completed ones.
+func MarkBlocked(c *config.Config, tasks []*Task) {
+	markBlocked(c, tasks, openIDs(c, tasks))
+}
+
+// openIDs returns the IDs of the tasks of tasks that aren't completed.
+func openIDs(c *config.Config, tasks []*Task) map[string]bool {
+	open := make(map[string]bool)
+	for _, t := range tasks {
+		if t.ID != "" && !t.IsCompleted(c) {
+			open[t.ID] = true
+		}
+	}
+	return open
+}
+
+// markBlocked sets Blocked on every task of tasks that isn't completed and
+// references an ID of open.
+func markBlocked(c *config.Config, tasks []*Task, open map[string]bool) {
+	for _, t := range tasks {
+		t.Blocked = false
+		if t.IsCompleted(c) {
+			continue
+		}
+		for _, ref := range t.References {
+			if ref != t.ID && open[ref] {
+				t.Blocked = true
+				break
+			}
+		}
+	}
+}
+
+// BlockingTasks returns the tasks of tasks that t references and that
+// aren't completed yet. A completed task has none.
+func BlockingTasks(c *config.Config, tasks []*Task, t *Task) []*Task {
+	if t.IsCompleted(c) {
+		return nil
+	}
+	var blocking []*Task
+	for _, dep := range GetDependencies(tasks, t) {
+		if dep != t && !dep.IsCompleted(c) {
+			blocking = append(blocking, dep)
+		}
+	}
+	return blocking
+}
+
+// IsReady reports whether the task can be worked on now: it is active or in
+// progress and not blocked (see MarkBlocked).
+func IsReady(c *config.Config, t *Task) bool {
+	return (t.IsActive(c) || t.IsInProgress(c)) && !t.Blocked
+}
+
+// UnblockDependents moves the tasks waiting on done (todo.waiting_keyword)
+// that have no other open dependency to todo.ready_keyword, recording the
+// transition, and returns them. Call it after completing done; inside
+// Journaled the moves join done's journal entry, so undoing the completion
+// undoes them too. It does nothing without a waiting keyword, or when done
+// has no ID or isn't completed.
+func UnblockDependents(c *config.Config, done *Task) ([]*Task, error) {
+	waiting, ready := c.WaitingKeywords()
+	if waiting == "" || done.ID == "" || !done.IsCompleted(c) {
+		return nil, nil
+	}
+	tasks, err := ListTasks(c, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	var unblocked []*Task
+	for _, t := range tasks {
+		if t.Keyword != waiting || t.Blocked || !slices.Contains(t.References, done.ID) {
+			continue
+		}
+		move := func() error {
+			if err := UpdateTaskStatus(t, ready, c); err != nil {
+				return err
+			}
+			return RecordStateTransition(t, waiting, ready)
+		}
+		if done.journal != nil {
+			t.journal = done.journal
+			err = move()
+			t.journal = nil
+		} else {
+			err = Journaled(t, fmt.Sprintf("Status %s -> %s: %s", waiting, ready, t.Title), move)
+		}
+		if err != nil {
+			return unblocked, fmt.Errorf("%s: %w", t.Title, err)
+		}
+		unblocked = append(unblocked, t)
+	}
+	return unblocked, nil
+}
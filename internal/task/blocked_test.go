@@ -0,0 +1,136 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMarkBlocked(t *testing.T) {
+	cfg := createTestConfig()
+
+	tests := []struct {
+		name string
+		task *Task
+		want bool
+	}{
+		{"no references", &Task{Keyword: "TODO", ID: "t1"}, false},
+		{"open dependency", &Task{Keyword: "TODO", References: []string{"open"}}, true},
+		{"completed dependency", &Task{Keyword: "TODO", References: []string{"done"}}, false},
+		{"unknown dependency", &Task{Keyword: "TODO", References: []string{"gone"}}, false},
+		{"one of several open", &Task{Keyword: "DOING", References: []string{"done", "open"}}, true},
+		{"self reference", &Task{Keyword: "TODO", ID: "self", References: []string{"self"}}, false},
+		{"completed tasks aren't blocked", &Task{Keyword: "DONE", References: []string{"open"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := []*Task{
+				{Keyword: "TODO", ID: "open"},
+				{Keyword: "DONE", ID: "done"},
+				tt.task,
+			}
+			MarkBlocked(cfg, tasks)
+			if tt.task.Blocked != tt.want {
+				t.Errorf("Blocked = %v, want %v", tt.task.Blocked, tt.want)
+			}
+			if ready := IsReady(cfg, tt.task); ready != (!tt.want && !tt.task.IsCompleted(cfg)) {
+				t.Errorf("IsReady() = %v", ready)
+			}
+			if n := len(BlockingTasks(cfg, tasks, tt.task)); (n > 0) != tt.want {
+				t.Errorf("BlockingTasks() = %d tasks, want blocked = %v", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestListTasks_BlockedAcrossProjects(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.WriteFile(filepath.Join(dir, "web", "tasks.md"), []byte("TODO: Ship UI ^schema\nTODO: Ship docs ^auth\nTODO: Polish ^triage\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "api", "tasks.md"), []byte("TODO: [schema] Migrate schema\nDONE: [auth] Add auth\n"), 0644)
+	karya := t.TempDir()
+	os.WriteFile(filepath.Join(karya, "inbox.md"), []byte("TODO: [triage] Triage bugs\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+	cfg.Directories.Karya = karya
+
+	tasks, err := ListTasks(cfg, "web", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"Ship UI": true, "Ship docs": false, "Polish": true}
+	for _, task := range tasks {
+		if task.Blocked != want[task.Title] || IsReady(cfg, task) == want[task.Title] {
+			t.Errorf("%s: Blocked = %v, IsReady = %v; want blocked %v", task.Title, task.Blocked, IsReady(cfg, task), want[task.Title])
+		}
+	}
+	if len(tasks) != len(want) {
+		t.Errorf("ListTasks(web) = %d tasks, want %d", len(tasks), len(want))
+	}
+}
+
+func TestUnblockDependents(t *testing.T) {
+	dir := t.TempDir()
+	web := filepath.Join(dir, "web", "tasks.md")
+	api := filepath.Join(dir, "api", "tasks.md")
+	os.MkdirAll(filepath.Dir(web), 0755)
+	os.MkdirAll(filepath.Dir(api), 0755)
+	original := "TODO: [db] Migrate schema\nWAITING: Deploy ^db\nWAITING: Cut release ^db ^qa\nTODO: Docs ^db\n"
+	os.WriteFile(web, []byte(original), 0644)
+	os.WriteFile(api, []byte("TODO: [qa] Run QA\nWAITING: Announce ^db\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Todo.Someday = append(cfg.Todo.Someday, "WAITING")
+	cfg.Todo.WaitingKeyword = "WAITING"
+	cfg.Directories.Projects = dir
+	cfg.Directories.Karya = t.TempDir()
+	EnableJournal(cfg)
+	t.Cleanup(func() { journalPath = "" })
+
+	tasks, _ := ListTasks(cfg, "web", false)
+	for _, task := range tasks {
+		if want := task.Keyword == "WAITING" || task.Title == "Docs"; task.Blocked != want {
+			t.Errorf("%s Blocked = %v, want %v", task.Title, task.Blocked, want)
+		}
+	}
+
+	done := tasks[0]
+	var unblocked []*Task
+	err := Journaled(done, "Complete", func() error {
+		if err := UpdateTaskStatus(done, "DONE", cfg); err != nil {
+			return err
+		}
+		var err error
+		unblocked, err = UnblockDependents(cfg, done)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("UnblockDependents() error: %v", err)
+	}
+	if len(unblocked) != 2 {
+		t.Fatalf("UnblockDependents() = %d tasks, want Deploy and Announce", len(unblocked))
+	}
+	got, _ := os.ReadFile(web)
+	if lines := strings.Split(string(got), "\n"); len(lines) != 6 || lines[1] != "TODO: Deploy ^db" ||
+		!strings.HasPrefix(lines[2], "  * LOG(WAITING -> TODO): ") || lines[3] != "WAITING: Cut release ^db ^qa" {
+		t.Errorf("web tasks = %q", got)
+	}
+	if got, _ := os.ReadFile(api); !strings.Contains(string(got), "\nTODO: Announce ^db\n") {
+		t.Errorf("api tasks = %q", got)
+	}
+
+	// Undoing the completion puts the dependents back
+	if _, err := Undo(cfg, 1); err != nil {
+		t.Fatalf("Undo() error: %v", err)
+	}
+	if got, _ := os.ReadFile(web); string(got) != original {
+		t.Errorf("web tasks after undo = %q", got)
+	}
+	if got, _ := os.ReadFile(api); string(got) != "TODO: [qa] Run QA\nWAITING: Announce ^db\n" {
+		t.Errorf("api tasks after undo = %q", got)
+	}
+}
@@ -0,0 +1,723 @@
+package task
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// CalendarEvent is one occurrence of an event read from the iCalendar files
+// listed in schedule.calendars. Events are read-only: the agenda shows them
+// next to tasks but karya never writes them back. End is exclusive; for
+// all-day events Start and End are midnights.
+type CalendarEvent struct {
+	Calendar    string
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+}
+
+// icsEvent is a VEVENT as written, before recurrence expansion.
+type icsEvent struct {
+	uid, summary, location, description string
+	start, end                          time.Time
+	duration                            time.Duration
+	hasEnd, allDay, cancelled           bool
+	rrule                               string
+	exdates                             []time.Time
+	recurrenceID                        time.Time
+}
+
+// CalendarEvents returns the occurrences of the configured calendars' events
+// that overlap the days [start, end], sorted by start. Each entry of
+// schedule.calendars is an .ics file or a directory searched for them, as
+// kept by calendar sync tools. Recurring events are expanded (see
+// expandRRule), honouring EXDATE and RECURRENCE-ID overrides; cancelled
+// events are left out.
+func CalendarEvents(c *config.Config, start, end time.Time) ([]CalendarEvent, error) {
+	rangeStart := truncateToDay(start)
+	rangeEnd := truncateToDay(end).AddDate(0, 0, 1)
+
+	var occurrences []CalendarEvent
+	for _, path := range c.Schedule.Calendars {
+		name, events, err := readCalendar(path)
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: %w", path, err)
+		}
+		occurrences = append(occurrences, expandEvents(name, events, rangeStart, rangeEnd)...)
+	}
+	slices.SortStableFunc(occurrences, func(a, b CalendarEvent) int { return a.Start.Compare(b.Start) })
+	return occurrences, nil
+}
+
+// readCalendar parses the .ics file at path, or every .ics file under the
+// directory at path. The calendar is named by the first X-WR-CALNAME found,
+// or else by path's base name.
+func readCalendar(path string) (string, []*icsEvent, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(p), ".ics") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	named := false
+	var events []*icsEvent
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", nil, err
+		}
+		calName, fileEvents, err := parseICS(f)
+		f.Close()
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if calName != "" && !named {
+			name, named = calName, true
+		}
+		events = append(events, fileEvents...)
+	}
+	return name, events, nil
+}
+
+// parseICS reads the VEVENTs of an iCalendar stream and the calendar's
+// X-WR-CALNAME. Properties it doesn't use, other components (VTODO,
+// VTIMEZONE) and components nested in events (VALARM) are skipped, and so
+// are events without a valid DTSTART.
+func parseICS(r io.Reader) (string, []*icsEvent, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var calName string
+	var events []*icsEvent
+	var stack []string
+	var ev *icsEvent
+	for _, line := range lines {
+		name, params, value, ok := parseContentLine(line)
+		if !ok {
+			continue
+		}
+		switch name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				ev = &icsEvent{}
+			}
+			continue
+		case "END":
+			if len(stack) == 2 && ev != nil {
+				if !ev.start.IsZero() {
+					events = append(events, ev)
+				}
+				ev = nil
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		if len(stack) == 1 && name == "X-WR-CALNAME" {
+			calName = icsUnescape(value)
+		}
+		if ev == nil || len(stack) != 2 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			ev.uid = value
+		case "SUMMARY":
+			ev.summary = icsUnescape(value)
+		case "LOCATION":
+			ev.location = icsUnescape(value)
+		case "DESCRIPTION":
+			ev.description = icsUnescape(value)
+		case "STATUS":
+			ev.cancelled = strings.EqualFold(value, "CANCELLED")
+		case "DTSTART":
+			if t, allDay, err := parseICSTime(value, params); err == nil {
+				ev.start, ev.allDay = t, allDay
+			}
+		case "DTEND":
+			if t, _, err := parseICSTime(value, params); err == nil {
+				ev.end, ev.hasEnd = t, true
+			}
+		case "DURATION":
+			if d, err := parseICSDuration(value); err == nil {
+				ev.duration = d
+			}
+		case "RRULE":
+			ev.rrule = value
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				if t, _, err := parseICSTime(v, params); err == nil {
+					ev.exdates = append(ev.exdates, t)
+				}
+			}
+		case "RECURRENCE-ID":
+			if t, _, err := parseICSTime(value, params); err == nil {
+				ev.recurrenceID = t
+			}
+		}
+	}
+	return calName, events, nil
+}
+
+// unfoldICS splits an iCalendar stream into content lines, joining folded
+// continuation lines (starting with a space or tab).
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseContentLine splits "NAME;PARAM=VALUE:value" into its parts. The name
+// and parameter names are upper-cased; quoted parameter values may contain
+// ':' and ';'.
+func parseContentLine(line string) (name string, params map[string]string, value string, ok bool) {
+	inQuote := false
+	colon := -1
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuote = !inQuote
+		case ':':
+			if !inQuote {
+				colon = i
+			}
+		}
+		if colon >= 0 {
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := splitOutsideQuotes(head, ';')
+	params = make(map[string]string)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, value, true
+}
+
+func splitOutsideQuotes(s string, sep byte) []string {
+	var parts []string
+	inQuote := false
+	last := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuote = !inQuote
+		case sep:
+			if !inQuote {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
+
+// parseICSTime parses a DATE or DATE-TIME value. UTC times ("Z") and times
+// with a TZID karya can load are converted to local time; floating times,
+// and times in zones Go doesn't know (such as Windows zone names), are taken
+// as local.
+func parseICSTime(value string, params map[string]string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation(icsDate, value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsStamp, value)
+		return t.In(time.Local), false, err
+	}
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(icsDateTime, value, loc)
+	return t, false, err
+}
+
+// parseICSDuration parses a DURATION value such as PT1H30M, P1D or -PT15M.
+func parseICSDuration(value string) (time.Duration, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, r := range s[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		num = ""
+		switch {
+		case r == 'W' && !inTime:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return sign * d, nil
+}
+
+// icsUnescape reverses icsEscape.
+func icsUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// expandEvents returns the occurrences of events overlapping
+// [rangeStart, rangeEnd).
+func expandEvents(calendar string, events []*icsEvent, rangeStart, rangeEnd time.Time) []CalendarEvent {
+	// Overrides of single occurrences, by UID and original start
+	overrides := make(map[string][]*icsEvent)
+	for _, ev := range events {
+		if !ev.recurrenceID.IsZero() {
+			overrides[ev.uid] = append(overrides[ev.uid], ev)
+		}
+	}
+
+	var out []CalendarEvent
+	add := func(ev *icsEvent, start time.Time) {
+		if ev.cancelled {
+			return
+		}
+		end := ev.length(start)
+		if !start.Before(rangeEnd) {
+			return
+		}
+		if instant := !end.After(start); instant && start.Before(rangeStart) || !instant && !end.After(rangeStart) {
+			return
+		}
+		out = append(out, CalendarEvent{
+			Calendar: calendar, UID: ev.uid, Summary: ev.summary, Location: ev.location,
+			Description: ev.description, Start: start.In(time.Local), End: end.In(time.Local), AllDay: ev.allDay,
+		})
+	}
+
+	for _, ev := range events {
+		if !ev.recurrenceID.IsZero() {
+			add(ev, ev.start)
+			continue
+		}
+		starts := []time.Time{ev.start}
+		if ev.rrule != "" {
+			// Look back far enough for occurrences that started before the
+			// range but still overlap it
+			from := rangeStart.Add(-(ev.length(ev.start).Sub(ev.start)))
+			starts = expandRRule(ev.start, ev.rrule, from, rangeEnd)
+		}
+		for _, start := range starts {
+			if slices.ContainsFunc(ev.exdates, start.Equal) {
+				continue
+			}
+			if slices.ContainsFunc(overrides[ev.uid], func(o *icsEvent) bool { return o.recurrenceID.Equal(start) }) {
+				continue
+			}
+			add(ev, start)
+		}
+	}
+	return out
+}
+
+// length returns the end of the occurrence starting at start: DTEND or
+// DURATION applied to it, or the end of the day for all-day events without
+// either.
+func (ev *icsEvent) length(start time.Time) time.Time {
+	switch {
+	case ev.hasEnd:
+		if ev.allDay {
+			days := int(truncateToDay(ev.end).Sub(truncateToDay(ev.start)).Hours()/24 + 0.5)
+			return start.AddDate(0, 0, days)
+		}
+		return start.Add(ev.end.Sub(ev.start))
+	case ev.duration != 0:
+		return start.Add(ev.duration)
+	case ev.allDay:
+		return start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// rrule is the subset of RFC 5545 recurrence rules karya expands: FREQ
+// (DAILY, WEEKLY, MONTHLY or YEARLY), INTERVAL, COUNT, UNTIL, BYDAY (with
+// ordinals such as 2MO or -1FR in monthly and yearly rules), BYMONTHDAY,
+// BYMONTH, BYSETPOS and WKST=MO. A rule with any other part isn't parsed.
+type rrule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []int
+	bySetPos   []int
+}
+
+type weekdayNum struct {
+	n       int // 0 for every such weekday of the period
+	weekday time.Weekday
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRRule(value string) (*rrule, error) {
+	r := &rrule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", v)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", v)
+			}
+			r.count = n
+		case "UNTIL":
+			t, allDay, err := parseICSTime(v, map[string]string{})
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", v)
+			}
+			if allDay {
+				t = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			r.until = t
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				d = strings.ToUpper(strings.TrimSpace(d))
+				if len(d) < 2 {
+					return nil, fmt.Errorf("invalid BYDAY %q", v)
+				}
+				wd, ok := icsWeekdays[d[len(d)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", v)
+				}
+				n := 0
+				if num := d[:len(d)-2]; num != "" {
+					var err error
+					if n, err = strconv.Atoi(num); err != nil {
+						return nil, fmt.Errorf("invalid BYDAY %q", v)
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n: n, weekday: wd})
+			}
+		case "BYMONTHDAY", "BYMONTH", "BYSETPOS":
+			for _, d := range strings.Split(v, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(d))
+				if err != nil || n == 0 {
+					return nil, fmt.Errorf("invalid %s %q", k, v)
+				}
+				switch strings.ToUpper(k) {
+				case "BYMONTH":
+					r.byMonth = append(r.byMonth, n)
+				case "BYMONTHDAY":
+					r.byMonthDay = append(r.byMonthDay, n)
+				default:
+					r.bySetPos = append(r.bySetPos, n)
+				}
+			}
+		case "WKST":
+			// Weeks always start on Monday (see periodStart)
+			if !strings.EqualFold(v, "MO") {
+				return nil, fmt.Errorf("unsupported WKST %q", v)
+			}
+		case "":
+			// Tolerate a trailing ";"
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", k)
+		}
+	}
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", r.freq)
+	}
+	return r, nil
+}
+
+// expandRRule returns the occurrences of the rule starting at dtstart that
+// start in [from, to). Occurrences keep dtstart's wall-clock time in its
+// own zone, so they don't shift across daylight saving changes. A rule karya
+// can't parse yields only dtstart.
+func expandRRule(dtstart time.Time, value string, from, to time.Time) []time.Time {
+	inRange := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	r, err := parseRRule(value)
+	if err != nil {
+		if inRange(dtstart) {
+			return []time.Time{dtstart}
+		}
+		return nil
+	}
+
+	// Periods are walked from dtstart's even when the range starts later, as
+	// COUNT counts every occurrence
+	var out []time.Time
+	seen := 0
+	for period := 0; r.periodStart(dtstart, period).Before(to); period++ {
+		for _, t := range r.period(dtstart, period) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.until.IsZero() && t.After(r.until) {
+				return out
+			}
+			if seen++; r.count > 0 && seen > r.count {
+				return out
+			}
+			if inRange(t) {
+				out = append(out, t)
+			}
+		}
+	}
+	return out
+}
+
+// periodStart returns the first day of the n-th period of the rule.
+func (r *rrule) periodStart(dtstart time.Time, n int) time.Time {
+	day := truncateToDay(dtstart)
+	step := n * r.interval
+	switch r.freq {
+	case "DAILY":
+		return day.AddDate(0, 0, step)
+	case "WEEKLY":
+		offset := (int(day.Weekday()) + 6) % 7 // weeks start on Monday (WKST=MO)
+		return day.AddDate(0, 0, -offset+7*step)
+	case "MONTHLY":
+		return time.Date(day.Year(), day.Month()+time.Month(step), 1, 0, 0, 0, 0, day.Location())
+	}
+	return time.Date(day.Year()+step, 1, 1, 0, 0, 0, 0, day.Location())
+}
+
+// period returns the occurrences of the n-th period in order, at dtstart's
+// time of day.
+func (r *rrule) period(dtstart time.Time, n int) []time.Time {
+	start := r.periodStart(dtstart, n)
+	at := func(d time.Time) time.Time {
+		return time.Date(d.Year(), d.Month(), d.Day(), dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+	}
+
+	var days []time.Time
+	switch r.freq {
+	case "DAILY":
+		if r.matches(start) {
+			days = append(days, start)
+		}
+	case "WEEKLY":
+		for i := range 7 {
+			d := start.AddDate(0, 0, i)
+			if len(r.byDay) == 0 && d.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if r.matches(d) {
+				days = append(days, d)
+			}
+		}
+	case "MONTHLY":
+		days = r.monthDays(start, dtstart)
+	case "YEARLY":
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []int{int(dtstart.Month())}
+		}
+		for _, m := range months {
+			days = append(days, r.monthDays(time.Date(start.Year(), time.Month(m), 1, 0, 0, 0, 0, start.Location()), dtstart)...)
+		}
+	}
+
+	out := make([]time.Time, len(days))
+	for i, d := range days {
+		out[i] = at(d)
+	}
+	slices.SortFunc(out, func(a, b time.Time) int { return a.Compare(b) })
+	out = slices.CompactFunc(out, time.Time.Equal)
+	if len(r.bySetPos) == 0 {
+		return out
+	}
+
+	// BYSETPOS picks from the period's occurrences by position, counting
+	// from the end when negative
+	var picked []time.Time
+	for _, pos := range r.bySetPos {
+		if pos < 0 {
+			pos += len(out) + 1
+		}
+		if pos >= 1 && pos <= len(out) {
+			picked = append(picked, out[pos-1])
+		}
+	}
+	slices.SortFunc(picked, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(picked, time.Time.Equal)
+}
+
+// monthDays returns the days of the month starting at first that the rule
+// selects: its BYMONTHDAY and BYDAY days, or dtstart's day of the month
+// (skipped in months too short for it).
+func (r *rrule) monthDays(first, dtstart time.Time) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	var days []time.Time
+	for _, md := range r.byMonthDay {
+		if md < 0 {
+			md = last + md + 1
+		}
+		if md >= 1 && md <= last {
+			if d := first.AddDate(0, 0, md-1); r.matchesDay(d, last) {
+				days = append(days, d)
+			}
+		}
+	}
+	if len(r.byMonthDay) > 0 {
+		return days
+	}
+	if len(r.byDay) > 0 {
+		for i := range last {
+			if d := first.AddDate(0, 0, i); r.matchesDay(d, last) {
+				days = append(days, d)
+			}
+		}
+		return days
+	}
+	if dtstart.Day() <= last {
+		days = append(days, first.AddDate(0, 0, dtstart.Day()-1))
+	}
+	return days
+}
+
+// matches reports whether a day of a daily or weekly rule passes its
+// BYMONTH, BYMONTHDAY and BYDAY filters.
+func (r *rrule) matches(d time.Time) bool {
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, int(d.Month())) {
+		return false
+	}
+	last := d.AddDate(0, 1, -d.Day()).Day()
+	if len(r.byMonthDay) > 0 && !slices.ContainsFunc(r.byMonthDay, func(md int) bool {
+		return md == d.Day() || md < 0 && last+md+1 == d.Day()
+	}) {
+		return false
+	}
+	return r.matchesDay(d, last)
+}
+
+// matchesDay reports whether d is one of the BYDAY weekdays, counting
+// ordinals (2MO, -1FR) within d's month of last days.
+func (r *rrule) matchesDay(d time.Time, last int) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, wd := range r.byDay {
+		if d.Weekday() != wd.weekday {
+			continue
+		}
+		switch {
+		case wd.n == 0:
+			return true
+		case wd.n > 0 && (d.Day()-1)/7+1 == wd.n:
+			return true
+		case wd.n < 0 && (last-d.Day())/7+1 == -wd.n:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseContentLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		name   string
+		params map[string]string
+		value  string
+	}{
+		{"SUMMARY:Standup", "SUMMARY", map[string]string{}, "Standup"},
+		{"dtstart;tzid=Europe/Berlin:20250701T090000", "DTSTART", map[string]string{"TZID": "Europe/Berlin"}, "20250701T090000"},
+		{`ATTENDEE;CN="Doe; Jane: PM":mailto:jane@example.com`, "ATTENDEE", map[string]string{"CN": "Doe; Jane: PM"}, "mailto:jane@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, params, value, ok := parseContentLine(tt.line)
+			if !ok || name != tt.name || value != tt.value || len(params) != len(tt.params) {
+				t.Fatalf("parseContentLine(%q) = %q, %v, %q, %v", tt.line, name, params, value, ok)
+			}
+			for k, v := range tt.params {
+				if params[k] != v {
+					t.Errorf("param %s = %q, want %q", k, params[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"PT1H30M", 90 * time.Minute, false},
+		{"P1D", 24 * time.Hour, false},
+		{"P1W", 7 * 24 * time.Hour, false},
+		{"P1DT2H", 26 * time.Hour, false},
+		{"-PT15M", -15 * time.Minute, false},
+		{"PT45S", 45 * time.Second, false},
+		{"1H", 0, true},
+		{"PT1", 0, true},
+		{"P1H", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseICSDuration(tt.value)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseICSDuration(%q) = %v, %v, want %v (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandRRule(t *testing.T) {
+	at := func(y int, m time.Month, d, h int) time.Time { return time.Date(y, m, d, h, 0, 0, 0, time.Local) }
+	days := func(times []time.Time) string {
+		var s []string
+		for _, t := range times {
+			s = append(s, t.Format("01-02 15:04"))
+		}
+		return strings.Join(s, " ")
+	}
+
+	tests := []struct {
+		name     string
+		dtstart  time.Time
+		rule     string
+		from, to time.Time
+		want     string
+	}{
+		{"daily", at(2025, 7, 1, 9), "FREQ=DAILY", at(2025, 7, 3, 0), at(2025, 7, 6, 0), "07-03 09:00 07-04 09:00 07-05 09:00"},
+		{"daily interval", at(2025, 7, 1, 9), "FREQ=DAILY;INTERVAL=3", at(2025, 7, 1, 0), at(2025, 7, 11, 0), "07-01 09:00 07-04 09:00 07-07 09:00 07-10 09:00"},
+		{"weekdays", at(2025, 7, 4, 9), "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", at(2025, 7, 4, 0), at(2025, 7, 9, 0), "07-04 09:00 07-07 09:00 07-08 09:00"},
+		{"weekly on several days", at(2025, 6, 30, 10), "FREQ=WEEKLY;BYDAY=MO,TH", at(2025, 7, 1, 0), at(2025, 7, 15, 0), "07-03 10:00 07-07 10:00 07-10 10:00 07-14 10:00"},
+		{"biweekly", at(2025, 7, 2, 10), "FREQ=WEEKLY;INTERVAL=2", at(2025, 7, 1, 0), at(2025, 8, 1, 0), "07-02 10:00 07-16 10:00 07-30 10:00"},
+		{"count counts earlier occurrences", at(2025, 6, 30, 10), "FREQ=DAILY;COUNT=4", at(2025, 7, 2, 0), at(2025, 7, 10, 0), "07-02 10:00 07-03 10:00"},
+		{"until", at(2025, 7, 1, 10), "FREQ=DAILY;UNTIL=20250703", at(2025, 7, 1, 0), at(2025, 7, 10, 0), "07-01 10:00 07-02 10:00 07-03 10:00"},
+		{"monthly skips short months", at(2025, 1, 31, 8), "FREQ=MONTHLY", at(2025, 1, 1, 0), at(2025, 5, 1, 0), "01-31 08:00 03-31 08:00"},
+		{"second tuesday", at(2025, 7, 8, 8), "FREQ=MONTHLY;BYDAY=2TU", at(2025, 7, 1, 0), at(2025, 10, 1, 0), "07-08 08:00 08-12 08:00 09-09 08:00"},
+		{"last friday", at(2025, 7, 25, 8), "FREQ=MONTHLY;BYDAY=-1FR", at(2025, 7, 1, 0), at(2025, 10, 1, 0), "07-25 08:00 08-29 08:00 09-26 08:00"},
+		{"last day of month", at(2025, 1, 31, 8), "FREQ=MONTHLY;BYMONTHDAY=-1", at(2025, 1, 1, 0), at(2025, 4, 1, 0), "01-31 08:00 02-28 08:00 03-31 08:00"},
+		{"yearly", at(2024, 7, 4, 12), "FREQ=YEARLY", at(2025, 1, 1, 0), at(2027, 1, 1, 0), "07-04 12:00 07-04 12:00"},
+		{"yearly by month", at(2025, 1, 15, 12), "FREQ=YEARLY;BYMONTH=1,7", at(2025, 1, 1, 0), at(2026, 1, 1, 0), "01-15 12:00 07-15 12:00"},
+		{"last weekday", at(2025, 6, 30, 9), "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", at(2025, 7, 1, 0), at(2025, 10, 1, 0), "07-31 09:00 08-29 09:00 09-30 09:00"},
+		{"first and third business days", at(2025, 7, 1, 9), "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1,3;WKST=MO", at(2025, 7, 1, 0), at(2025, 9, 1, 0), "07-01 09:00 07-03 09:00 08-01 09:00 08-05 09:00"},
+		{"unsupported rule keeps the first", at(2025, 7, 1, 9), "FREQ=HOURLY", at(2025, 7, 1, 0), at(2025, 7, 2, 0), "07-01 09:00"},
+		{"unsupported part keeps the first", at(2025, 7, 1, 9), "FREQ=DAILY;BYHOUR=9,17", at(2025, 7, 1, 0), at(2025, 7, 4, 0), "07-01 09:00"},
+		{"unsupported week start keeps the first", at(2025, 7, 1, 9), "FREQ=WEEKLY;WKST=SU;BYDAY=SU,TU", at(2025, 7, 1, 0), at(2025, 7, 14, 0), "07-01 09:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := days(expandRRule(tt.dtstart, tt.rule, tt.from, tt.to)); got != tt.want {
+				t.Errorf("expandRRule(%s) = %q, want %q", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandRRule_KeepsWallClockAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	dtstart := time.Date(2025, 10, 20, 9, 0, 0, 0, berlin)
+	got := expandRRule(dtstart, "FREQ=WEEKLY", dtstart, dtstart.AddDate(0, 0, 8))
+	if len(got) != 2 || got[1].In(berlin).Hour() != 9 {
+		t.Errorf("expandRRule() = %v, want 09:00 Berlin time after the clocks change", got)
+	}
+}
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"X-WR-CALNAME:Work\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Stand\r\n" +
+	" up\r\n" +
+	"DTSTART:20250630T090000\r\n" +
+	"DTEND:20250630T091500\r\n" +
+	"RRULE:FREQ=DAILY;COUNT=5\r\n" +
+	"EXDATE:20250702T090000\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT5M\r\n" +
+	"SUMMARY:Alarm\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"RECURRENCE-ID:20250703T090000\r\n" +
+	"SUMMARY:Standup (late)\r\n" +
+	"DTSTART:20250703T110000\r\n" +
+	"DURATION:PT30M\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:offsite\r\n" +
+	"SUMMARY:Offsite\\, day 1\r\n" +
+	"LOCATION:HQ\r\n" +
+	"DTSTART;VALUE=DATE:20250701\r\n" +
+	"DTEND;VALUE=DATE:20250703\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:dropped\r\n" +
+	"SUMMARY:Dropped\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20250701T150000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:todo\r\n" +
+	"SUMMARY:Not an event\r\n" +
+	"DTSTART:20250701T150000\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestCalendarEvents(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "synced", ".cache"), 0755)
+	os.WriteFile(filepath.Join(dir, "work.ics"), []byte(testCalendar), 0644)
+	os.WriteFile(filepath.Join(dir, "synced", "a.ics"), []byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:a\nSUMMARY:Lunch\nDTSTART:20250702T120000\nDTEND:20250702T130000\nEND:VEVENT\nEND:VCALENDAR\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "synced", ".cache", "b.ics"), []byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:b\nSUMMARY:Hidden\nDTSTART:20250702T120000\nEND:VEVENT\nEND:VCALENDAR\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Schedule.Calendars = []string{filepath.Join(dir, "work.ics"), filepath.Join(dir, "synced")}
+	day := func(d int) time.Time { return time.Date(2025, 7, d, 0, 0, 0, 0, time.Local) }
+
+	events, err := CalendarEvents(cfg, day(1), day(3))
+	if err != nil {
+		t.Fatalf("CalendarEvents() error: %v", err)
+	}
+	var got []string
+	for _, ev := range events {
+		got = append(got, ev.Calendar+"/"+ev.Summary+" "+ev.Start.Format("02 15:04")+"-"+ev.End.Format("02 15:04"))
+	}
+	want := []string{
+		"Work/Offsite, day 1 01 00:00-03 00:00",
+		"Work/Standup 01 09:00-01 09:15",
+		"synced/Lunch 02 12:00-02 13:00",
+		"Work/Standup (late) 03 11:00-03 11:30",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CalendarEvents() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	cfg.Schedule.Calendars = []string{filepath.Join(dir, "missing.ics")}
+	if _, err := CalendarEvents(cfg, day(1), day(3)); err == nil {
+		t.Error("CalendarEvents() with a missing calendar succeeded")
+	}
+}
+
+func TestQueryAgenda_CalendarEvents(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "projects", "web"), 0755)
+	os.WriteFile(filepath.Join(dir, "projects", "web", "tasks.md"), []byte("TODO: Review @s:2025-07-01\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "work.ics"), []byte(testCalendar), 0644)
+
+	cfg := createTestConfig()
+	cfg.Directories.Projects = filepath.Join(dir, "projects")
+	cfg.Schedule.Calendars = []string{filepath.Join(dir, "work.ics")}
+	day := func(d int) time.Time { return time.Date(2025, 7, d, 0, 0, 0, 0, time.Local) }
+
+	days, err := QueryAgenda(cfg, day(1), day(2), false)
+	if err != nil {
+		t.Fatalf("QueryAgenda() error: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("QueryAgenda() = %d days, want 2", len(days))
+	}
+
+	// Timed event first, then the all-day event before the untimed task
+	first := days[0].Items
+	if len(first) != 3 {
+		t.Fatalf("first day = %d items, want 3", len(first))
+	}
+	if ev := first[0].Event; ev == nil || ev.Summary != "Standup" || !first[0].HasTime || !first[0].HasEnd {
+		t.Errorf("first item = %+v, want the timed standup", first[0])
+	}
+	if ev := first[1].Event; ev == nil || ev.Summary != "Offsite, day 1" || first[1].HasTime {
+		t.Errorf("second item = %+v, want the all-day offsite", first[1])
+	}
+	if first[2].Task == nil || first[2].Task.Title != "Review" {
+		t.Errorf("third item = %+v, want the task", first[2])
+	}
+
+	// The offsite covers both days; the excluded standup doesn't appear
+	second := days[1].Items
+	if len(second) != 1 || second[0].Event == nil || second[0].Event.UID != "offsite" {
+		t.Errorf("second day = %+v, want only the offsite", second)
+	}
+}
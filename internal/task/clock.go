@@ -2,8 +2,8 @@ package task
 
 import (
 	"fmt"
-	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -21,12 +21,29 @@ type ClockTableEntry struct {
 	Task         *Task
 	Duration     time.Duration
 	WasCompleted bool
+	Estimate     time.Duration // Effort estimate (~3h), 0 if none
+	Actual       time.Duration // All time clocked on the task, not just within the range
+	Pomodoros    int           // Completed pomodoros recorded on the task
+}
+
+// OverBudget reports whether the task has used more time than estimated.
+func (e ClockTableEntry) OverBudget() bool {
+	return e.Estimate > 0 && e.Actual > e.Estimate
 }
 
 type ClockTableProject struct {
-	Project string
-	Total   time.Duration
-	Entries []ClockTableEntry
+	Project   string
+	Total     time.Duration
+	Entries   []ClockTableEntry
+	Estimate  time.Duration // Sum of estimates of the project's estimated tasks
+	Actual    time.Duration // All time clocked on those estimated tasks
+	Pomodoros int           // Completed pomodoros recorded on the project's tasks
+}
+
+// OverBudget reports whether the project's estimated tasks have used more
+// time than estimated in total.
+func (p ClockTableProject) OverBudget() bool {
+	return p.Estimate > 0 && p.Actual > p.Estimate
 }
 
 type ClockTable struct {
@@ -45,34 +62,19 @@ type StateTransition struct {
 }
 
 var clockLineRe = regexp.MustCompile(`^\s*(?:[-*+]\s*)?CLOCK:\s*(.+)$`)
-var clockTimestampRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2})--(\d{4}-\d{2}-\d{2}T\d{2}:\d{2})?$`)
+var clockTimestampRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}` + stampZonePattern + `?)--(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}` + stampZonePattern + `?)?$`)
 var completedLineRe = regexp.MustCompile(`^\s*(?:[-*+]\s*)?COMPLETED:\s*(.+)$`)
 var logEntryRe = regexp.MustCompile(`^\s*(?:[-*+]\s*)?LOG\(([A-Z_]+)\s*->\s*([A-Z_]+)\):\s*(.+)$`)
 
 // ParseClockEntries reads a task's sub-lines and extracts CLOCK entries.
 func ParseClockEntries(t *Task) ([]ClockEntry, error) {
-	raw, err := ReadRawBlock(t)
+	lines, err := taskSubLines(t)
 	if err != nil {
 		return nil, err
 	}
-	if raw == "" {
-		return nil, nil
-	}
 
-	lines := strings.Split(raw, "\n")
 	var entries []ClockEntry
-	expectedRawIndent := detectSubItemRawIndent(lines)
-	if expectedRawIndent < 0 {
-		return nil, nil
-	}
-
-	for _, line := range lines[1:] { // skip task line itself
-		if line == "" {
-			continue
-		}
-		if countLeadingSpaces(line) != expectedRawIndent {
-			continue
-		}
+	for _, line := range lines {
 		m := clockLineRe.FindStringSubmatch(line)
 		if m == nil {
 			continue
@@ -83,14 +85,14 @@ func ParseClockEntries(t *Task) ([]ClockEntry, error) {
 			continue
 		}
 
-		start, err := time.ParseInLocation("2006-01-02T15:04", tm[1], time.Local)
+		start, err := parseStamp(tm[1])
 		if err != nil {
 			continue
 		}
 
 		entry := ClockEntry{Start: start}
 		if tm[2] != "" {
-			end, err := time.ParseInLocation("2006-01-02T15:04", tm[2], time.Local)
+			end, err := parseStamp(tm[2])
 			if err == nil {
 				entry.End = end
 			}
@@ -158,29 +160,10 @@ func subItemWriteIndent(lines []string) int {
 	return taskRawSpaces + 2
 }
 
-// subItemIndentForTask reads the task's raw block and returns the indent string
-// to use when writing direct sub-items (CLOCK, COMPLETED entries).
-func subItemIndentForTask(t *Task) (string, error) {
-	raw, err := ReadRawBlock(t)
-	if err != nil {
-		return strings.Repeat(" ", countLeadingSpaces("")+2), err
-	}
-	if raw == "" {
-		// No existing block — use task line's raw whitespace + 2
-		content, err := os.ReadFile(t.FilePath)
-		if err != nil {
-			return "  ", err
-		}
-		filelines := strings.Split(string(content), "\n")
-		if t.LineNum-1 < len(filelines) {
-			ws := countLeadingSpaces(filelines[t.LineNum-1])
-			return strings.Repeat(" ", ws+2), nil
-		}
-		return "  ", nil
-	}
-	lines := strings.Split(raw, "\n")
-	indent := subItemWriteIndent(lines)
-	return strings.Repeat(" ", indent), nil
+// subItemIndentAt returns the indent string to use when writing direct
+// sub-items (CLOCK, LOG entries) under the task whose line is lines[idx].
+func subItemIndentAt(lines []string, idx, indentLevel int) string {
+	return strings.Repeat(" ", subItemWriteIndent(blockLines(lines, idx+1, indentLevel)))
 }
 
 // IsClockActive returns true if the task has an open (running) clock entry.
@@ -267,8 +250,13 @@ func QueryClockTable(c *config.Config, start, end time.Time) (*ClockTable, error
 		}
 
 		entry := ClockTableEntry{
-			Task:     t,
-			Duration: total,
+			Task:      t,
+			Duration:  total,
+			Pomodoros: PomodoroCount(t),
+		}
+		if est := t.EstimateDuration(); est > 0 {
+			entry.Estimate = est
+			entry.Actual = ClockedTotal(t)
 		}
 
 		// Check if this recurring task was completed during the view range
@@ -299,15 +287,22 @@ func QueryClockTable(c *config.Config, start, end time.Time) (*ClockTable, error
 			return entries[i].Duration > entries[j].Duration
 		})
 
-		var projTotal time.Duration
+		var projTotal, projEstimate, projActual time.Duration
+		var projPomodoros int
 		for _, e := range entries {
 			projTotal += e.Duration
+			projEstimate += e.Estimate
+			projActual += e.Actual
+			projPomodoros += e.Pomodoros
 		}
 
 		table.Projects = append(table.Projects, ClockTableProject{
-			Project: proj,
-			Total:   projTotal,
-			Entries: entries,
+			Project:   proj,
+			Total:     projTotal,
+			Entries:   entries,
+			Estimate:  projEstimate,
+			Actual:    projActual,
+			Pomodoros: projPomodoros,
 		})
 		table.GrandTotal += projTotal
 	}
@@ -323,6 +318,11 @@ func QueryClockTable(c *config.Config, start, end time.Time) (*ClockTable, error
 // ClockIn appends a new open CLOCK entry after the task line.
 // Returns error if task already has an active clock.
 func ClockIn(t *Task) error {
+	return clockInAt(t, time.Now())
+}
+
+// clockInAt is ClockIn with the entry starting at the given time.
+func clockInAt(t *Task, at time.Time) error {
 	if IsClockActive(t) {
 		return fmt.Errorf("task already clocked in")
 	}
@@ -330,75 +330,51 @@ func ClockIn(t *Task) error {
 		return fmt.Errorf("task has no file location")
 	}
 
-	content, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	if t.LineNum-1 >= len(lines) {
-		return fmt.Errorf("line number out of range")
-	}
-
-	indent, _ := subItemIndentForTask(t)
-	clockLine := fmt.Sprintf("%s* CLOCK: %s--", indent, time.Now().Format("2006-01-02T15:04"))
-
-	// Insert after task line
-	newLines := make([]string, 0, len(lines)+1)
-	newLines = append(newLines, lines[:t.LineNum]...)
-	newLines = append(newLines, clockLine)
-	newLines = append(newLines, lines[t.LineNum:]...)
-
-	return os.WriteFile(t.FilePath, []byte(strings.Join(newLines, "\n")), 0644)
+	return mutateTask(t, "Clock in: "+t.Title, func(lines []string, idx int) ([]string, error) {
+		_, level := StripLinePrefix(lines[idx])
+		indent := subItemIndentAt(lines, idx, level)
+		clockLine := fmt.Sprintf("%s* CLOCK: %s--", indent, formatStamp(at))
+		return slices.Insert(lines, idx+1, clockLine), nil
+	})
 }
 
 // ClockOut completes the open CLOCK entry for the task.
 // Returns error if no active clock found.
 func ClockOut(t *Task) error {
+	return clockOutAt(t, time.Now())
+}
+
+// clockOutAt is ClockOut with the entry ending at the given time.
+func clockOutAt(t *Task, at time.Time) error {
 	if t.FilePath == "" || t.LineNum == 0 {
 		return fmt.Errorf("task has no file location")
 	}
 
-	content, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	taskIdx := t.LineNum - 1
-	if taskIdx >= len(lines) {
-		return fmt.Errorf("line number out of range")
-	}
-
-	now := time.Now().Format("2006-01-02T15:04")
-	found := false
-	for i := taskIdx + 1; i < len(lines); i++ {
-		line := lines[i]
-		if line == "" {
-			continue
-		}
-		_, level := StripLinePrefix(line)
-		if level <= t.IndentLevel {
-			break
-		}
-		trimmed := strings.TrimSpace(line)
-		// Strip optional bullet prefix for matching
-		stripped := trimmed
-		if strings.HasPrefix(stripped, "* ") || strings.HasPrefix(stripped, "- ") || strings.HasPrefix(stripped, "+ ") {
-			stripped = stripped[2:]
-		}
-		if strings.HasPrefix(stripped, "CLOCK:") && strings.HasSuffix(stripped, "--") {
-			lines[i] = line + now
-			found = true
-			break
+	now := formatStamp(at)
+	return mutateTask(t, "Clock out: "+t.Title, func(lines []string, taskIdx int) ([]string, error) {
+		_, taskLevel := StripLinePrefix(lines[taskIdx])
+		for i := taskIdx + 1; i < len(lines); i++ {
+			line := lines[i]
+			if line == "" {
+				continue
+			}
+			_, level := StripLinePrefix(line)
+			if level <= taskLevel {
+				break
+			}
+			trimmed := strings.TrimSpace(line)
+			// Strip optional bullet prefix for matching
+			stripped := trimmed
+			if strings.HasPrefix(stripped, "* ") || strings.HasPrefix(stripped, "- ") || strings.HasPrefix(stripped, "+ ") {
+				stripped = stripped[2:]
+			}
+			if strings.HasPrefix(stripped, "CLOCK:") && strings.HasSuffix(stripped, "--") {
+				lines[i] = line + now
+				return lines, nil
+			}
 		}
-	}
-
-	if !found {
-		return fmt.Errorf("no active clock entry found")
-	}
-
-	return os.WriteFile(t.FilePath, []byte(strings.Join(lines, "\n")), 0644)
+		return nil, fmt.Errorf("no active clock entry found")
+	})
 }
 
 // ParseCompletionEntries reads a task's sub-lines and extracts COMPLETED entries.
@@ -417,32 +393,17 @@ func ParseCompletionEntries(t *Task) ([]CompletionEntry, error) {
 
 // ParseStateTransitions reads a task's sub-lines and extracts LOG and legacy COMPLETED entries.
 func ParseStateTransitions(t *Task) ([]StateTransition, error) {
-	raw, err := ReadRawBlock(t)
+	lines, err := taskSubLines(t)
 	if err != nil {
 		return nil, err
 	}
-	if raw == "" {
-		return nil, nil
-	}
 
-	lines := strings.Split(raw, "\n")
 	var entries []StateTransition
-	expectedRawIndent := detectSubItemRawIndent(lines)
-	if expectedRawIndent < 0 {
-		return nil, nil
-	}
-
-	for _, line := range lines[1:] {
-		if line == "" {
-			continue
-		}
-		if countLeadingSpaces(line) != expectedRawIndent {
-			continue
-		}
+	for _, line := range lines {
 		// Try new LOG format first
 		if m := logEntryRe.FindStringSubmatch(line); m != nil {
 			ts := strings.TrimSpace(m[3])
-			parsed, err := time.ParseInLocation("2006-01-02T15:04", ts, time.Local)
+			parsed, err := parseStamp(ts)
 			if err != nil {
 				continue
 			}
@@ -456,7 +417,7 @@ func ParseStateTransitions(t *Task) ([]StateTransition, error) {
 		// Fall back to legacy COMPLETED format
 		if m := completedLineRe.FindStringSubmatch(line); m != nil {
 			ts := strings.TrimSpace(m[1])
-			parsed, err := time.ParseInLocation("2006-01-02T15:04", ts, time.Local)
+			parsed, err := parseStamp(ts)
 			if err != nil {
 				continue
 			}
@@ -480,56 +441,38 @@ func recordOrUpdateTransition(t *Task, schedDay time.Time, fromKeyword, toKeywor
 		return fmt.Errorf("task has no file location")
 	}
 
-	content, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return err
-	}
+	now := formatStamp(time.Now())
 
-	lines := strings.Split(string(content), "\n")
-	taskIdx := t.LineNum - 1
-	if taskIdx >= len(lines) {
-		return fmt.Errorf("line number out of range")
-	}
-
-	indent, _ := subItemIndentForTask(t)
-	now := time.Now().Format("2006-01-02T15:04")
-	dayStr := schedDay.Format("2006-01-02")
+	return mutateTask(t, fmt.Sprintf("Log %s -> %s: %s", fromKeyword, toKeyword, t.Title), func(lines []string, taskIdx int) ([]string, error) {
+		_, taskLevel := StripLinePrefix(lines[taskIdx])
+		indent := subItemIndentAt(lines, taskIdx, taskLevel)
+		logLine := fmt.Sprintf("%s* LOG(%s -> %s): %s", indent, fromKeyword, toKeyword, now)
 
-	// Search for an existing LOG or COMPLETED entry on the same day
-	for i := taskIdx + 1; i < len(lines); i++ {
-		line := lines[i]
-		if line == "" {
-			continue
-		}
-		_, level := StripLinePrefix(line)
-		if level <= t.IndentLevel {
-			break
-		}
-		if m := logEntryRe.FindStringSubmatch(line); m != nil {
-			ts := strings.TrimSpace(m[3])
-			if strings.HasPrefix(ts, dayStr) {
-				lines[i] = fmt.Sprintf("%s* LOG(%s -> %s): %s", indent, fromKeyword, toKeyword, now)
-				return os.WriteFile(t.FilePath, []byte(strings.Join(lines, "\n")), 0644)
+		// Search for an existing LOG or COMPLETED entry on the same day
+		for i := taskIdx + 1; i < len(lines); i++ {
+			line := lines[i]
+			if line == "" {
+				continue
 			}
-		}
-		if m := completedLineRe.FindStringSubmatch(line); m != nil {
-			ts := strings.TrimSpace(m[1])
-			if strings.HasPrefix(ts, dayStr) {
-				lines[i] = fmt.Sprintf("%s* LOG(%s -> %s): %s", indent, fromKeyword, toKeyword, now)
-				return os.WriteFile(t.FilePath, []byte(strings.Join(lines, "\n")), 0644)
+			_, level := StripLinePrefix(line)
+			if level <= taskLevel {
+				break
+			}
+			var ts string
+			if m := logEntryRe.FindStringSubmatch(line); m != nil {
+				ts = m[3]
+			} else if m := completedLineRe.FindStringSubmatch(line); m != nil {
+				ts = m[1]
+			}
+			if stamp, err := parseStamp(strings.TrimSpace(ts)); err == nil && truncateToDay(stamp).Equal(schedDay) {
+				lines[i] = logLine
+				return lines, nil
 			}
 		}
-	}
 
-	// No existing entry for this day — append new one
-	logLine := fmt.Sprintf("%s* LOG(%s -> %s): %s", indent, fromKeyword, toKeyword, now)
-
-	newLines := make([]string, 0, len(lines)+1)
-	newLines = append(newLines, lines[:t.LineNum]...)
-	newLines = append(newLines, logLine)
-	newLines = append(newLines, lines[t.LineNum:]...)
-
-	return os.WriteFile(t.FilePath, []byte(strings.Join(newLines, "\n")), 0644)
+		// No existing entry for this day — append new one
+		return slices.Insert(lines, taskIdx+1, logLine), nil
+	})
 }
 
 // RecordStateTransition appends a LOG entry after the task line.
@@ -538,25 +481,12 @@ func RecordStateTransition(t *Task, fromKeyword, toKeyword string) error {
 		return fmt.Errorf("task has no file location")
 	}
 
-	content, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	if t.LineNum-1 >= len(lines) {
-		return fmt.Errorf("line number out of range")
-	}
-
-	indent, _ := subItemIndentForTask(t)
-	logLine := fmt.Sprintf("%s* LOG(%s -> %s): %s", indent, fromKeyword, toKeyword, time.Now().Format("2006-01-02T15:04"))
-
-	newLines := make([]string, 0, len(lines)+1)
-	newLines = append(newLines, lines[:t.LineNum]...)
-	newLines = append(newLines, logLine)
-	newLines = append(newLines, lines[t.LineNum:]...)
-
-	return os.WriteFile(t.FilePath, []byte(strings.Join(newLines, "\n")), 0644)
+	now := formatStamp(time.Now())
+	return mutateTask(t, fmt.Sprintf("Log %s -> %s: %s", fromKeyword, toKeyword, t.Title), func(lines []string, idx int) ([]string, error) {
+		_, level := StripLinePrefix(lines[idx])
+		logLine := fmt.Sprintf("%s* LOG(%s -> %s): %s", subItemIndentAt(lines, idx, level), fromKeyword, toKeyword, now)
+		return slices.Insert(lines, idx+1, logLine), nil
+	})
 }
 
 // RecordCompletion appends a LOG entry after the task line (backward-compatible wrapper).
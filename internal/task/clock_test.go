@@ -141,6 +141,65 @@ func TestClipDuration(t *testing.T) {
 	}
 }
 
+func TestClockDuration_DST(t *testing.T) {
+	withZones(t, "Europe/Berlin", "")
+	tests := []struct {
+		name  string
+		clock string
+		want  time.Duration
+	}{
+		// Clocks go from 02:00 to 03:00 on 2025-03-30 and back on 2025-10-26
+		{"local spring forward", "2025-03-30T01:30--2025-03-30T03:30", time.Hour},
+		{"local fall back", "2025-10-26T01:30--2025-10-26T03:30", 3 * time.Hour},
+		{"local across the day", "2025-03-29T22:00--2025-03-30T06:00", 7 * time.Hour},
+		{"named zone", "2025-03-30T01:30[Europe/Berlin]--2025-03-30T03:30[Europe/Berlin]", time.Hour},
+		{"offsets through the repeated hour", "2025-10-26T02:30+02:00--2025-10-26T02:30+01:00", time.Hour},
+		{"other zone's DST day", "2025-03-08T23:00[America/New_York]--2025-03-09T03:00[America/New_York]", 3 * time.Hour},
+		{"mixed zones", "2025-07-01T09:00Z--2025-07-01T12:00+02:00", time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filepath.Join(t.TempDir(), "test.md")
+			os.WriteFile(f, []byte("DOING: Deploy\n  CLOCK: "+tt.clock+"\n"), 0644)
+			task := &Task{Keyword: "DOING", Title: "Deploy", FilePath: f, LineNum: 1}
+
+			entries, err := ParseClockEntries(task)
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("ParseClockEntries() = %v, %v, want one entry", entries, err)
+			}
+			if got := entries[0].End.Sub(entries[0].Start); got != tt.want {
+				t.Errorf("duration = %v, want %v", got, tt.want)
+			}
+			if got := ClockedTotal(task); got != tt.want {
+				t.Errorf("ClockedTotal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClockInOut_Zone(t *testing.T) {
+	withZones(t, "", "Asia/Tokyo")
+	f := filepath.Join(t.TempDir(), "test.md")
+	os.WriteFile(f, []byte("TODO: My task\n"), 0644)
+	task := &Task{Keyword: "TODO", Title: "My task", FilePath: f, LineNum: 1}
+
+	if err := ClockIn(task); err != nil {
+		t.Fatalf("clock in failed: %v", err)
+	}
+	if err := ClockOut(task); err != nil {
+		t.Fatalf("clock out failed: %v", err)
+	}
+	result, _ := os.ReadFile(f)
+	if strings.Count(string(result), "[Asia/Tokyo]") != 2 {
+		t.Errorf("expected both stamps in Asia/Tokyo, got %q", result)
+	}
+	entries, err := ParseClockEntries(task)
+	if err != nil || len(entries) != 1 || entries[0].Open {
+		t.Fatalf("ParseClockEntries() = %v, %v, want one closed entry", entries, err)
+	}
+}
+
 func TestClockIn(t *testing.T) {
 	dir := t.TempDir()
 	f := filepath.Join(dir, "test.md")
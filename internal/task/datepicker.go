@@ -2,6 +2,7 @@ package task
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -39,6 +40,8 @@ const (
 	recFocusMode recurrenceFocus = iota
 	recFocusInterval
 	recFocusUnit
+	recFocusDays
+	recFocusLimit
 )
 
 type DatePicker struct {
@@ -54,6 +57,10 @@ type DatePicker struct {
 	// Section navigation
 	Section pickerSection
 
+	// SpanDays is how many days after Cursor a range (DATE--DATE) ends; 0
+	// for a single day. A timed range ends at the end time on its last day.
+	SpanDays int
+
 	// Time
 	HasTime    bool
 	Hour       int
@@ -62,6 +69,10 @@ type DatePicker struct {
 	EndHour    int
 	EndMinute  int
 	TimeFocus  timeFocus
+	// Zone the time is written in, with a suffix; nil for floating local
+	// time. New times get the configured zone (see UseTimeZone).
+	Zone      *time.Location
+	tokenZone *time.Location
 
 	// Recurrence
 	HasRecurrence      bool
@@ -69,10 +80,21 @@ type DatePicker struct {
 	RecurrenceInterval int
 	RecurrenceUnit     byte
 	RecFocus           recurrenceFocus
+	// Days and limits; weekdays apply to w repeats, month days to m repeats
+	RecurrenceWeekdays  []time.Weekday
+	RecurrenceMonthDays []MonthDay
+	RecurrenceCount     int
+	RecurrenceUntil     time.Time
 
 	// Warning
 	HasWarning  bool
 	WarningDays int
+
+	// Text mode: the date typed in words (see ParseNaturalDate) and the
+	// token it resolves to, shown before enter applies it
+	TextMode  bool
+	Text      string
+	TextToken string
 }
 
 func NewDatePicker(t *Task, field DatePickerField) *DatePicker {
@@ -84,6 +106,7 @@ func NewDatePicker(t *Task, field DatePickerField) *DatePicker {
 		RecurrenceInterval: 1,
 		RecurrenceUnit:     'w',
 		WarningDays:        7,
+		Zone:               writeZone,
 	}
 
 	var raw string
@@ -96,22 +119,24 @@ func NewDatePicker(t *Task, field DatePickerField) *DatePicker {
 
 	if raw != "" {
 		if s, err := ParseSchedule(raw); err == nil {
-			dp.Cursor = s.Date
+			dp.loadZone(s)
 			if s.HasTime {
 				dp.HasTime = true
-				dp.Hour = s.Date.Hour()
-				dp.Minute = s.Date.Minute()
+				dp.Hour = dp.Cursor.Hour()
+				dp.Minute = dp.Cursor.Minute()
 			}
 			if s.HasEnd {
 				dp.HasEndTime = true
-				dp.EndHour = s.EndTime.Hour()
-				dp.EndMinute = s.EndTime.Minute()
+				end := s.EndTime.In(s.loc())
+				dp.EndHour = end.Hour()
+				dp.EndMinute = end.Minute()
+			}
+			if s.IsRange() {
+				dp.loadRange(s)
 			}
 			if s.Recurrence != nil {
 				dp.HasRecurrence = true
-				dp.RecurrenceMode = s.Recurrence.Mode
-				dp.RecurrenceInterval = s.Recurrence.Interval
-				dp.RecurrenceUnit = s.Recurrence.Unit
+				dp.loadRecurrence(s.Recurrence)
 			}
 			if s.Warning != nil {
 				dp.HasWarning = true
@@ -126,7 +151,16 @@ func NewDatePicker(t *Task, field DatePickerField) *DatePicker {
 func (dp *DatePicker) Update(key string) {
 	dp.ErrorMsg = ""
 
+	if dp.TextMode {
+		dp.updateText(key)
+		return
+	}
+
 	switch key {
+	case "/":
+		dp.TextMode = true
+		dp.Text, dp.TextToken = "", ""
+		return
 	case "esc":
 		dp.Cancelled = true
 		return
@@ -159,6 +193,41 @@ func (dp *DatePicker) Update(key string) {
 	}
 }
 
+// updateText edits the typed date. Enter applies the token it resolves to,
+// leaving the picker open to review or confirm it; esc drops it.
+func (dp *DatePicker) updateText(key string) {
+	switch key {
+	case "esc":
+		dp.TextMode = false
+		return
+	case "enter":
+		token, err := ParseNaturalDate(dp.Text, time.Now())
+		if err != nil {
+			dp.ErrorMsg = err.Error()
+			return
+		}
+		dp.loadFromToken(token)
+		dp.unclearActive()
+		dp.TextMode = false
+		return
+	case "backspace":
+		if r := []rune(dp.Text); len(r) > 0 {
+			dp.Text = string(r[:len(r)-1])
+		}
+	case " ", "space":
+		dp.Text += " "
+	default:
+		if len([]rune(key)) != 1 {
+			return
+		}
+		dp.Text += key
+	}
+	dp.TextToken = ""
+	if token, err := ParseNaturalDate(dp.Text, time.Now()); err == nil {
+		dp.TextToken = token
+	}
+}
+
 func (dp *DatePicker) toggleField() {
 	if dp.Field == FieldScheduled {
 		dp.Field = FieldDue
@@ -175,22 +244,25 @@ func (dp *DatePicker) toggleField() {
 
 func (dp *DatePicker) loadFromToken(raw string) {
 	if s, err := ParseSchedule(raw); err == nil {
-		dp.Cursor = s.Date
+		dp.loadZone(s)
 		dp.HasTime = s.HasTime
 		if s.HasTime {
-			dp.Hour = s.Date.Hour()
-			dp.Minute = s.Date.Minute()
+			dp.Hour = dp.Cursor.Hour()
+			dp.Minute = dp.Cursor.Minute()
 		}
 		dp.HasEndTime = s.HasEnd
 		if s.HasEnd {
-			dp.EndHour = s.EndTime.Hour()
-			dp.EndMinute = s.EndTime.Minute()
+			end := s.EndTime.In(s.loc())
+			dp.EndHour = end.Hour()
+			dp.EndMinute = end.Minute()
+		}
+		dp.SpanDays = 0
+		if s.IsRange() {
+			dp.loadRange(s)
 		}
 		dp.HasRecurrence = s.Recurrence != nil
 		if s.Recurrence != nil {
-			dp.RecurrenceMode = s.Recurrence.Mode
-			dp.RecurrenceInterval = s.Recurrence.Interval
-			dp.RecurrenceUnit = s.Recurrence.Unit
+			dp.loadRecurrence(s.Recurrence)
 		}
 		dp.HasWarning = s.Warning != nil
 		if s.Warning != nil {
@@ -199,6 +271,53 @@ func (dp *DatePicker) loadFromToken(raw string) {
 	}
 }
 
+// loadZone puts the cursor on the token's date as written, in its zone. A
+// floating time keeps its clock time and gets the configured zone.
+func (dp *DatePicker) loadZone(s *Schedule) {
+	dp.Cursor = s.Date.In(s.loc())
+	dp.tokenZone = s.Zone
+	dp.Zone = writeZone
+	if s.Zone != nil {
+		dp.Zone = s.Zone
+	}
+}
+
+// loadRange takes a range's length in days and, when it is timed, its end
+// time on the last day.
+func (dp *DatePicker) loadRange(s *Schedule) {
+	end := s.EndDate.In(s.loc())
+	dp.SpanDays = daysBetween(dp.Cursor, end)
+	if s.HasTime {
+		dp.HasEndTime = true
+		dp.EndHour = end.Hour()
+		dp.EndMinute = end.Minute()
+	}
+}
+
+// cycleZone switches the time between the token's zone, the configured
+// zone, UTC and floating local time, keeping its clock time.
+func (dp *DatePicker) cycleZone() {
+	var zones []*time.Location
+	for _, z := range []*time.Location{dp.tokenZone, writeZone, time.UTC} {
+		if z != nil && !slices.Contains(zones, z) {
+			zones = append(zones, z)
+		}
+	}
+	zones = append(zones, nil)
+	i := slices.Index(zones, dp.Zone)
+	dp.Zone = zones[(i+1)%len(zones)]
+}
+
+func (dp *DatePicker) loadRecurrence(r *RecurrenceSpec) {
+	dp.RecurrenceMode = r.Mode
+	dp.RecurrenceInterval = r.Interval
+	dp.RecurrenceUnit = r.Unit
+	dp.RecurrenceWeekdays = slices.Clone(r.Weekdays)
+	dp.RecurrenceMonthDays = slices.Clone(r.MonthDays)
+	dp.RecurrenceCount = r.Count
+	dp.RecurrenceUntil = r.Until
+}
+
 func (dp *DatePicker) updateDate(key string) {
 	switch key {
 	case "backspace":
@@ -228,11 +347,22 @@ func (dp *DatePicker) updateDate(key string) {
 	case ".":
 		dp.unclearActive()
 		dp.Cursor = time.Now()
+	case ">":
+		dp.unclearActive()
+		dp.SpanDays++
+	case "<":
+		dp.unclearActive()
+		if dp.SpanDays > 0 {
+			dp.SpanDays--
+		}
 	}
 }
 
 func (dp *DatePicker) updateTime(key string) {
 	switch key {
+	case "z":
+		dp.cycleZone()
+		return
 	case "backspace":
 		if dp.TimeFocus == timeFocusEndHour || dp.TimeFocus == timeFocusEndMinute {
 			dp.HasEndTime = false
@@ -341,7 +471,16 @@ func (dp *DatePicker) updateTime(key string) {
 func (dp *DatePicker) updateRecurrence(key string) {
 	switch key {
 	case "backspace":
-		dp.HasRecurrence = false
+		switch dp.RecFocus {
+		case recFocusDays:
+			dp.RecurrenceWeekdays = nil
+			dp.RecurrenceMonthDays = nil
+		case recFocusLimit:
+			dp.RecurrenceCount = 0
+			dp.RecurrenceUntil = time.Time{}
+		default:
+			dp.HasRecurrence = false
+		}
 		return
 	case "left", "h":
 		dp.HasRecurrence = true
@@ -351,7 +490,7 @@ func (dp *DatePicker) updateRecurrence(key string) {
 		return
 	case "right", "l":
 		dp.HasRecurrence = true
-		if dp.RecFocus < recFocusUnit {
+		if dp.RecFocus < recFocusLimit {
 			dp.RecFocus++
 		}
 		return
@@ -364,6 +503,10 @@ func (dp *DatePicker) updateRecurrence(key string) {
 			dp.RecurrenceInterval++
 		case recFocusUnit:
 			dp.RecurrenceUnit = nextUnit(dp.RecurrenceUnit)
+		case recFocusDays:
+			dp.cycleMonthDays(1)
+		case recFocusLimit:
+			dp.adjustLimit(1)
 		}
 		return
 	case "down", "j":
@@ -377,6 +520,10 @@ func (dp *DatePicker) updateRecurrence(key string) {
 			}
 		case recFocusUnit:
 			dp.RecurrenceUnit = prevUnit(dp.RecurrenceUnit)
+		case recFocusDays:
+			dp.cycleMonthDays(-1)
+		case recFocusLimit:
+			dp.adjustLimit(-1)
 		}
 		return
 	case "b":
@@ -399,11 +546,99 @@ func (dp *DatePicker) updateRecurrence(key string) {
 		dp.HasRecurrence = true
 		dp.RecurrenceUnit = 'y'
 		return
+	case "x":
+		dp.HasRecurrence = true
+		dp.RecFocus = recFocusLimit
+		dp.RecurrenceUntil = time.Time{}
+		if dp.RecurrenceCount == 0 {
+			dp.RecurrenceCount = 10
+		}
+		return
+	case "u":
+		dp.HasRecurrence = true
+		dp.RecFocus = recFocusLimit
+		dp.RecurrenceCount = 0
+		if dp.RecurrenceUntil.IsZero() {
+			// Default to the end of the year
+			dp.RecurrenceUntil = time.Date(dp.Cursor.Year(), time.December, 31, 0, 0, 0, 0, time.Local)
+		}
+		return
 	}
 
-	if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+	if len(key) != 1 || key[0] < '0' || key[0] > '9' {
+		return
+	}
+	digit := int(key[0] - '0')
+	switch dp.RecFocus {
+	case recFocusDays:
+		// 1-7 toggle Monday-Sunday of a weekly repeat
+		if digit < 1 || digit > 7 {
+			return
+		}
 		dp.HasRecurrence = true
-		dp.RecurrenceInterval = int(key[0] - '0')
+		dp.RecurrenceUnit = 'w'
+		wd := time.Weekday(digit % 7)
+		if i := slices.Index(dp.RecurrenceWeekdays, wd); i >= 0 {
+			dp.RecurrenceWeekdays = slices.Delete(dp.RecurrenceWeekdays, i, i+1)
+		} else {
+			dp.RecurrenceWeekdays = append(dp.RecurrenceWeekdays, wd)
+			slices.Sort(dp.RecurrenceWeekdays)
+		}
+	case recFocusLimit:
+		dp.HasRecurrence = true
+		dp.RecurrenceUntil = time.Time{}
+		newCount := (dp.RecurrenceCount%100)*10 + digit
+		if newCount == 0 {
+			newCount = 1
+		}
+		dp.RecurrenceCount = newCount
+	default:
+		if digit > 0 {
+			dp.HasRecurrence = true
+			dp.RecurrenceInterval = digit
+		}
+	}
+}
+
+// monthDayChoices are the month days offered for the cursor's date: its
+// day of the month, its weekday as the Nth and the last of the month, the
+// month's last day and its first and last business days.
+func (dp *DatePicker) monthDayChoices() [][]MonthDay {
+	d := dp.Cursor
+	return [][]MonthDay{
+		nil,
+		{{Kind: MonthDayDate, N: d.Day()}},
+		{{Kind: MonthDayWeekday, N: (d.Day()-1)/7 + 1, Weekday: d.Weekday()}},
+		{{Kind: MonthDayWeekday, N: -1, Weekday: d.Weekday()}},
+		{{Kind: MonthDayDate, N: -1}},
+		{{Kind: MonthDayBusiness, N: 1}},
+		{{Kind: MonthDayBusiness, N: -1}},
+	}
+}
+
+// cycleMonthDays moves through monthDayChoices, switching to a monthly
+// repeat; days typed into the token that aren't a choice start the cycle over.
+func (dp *DatePicker) cycleMonthDays(step int) {
+	choices := dp.monthDayChoices()
+	i := slices.IndexFunc(choices, func(c []MonthDay) bool { return slices.Equal(c, dp.RecurrenceMonthDays) })
+	if i < 0 {
+		i = 0
+	}
+	i = (i + step + len(choices)) % len(choices)
+	dp.RecurrenceUnit = 'm'
+	dp.RecurrenceMonthDays = choices[i]
+}
+
+// adjustLimit changes the count by one, or the until date by a month.
+func (dp *DatePicker) adjustLimit(step int) {
+	switch {
+	case !dp.RecurrenceUntil.IsZero():
+		until := addMonths(dp.RecurrenceUntil, step)
+		if !until.Before(truncateToDay(dp.Cursor)) {
+			dp.RecurrenceUntil = until
+		}
+	case dp.RecurrenceCount+step >= 1:
+		dp.RecurrenceCount += step
 	}
 }
 
@@ -488,20 +723,43 @@ func (dp *DatePicker) buildToken() string {
 		HasTime: dp.HasTime,
 	}
 	if dp.HasTime {
+		sched.Zone = dp.Zone
 		sched.Date = time.Date(dp.Cursor.Year(), dp.Cursor.Month(), dp.Cursor.Day(),
-			dp.Hour, dp.Minute, 0, 0, time.Local)
+			dp.Hour, dp.Minute, 0, 0, sched.loc())
 		sched.HasTime = true
 	}
 	if dp.HasTime && dp.HasEndTime {
 		sched.HasEnd = true
 		sched.EndTime = time.Date(dp.Cursor.Year(), dp.Cursor.Month(), dp.Cursor.Day(),
-			dp.EndHour, dp.EndMinute, 0, 0, time.Local)
+			dp.EndHour, dp.EndMinute, 0, 0, sched.loc())
+	}
+	if dp.SpanDays > 0 {
+		// The end time moves to the range's last day; a timed range
+		// without one ends at its start time
+		last := dp.Cursor.AddDate(0, 0, dp.SpanDays)
+		hour, minute := 0, 0
+		switch {
+		case sched.HasEnd:
+			hour, minute = dp.EndHour, dp.EndMinute
+		case dp.HasTime:
+			hour, minute = dp.Hour, dp.Minute
+		}
+		sched.HasEnd = false
+		sched.EndDate = time.Date(last.Year(), last.Month(), last.Day(), hour, minute, 0, 0, sched.loc())
 	}
 	if dp.HasRecurrence {
 		sched.Recurrence = &RecurrenceSpec{
 			Mode:     dp.RecurrenceMode,
 			Interval: dp.RecurrenceInterval,
 			Unit:     dp.RecurrenceUnit,
+			Count:    dp.RecurrenceCount,
+			Until:    dp.RecurrenceUntil,
+		}
+		switch dp.RecurrenceUnit {
+		case 'w':
+			sched.Recurrence.Weekdays = dp.RecurrenceWeekdays
+		case 'm':
+			sched.Recurrence.MonthDays = dp.RecurrenceMonthDays
 		}
 	}
 	if dp.HasWarning {
@@ -612,6 +870,11 @@ func (dp *DatePicker) View(width int) string {
 		dateLabelStyle = sectionActiveStyle
 	}
 	b.WriteString(dateLabelStyle.Render("  [Date]"))
+	if dp.SpanDays > 0 {
+		last := dp.Cursor.AddDate(0, 0, dp.SpanDays)
+		b.WriteString(dimStyle.Render(fmt.Sprintf("       %s → %s (%d days)",
+			dp.Cursor.Format("Mon Jan 02"), last.Format("Mon Jan 02"), dp.SpanDays+1)))
+	}
 	b.WriteString("\n")
 
 	calWidth := 22
@@ -656,6 +919,10 @@ func (dp *DatePicker) View(width int) string {
 				b.WriteString(dimStyle.Render(endMinStr))
 			}
 		}
+		if dp.SpanDays > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf(" +%dd", dp.SpanDays)))
+		}
+		b.WriteString(dimStyle.Render(dp.zoneInfo()))
 	} else {
 		b.WriteString(dimStyle.Render("--:--"))
 	}
@@ -668,24 +935,35 @@ func (dp *DatePicker) View(width int) string {
 	}
 	b.WriteString(recLabelStyle.Render("  [Recurrence] "))
 	if dp.HasRecurrence {
-		modeStr := recurrenceModeStr(dp.RecurrenceMode)
-		intervalStr := fmt.Sprintf("%d", dp.RecurrenceInterval)
-		unitStr := string(dp.RecurrenceUnit)
-		if dp.Section == sectionRecurrence {
-			switch dp.RecFocus {
-			case recFocusMode:
-				b.WriteString(sectionActiveStyle.Render(modeStr))
-				b.WriteString(dimStyle.Render(intervalStr + unitStr))
-			case recFocusInterval:
-				b.WriteString(dimStyle.Render(modeStr))
-				b.WriteString(sectionActiveStyle.Render(intervalStr))
-				b.WriteString(dimStyle.Render(unitStr))
-			case recFocusUnit:
-				b.WriteString(dimStyle.Render(modeStr+intervalStr))
-				b.WriteString(sectionActiveStyle.Render(unitStr))
+		// The repeat as its token parts; the focused part is highlighted
+		// and shows a placeholder when empty
+		parts := []struct {
+			text, placeholder string
+		}{
+			{recurrenceModeStr(dp.RecurrenceMode), ""},
+			{fmt.Sprintf("%d", dp.RecurrenceInterval), ""},
+			{string(dp.RecurrenceUnit), ""},
+			{"", ":days"},
+			{"", " no end"},
+		}
+		if days := dp.recurrenceDays(); days != "" {
+			parts[recFocusDays].text = ":" + days
+		}
+		if dp.RecurrenceCount > 0 {
+			parts[recFocusLimit].text = fmt.Sprintf("x%d", dp.RecurrenceCount)
+		} else if !dp.RecurrenceUntil.IsZero() {
+			parts[recFocusLimit].text = ".." + dp.RecurrenceUntil.Format("2006-01-02")
+		}
+		for i, part := range parts {
+			if dp.Section == sectionRecurrence && recurrenceFocus(i) == dp.RecFocus {
+				if part.text == "" {
+					b.WriteString(sectionActiveStyle.Render(part.placeholder))
+				} else {
+					b.WriteString(sectionActiveStyle.Render(part.text))
+				}
+			} else {
+				b.WriteString(dimStyle.Render(part.text))
 			}
-		} else {
-			b.WriteString(dimStyle.Render(modeStr + intervalStr + unitStr))
 		}
 		b.WriteString(dimStyle.Render(fmt.Sprintf("  (%s)", recurrenceModeName(dp.RecurrenceMode))))
 	} else {
@@ -711,6 +989,19 @@ func (dp *DatePicker) View(width int) string {
 	}
 	b.WriteString("\n")
 
+	// Typed date
+	if dp.TextMode {
+		b.WriteString("\n")
+		b.WriteString(activeStyle.Render("  Date: " + dp.Text + "_"))
+		switch {
+		case dp.TextToken != "":
+			b.WriteString(dimStyle.Render("  → " + dp.TextToken))
+		case strings.TrimSpace(dp.Text) != "":
+			b.WriteString(dimStyle.Render("  → ?"))
+		}
+		b.WriteString("\n")
+	}
+
 	// Error
 	if dp.ErrorMsg != "" {
 		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
@@ -720,22 +1011,65 @@ func (dp *DatePicker) View(width int) string {
 
 	// Help
 	b.WriteString("\n\n")
+	if dp.TextMode {
+		b.WriteString(dimStyle.Render("type a date: tomorrow 3pm, next fri, every mon at 9, in 2 weeks, +3bd"))
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("enter: apply • backspace: delete • esc: back"))
+		return boxStyle.Render(b.String())
+	}
 	switch dp.Section {
 	case sectionDate:
-		b.WriteString(dimStyle.Render("arrows: navigate • [/]: month • .: today • f: field"))
+		b.WriteString(dimStyle.Render("arrows: navigate • [/]: month • .: today • </>: span days • f: field"))
 	case sectionTime:
-		b.WriteString(dimStyle.Render("h/l: navigate • j/k: adjust • 0-9: type • l past min: end time • backspace: clear"))
+		b.WriteString(dimStyle.Render("h/l: navigate • j/k: adjust • 0-9: type • l past min: end time • z: zone • backspace: clear"))
 	case sectionRecurrence:
-		b.WriteString(dimStyle.Render("h/l: mode/interval/unit • j/k: adjust • b/d/w/m/y: unit • backspace: clear"))
+		switch dp.RecFocus {
+		case recFocusDays:
+			b.WriteString(dimStyle.Render("1-7: toggle Mon-Sun (weekly) • j/k: month day (monthly) • backspace: clear"))
+		case recFocusLimit:
+			b.WriteString(dimStyle.Render("x: count • u: until • j/k: adjust • 0-9: type count • backspace: no end"))
+		default:
+			b.WriteString(dimStyle.Render("h/l: mode/interval/unit/days/end • j/k: adjust • b/d/w/m/y: unit • backspace: clear"))
+		}
 	case sectionWarning:
 		b.WriteString(dimStyle.Render("j/k: adjust days • 0-9: type • backspace: clear"))
 	}
 	b.WriteString("\n")
-	b.WriteString(dimStyle.Render("tab/shift+tab: section • enter: confirm • esc: cancel"))
+	b.WriteString(dimStyle.Render("tab/shift+tab: section • /: type a date • enter: confirm • esc: cancel"))
 
 	return boxStyle.Render(b.String())
 }
 
+// zoneInfo describes the time's zone, with the local time it converts to
+// when that differs: " [America/New_York] = 15:00 local".
+func (dp *DatePicker) zoneInfo() string {
+	if dp.Zone == nil {
+		return ""
+	}
+	start := time.Date(dp.Cursor.Year(), dp.Cursor.Month(), dp.Cursor.Day(), dp.Hour, dp.Minute, 0, 0, dp.Zone)
+	info := " " + formatZone(dp.Zone, true)
+	local := start.In(time.Local)
+	switch {
+	case local.Day() != start.Day():
+		info += " = " + local.Format("Mon 15:04") + " local"
+	case local.Hour() != start.Hour() || local.Minute() != start.Minute():
+		info += " = " + local.Format("15:04") + " local"
+	}
+	return info
+}
+
+// recurrenceDays returns the DAYS part for the current unit.
+func (dp *DatePicker) recurrenceDays() string {
+	r := &RecurrenceSpec{}
+	switch dp.RecurrenceUnit {
+	case 'w':
+		r.Weekdays = dp.RecurrenceWeekdays
+	case 'm':
+		r.MonthDays = dp.RecurrenceMonthDays
+	}
+	return r.formatDays()
+}
+
 func recurrenceModeStr(mode RecurrenceMode) string {
 	switch mode {
 	case RecurrenceFromDone:
@@ -762,13 +1096,14 @@ func (dp *DatePicker) renderCalendars(count int) string {
 	today := time.Now()
 	todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
 	cursorDate := time.Date(dp.Cursor.Year(), dp.Cursor.Month(), dp.Cursor.Day(), 0, 0, 0, 0, time.Local)
+	spanEnd := cursorDate.AddDate(0, 0, dp.SpanDays)
 
 	var allMonthLines [][]string
 	baseYear, baseMonth, _ := dp.Cursor.Date()
 
 	for i := range count {
 		y, m := shiftMonth(baseYear, baseMonth, i)
-		lines := renderMonth(y, m, todayDate, cursorDate)
+		lines := renderMonth(y, m, todayDate, cursorDate, spanEnd)
 		allMonthLines = append(allMonthLines, lines)
 	}
 
@@ -815,11 +1150,14 @@ func shiftMonth(year int, month time.Month, offset int) (int, time.Month) {
 	return year, time.Month(m)
 }
 
-func renderMonth(year int, month time.Month, todayDate, cursorDate time.Time) []string {
+// renderMonth renders a month's calendar with the cursor's date selected and
+// the rest of its range, up to spanEnd, highlighted.
+func renderMonth(year int, month time.Month, todayDate, cursorDate, spanEnd time.Time) []string {
 	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true)
 	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	todayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
 	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("14")).Bold(true)
+	spanStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Background(lipgloss.Color("237"))
 
 	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
 	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
@@ -849,6 +1187,8 @@ func renderMonth(year int, month time.Month, todayDate, cursorDate time.Time) []
 
 		if d.Equal(cursorDate) {
 			row.WriteString(selectedStyle.Render(cell))
+		} else if d.After(cursorDate) && !d.After(spanEnd) {
+			row.WriteString(spanStyle.Render(cell))
 		} else if d.Equal(todayDate) {
 			row.WriteString(todayStyle.Render(cell))
 		} else {
@@ -0,0 +1,144 @@
+package task
+
+import "testing"
+
+func TestDatePicker_Recurrence(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		keys  []string
+		want  string
+	}{
+		{"unchanged", "2025-06-02+1w:mon,fri..2025-12-31!2d", nil, "2025-06-02+1w:mon,fri..2025-12-31!2d"},
+		{"toggle weekdays", "2025-06-02+1w", []string{"l", "l", "l", "1", "3", "5", "3"}, "2025-06-02+1w:mon,fri"},
+		{"weekdays switch to weekly", "2025-06-02+1d", []string{"l", "l", "l", "7"}, "2025-06-02+1w:sun"},
+		{"day of the month", "2025-01-14+1w", []string{"l", "l", "l", "k"}, "2025-01-14+1m:14"},
+		{"nth weekday", "2025-01-14+1w", []string{"l", "l", "l", "k", "k"}, "2025-01-14+1m:2tue"},
+		{"last business day", "2025-01-14+1m", []string{"l", "l", "l", "j"}, "2025-01-14+1m:-1bd"},
+		{"clear days", "2025-06-02+1w:mon,fri", []string{"l", "l", "l", "backspace"}, "2025-06-02+1w"},
+		{"count", "2025-06-02+1d", []string{"x", "j"}, "2025-06-02+1dx9"},
+		{"typed count", "2025-06-02+1d", []string{"x", "0", "4"}, "2025-06-02+1dx4"},
+		{"until", "2025-06-02+1d", []string{"u", "k"}, "2025-06-02+1d..2026-01-31"},
+		{"clear limit", "2025-06-02+1dx3", []string{"l", "l", "l", "l", "backspace"}, "2025-06-02+1d"},
+		{"days ignored for another unit", "2025-06-02+1w:mon", []string{"m"}, "2025-06-02+1m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := NewDatePicker(&Task{ScheduledAt: tt.token}, FieldScheduled)
+			dp.Update("tab")
+			dp.Update("tab")
+			for _, key := range tt.keys {
+				dp.Update(key)
+			}
+			if got, _, _, _ := dp.Result(); got != tt.want {
+				t.Errorf("Result() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatePicker_Zone(t *testing.T) {
+	tests := []struct {
+		name      string
+		writeZone string
+		token     string
+		keys      []string
+		want      string
+	}{
+		{"unchanged", "", "2025-07-01T09:00-10:00[America/New_York]", nil, "2025-07-01T09:00-10:00[America/New_York]"},
+		{"floating stays floating", "", "2025-07-01T09:00", nil, "2025-07-01T09:00"},
+		{"floating gets the configured zone", "Asia/Tokyo", "2025-07-01T09:00", nil, "2025-07-01T09:00[Asia/Tokyo]"},
+		{"cycle to UTC", "", "2025-07-01T09:00", []string{"z"}, "2025-07-01T09:00Z"},
+		{"cycle back to floating", "", "2025-07-01T09:00", []string{"z", "z"}, "2025-07-01T09:00"},
+		{"cycle from the token's zone", "Asia/Tokyo", "2025-07-01T09:00[America/New_York]", []string{"z"}, "2025-07-01T09:00[Asia/Tokyo]"},
+		{"dates have no zone", "Asia/Tokyo", "2025-07-01", []string{"z"}, "2025-07-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withZones(t, "Europe/Berlin", tt.writeZone)
+			dp := NewDatePicker(&Task{ScheduledAt: tt.token}, FieldScheduled)
+			dp.Update("tab")
+			for _, key := range tt.keys {
+				dp.Update(key)
+			}
+			if got, _, _, _ := dp.Result(); got != tt.want {
+				t.Errorf("Result() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatePicker_Range(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		keys  []string
+		want  string
+	}{
+		{"unchanged", "2025-07-01--2025-07-05+1w", nil, "2025-07-01--2025-07-05+1w"},
+		{"timed unchanged", "2025-07-01T18:00--2025-07-03T09:00", nil, "2025-07-01T18:00--2025-07-03T09:00"},
+		{"extend", "2025-07-01", []string{">", ">"}, "2025-07-01--2025-07-03"},
+		{"shrink to a day", "2025-07-01--2025-07-02", []string{"<", "<"}, "2025-07-01"},
+		{"move keeps the length", "2025-07-01--2025-07-05", []string{"l"}, "2025-07-02--2025-07-06"},
+		{"end time moves to the last day", "2025-07-01T09:00-17:00", []string{">"}, "2025-07-01T09:00--2025-07-02T17:00"},
+		{"timed without an end time", "2025-07-01T09:00", []string{">"}, "2025-07-01T09:00--2025-07-02T09:00"},
+		{"back to a day keeps the end time", "2025-07-01T18:00--2025-07-03T09:00", []string{"<", "<"}, "2025-07-01T18:00-09:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := NewDatePicker(&Task{ScheduledAt: tt.token}, FieldScheduled)
+			for _, key := range tt.keys {
+				dp.Update(key)
+			}
+			if got, _, _, _ := dp.Result(); got != tt.want {
+				t.Errorf("Result() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatePicker_Text(t *testing.T) {
+	typed := func(s string) []string {
+		keys := []string{"/"}
+		for _, r := range s {
+			keys = append(keys, string(r))
+		}
+		return keys
+	}
+	tests := []struct {
+		name      string
+		token     string
+		keys      []string
+		want      string
+		wantText  bool // still typing
+		wantError bool
+	}{
+		{"applies the date", "2025-06-02", append(typed("jul 4 2025 3pm"), "enter"), "2025-07-04T15:00", false, false},
+		{"replaces repeat and warning", "2025-06-02+1w!2d", append(typed("2025-07-01 every 2 weeks"), "enter"), "2025-07-01+2w", false, false},
+		{"backspace", "2025-06-02", append(typed("2025-07-011"), "backspace", "enter"), "2025-07-01", false, false},
+		{"unknown stays open", "2025-06-02", append(typed("someday"), "enter"), "2025-06-02", true, true},
+		{"esc drops it", "2025-06-02", append(typed("2025-07-01"), "esc"), "2025-06-02", false, false},
+		{"then adjust", "2025-06-02", append(typed("2025-07-01"), "enter", "l"), "2025-07-02", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := NewDatePicker(&Task{ScheduledAt: tt.token}, FieldScheduled)
+			for _, key := range tt.keys {
+				dp.Update(key)
+			}
+			if dp.Confirmed || dp.Cancelled {
+				t.Fatalf("picker closed: confirmed %v, cancelled %v", dp.Confirmed, dp.Cancelled)
+			}
+			if dp.TextMode != tt.wantText || (dp.ErrorMsg != "") != tt.wantError {
+				t.Errorf("TextMode = %v, ErrorMsg = %q", dp.TextMode, dp.ErrorMsg)
+			}
+			if got, _, _, _ := dp.Result(); got != tt.want {
+				t.Errorf("Result() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
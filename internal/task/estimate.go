@@ -0,0 +1,106 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// estimatePattern is the amount of an effort estimate, as in "~3h",
+// "~1h30m" or "~2d".
+const estimatePattern = `(?:\d+(?:\.\d+)?[mhdw])+`
+
+// estimateRe matches an estimate word in a task line. It ends where the
+// line model ends a word (see nextWord), so "~3h," is text to both.
+var estimateRe = regexp.MustCompile(`(?:^|\s)~(` + estimatePattern + `)(?:[ \t]|>>|$)`)
+
+var estimatePartRe = regexp.MustCompile(`(\d+(?:\.\d+)?)([mhdw])`)
+
+// Working-time units used by estimates: a day is a working day, a week is
+// five working days.
+const (
+	estimateDay  = 8 * time.Hour
+	estimateWeek = 5 * estimateDay
+)
+
+// ParseEstimate parses an estimate such as "3h", "1h30m", "1.5d" or "2w".
+// Units are m (minutes), h (hours), d (8h working days) and w (5d weeks).
+func ParseEstimate(s string) (time.Duration, error) {
+	if s == "" || estimatePartRe.ReplaceAllString(s, "") != "" {
+		return 0, fmt.Errorf("invalid estimate %q (e.g. 30m, 3h, 1h30m, 2d, 1w)", s)
+	}
+	var total time.Duration
+	for _, m := range estimatePartRe.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid estimate %q: %w", s, err)
+		}
+		unit := time.Minute
+		switch m[2] {
+		case "h":
+			unit = time.Hour
+		case "d":
+			unit = estimateDay
+		case "w":
+			unit = estimateWeek
+		}
+		total += time.Duration(n * float64(unit))
+	}
+	return total, nil
+}
+
+// EstimateDuration returns the task's effort estimate, or 0 if it has none.
+func (t *Task) EstimateDuration() time.Duration {
+	if t.Estimate == "" {
+		return 0
+	}
+	d, err := ParseEstimate(t.Estimate)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// ClockedTotal returns the total time clocked on the task across all CLOCK
+// entries. A running clock counts up to now.
+func ClockedTotal(t *Task) time.Duration {
+	entries, err := ParseClockEntries(t)
+	if err != nil {
+		return 0
+	}
+	var total time.Duration
+	now := time.Now()
+	for _, e := range entries {
+		end := e.End
+		if e.Open {
+			end = now
+		}
+		if d := end.Sub(e.Start); d > 0 {
+			total += d
+		}
+	}
+	return total
+}
+
+// IsOverBudget reports whether more time has been clocked on the task than
+// its estimate. Tasks without an estimate are never over budget.
+func IsOverBudget(t *Task) bool {
+	est := t.EstimateDuration()
+	return est > 0 && ClockedTotal(t) > est
+}
+
+// cutEstimate removes the estimate words from a title and returns the
+// first one's amount.
+func cutEstimate(title string) (rest, estimate string) {
+	for {
+		m := estimateRe.FindStringSubmatchIndex(title)
+		if m == nil {
+			return title, estimate
+		}
+		if estimate == "" {
+			estimate = title[m[2]:m[3]]
+		}
+		title = title[:m[0]] + title[m[3]:]
+	}
+}
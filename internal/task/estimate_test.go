@@ -0,0 +1,154 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseLineWithEstimate(t *testing.T) {
+	cfg := createTestConfig()
+
+	tests := []struct {
+		name         string
+		line         string
+		wantEstimate string
+		wantTitle    string
+		wantAssignee string
+	}{
+		{"hours", "TODO: Write report ~3h #docs", "3h", "Write report", ""},
+		{"compound", "TODO: [r-1] Write report ~1h30m", "1h30m", "Write report", ""},
+		{"after assignee", "TODO: Write report >> alice ~2d", "2d", "Write report", "alice"},
+		{"fractional", "- TODO: Write report ~1.5h @2025-01-15", "1.5h", "Write report", ""},
+		{"home path is not an estimate", "TODO: Clean ~/tmp", "", "Clean ~/tmp", ""},
+		{"unknown unit", "TODO: Wait ~3y", "", "Wait ~3y", ""},
+		{"embedded tilde", "TODO: Approx~3h", "", "Approx~3h", ""},
+		{"trailing punctuation", "TODO: Done in ~3h, maybe", "", "Done in ~3h, maybe", ""},
+		{"before assignee", "TODO: Write report ~2d>>alice", "2d", "Write report", "alice"},
+		{"first of two", "TODO: Write report ~2d ~3h", "2d", "Write report", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := ParseLine(cfg, tt.line, "test", "20250101000000", "test.md")
+			if task == nil {
+				t.Fatal("ParseLine returned nil")
+			}
+			if task.Estimate != tt.wantEstimate {
+				t.Errorf("Estimate = %q, want %q", task.Estimate, tt.wantEstimate)
+			}
+			if task.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", task.Title, tt.wantTitle)
+			}
+			if task.Assignee != tt.wantAssignee {
+				t.Errorf("Assignee = %q, want %q", task.Assignee, tt.wantAssignee)
+			}
+			// The line model must read the same estimate, or edits lose it
+			if m, _ := ParseLineModel(tt.line); m.Estimate() != tt.wantEstimate {
+				t.Errorf("LineModel Estimate() = %q, want %q", m.Estimate(), tt.wantEstimate)
+			}
+		})
+	}
+}
+
+func TestParseEstimate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"3h", 3 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"1.5h", 90 * time.Minute, false},
+		{"2d", 16 * time.Hour, false},
+		{"1w", 40 * time.Hour, false},
+		{"", 0, true},
+		{"3", 0, true},
+		{"3y", 0, true},
+		{"h3", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseEstimate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEstimate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseEstimate(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsOverBudget(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "test.md")
+	content := `DOING: Write design doc ~2h
+  CLOCK: 2026-06-17T09:30--2026-06-17T11:15
+  CLOCK: 2026-06-18T14:00--2026-06-18T15:30
+`
+	os.WriteFile(f, []byte(content), 0644)
+
+	task := &Task{Keyword: "DOING", Title: "Write design doc", Estimate: "2h", FilePath: f, LineNum: 1}
+	if got := ClockedTotal(task); got != 3*time.Hour+15*time.Minute {
+		t.Errorf("ClockedTotal = %v, want 3h15m", got)
+	}
+	if !IsOverBudget(task) {
+		t.Error("expected task to be over budget")
+	}
+
+	task.Estimate = "1d"
+	if IsOverBudget(task) {
+		t.Error("expected task to be within budget")
+	}
+	task.Estimate = ""
+	if IsOverBudget(task) {
+		t.Error("task without estimate must not be over budget")
+	}
+}
+
+func TestQueryClockTable_Estimates(t *testing.T) {
+	cfg, tmpDir := makeProcessFileConfig(t)
+	cfg.Directories.Karya = t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "work"), 0755)
+	writeTaskFile(t, tmpDir, "work/tasks.md", `DOING: Write design doc ~2h
+  CLOCK: 2026-06-10T09:00--2026-06-10T11:00
+  CLOCK: 2026-06-17T09:30--2026-06-17T10:30
+TODO: Review PR ~1h
+  CLOCK: 2026-06-17T14:00--2026-06-17T14:30
+TODO: Unestimated
+  CLOCK: 2026-06-17T16:00--2026-06-17T16:15
+`)
+
+	day := time.Date(2026, 6, 17, 0, 0, 0, 0, time.Local)
+	table, err := QueryClockTable(cfg, day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Projects) != 1 || len(table.Projects[0].Entries) != 3 {
+		t.Fatalf("unexpected table: %+v", table)
+	}
+
+	byTitle := make(map[string]ClockTableEntry)
+	for _, e := range table.Projects[0].Entries {
+		byTitle[e.Task.Title] = e
+	}
+	doc := byTitle["Write design doc"]
+	if doc.Duration != time.Hour || doc.Actual != 3*time.Hour || doc.Estimate != 2*time.Hour || !doc.OverBudget() {
+		t.Errorf("design doc entry = %+v, want 1h in range, 3h actual, 2h estimate, over budget", doc)
+	}
+	if pr := byTitle["Review PR"]; pr.Actual != 30*time.Minute || pr.OverBudget() {
+		t.Errorf("review entry = %+v, want 30m actual within budget", pr)
+	}
+	if un := byTitle["Unestimated"]; un.Estimate != 0 || un.Actual != 0 {
+		t.Errorf("unestimated entry = %+v, want no estimate/actual", un)
+	}
+
+	proj := table.Projects[0]
+	if proj.Estimate != 3*time.Hour || proj.Actual != 3*time.Hour+30*time.Minute || !proj.OverBudget() {
+		t.Errorf("project estimate/actual = %v/%v, want 3h/3h30m over budget", proj.Estimate, proj.Actual)
+	}
+}
@@ -0,0 +1,451 @@
+package task
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// ExportVersion is the version of the export schema below. It changes only
+// when a field is renamed or removed; new fields may be added within a
+// version.
+const ExportVersion = 1
+
+// ExportFormats lists the formats accepted by WriteTasks, WriteProjects and
+// WriteAgenda.
+var ExportFormats = []string{"json", "csv", "tsv", "markdown"}
+
+// ExportTask is the exported form of a Task. Location ("file:line")
+// identifies tasks without an ID; Parent is the parent's Location.
+type ExportTask struct {
+	Location        string            `json:"location"`
+	ID              string            `json:"id,omitempty"`
+	Keyword         string            `json:"keyword"`
+	Category        string            `json:"category"` // active, inprogress, completed or someday
+	PriorityCookie  string            `json:"priority_cookie,omitempty"`
+	Title           string            `json:"title"`
+	Tags            []string          `json:"tags,omitempty"`
+	References      []string          `json:"references,omitempty"`
+	Scheduled       *ExportDate       `json:"scheduled,omitempty"`
+	Due             *ExportDate       `json:"due,omitempty"`
+	Estimate        string            `json:"estimate,omitempty"`
+	EstimateMinutes int               `json:"estimate_minutes,omitempty"`
+	ClockedMinutes  int               `json:"clocked_minutes,omitempty"`
+	ClockActive     bool              `json:"clock_active,omitempty"`
+	Assignee        string            `json:"assignee,omitempty"`
+	Project         string            `json:"project"`
+	Zettel          string            `json:"zettel,omitempty"`
+	Properties      map[string]string `json:"properties,omitempty"`
+	File            string            `json:"file"`
+	Line            int               `json:"line"`
+	Parent          string            `json:"parent,omitempty"`
+	Depth           int               `json:"depth"`
+	InCycle         bool              `json:"in_cycle,omitempty"`
+	Blocked         bool              `json:"blocked,omitempty"`
+	DuplicateID     bool              `json:"duplicate_id,omitempty"`
+	Children        []*ExportTask     `json:"children,omitempty"`
+}
+
+// ExportDate is a parsed @s:/@d: date. Raw is the token as written; Date,
+// Time and EndTime are its parts (YYYY-MM-DD, HH:MM), in Zone when the time
+// has one (an IANA name, UTC or an offset such as +02:00). A range spanning
+// days has an EndDate, with EndTime its time on that day. Invalid dates have
+// only Raw.
+type ExportDate struct {
+	Raw         string            `json:"raw"`
+	Date        string            `json:"date,omitempty"`
+	Time        string            `json:"time,omitempty"`
+	EndTime     string            `json:"end_time,omitempty"`
+	EndDate     string            `json:"end_date,omitempty"`
+	Zone        string            `json:"zone,omitempty"`
+	Recurrence  *ExportRecurrence `json:"recurrence,omitempty"`
+	WarningDays int               `json:"warning_days,omitempty"`
+}
+
+// ExportRecurrence is a date's repeater: Mode is fixed (+N), from_done (.+N)
+// or next_future (++N); Unit is d, w, m, y or b. Days are the repeat's
+// weekdays or month days as written in the token (mon, 15, 2tue, -1bd);
+// Count and Until its limits, when set.
+type ExportRecurrence struct {
+	Mode     string   `json:"mode"`
+	Interval int      `json:"interval"`
+	Unit     string   `json:"unit"`
+	Days     []string `json:"days,omitempty"`
+	Count    int      `json:"count,omitempty"`
+	Until    string   `json:"until,omitempty"`
+}
+
+// ExportTasks converts tasks for export. A task whose parent is also in
+// tasks is nested under it in Children; the others are returned in order.
+func ExportTasks(c *config.Config, tasks []*Task) []*ExportTask {
+	exported := make(map[*Task]*ExportTask, len(tasks))
+	for _, t := range tasks {
+		exported[t] = exportTask(c, t)
+	}
+	depth := func(t *Task) int {
+		d := 0
+		for p := t.Parent; p != nil; p = p.Parent {
+			d++
+		}
+		return d
+	}
+	var roots []*ExportTask
+	for _, t := range tasks {
+		e := exported[t]
+		e.Depth = depth(t)
+		if parent, ok := exported[t.Parent]; ok {
+			parent.Children = append(parent.Children, e)
+		} else {
+			roots = append(roots, e)
+		}
+	}
+	return roots
+}
+
+func exportTask(c *config.Config, t *Task) *ExportTask {
+	e := &ExportTask{
+		Location:       taskLocation(t),
+		ID:             t.ID,
+		Keyword:        t.Keyword,
+		Category:       taskCategory(c, t),
+		PriorityCookie: t.PriorityCookie,
+		Title:          t.Title,
+		Tags:           t.Tags,
+		References:     t.References,
+		Scheduled:      exportDate(t.ScheduledAt),
+		Due:            exportDate(t.DueAt),
+		Estimate:       t.Estimate,
+		Assignee:       t.Assignee,
+		Project:        t.Project,
+		Zettel:         t.Zettel,
+		Properties:     t.Properties,
+		File:           t.FilePath,
+		Line:           t.LineNum,
+		InCycle:        t.InCycle,
+		Blocked:        t.Blocked,
+		DuplicateID:    t.DuplicateID,
+	}
+	if t.Parent != nil {
+		e.Parent = taskLocation(t.Parent)
+	}
+	e.EstimateMinutes = int(t.EstimateDuration() / time.Minute)
+	if t.FilePath != "" {
+		e.ClockedMinutes = int(ClockedTotal(t) / time.Minute)
+		e.ClockActive = IsClockActive(t)
+	}
+	return e
+}
+
+func taskLocation(t *Task) string {
+	return fmt.Sprintf("%s:%d", t.FilePath, t.LineNum)
+}
+
+func exportDate(raw string) *ExportDate {
+	if raw == "" {
+		return nil
+	}
+	d := &ExportDate{Raw: raw}
+	s, err := ParseSchedule(raw)
+	if err != nil {
+		return d
+	}
+	start, end := s.Date.In(s.loc()), s.EndTime.In(s.loc())
+	d.Date = start.Format("2006-01-02")
+	if s.HasTime {
+		d.Time = start.Format("15:04")
+	}
+	if s.HasEnd {
+		d.EndTime = end.Format("15:04")
+	}
+	if s.IsRange() {
+		endDate := s.EndDate.In(s.loc())
+		d.EndDate = endDate.Format("2006-01-02")
+		if s.HasTime {
+			d.EndTime = endDate.Format("15:04")
+		}
+	}
+	if s.Zone != nil {
+		d.Zone = s.Zone.String()
+	}
+	if r := s.Recurrence; r != nil {
+		mode := "fixed"
+		switch r.Mode {
+		case RecurrenceFromDone:
+			mode = "from_done"
+		case RecurrenceNextFuture:
+			mode = "next_future"
+		}
+		d.Recurrence = &ExportRecurrence{Mode: mode, Interval: r.Interval, Unit: string(r.Unit), Count: r.Count}
+		if days := r.formatDays(); days != "" {
+			d.Recurrence.Days = strings.Split(days, ",")
+		}
+		if !r.Until.IsZero() {
+			d.Recurrence.Until = r.Until.Format("2006-01-02")
+		}
+	}
+	if s.Warning != nil {
+		d.WarningDays = s.Warning.Days
+	}
+	return d
+}
+
+// taskColumns are the columns of the CSV, TSV and Markdown task exports.
+// Lists are space-separated and properties are "key=value" pairs joined
+// by "; ".
+var taskColumns = []string{
+	"project", "keyword", "category", "priority", "id", "title", "tags", "references",
+	"scheduled", "due", "estimate", "estimate_minutes", "clocked_minutes", "assignee",
+	"properties", "blocked", "file", "line", "parent", "depth",
+}
+
+func (e *ExportTask) row() []string {
+	raw := func(d *ExportDate) string {
+		if d == nil {
+			return ""
+		}
+		return d.Raw
+	}
+	var props []string
+	for _, k := range sortedKeys(e.Properties) {
+		props = append(props, k+"="+e.Properties[k])
+	}
+	return []string{
+		e.Project, e.Keyword, e.Category, e.PriorityCookie, e.ID, e.Title,
+		strings.Join(e.Tags, " "), strings.Join(e.References, " "),
+		raw(e.Scheduled), raw(e.Due), e.Estimate,
+		strconv.Itoa(e.EstimateMinutes), strconv.Itoa(e.ClockedMinutes), e.Assignee,
+		strings.Join(props, "; "), strconv.FormatBool(e.Blocked),
+		e.File, strconv.Itoa(e.Line), e.Parent, strconv.Itoa(e.Depth),
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flatten lists the tasks depth-first, each parent before its children.
+func flatten(tasks []*ExportTask) []*ExportTask {
+	var flat []*ExportTask
+	for _, e := range tasks {
+		flat = append(flat, e)
+		flat = append(flat, flatten(e.Children)...)
+	}
+	return flat
+}
+
+// WriteTasks writes tasks in the given format (see ExportFormats). JSON
+// nests children under their parents; the tabular formats list them after
+// their parent with the parent's location in the parent column.
+func WriteTasks(w io.Writer, c *config.Config, tasks []*Task, format string) error {
+	exported := ExportTasks(c, tasks)
+	if format == "json" {
+		return writeJSON(w, struct {
+			Version int           `json:"version"`
+			Tasks   []*ExportTask `json:"tasks"`
+		}{ExportVersion, nonNil(exported)})
+	}
+	var rows [][]string
+	for _, e := range flatten(exported) {
+		rows = append(rows, e.row())
+	}
+	return writeTable(w, format, taskColumns, rows)
+}
+
+// ExportProject is one row of the project summary export.
+type ExportProject struct {
+	Project string `json:"project"`
+	Tasks   int    `json:"tasks"`
+}
+
+// WriteProjects writes the per-project task counts of SummarizeProjects,
+// sorted by project name.
+func WriteProjects(w io.Writer, summary map[string]int, format string) error {
+	projects := make([]ExportProject, 0, len(summary))
+	for p, n := range summary {
+		projects = append(projects, ExportProject{Project: p, Tasks: n})
+	}
+	slices.SortFunc(projects, func(a, b ExportProject) int { return strings.Compare(a.Project, b.Project) })
+	if format == "json" {
+		return writeJSON(w, struct {
+			Version  int             `json:"version"`
+			Projects []ExportProject `json:"projects"`
+		}{ExportVersion, projects})
+	}
+	var rows [][]string
+	for _, p := range projects {
+		rows = append(rows, []string{p.Project, strconv.Itoa(p.Tasks)})
+	}
+	return writeTable(w, format, []string{"project", "tasks"}, rows)
+}
+
+// ExportAgendaItem is an agenda entry: Kind is scheduled, deadline,
+// completed (a past completion of a recurring task) or event (from a
+// calendar, with Event set instead of Task). An item of a range spanning
+// days is day SpanDay of SpanDays.
+type ExportAgendaItem struct {
+	Date        string       `json:"date"`
+	Time        string       `json:"time,omitempty"`
+	EndTime     string       `json:"end_time,omitempty"`
+	Kind        string       `json:"kind"`
+	Overdue     bool         `json:"overdue,omitempty"`
+	Warning     bool         `json:"warning,omitempty"`
+	ClockActive bool         `json:"clock_active,omitempty"`
+	TargetState string       `json:"target_state,omitempty"`
+	SpanDay     int          `json:"span_day,omitempty"`
+	SpanDays    int          `json:"span_days,omitempty"`
+	Task        *ExportTask  `json:"task,omitempty"`
+	Event       *ExportEvent `json:"event,omitempty"`
+}
+
+// ExportEvent is a calendar event shown in the agenda.
+type ExportEvent struct {
+	Calendar    string `json:"calendar"`
+	UID         string `json:"uid,omitempty"`
+	Summary     string `json:"summary"`
+	Location    string `json:"location,omitempty"`
+	Description string `json:"description,omitempty"`
+	AllDay      bool   `json:"all_day,omitempty"`
+}
+
+// ExportAgendaDay groups the items of one day.
+type ExportAgendaDay struct {
+	Date  string             `json:"date"`
+	Items []ExportAgendaItem `json:"items"`
+}
+
+// ExportAgenda converts the days of QueryAgenda for export.
+func ExportAgenda(c *config.Config, days []AgendaDay) []ExportAgendaDay {
+	exported := make([]ExportAgendaDay, 0, len(days))
+	for _, day := range days {
+		d := ExportAgendaDay{Date: day.Date.Format("2006-01-02"), Items: []ExportAgendaItem{}}
+		for _, item := range day.Items {
+			kind := "scheduled"
+			switch {
+			case item.Event != nil:
+				kind = "event"
+			case item.IsCompleted:
+				kind = "completed"
+			case item.IsDeadline:
+				kind = "deadline"
+			}
+			e := ExportAgendaItem{
+				Date:        item.Date.Format("2006-01-02"),
+				Kind:        kind,
+				Overdue:     item.IsOverdue,
+				Warning:     item.Warning,
+				ClockActive: item.ClockActive,
+				TargetState: item.TargetState,
+			}
+			if ev := item.Event; ev != nil {
+				e.Event = &ExportEvent{
+					Calendar: ev.Calendar, UID: ev.UID, Summary: ev.Summary,
+					Location: ev.Location, Description: ev.Description, AllDay: ev.AllDay,
+				}
+			} else {
+				e.Task = exportTask(c, item.Task)
+			}
+			if item.HasTime {
+				e.Time = item.Date.Format("15:04")
+			}
+			if item.HasEnd {
+				e.EndTime = item.EndTime.Format("15:04")
+			}
+			if item.Span != nil {
+				e.SpanDay, e.SpanDays = item.Span.Day, item.Span.Days
+			}
+			d.Items = append(d.Items, e)
+		}
+		exported = append(exported, d)
+	}
+	return exported
+}
+
+// agendaColumns are the columns of the tabular agenda exports.
+var agendaColumns = []string{"date", "time", "end_time", "kind", "overdue", "project", "keyword", "id", "title", "file", "line"}
+
+// WriteAgenda writes agenda days in the given format, one row per item in
+// the tabular formats. Event rows have the calendar as project and the
+// event's summary as title.
+func WriteAgenda(w io.Writer, c *config.Config, days []AgendaDay, format string) error {
+	exported := ExportAgenda(c, days)
+	if format == "json" {
+		return writeJSON(w, struct {
+			Version int               `json:"version"`
+			Days    []ExportAgendaDay `json:"days"`
+		}{ExportVersion, exported})
+	}
+	var rows [][]string
+	for _, d := range exported {
+		for _, item := range d.Items {
+			row := []string{item.Date, item.Time, item.EndTime, item.Kind, strconv.FormatBool(item.Overdue)}
+			if ev := item.Event; ev != nil {
+				row = append(row, ev.Calendar, "", "", ev.Summary, "", "")
+			} else {
+				t := item.Task
+				row = append(row, t.Project, t.Keyword, t.ID, t.Title, t.File, strconv.Itoa(t.Line))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return writeTable(w, format, agendaColumns, rows)
+}
+
+func nonNil(tasks []*ExportTask) []*ExportTask {
+	if tasks == nil {
+		return []*ExportTask{}
+	}
+	return tasks
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeTable writes a header and rows as CSV, TSV or a Markdown table.
+func writeTable(w io.Writer, format string, header []string, rows [][]string) error {
+	switch format {
+	case "csv", "tsv":
+		cw := csv.NewWriter(w)
+		if format == "tsv" {
+			cw.Comma = '\t'
+		}
+		cw.Write(header)
+		cw.WriteAll(rows)
+		return cw.Error()
+	case "markdown":
+		cell := strings.NewReplacer("|", `\|`, "\n", " ")
+		writeRow := func(cells []string) {
+			escaped := make([]string, len(cells))
+			for i, c := range cells {
+				escaped[i] = cell.Replace(c)
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+		}
+		writeRow(header)
+		sep := make([]string, len(header))
+		for i := range sep {
+			sep[i] = "---"
+		}
+		writeRow(sep)
+		for _, r := range rows {
+			writeRow(r)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(ExportFormats, ", "))
+}
@@ -0,0 +1,209 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportDate(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want *ExportDate
+	}{
+		{"", nil},
+		{"2025-03-15", &ExportDate{Raw: "2025-03-15", Date: "2025-03-15"}},
+		{"2025-06-17T09:00-10:30", &ExportDate{Raw: "2025-06-17T09:00-10:30", Date: "2025-06-17", Time: "09:00", EndTime: "10:30"}},
+		{"2025-03-15T14:00+1w!2d", &ExportDate{
+			Raw: "2025-03-15T14:00+1w!2d", Date: "2025-03-15", Time: "14:00",
+			Recurrence: &ExportRecurrence{Mode: "fixed", Interval: 1, Unit: "w"}, WarningDays: 2,
+		}},
+		{"2025-03-15.+3d", &ExportDate{Raw: "2025-03-15.+3d", Date: "2025-03-15", Recurrence: &ExportRecurrence{Mode: "from_done", Interval: 3, Unit: "d"}}},
+		{"2020-01-06++1m", &ExportDate{Raw: "2020-01-06++1m", Date: "2020-01-06", Recurrence: &ExportRecurrence{Mode: "next_future", Interval: 1, Unit: "m"}}},
+		{"2025-06-02+1w:mon,frix4", &ExportDate{Raw: "2025-06-02+1w:mon,frix4", Date: "2025-06-02", Recurrence: &ExportRecurrence{Mode: "fixed", Interval: 1, Unit: "w", Days: []string{"mon", "fri"}, Count: 4}}},
+		{"2025-01-31+1m:-1bd..2025-12-31", &ExportDate{Raw: "2025-01-31+1m:-1bd..2025-12-31", Date: "2025-01-31", Recurrence: &ExportRecurrence{Mode: "fixed", Interval: 1, Unit: "m", Days: []string{"-1bd"}, Until: "2025-12-31"}}},
+		{"2025-07-01T23:30-23:45[Asia/Tokyo]", &ExportDate{Raw: "2025-07-01T23:30-23:45[Asia/Tokyo]", Date: "2025-07-01", Time: "23:30", EndTime: "23:45", Zone: "Asia/Tokyo"}},
+		{"2025-07-01--2025-07-05", &ExportDate{Raw: "2025-07-01--2025-07-05", Date: "2025-07-01", EndDate: "2025-07-05"}},
+		{"2025-07-01T18:00--2025-07-03T09:00+1w", &ExportDate{Raw: "2025-07-01T18:00--2025-07-03T09:00+1w", Date: "2025-07-01", Time: "18:00", EndDate: "2025-07-03", EndTime: "09:00", Recurrence: &ExportRecurrence{Mode: "fixed", Interval: 1, Unit: "w"}}},
+		{"someday", &ExportDate{Raw: "someday"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := exportDate(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("exportDate(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTasks_JSON(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.WriteFile(filepath.Join(dir, "web", "tasks.md"), []byte(
+		"TODO: [#A] [w1] Login #auth @s:2025-07-01+1w ~2h\n"+
+			"  - DOING: [w2] Form ^w1\n"+
+			"  owner:: alice\n"+
+			"TODO: Logout\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+	tasks, err := ListTasks(cfg, "", false)
+	if err != nil {
+		t.Fatalf("ListTasks() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTasks(&buf, cfg, tasks, "json"); err != nil {
+		t.Fatalf("WriteTasks() error: %v", err)
+	}
+	var got struct {
+		Version int           `json:"version"`
+		Tasks   []*ExportTask `json:"tasks"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output isn't JSON: %v\n%s", err, buf.String())
+	}
+	if got.Version != ExportVersion || len(got.Tasks) != 2 {
+		t.Fatalf("version %d, %d top-level tasks, want %d and 2", got.Version, len(got.Tasks), ExportVersion)
+	}
+
+	login := got.Tasks[0]
+	if login.ID != "w1" || login.PriorityCookie != "A" || login.Category != "active" || login.EstimateMinutes != 120 {
+		t.Errorf("login = %+v", login)
+	}
+	if login.Scheduled == nil || login.Scheduled.Recurrence == nil || login.Scheduled.Recurrence.Unit != "w" {
+		t.Errorf("login scheduled = %+v, want a weekly recurrence", login.Scheduled)
+	}
+	if len(login.Children) != 1 {
+		t.Fatalf("login children = %d, want 1", len(login.Children))
+	}
+	form := login.Children[0]
+	if form.ID != "w2" || form.Category != "inprogress" || form.Parent != login.Location || form.Depth != 1 || !form.Blocked {
+		t.Errorf("form = %+v", form)
+	}
+	if !strings.HasSuffix(login.Location, "tasks.md:1") {
+		t.Errorf("login location = %q", login.Location)
+	}
+}
+
+func TestWriteTasks_Tables(t *testing.T) {
+	cfg := createTestConfig()
+	parent := &Task{Keyword: "TODO", Title: "Login | SSO", Project: "web", FilePath: "/p/web.md", LineNum: 1, Tags: []string{"a", "b"}}
+	child := &Task{Keyword: "DONE", Title: "Form", Project: "web", FilePath: "/p/web.md", LineNum: 2, Parent: parent,
+		Properties: map[string]string{"z": "1", "a": "2"}}
+	tasks := []*Task{parent, child}
+
+	tests := []struct {
+		format string
+		want   []string // lines
+	}{
+		{"csv", []string{
+			strings.Join(taskColumns, ","),
+			"web,TODO,active,,,Login | SSO,a b,,,,,0,0,,,false,/p/web.md,1,,0",
+			"web,DONE,completed,,,Form,,,,,,0,0,,a=2; z=1,false,/p/web.md,2,/p/web.md:1,1",
+		}},
+		{"tsv", []string{
+			strings.Join(taskColumns, "\t"),
+			"web\tTODO\tactive\t\t\tLogin | SSO\ta b\t\t\t\t\t0\t0\t\t\tfalse\t/p/web.md\t1\t\t0",
+			"web\tDONE\tcompleted\t\t\tForm\t\t\t\t\t\t0\t0\t\ta=2; z=1\tfalse\t/p/web.md\t2\t/p/web.md:1\t1",
+		}},
+		{"markdown", []string{
+			"| " + strings.Join(taskColumns, " | ") + " |",
+			"|" + strings.Repeat(" --- |", len(taskColumns)),
+			`| web | TODO | active |  |  | Login \| SSO | a b |  |  |  |  | 0 | 0 |  |  | false | /p/web.md | 1 |  | 0 |`,
+			"| web | DONE | completed |  |  | Form |  |  |  |  |  | 0 | 0 |  | a=2; z=1 | false | /p/web.md | 2 | /p/web.md:1 | 1 |",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteTasks(&buf, cfg, tasks, tt.format); err != nil {
+				t.Fatalf("WriteTasks() error: %v", err)
+			}
+			got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WriteTasks(%s) =\n%s\nwant\n%s", tt.format, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestWriteProjects(t *testing.T) {
+	summary := map[string]int{"web": 3, "api": 1}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"csv", "project,tasks\napi,1\nweb,3\n"},
+		{"json", "{\n  \"version\": 1,\n  \"projects\": [\n    {\n      \"project\": \"api\",\n      \"tasks\": 1\n    },\n    {\n      \"project\": \"web\",\n      \"tasks\": 3\n    }\n  ]\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteProjects(&buf, summary, tt.format); err != nil {
+				t.Fatalf("WriteProjects() error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("WriteProjects(%s) = %q, want %q", tt.format, buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteAgenda(t *testing.T) {
+	cfg := createTestConfig()
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local)
+	task := &Task{Keyword: "TODO", Title: "Standup", Project: "team", ID: "s1", FilePath: "/p/team.md", LineNum: 4}
+	days := []AgendaDay{
+		{Date: day, Items: []AgendaItem{
+			{Task: task, Date: day.Add(9 * time.Hour), HasTime: true, HasEnd: true, EndTime: day.Add(9*time.Hour + 15*time.Minute)},
+			{Task: task, Date: day, IsDeadline: true, IsOverdue: true},
+		}},
+		{Date: day.AddDate(0, 0, 1)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAgenda(&buf, cfg, days, "csv"); err != nil {
+		t.Fatalf("WriteAgenda() error: %v", err)
+	}
+	want := strings.Join(agendaColumns, ",") + "\n" +
+		"2025-07-01,09:00,09:15,scheduled,false,team,TODO,s1,Standup,/p/team.md,4\n" +
+		"2025-07-01,,,deadline,true,team,TODO,s1,Standup,/p/team.md,4\n"
+	if buf.String() != want {
+		t.Errorf("WriteAgenda(csv) = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteAgenda(&buf, cfg, days, "json"); err != nil {
+		t.Fatalf("WriteAgenda() error: %v", err)
+	}
+	var got struct {
+		Version int               `json:"version"`
+		Days    []ExportAgendaDay `json:"days"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output isn't JSON: %v", err)
+	}
+	if len(got.Days) != 2 || len(got.Days[0].Items) != 2 || got.Days[1].Items == nil {
+		t.Fatalf("days = %+v", got.Days)
+	}
+	if item := got.Days[0].Items[0]; item.Time != "09:00" || item.EndTime != "09:15" || item.Task.ID != "s1" {
+		t.Errorf("first item = %+v", item)
+	}
+}
+
+func TestWriteTasks_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteTasks(&buf, createTestConfig(), nil, "xml")
+	if err == nil || !strings.Contains(err.Error(), `unknown format "xml"`) {
+		t.Errorf("WriteTasks(xml) error = %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// Graph edge kinds. Dependency edges point from the referenced task to the
+// task that depends on it, so arrows follow the order work happens in; child
+// edges point from a parent to a task nested under it.
+const (
+	EdgeDependency = "dependency"
+	EdgeChild      = "child"
+)
+
+// GraphNode is a task in a dependency graph. Key identifies the node within
+// the graph: the task's ID, or a generated "_N" for tasks without one.
+// Missing nodes stand for ^references to IDs no listed task has.
+type GraphNode struct {
+	Key      string `json:"key"`
+	ID       string `json:"id,omitempty"`
+	Keyword  string `json:"keyword,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Project  string `json:"project,omitempty"`
+	Category string `json:"category"` // active, inprogress, completed, someday or missing
+	Estimate string `json:"estimate,omitempty"`
+	DueAt    string `json:"due_at,omitempty"`
+	InCycle  bool   `json:"in_cycle,omitempty"`
+	Blocked  bool   `json:"blocked,omitempty"`
+	Critical bool   `json:"critical,omitempty"`
+
+	task *Task
+}
+
+// GraphEdge connects two nodes by key.
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Kind     string `json:"kind"`
+	Critical bool   `json:"critical,omitempty"`
+}
+
+// Graph is the dependency and nesting graph of a set of tasks, as written by
+// "todo graph". CriticalPath lists the keys of the critical path's nodes in
+// the order they have to be done (see BuildGraph).
+type Graph struct {
+	Nodes            []*GraphNode `json:"nodes"`
+	Edges            []GraphEdge  `json:"edges"`
+	CriticalPath     []string     `json:"critical_path,omitempty"`
+	CriticalEstimate string       `json:"critical_estimate,omitempty"`
+}
+
+// BuildGraph returns the graph of the tasks' ^references and parent/child
+// nesting. Only tasks with at least one edge are nodes. With a project, only
+// edges touching one of its tasks are kept, so dependencies on other
+// projects still show; tasks should then be the whole workspace's. Cycle
+// participants are flagged as by DetectCycles.
+//
+// The critical path is the chain of open dependencies with the most
+// estimated work, ties going to the chain holding the earliest due date and
+// then to the longest one. Completed tasks and cycle participants are left
+// out, and a chain needs an estimate or a due date to count.
+func BuildGraph(c *config.Config, tasks []*Task, project string) *Graph {
+	DetectCycles(tasks)
+
+	g := &Graph{}
+	nodes := make(map[*Task]*GraphNode)
+	missing := make(map[string]*GraphNode)
+	unnamed := 0
+	node := func(t *Task) *GraphNode {
+		if n, ok := nodes[t]; ok {
+			return n
+		}
+		key := t.ID
+		if key == "" || t.DuplicateID {
+			unnamed++
+			key = fmt.Sprintf("_%d", unnamed)
+		}
+		n := &GraphNode{
+			Key: key, ID: t.ID, Keyword: t.Keyword, Title: t.Title, Project: t.Project,
+			Category: taskCategory(c, t), Estimate: t.Estimate, DueAt: t.DueAt,
+			InCycle: t.InCycle, Blocked: t.Blocked, task: t,
+		}
+		nodes[t] = n
+		g.Nodes = append(g.Nodes, n)
+		return n
+	}
+	inProject := func(t *Task) bool { return project == "" || t.Project == project }
+
+	for _, t := range tasks {
+		for _, ref := range t.References {
+			dep := GetTaskByID(tasks, ref)
+			if dep == nil {
+				if !inProject(t) {
+					continue
+				}
+				m, ok := missing[ref]
+				if !ok {
+					m = &GraphNode{Key: ref, ID: ref, Category: "missing"}
+					missing[ref] = m
+					g.Nodes = append(g.Nodes, m)
+				}
+				g.Edges = append(g.Edges, GraphEdge{From: m.Key, To: node(t).Key, Kind: EdgeDependency})
+				continue
+			}
+			if inProject(t) || inProject(dep) {
+				g.Edges = append(g.Edges, GraphEdge{From: node(dep).Key, To: node(t).Key, Kind: EdgeDependency})
+			}
+		}
+		if t.Parent != nil && (inProject(t) || inProject(t.Parent)) {
+			g.Edges = append(g.Edges, GraphEdge{From: node(t.Parent).Key, To: node(t).Key, Kind: EdgeChild})
+		}
+	}
+
+	g.markCriticalPath(c)
+	return g
+}
+
+// taskCategory returns the status category of the task's keyword.
+func taskCategory(c *config.Config, t *Task) string {
+	switch {
+	case t.IsInProgress(c):
+		return "inprogress"
+	case t.IsCompleted(c):
+		return "completed"
+	case t.IsSomeday(c):
+		return "someday"
+	}
+	return "active"
+}
+
+// pathScore ranks candidate critical paths.
+type pathScore struct {
+	work  time.Duration
+	due   string // earliest due day on the path, "" if none
+	steps int
+}
+
+func (a pathScore) better(b pathScore) bool {
+	if a.work != b.work {
+		return a.work > b.work
+	}
+	if a.due != b.due {
+		return b.due == "" || (a.due != "" && a.due < b.due)
+	}
+	return a.steps > b.steps
+}
+
+func (g *Graph) markCriticalPath(c *config.Config) {
+	byKey := make(map[string]*GraphNode)
+	for _, n := range g.Nodes {
+		byKey[n.Key] = n
+	}
+	open := func(n *GraphNode) bool {
+		return n.task != nil && !n.task.IsCompleted(c) && !n.InCycle
+	}
+	deps := make(map[string][]string)
+	for _, e := range g.Edges {
+		if e.Kind == EdgeDependency && open(byKey[e.From]) && open(byKey[e.To]) {
+			deps[e.To] = append(deps[e.To], e.From)
+		}
+	}
+
+	// Best chain ending at each node; the open subgraph has no cycles
+	best := make(map[string]pathScore)
+	prev := make(map[string]string)
+	var score func(key string) pathScore
+	score = func(key string) pathScore {
+		if s, ok := best[key]; ok {
+			return s
+		}
+		var s pathScore
+		for _, d := range deps[key] {
+			if ds := score(d); ds.better(s) {
+				s, prev[key] = ds, d
+			}
+		}
+		n := byKey[key]
+		s.work += n.task.EstimateDuration()
+		if day := dueDay(n.DueAt); day != "" && (s.due == "" || day < s.due) {
+			s.due = day
+		}
+		s.steps++
+		best[key] = s
+		return s
+	}
+
+	end, endScore := "", pathScore{}
+	for _, n := range g.Nodes {
+		if !open(n) {
+			continue
+		}
+		if s := score(n.Key); (s.work > 0 || s.due != "") && (end == "" || s.better(endScore)) {
+			end, endScore = n.Key, s
+		}
+	}
+	if end == "" {
+		return
+	}
+
+	for key := end; key != ""; key = prev[key] {
+		g.CriticalPath = append(g.CriticalPath, key)
+		byKey[key].Critical = true
+	}
+	slices.Reverse(g.CriticalPath)
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		if e.Kind == EdgeDependency && prev[e.To] == e.From && byKey[e.To].Critical {
+			e.Critical = true
+		}
+	}
+	if endScore.work > 0 {
+		g.CriticalEstimate = FormatDuration(endScore.work)
+	}
+}
+
+// dueDay returns the YYYY-MM-DD part of a due date.
+func dueDay(due string) string {
+	if len(due) < 10 {
+		return ""
+	}
+	return due[:10]
+}
+
+// Node fill colours by category, shared by the DOT and Mermaid output.
+var graphColors = map[string]string{
+	"active":     "#cfe2ff",
+	"inprogress": "#fff3cd",
+	"completed":  "#d1e7dd",
+	"someday":    "#e2e3e5",
+	"missing":    "#ffffff",
+}
+
+// nodeLabel is the text shown for a node: [id] title.
+func (n *GraphNode) nodeLabel() string {
+	if n.task == nil {
+		return "[" + n.ID + "] (missing)"
+	}
+	if n.ID != "" {
+		return fmt.Sprintf("[%s] %s", n.ID, n.Title)
+	}
+	return n.Title
+}
+
+// DOT renders the graph in Graphviz's dot language.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph tasks {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		attrs := []string{
+			fmt.Sprintf("label=%s", dotQuote(n.nodeLabel())),
+			fmt.Sprintf("fillcolor=%s", dotQuote(graphColors[n.Category])),
+		}
+		switch {
+		case n.InCycle:
+			attrs = append(attrs, `color="red"`, "penwidth=2")
+		case n.Critical:
+			attrs = append(attrs, `color="darkorange"`, "penwidth=2")
+		}
+		if n.Category == "missing" {
+			attrs = append(attrs, `style="rounded,dashed"`)
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.Key), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		var attrs []string
+		if e.Kind == EdgeChild {
+			attrs = append(attrs, "style=dashed", "arrowhead=none")
+		}
+		if e.Critical {
+			attrs = append(attrs, `color="darkorange"`, "penwidth=2")
+		}
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// Mermaid renders the graph as a Mermaid flowchart. Mermaid node IDs are
+// generated (n1, n2...) since task IDs may contain characters it rejects.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.Key] = fmt.Sprintf("n%d", i+1)
+		fmt.Fprintf(&b, "  %s[\"%s\"]:::%s\n", ids[n.Key], mermaidEscape(n.nodeLabel()), n.Category)
+	}
+	var critical []string
+	for i, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == EdgeChild {
+			arrow = "-.-"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+		if e.Critical {
+			critical = append(critical, fmt.Sprint(i))
+		}
+	}
+	for _, category := range []string{"active", "inprogress", "completed", "someday", "missing"} {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", category, graphColors[category])
+	}
+	for _, n := range g.Nodes {
+		switch {
+		case n.InCycle:
+			fmt.Fprintf(&b, "  style %s stroke:red,stroke-width:2px\n", ids[n.Key])
+		case n.Critical:
+			fmt.Fprintf(&b, "  style %s stroke:darkorange,stroke-width:2px\n", ids[n.Key])
+		}
+	}
+	if len(critical) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:darkorange,stroke-width:2px\n", strings.Join(critical, ","))
+	}
+	return b.String()
+}
+
+// mermaidEscape replaces the characters Mermaid can't take inside a quoted
+// label with their entity codes.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;").Replace(s)
+}
+
+// JSON renders the graph as indented JSON.
+func (g *Graph) JSON() (string, error) {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
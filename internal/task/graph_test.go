@@ -0,0 +1,138 @@
+package task
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// graphTestTasks lists a workspace whose web project depends on api.
+func graphTestTasks(t *testing.T) []*Task {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"web/tasks.md": "TODO: [ui] Build UI ~2d ^api ^design\n" +
+			"DONE: [design] Design ~1w\n" +
+			"TODO: [launch] Launch @d:2025-09-01 ^ui ^docs\n" +
+			"TODO: [docs] Write docs ~1h\n" +
+			"TODO: Plan release\n" +
+			"  - TODO: Book room\n" +
+			"TODO: [x] Loop ^y\nTODO: [y] Loop back ^x\n" +
+			"TODO: [orphan] Orphaned ^gone\n",
+		"api/tasks.md": "DOING: [api] Build API ~3d ^schema\nTODO: [schema] Schema ~4h\nTODO: [other] Unrelated ~1w\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+	tasks, err := ListTasks(cfg, "", true)
+	if err != nil {
+		t.Fatalf("ListTasks() error: %v", err)
+	}
+	return tasks
+}
+
+func TestBuildGraph(t *testing.T) {
+	cfg := createTestConfig()
+	tasks := graphTestTasks(t)
+
+	tests := []struct {
+		name      string
+		project   string
+		wantNodes []string
+		wantPath  []string
+	}{
+		{
+			name:      "workspace",
+			wantNodes: []string{"_1", "_2", "api", "design", "docs", "gone", "launch", "orphan", "schema", "ui", "x", "y"},
+			wantPath:  []string{"schema", "api", "ui", "launch"},
+		},
+		{
+			name:      "project keeps cross-project dependencies",
+			project:   "api",
+			wantNodes: []string{"api", "schema", "ui"},
+			wantPath:  []string{"schema", "api", "ui"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := BuildGraph(cfg, tasks, tt.project)
+			var keys []string
+			for _, n := range g.Nodes {
+				keys = append(keys, n.Key)
+			}
+			slices.Sort(keys)
+			if !slices.Equal(keys, tt.wantNodes) {
+				t.Errorf("nodes = %v, want %v", keys, tt.wantNodes)
+			}
+			if !slices.Equal(g.CriticalPath, tt.wantPath) {
+				t.Errorf("CriticalPath = %v, want %v", g.CriticalPath, tt.wantPath)
+			}
+		})
+	}
+
+	g := BuildGraph(cfg, tasks, "")
+	nodes := make(map[string]*GraphNode)
+	for _, n := range g.Nodes {
+		nodes[n.Key] = n
+	}
+	if !nodes["x"].InCycle || !nodes["y"].InCycle || nodes["ui"].InCycle {
+		t.Error("cycle participants not flagged")
+	}
+	if nodes["gone"].Category != "missing" || nodes["design"].Category != "completed" || nodes["api"].Category != "inprogress" {
+		t.Errorf("categories = %s/%s/%s", nodes["gone"].Category, nodes["design"].Category, nodes["api"].Category)
+	}
+	if g.CriticalEstimate != "44:00" {
+		t.Errorf("CriticalEstimate = %q, want 4h+3d+2d (8h days) = 44:00", g.CriticalEstimate)
+	}
+	if !slices.Contains(g.Edges, GraphEdge{From: "_1", To: "_2", Kind: EdgeChild}) {
+		t.Errorf("edges = %v, want the nesting edge", g.Edges)
+	}
+	if !slices.Contains(g.Edges, GraphEdge{From: "ui", To: "launch", Kind: EdgeDependency, Critical: true}) ||
+		!slices.Contains(g.Edges, GraphEdge{From: "docs", To: "launch", Kind: EdgeDependency}) {
+		t.Errorf("edges = %v, want ui -> launch on the critical path", g.Edges)
+	}
+}
+
+func TestGraphFormats(t *testing.T) {
+	g := BuildGraph(createTestConfig(), graphTestTasks(t), "")
+
+	dot := g.DOT()
+	for _, want := range []string{
+		"digraph tasks {",
+		`"ui" -> "launch" [color="darkorange", penwidth=2];`,
+		`"_1" -> "_2" [style=dashed, arrowhead=none];`,
+		`"x" [label="[x] Loop", fillcolor="#cfe2ff", color="red", penwidth=2];`,
+		`style="rounded,dashed"`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT() missing %q:\n%s", want, dot)
+		}
+	}
+
+	mermaid := g.Mermaid()
+	for _, want := range []string{"flowchart LR\n", `["[ui] Build UI"]:::active`, " -.- ", "classDef completed fill:#d1e7dd", "linkStyle "} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, mermaid)
+		}
+	}
+
+	out, err := g.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error: %v", err)
+	}
+	var decoded Graph
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("JSON() output doesn't parse: %v", err)
+	}
+	if len(decoded.Nodes) != len(g.Nodes) || !slices.Equal(decoded.CriticalPath, g.CriticalPath) {
+		t.Errorf("JSON() round trip = %+v", decoded)
+	}
+}
@@ -0,0 +1,314 @@
+package task
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// ICS date formats. Times are floating (no time zone), like the dates in
+// task files: calendar apps show them in their own local time. Times written
+// with a zone are exported in UTC.
+const (
+	icsDate     = "20060102"
+	icsDateTime = "20060102T150405"
+	icsStamp    = "20060102T150405Z"
+)
+
+// RRule returns the iCalendar RRULE value for a recurrence starting at
+// anchor, and false when the series karya computes (see ExpandOccurrences)
+// has no exact RRULE equivalent: .+ repeats (the next date depends on the
+// completion), business days, and month or year steps from a day that
+// doesn't exist in every month (karya caps the 31st to the month's last day
+// and Feb 29 to Mar 1, where RRULE skips those months). A repeat limited to
+// certain days maps to BYDAY, BYMONTHDAY or (for business days) BYSETPOS
+// when anchor is one of those days, since karya always counts the stored
+// date as the first occurrence. timed says whether UNTIL needs a time.
+func (r *RecurrenceSpec) RRule(anchor time.Time, timed bool) (string, bool) {
+	if r == nil || r.Mode == RecurrenceFromDone || r.Interval < 1 {
+		return "", false
+	}
+	if r.hasDays() && !r.matches(truncateToDay(anchor)) {
+		return "", false
+	}
+	var parts []string
+	switch r.Unit {
+	case 'd':
+		parts = append(parts, "FREQ=DAILY")
+	case 'w':
+		parts = append(parts, "FREQ=WEEKLY")
+		if len(r.Weekdays) > 0 {
+			days := make([]string, len(r.Weekdays))
+			for i, wd := range r.Weekdays {
+				days[i] = icsWeekday(wd)
+			}
+			parts = append(parts, "BYDAY="+strings.Join(days, ","))
+		}
+	case 'm':
+		if !r.hasDays() && anchor.Day() > 28 {
+			return "", false
+		}
+		parts = append(parts, "FREQ=MONTHLY")
+		if len(r.MonthDays) > 0 {
+			by, ok := monthDaysRule(r.MonthDays)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, by)
+		}
+	case 'y':
+		if anchor.Month() == time.February && anchor.Day() == 29 {
+			return "", false
+		}
+		parts = append(parts, "FREQ=YEARLY")
+	default:
+		return "", false
+	}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	switch {
+	case r.Count > 0:
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	case !r.Until.IsZero() && timed:
+		parts = append(parts, "UNTIL="+r.Until.Format(icsDate)+"T235959")
+	case !r.Until.IsZero():
+		parts = append(parts, "UNTIL="+r.Until.Format(icsDate))
+	}
+	return strings.Join(parts, ";"), true
+}
+
+// icsWeekday returns a weekday as RRULE writes it: MO, TU, ...
+func icsWeekday(wd time.Weekday) string {
+	return strings.ToUpper(wd.String()[:2])
+}
+
+// monthDaysRule returns the BY parts for a monthly repeat's days. RRULE
+// intersects BYDAY and BYMONTHDAY where karya takes the union, so only
+// lists of one kind map.
+func monthDaysRule(days []MonthDay) (string, bool) {
+	var items []string
+	for _, md := range days {
+		if md.Kind != days[0].Kind {
+			return "", false
+		}
+		switch md.Kind {
+		case MonthDayWeekday:
+			items = append(items, strconv.Itoa(md.N)+icsWeekday(md.Weekday))
+		default:
+			items = append(items, strconv.Itoa(md.N))
+		}
+	}
+	switch days[0].Kind {
+	case MonthDayWeekday:
+		return "BYDAY=" + strings.Join(items, ","), true
+	case MonthDayBusiness:
+		return "BYDAY=MO,TU,WE,TH,FR;BYSETPOS=" + strings.Join(items, ","), true
+	}
+	return "BYMONTHDAY=" + strings.Join(items, ","), true
+}
+
+// WriteICS writes the agenda as an iCalendar file. Scheduled items become
+// VEVENTs (all-day unless timed, with DTEND when they have an end time or
+// span days) and deadlines VTODOs with DUE, at the end of a range. A recurring task whose repeat maps to an RRULE
+// is written once, anchored at its stored date, so the calendar app expands
+// it; other recurring tasks, and zoned recurring times, are written once
+// per occurrence in days.
+// Completed history items and calendar events (which come from other
+// calendars already) are left out. stamp is the DTSTAMP of every component.
+//
+// UIDs are derived from the task ID, or from its file (relative to the
+// projects directory) and line when it has none or a duplicate, so
+// importing a newer export updates the entries instead of duplicating them.
+func WriteICS(w io.Writer, c *config.Config, days []AgendaDay, stamp time.Time) error {
+	iw := &icsWriter{w: w}
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:-//karya//agenda//EN")
+	iw.line("CALSCALE:GREGORIAN")
+	iw.line("X-WR-CALNAME:karya")
+
+	type entryKey struct {
+		task     *Task
+		deadline bool
+		start    time.Time // of a range occurrence; zero for a whole series
+	}
+	written := make(map[entryKey]bool)
+	for _, day := range days {
+		for _, item := range day.Items {
+			if item.IsCompleted || item.Event != nil || item.Schedule == nil {
+				continue
+			}
+			sched := item.Schedule
+			key := entryKey{task: item.Task, deadline: item.IsDeadline}
+			rrule, ok := sched.Recurrence.RRule(sched.Date, sched.HasTime)
+			if ok && sched.HasTime && sched.Zone != nil {
+				// Zoned times are written in UTC, where an RRULE would
+				// drift across the zone's DST changes and midnight
+				rrule, ok = "", false
+			}
+			start, uid := item.Date, icsUID(c, item.Task, item.IsDeadline)
+			if item.Span != nil {
+				// A range is written once, not on each day it covers
+				start, key.start = item.Span.Start, item.Span.Start
+				item.HasTime = sched.HasTime
+			}
+			switch {
+			case ok:
+				key.start = time.Time{}
+				start = sched.Date
+			case sched.Recurrence != nil && sched.Recurrence.Mode != RecurrenceFromDone:
+				uid = strings.Replace(uid, "@", "-"+start.Format(icsDate)+"@", 1)
+			}
+			if ok || item.Span != nil {
+				if written[key] {
+					continue
+				}
+				written[key] = true
+			}
+			iw.component(c, item, start, rrule, uid, stamp)
+		}
+	}
+
+	iw.line("END:VCALENDAR")
+	return iw.err
+}
+
+// icsUID returns the stable UID of a task's scheduled or deadline entry.
+func icsUID(c *config.Config, t *Task, deadline bool) string {
+	kind := "scheduled"
+	if deadline {
+		kind = "deadline"
+	}
+	if t.ID != "" && !t.DuplicateID {
+		return fmt.Sprintf("%s-%s@karya", t.ID, kind)
+	}
+	path := t.FilePath
+	if rel, err := filepath.Rel(c.Directories.Projects, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s:%d", filepath.ToSlash(path), t.LineNum)))
+	return fmt.Sprintf("%x-%s@karya", sum[:8], kind)
+}
+
+// icsWriter writes content lines, folded at 75 octets and ended with CRLF
+// as RFC 5545 requires, keeping the first error.
+type icsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (iw *icsWriter) line(s string) {
+	if iw.err != nil {
+		return
+	}
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	_, iw.err = io.WriteString(iw.w, b.String())
+}
+
+func (iw *icsWriter) component(c *config.Config, item AgendaItem, start time.Time, rrule, uid string, stamp time.Time) {
+	t := item.Task
+	name := "VEVENT"
+	if item.IsDeadline {
+		name = "VTODO"
+	}
+	iw.line("BEGIN:" + name)
+	iw.line("UID:" + uid)
+	iw.line("DTSTAMP:" + stamp.UTC().Format(icsStamp))
+	iw.line("SUMMARY:" + icsEscape(t.Title))
+
+	zoned := item.Schedule != nil && item.Schedule.Zone != nil
+	isRange := item.Schedule != nil && item.Schedule.IsRange()
+	date := func(prop string, d time.Time) {
+		switch {
+		case item.HasTime && zoned:
+			iw.line(prop + ":" + d.UTC().Format(icsStamp))
+		case item.HasTime:
+			iw.line(prop + ":" + d.Format(icsDateTime))
+		default:
+			iw.line(prop + ";VALUE=DATE:" + d.Format(icsDate))
+		}
+	}
+	if item.IsDeadline {
+		// A recurring VTODO needs a DTSTART to anchor its RRULE; a range
+		// is due at its end
+		due := start
+		if isRange {
+			due = item.Schedule.EndDateOn(start)
+		}
+		if rrule != "" || isRange {
+			date("DTSTART", start)
+		}
+		date("DUE", due)
+		status := "NEEDS-ACTION"
+		switch {
+		case t.IsCompleted(c):
+			status = "COMPLETED"
+		case t.IsInProgress(c):
+			status = "IN-PROCESS"
+		}
+		iw.line("STATUS:" + status)
+	} else {
+		date("DTSTART", start)
+		switch {
+		case isRange && item.HasTime:
+			date("DTEND", item.Schedule.EndDateOn(start))
+		case isRange:
+			iw.line("DTEND;VALUE=DATE:" + item.Schedule.EndDateOn(start).AddDate(0, 0, 1).Format(icsDate))
+		case item.HasEnd && zoned:
+			date("DTEND", item.Schedule.EndOn(start))
+		case item.HasEnd:
+			end := time.Date(start.Year(), start.Month(), start.Day(), item.EndTime.Hour(), item.EndTime.Minute(), 0, 0, start.Location())
+			date("DTEND", end)
+		case !item.HasTime:
+			iw.line("DTEND;VALUE=DATE:" + start.AddDate(0, 0, 1).Format(icsDate))
+		}
+	}
+	if rrule != "" {
+		iw.line("RRULE:" + rrule)
+	}
+
+	switch t.PriorityCookie {
+	case "A":
+		iw.line("PRIORITY:1")
+	case "B":
+		iw.line("PRIORITY:5")
+	case "C":
+		iw.line("PRIORITY:9")
+	}
+	if len(t.Tags) > 0 {
+		tags := make([]string, len(t.Tags))
+		for i, tag := range t.Tags {
+			tags[i] = icsEscape(tag)
+		}
+		iw.line("CATEGORIES:" + strings.Join(tags, ","))
+	}
+	desc := fmt.Sprintf("%s: %s\nProject: %s\n%s:%d", t.Keyword, t.Title, t.Project, t.FilePath, t.LineNum)
+	iw.line("DESCRIPTION:" + icsEscape(desc))
+	iw.line("END:" + name)
+}
+
+// icsEscape escapes a TEXT property value.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,292 @@
+package task
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceSpec_RRule(t *testing.T) {
+	tests := []struct {
+		token  string
+		want   string
+		wantOK bool
+	}{
+		{"2025-07-01", "", false},
+		{"2025-07-01+1d", "FREQ=DAILY", true},
+		{"2025-07-01+2w", "FREQ=WEEKLY;INTERVAL=2", true},
+		{"2025-07-01++1w", "FREQ=WEEKLY", true},
+		{"2025-07-28+1m", "FREQ=MONTHLY", true},
+		{"2025-07-31+1m", "", false},
+		{"2025-07-01+1y", "FREQ=YEARLY", true},
+		{"2024-02-29+1y", "", false},
+		{"2025-07-01.+1w", "", false},
+		{"2025-07-01+3b", "", false},
+		{"2025-06-02+1w:mon,wed,fri", "FREQ=WEEKLY;BYDAY=MO,WE,FR", true},
+		{"2025-06-03+1w:mon", "", false},
+		{"2025-01-14+1m:2tue", "FREQ=MONTHLY;BYDAY=2TU", true},
+		{"2025-01-31+1m:-1", "FREQ=MONTHLY;BYMONTHDAY=-1", true},
+		{"2025-05-30+1m:-1bd", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", true},
+		{"2025-01-15+3m:1,15x6", "FREQ=MONTHLY;BYMONTHDAY=1,15;INTERVAL=3;COUNT=6", true},
+		{"2025-01-15+1m:2wed,15", "", false},
+		{"2025-07-01+1d..2025-07-31", "FREQ=DAILY;UNTIL=20250731", true},
+		{"2025-07-01T09:00+1d..2025-07-31", "FREQ=DAILY;UNTIL=20250731T235959", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			s, err := ParseSchedule(tt.token)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error: %v", err)
+			}
+			got, ok := s.Recurrence.RRule(s.Date, s.HasTime)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RRule() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestRecurrenceSpec_RRuleRoundTrip checks that the calendar overlay
+// expands an exported RRULE to the dates karya itself computes.
+func TestRecurrenceSpec_RRuleRoundTrip(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(1, 0, 0)
+	for _, token := range []string{
+		"2025-05-30+1m:-1bd",
+		"2025-01-14+1m:2tue",
+		"2025-01-31+1m:-1",
+		"2025-06-02+1w:mon,wed,fri",
+		"2025-01-15+3m:1,15x6",
+	} {
+		t.Run(token, func(t *testing.T) {
+			s, err := ParseSchedule(token)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error: %v", err)
+			}
+			rule, ok := s.Recurrence.RRule(s.Date, s.HasTime)
+			if !ok {
+				t.Fatalf("RRule() has no equivalent")
+			}
+			want := s.ExpandOccurrences(from, to.AddDate(0, 0, -1))
+			got := expandRRule(s.Date, rule, from, to)
+			if !slices.EqualFunc(got, want, time.Time.Equal) {
+				t.Errorf("expandRRule(%s) = %v, want %v", rule, got, want)
+			}
+		})
+	}
+}
+
+func TestICSUID(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Directories.Projects = "/p"
+	tests := []struct {
+		name     string
+		task     *Task
+		deadline bool
+		want     string
+	}{
+		{"ID", &Task{ID: "w1", FilePath: "/p/web/tasks.md", LineNum: 3}, false, "w1-scheduled@karya"},
+		{"deadline", &Task{ID: "w1", FilePath: "/p/web/tasks.md", LineNum: 3}, true, "w1-deadline@karya"},
+		{"duplicate ID uses the location", &Task{ID: "x", DuplicateID: true, FilePath: "/p/web/tasks.md", LineNum: 3}, false, icsUID(cfg, &Task{FilePath: "/p/web/tasks.md", LineNum: 3}, false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := icsUID(cfg, tt.task, tt.deadline); got != tt.want {
+				t.Errorf("icsUID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The file/line UID doesn't depend on where the projects directory is
+	moved := createTestConfig()
+	moved.Directories.Projects = "/q"
+	if a, b := icsUID(cfg, &Task{FilePath: "/p/web/tasks.md", LineNum: 3}, false), icsUID(moved, &Task{FilePath: "/q/web/tasks.md", LineNum: 3}, false); a != b {
+		t.Errorf("UIDs %q and %q differ for the same project file", a, b)
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	cfg := createTestConfig()
+	item := func(task *Task, token string, deadline bool, occ time.Time) AgendaItem {
+		s, err := ParseSchedule(token)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error: %v", token, err)
+		}
+		if occ.IsZero() {
+			occ = s.Date
+		}
+		return AgendaItem{Task: task, Date: occ, HasTime: s.HasTime, HasEnd: s.HasEnd, EndTime: s.EndTime, IsDeadline: deadline, Schedule: s}
+	}
+	day := func(d int) time.Time { return time.Date(2025, 7, d, 0, 0, 0, 0, time.Local) }
+
+	standup := &Task{ID: "s1", Keyword: "TODO", Title: "Standup", Tags: []string{"team"}, Project: "web", FilePath: "/p/web.md", LineNum: 1}
+	report := &Task{ID: "r1", Keyword: "DOING", Title: "Report, draft", PriorityCookie: "A", Project: "web", FilePath: "/p/web.md", LineNum: 2}
+	review := &Task{ID: "v1", Keyword: "TODO", Title: "Review", Project: "web", FilePath: "/p/web.md", LineNum: 3}
+	days := []AgendaDay{
+		{Date: day(1), Items: []AgendaItem{
+			item(standup, "2025-07-01T09:00-09:15+1w", false, time.Time{}),
+			item(review, "2025-07-01+2b", false, time.Time{}),
+			{Task: standup, Date: day(1), IsCompleted: true, Schedule: &Schedule{}},
+		}},
+		{Date: day(3), Items: []AgendaItem{
+			item(review, "2025-07-01+2b", false, day(3)),
+			item(report, "2025-07-03", true, time.Time{}),
+		}},
+		{Date: day(8), Items: []AgendaItem{
+			item(standup, "2025-07-01T09:00-09:15+1w", false, day(8).Add(9*time.Hour)),
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, cfg, days, time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteICS() error: %v", err)
+	}
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//karya//agenda//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:karya",
+		"BEGIN:VEVENT",
+		"UID:s1-scheduled@karya",
+		"DTSTAMP:20250630T120000Z",
+		"SUMMARY:Standup",
+		"DTSTART:20250701T090000",
+		"DTEND:20250701T091500",
+		"RRULE:FREQ=WEEKLY",
+		"CATEGORIES:team",
+		`DESCRIPTION:TODO: Standup\nProject: web\n/p/web.md:1`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:v1-scheduled-20250701@karya",
+		"DTSTAMP:20250630T120000Z",
+		"SUMMARY:Review",
+		"DTSTART;VALUE=DATE:20250701",
+		"DTEND;VALUE=DATE:20250702",
+		`DESCRIPTION:TODO: Review\nProject: web\n/p/web.md:3`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:v1-scheduled-20250703@karya",
+		"DTSTAMP:20250630T120000Z",
+		"SUMMARY:Review",
+		"DTSTART;VALUE=DATE:20250703",
+		"DTEND;VALUE=DATE:20250704",
+		`DESCRIPTION:TODO: Review\nProject: web\n/p/web.md:3`,
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:r1-deadline@karya",
+		"DTSTAMP:20250630T120000Z",
+		`SUMMARY:Report\, draft`,
+		"DUE;VALUE=DATE:20250703",
+		"STATUS:IN-PROCESS",
+		"PRIORITY:1",
+		`DESCRIPTION:DOING: Report\, draft\nProject: web\n/p/web.md:2`,
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n") + "\r\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteICS() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteICS_Zone(t *testing.T) {
+	withZones(t, "Europe/Berlin", "")
+	s, err := ParseSchedule("2025-03-03T09:00-10:00[America/New_York]+1w")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error: %v", err)
+	}
+	call := &Task{ID: "c1", Keyword: "TODO", Title: "Call", FilePath: "/p/web.md", LineNum: 1}
+	var days []AgendaDay
+	for _, occ := range s.ExpandOccurrences(time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local), time.Date(2025, 3, 11, 0, 0, 0, 0, time.Local)) {
+		days = append(days, AgendaDay{Date: truncateToDay(occ), Items: []AgendaItem{
+			{Task: call, Date: occ, HasTime: true, HasEnd: true, EndTime: s.EndOn(occ), Schedule: s},
+		}})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, createTestConfig(), days, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteICS() error: %v", err)
+	}
+	got := buf.String()
+	// One event per occurrence in UTC, following New York's DST change
+	for _, line := range []string{
+		"UID:c1-scheduled-20250303@karya",
+		"DTSTART:20250303T140000Z",
+		"DTEND:20250303T150000Z",
+		"UID:c1-scheduled-20250310@karya",
+		"DTSTART:20250310T130000Z",
+		"DTEND:20250310T140000Z",
+	} {
+		if !strings.Contains(got, line+"\r\n") {
+			t.Errorf("WriteICS() missing %q in\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "RRULE") {
+		t.Errorf("WriteICS() wrote an RRULE for a zoned time:\n%s", got)
+	}
+}
+
+func TestWriteICS_Range(t *testing.T) {
+	c := createTestConfig()
+	offsite := &Task{ID: "o1", Keyword: "TODO", Title: "Offsite", FilePath: "/p/web.md", LineNum: 1}
+	oncall := &Task{ID: "c1", Keyword: "TODO", Title: "On call", FilePath: "/p/web.md", LineNum: 2}
+	dayMap := make(map[time.Time][]AgendaItem)
+	start, end := time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local), time.Date(2025, 7, 7, 0, 0, 0, 0, time.Local)
+	addAgendaEntries(c, offsite, "2025-06-30--2025-07-03", false, start, end, start, false, dayMap)
+	addAgendaEntries(c, oncall, "2025-07-04T18:00--2025-07-07T09:00", false, start, end, start, false, dayMap)
+	var days []AgendaDay
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, AgendaDay{Date: d, Items: dayMap[d]})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, c, days, time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteICS() error: %v", err)
+	}
+	got := buf.String()
+	// Each range is one event, whichever of its days the export starts on
+	if n := strings.Count(got, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("WriteICS() wrote %d events, want 2:\n%s", n, got)
+	}
+	for _, line := range []string{
+		"DTSTART;VALUE=DATE:20250630",
+		"DTEND;VALUE=DATE:20250704",
+		"DTSTART:20250704T180000",
+		"DTEND:20250707T090000",
+	} {
+		if !strings.Contains(got, line+"\r\n") {
+			t.Errorf("WriteICS() missing %q in\n%s", line, got)
+		}
+	}
+}
+
+func TestICSWriter_Fold(t *testing.T) {
+	var buf bytes.Buffer
+	iw := &icsWriter{w: &buf}
+	iw.line("SUMMARY:" + strings.Repeat("é", 100))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	if len(lines) < 3 {
+		t.Fatalf("line folded into %d lines, want at least 3", len(lines))
+	}
+	var unfolded strings.Builder
+	for i, l := range lines {
+		if len(l) > 75 {
+			t.Errorf("line %d is %d octets, want at most 75", i, len(l))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(l, " ") {
+				t.Errorf("continuation line %d = %q, want a leading space", i, l)
+			}
+			l = l[1:]
+		}
+		unfolded.WriteString(l)
+	}
+	if unfolded.String() != "SUMMARY:"+strings.Repeat("é", 100) {
+		t.Errorf("unfolded = %q", unfolded.String())
+	}
+}
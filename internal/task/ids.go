@@ -0,0 +1,152 @@
+package task
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// Generated IDs are short enough to type after "^": five characters from an
+// alphabet without look-alikes (0/o, 1/l/i) or upper case.
+const (
+	idAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+	idLength   = 5
+)
+
+// newTaskID returns a random ID that is not in used, and adds it to used.
+func newTaskID(used map[string]bool) string {
+	for {
+		var b strings.Builder
+		for range idLength {
+			b.WriteByte(idAlphabet[rand.IntN(len(idAlphabet))])
+		}
+		if id := b.String(); !used[id] {
+			used[id] = true
+			return id
+		}
+	}
+}
+
+// workspaceIDs returns the IDs of every task in the workspace, completed and
+// archived ones included, so that a new ID never collides with a task that
+// could still be referenced.
+func workspaceIDs(c *config.Config) (map[string]bool, error) {
+	cc := *c
+	cc.Todo.IncludeArchive = true
+	tasks, err := ListTasks(&cc, "", true)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	for _, t := range tasks {
+		if t.ID != "" {
+			used[t.ID] = true
+		}
+	}
+	return used, nil
+}
+
+// DuplicateIDs returns the tasks sharing an ID with another task, keyed by
+// ID, and sets their DuplicateID field. Such tasks can't be told apart by
+// ^references or GetTaskByID, which picks the first.
+func DuplicateIDs(tasks []*Task) map[string][]*Task {
+	byID := make(map[string][]*Task)
+	for _, t := range tasks {
+		if t.ID != "" {
+			byID[t.ID] = append(byID[t.ID], t)
+		}
+	}
+	dups := make(map[string][]*Task)
+	for id, matches := range byID {
+		if len(matches) < 2 {
+			continue
+		}
+		for _, t := range matches {
+			t.DuplicateID = true
+		}
+		dups[id] = matches
+	}
+	return dups
+}
+
+// WorkspaceDuplicateIDs returns the IDs shared by more than one task of the
+// whole workspace, completed and archived tasks included, with every task
+// sharing each. With a project, only IDs used by one of its tasks are
+// returned, along with the other projects' tasks they clash with.
+func WorkspaceDuplicateIDs(c *config.Config, project string) (map[string][]*Task, error) {
+	cc := *c
+	cc.Todo.IncludeArchive = true
+	tasks, err := ListTasks(&cc, "", true)
+	if err != nil {
+		return nil, err
+	}
+	dups := DuplicateIDs(tasks)
+	if project != "" && project != "*" {
+		for id, matches := range dups {
+			if !slices.ContainsFunc(matches, func(t *Task) bool { return t.Project == project }) {
+				delete(dups, id)
+			}
+		}
+	}
+	return dups, nil
+}
+
+// FindTasksByID returns every task with the given ID. More than one match
+// means the ID is duplicated (see DuplicateIDs).
+func FindTasksByID(tasks []*Task, id string) []*Task {
+	if id == "" {
+		return nil
+	}
+	var matches []*Task
+	for _, t := range tasks {
+		if t.ID == id {
+			matches = append(matches, t)
+		}
+	}
+	return matches
+}
+
+// AssignIDs gives every task of the project ("" for all) that has no ID a
+// new one, unique across the workspace, and returns the tasks it changed
+// along with the files written (for committing). Completed tasks get IDs
+// too, as other tasks may come to reference them. All changes are one
+// journal entry, so a single undo removes them.
+func AssignIDs(c *config.Config, project string) ([]*Task, []string, error) {
+	used, err := workspaceIDs(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks, err := ListTasks(c, project, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var assigned []*Task
+	var files []string
+	journal := &JournalEntry{Kind: JournalEdit}
+	defer func() {
+		journal.Summary = fmt.Sprintf("Assign IDs to %d task(s)", len(assigned))
+		appendJournal(journal)
+	}()
+	for _, t := range tasks {
+		if t.ID != "" {
+			continue
+		}
+		id := newTaskID(used)
+		t.journal = journal
+		_, err := editLine(t, "", func(m *LineModel) { m.SetID(id) })
+		t.journal = nil
+		if err != nil {
+			return assigned, files, fmt.Errorf("%s: %w", t.Title, err)
+		}
+		t.ID = id
+		assigned = append(assigned, t)
+		if !slices.Contains(files, t.FilePath) {
+			files = append(files, t.FilePath)
+		}
+	}
+	return assigned, files, nil
+}
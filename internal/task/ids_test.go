@@ -0,0 +1,226 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNewTaskID(t *testing.T) {
+	idRe := regexp.MustCompile(`^[2-9a-hjkmnp-z]{5}$`)
+	used := map[string]bool{}
+	for range 1000 {
+		id := newTaskID(used)
+		if !idRe.MatchString(id) {
+			t.Fatalf("newTaskID() = %q, want five characters from the ID alphabet", id)
+		}
+	}
+	if len(used) != 1000 {
+		t.Errorf("newTaskID() returned %d unique IDs out of 1000", len(used))
+	}
+}
+
+func TestDuplicateIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want map[string]int // ID -> number of tasks sharing it
+	}{
+		{"unique", []string{"a", "b", ""}, map[string]int{}},
+		{"missing IDs aren't duplicates", []string{"", "", "a"}, map[string]int{}},
+		{"pair", []string{"a", "b", "a"}, map[string]int{"a": 2}},
+		{"several", []string{"a", "b", "a", "b", "b"}, map[string]int{"a": 2, "b": 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tasks []*Task
+			for _, id := range tt.ids {
+				tasks = append(tasks, &Task{ID: id})
+			}
+			got := DuplicateIDs(tasks)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DuplicateIDs() = %d IDs, want %d", len(got), len(tt.want))
+			}
+			for id, n := range tt.want {
+				if len(got[id]) != n {
+					t.Errorf("DuplicateIDs()[%q] = %d tasks, want %d", id, len(got[id]), n)
+				}
+			}
+			for _, task := range tasks {
+				if want := tt.want[task.ID] > 0; task.DuplicateID != want {
+					t.Errorf("task %q DuplicateID = %v, want %v", task.ID, task.DuplicateID, want)
+				}
+			}
+			if n := len(FindTasksByID(tasks, "a")); n != strings.Count(strings.Join(tt.ids, ","), "a") {
+				t.Errorf("FindTasksByID(a) = %d tasks", n)
+			}
+		})
+	}
+}
+
+func TestListTasks_DuplicateIDs(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.WriteFile(filepath.Join(dir, "web", "tasks.md"), []byte("TODO: [x1] Login\nDONE: [x2] Logout\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "api", "tasks.md"), []byte("TODO: [x2] Auth\nTODO: [x3] Rate limit\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+
+	// The completed task still makes its ID ambiguous
+	tasks, err := ListTasks(cfg, "", false)
+	if err != nil {
+		t.Fatalf("ListTasks() error: %v", err)
+	}
+	for _, task := range tasks {
+		if want := task.ID == "x2"; task.DuplicateID != want {
+			t.Errorf("%s DuplicateID = %v, want %v", task.Title, task.DuplicateID, want)
+		}
+	}
+
+	// A project's listing still sees IDs taken in other projects
+	tasks, err = ListTasks(cfg, "api", false)
+	if err != nil {
+		t.Fatalf("ListTasks(api) error: %v", err)
+	}
+	for _, task := range tasks {
+		if want := task.ID == "x2"; task.DuplicateID != want {
+			t.Errorf("api: %s DuplicateID = %v, want %v", task.Title, task.DuplicateID, want)
+		}
+	}
+}
+
+func TestWorkspaceDuplicateIDs(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"web", "api", "ops"} {
+		os.MkdirAll(filepath.Join(dir, p), 0755)
+	}
+	os.WriteFile(filepath.Join(dir, "web", "tasks.md"), []byte("TODO: [x1] Login\nDONE: [x2] Logout\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "api", "tasks.md"), []byte("TODO: [x2] Auth\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "ops", "tasks.md"), []byte("TODO: [x3] Deploy\nTODO: [x3] Monitor\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+	cfg.Directories.Karya = t.TempDir()
+
+	tests := []struct {
+		project string
+		want    []string // ID: number of tasks sharing it
+	}{
+		{"", []string{"x2: 2", "x3: 2"}},
+		{"api", []string{"x2: 2"}},
+		{"ops", []string{"x3: 2"}},
+		{"web", []string{"x2: 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.project, func(t *testing.T) {
+			dups, err := WorkspaceDuplicateIDs(cfg, tt.project)
+			if err != nil {
+				t.Fatalf("WorkspaceDuplicateIDs() error: %v", err)
+			}
+			var got []string
+			for id, matches := range dups {
+				got = append(got, fmt.Sprintf("%s: %d", id, len(matches)))
+			}
+			sort.Strings(got)
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("WorkspaceDuplicateIDs(%q) = %v, want %v", tt.project, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignIDs(t *testing.T) {
+	dir := t.TempDir()
+	web := filepath.Join(dir, "web", "tasks.md")
+	api := filepath.Join(dir, "api", "tasks.md")
+	os.MkdirAll(filepath.Dir(web), 0755)
+	os.MkdirAll(filepath.Dir(api), 0755)
+	os.WriteFile(web, []byte("TODO: [#A] Login #auth\n  - DONE: Form\nTODO: [w1] Logout\n"), 0644)
+	os.WriteFile(api, []byte("TODO: Auth\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+	cfg.Directories.Karya = t.TempDir()
+	EnableJournal(cfg)
+	t.Cleanup(func() { journalPath = "" })
+
+	assigned, files, err := AssignIDs(cfg, "web")
+	if err != nil {
+		t.Fatalf("AssignIDs() error: %v", err)
+	}
+	if len(assigned) != 2 || len(files) != 1 || files[0] != web {
+		t.Fatalf("AssignIDs() = %d tasks in %v, want 2 in web", len(assigned), files)
+	}
+	lineRe := regexp.MustCompile(`^TODO: \[#A\] \[(\w{5})\] Login #auth\n  - DONE: \[(\w{5})\] Form\nTODO: \[w1\] Logout\n$`)
+	content, _ := os.ReadFile(web)
+	m := lineRe.FindStringSubmatch(string(content))
+	if m == nil || m[1] == m[2] || assigned[0].ID != m[1] || assigned[1].ID != m[2] {
+		t.Errorf("web tasks = %q, assigned %q and %q", content, assigned[0].ID, assigned[1].ID)
+	}
+	if got, _ := os.ReadFile(api); string(got) != "TODO: Auth\n" {
+		t.Errorf("api tasks = %q, want other projects untouched", got)
+	}
+
+	// One undo removes every assigned ID
+	if _, err := Undo(cfg, 1); err != nil {
+		t.Fatalf("Undo() error: %v", err)
+	}
+	if got, _ := os.ReadFile(web); string(got) != "TODO: [#A] Login #auth\n  - DONE: Form\nTODO: [w1] Logout\n" {
+		t.Errorf("web tasks after undo = %q", got)
+	}
+}
+
+func TestAddTask_IDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		autoIDs bool
+		line    string
+		want    string // regexp for the added line
+		wantErr string
+	}{
+		{name: "no ID by default", line: "TODO: Plan", want: `^TODO: Plan$`},
+		{name: "auto ID", autoIDs: true, line: "TODO: [#B] Plan #x", want: `^TODO: \[#B\] \[\w{5}\] Plan #x$`},
+		{name: "explicit ID kept", autoIDs: true, line: "TODO: [p1] Plan", want: `^TODO: \[p1\] Plan$`},
+		{name: "ID used elsewhere", line: "TODO: [w1] Plan", wantErr: "already in use"},
+		{name: "ID used by a completed task", line: "TODO: [w2] Plan", wantErr: "already in use"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.MkdirAll(filepath.Join(dir, "web"), 0755)
+			os.MkdirAll(filepath.Join(dir, "api"), 0755)
+			os.WriteFile(filepath.Join(dir, "web", "README.md"), []byte("TODO: [w1] Login\nDONE: [w2] Logout\n"), 0644)
+
+			cfg := createTestConfig()
+			cfg.Directories.Projects = dir
+			cfg.Todo.AutoIDs = tt.autoIDs
+
+			got, _, err := AddTask(cfg, "api", "", tt.line)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AddTask() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddTask() error: %v", err)
+			}
+			content, _ := os.ReadFile(got.FilePath)
+			line := strings.Split(string(content), "\n")[got.LineNum-1]
+			if !regexp.MustCompile(tt.want).MatchString(line) {
+				t.Errorf("added line = %q, want %s", line, tt.want)
+			}
+			if got.ID != "" && !strings.Contains(line, "["+got.ID+"]") {
+				t.Errorf("task ID = %q, line %q", got.ID, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// indexVersion is bumped whenever the on-disk index layout or the parsing
+// rules change, so stale caches are discarded instead of misread.
+const indexVersion = 6
+
+// fileStamp identifies the state of a source file. A file whose modification
+// time and size both match its stamp is assumed unchanged.
+type fileStamp struct {
+	ModTime int64 `json:"mtime"`
+	Size    int64 `json:"size"`
+}
+
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+}
+
+// indexedTask is the cached form of a Task. Parent is the position of the
+// parent task within the same file's task list, or -1 for root tasks.
+type indexedTask struct {
+	Keyword     string            `json:"keyword"`
+	ID          string            `json:"id,omitempty"`
+	Cookie      string            `json:"priority_cookie,omitempty"`
+	Checkbox    string            `json:"checkbox,omitempty"`
+	Title       string            `json:"title"`
+	Tags        []string          `json:"tags,omitempty"`
+	References  []string          `json:"references,omitempty"`
+	ScheduledAt string            `json:"scheduled_at,omitempty"`
+	DueAt       string            `json:"due_at,omitempty"`
+	Estimate    string            `json:"estimate,omitempty"`
+	Assignee    string            `json:"assignee,omitempty"`
+	Project     string            `json:"project"`
+	Zettel      string            `json:"zettel"`
+	Properties  map[string]string `json:"properties,omitempty"`
+	IndentLevel int               `json:"indent"`
+	LineNum     int               `json:"line"`
+	Parent      int               `json:"parent"`
+	SubLines    []string          `json:"sub_lines,omitempty"`
+}
+
+// indexedFile holds the parsed tasks of one source file.
+type indexedFile struct {
+	Stamp fileStamp     `json:"stamp"`
+	Tasks []indexedTask `json:"tasks"`
+}
+
+// indexSnapshot is the on-disk layout of the task index.
+type indexSnapshot struct {
+	Version     int                     `json:"version"`
+	Fingerprint string                  `json:"fingerprint"`
+	Files       map[string]*indexedFile `json:"files"`
+}
+
+// taskIndex caches parsed tasks per file so that ListTasks only re-parses
+// files whose modification time or size changed since the last scan. It is
+// persisted under $KARYA/.cache and shared by every karya command that lists
+// tasks (todo, agenda, the MCP server).
+type taskIndex struct {
+	mu          sync.Mutex
+	path        string
+	fingerprint string
+	files       map[string]*indexedFile
+	dirty       bool
+}
+
+var (
+	indexesMu sync.Mutex
+	indexes   = make(map[string]*taskIndex)
+)
+
+// IndexPath returns the location of the on-disk task index, or "" when no
+// karya directory is configured.
+func IndexPath(c *config.Config) string {
+	if c.Directories.Karya == "" {
+		return ""
+	}
+	return filepath.Join(c.Directories.Karya, ".cache", "tasks.json")
+}
+
+// indexFingerprint summarizes the configuration that affects parsing. A cache
+// built under a different fingerprint is discarded.
+func indexFingerprint(c *config.Config) string {
+	h := sha256.New()
+	active, completed := c.CheckboxKeywords()
+	fmt.Fprintf(h, "%d|%t|%s|%s|%v|%v|%v|%v|%t|%s|%s", indexVersion, c.Todo.Structured,
+		c.Directories.Projects, c.GetInboxFilePath(),
+		c.Todo.Active, c.Todo.InProgress, c.Todo.Completed, c.Todo.Someday,
+		c.Todo.Checkboxes, active, completed)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// openIndex returns the process-wide index for the given configuration,
+// loading it from disk on first use. Returns nil when caching is unavailable.
+func openIndex(c *config.Config) *taskIndex {
+	path := IndexPath(c)
+	if path == "" {
+		return nil
+	}
+	fp := indexFingerprint(c)
+
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+
+	ix, ok := indexes[path]
+	if !ok {
+		ix = &taskIndex{path: path, fingerprint: fp, files: make(map[string]*indexedFile)}
+		ix.readFromDisk()
+		indexes[path] = ix
+	}
+
+	ix.mu.Lock()
+	if ix.fingerprint != fp {
+		ix.fingerprint = fp
+		ix.files = make(map[string]*indexedFile)
+		ix.dirty = true
+	}
+	ix.mu.Unlock()
+	return ix
+}
+
+// readFromDisk populates the index from its snapshot file. A missing,
+// unreadable or outdated snapshot leaves the index empty.
+func (ix *taskIndex) readFromDisk() {
+	data, err := os.ReadFile(ix.path)
+	if err != nil {
+		return
+	}
+	var snap indexSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return
+	}
+	if snap.Version != indexVersion || snap.Fingerprint != ix.fingerprint || snap.Files == nil {
+		return
+	}
+	ix.files = snap.Files
+}
+
+// load returns the tasks of filePath, reusing the cached entry when the file
+// is unchanged and calling parse otherwise.
+func (ix *taskIndex) load(filePath string, parse func() ([]*Task, error)) ([]*Task, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		ix.forget(filePath)
+		return nil, err
+	}
+	stamp := stampOf(info)
+
+	ix.mu.Lock()
+	entry, ok := ix.files[filePath]
+	ix.mu.Unlock()
+	if ok && entry.Stamp == stamp {
+		return entry.materialize(filePath), nil
+	}
+
+	tasks, err := parse()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return tasks, nil
+	}
+	entry = newIndexedFile(stamp, tasks, strings.Split(string(data), "\n"))
+
+	ix.mu.Lock()
+	ix.files[filePath] = entry
+	ix.dirty = true
+	ix.mu.Unlock()
+	return tasks, nil
+}
+
+// forget drops a file from the index.
+func (ix *taskIndex) forget(filePath string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if _, ok := ix.files[filePath]; ok {
+		delete(ix.files, filePath)
+		ix.dirty = true
+	}
+}
+
+// prune drops entries under root that are not in keep, so deleted files
+// don't linger in the cache. Paths listed in except are never pruned.
+func (ix *taskIndex) prune(root string, keep []string, except ...string) {
+	keepSet := make(map[string]bool, len(keep)+len(except))
+	for _, p := range keep {
+		keepSet[p] = true
+	}
+	for _, p := range except {
+		keepSet[p] = true
+	}
+	prefix := filepath.Clean(root) + string(filepath.Separator)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for p := range ix.files {
+		if strings.HasPrefix(p, prefix) && !keepSet[p] {
+			delete(ix.files, p)
+			ix.dirty = true
+		}
+	}
+}
+
+// save writes the index to disk if it changed. The snapshot is written to a
+// temporary file and renamed into place so concurrent readers never see a
+// partial file.
+func (ix *taskIndex) save() error {
+	ix.mu.Lock()
+	if !ix.dirty {
+		ix.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(indexSnapshot{
+		Version:     indexVersion,
+		Fingerprint: ix.fingerprint,
+		Files:       ix.files,
+	})
+	ix.dirty = false
+	ix.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := ix.writeSnapshot(data); err != nil {
+		// Retry on the next listing
+		ix.mu.Lock()
+		ix.dirty = true
+		ix.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (ix *taskIndex) writeSnapshot(data []byte) error {
+	dir := filepath.Dir(ix.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tasks-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), ix.path)
+}
+
+// newIndexedFile builds the cache entry for freshly parsed tasks. fileLines
+// is the file content used to capture each task's CLOCK/LOG/COMPLETED
+// sub-lines; the same sub-lines are attached to the tasks themselves.
+func newIndexedFile(stamp fileStamp, tasks []*Task, fileLines []string) *indexedFile {
+	pos := make(map[*Task]int, len(tasks))
+	for i, t := range tasks {
+		pos[t] = i
+	}
+
+	entry := &indexedFile{Stamp: stamp, Tasks: make([]indexedTask, len(tasks))}
+	for i, t := range tasks {
+		parent := -1
+		if t.Parent != nil {
+			if p, ok := pos[t.Parent]; ok {
+				parent = p
+			}
+		}
+		subLines := extractSubLines(blockLines(fileLines, t.LineNum, t.IndentLevel))
+		t.subLines = subLines
+		t.subStamp = stamp
+		t.hasSubLines = true
+
+		entry.Tasks[i] = indexedTask{
+			Keyword:     t.Keyword,
+			ID:          t.ID,
+			Cookie:      t.PriorityCookie,
+			Checkbox:    t.Checkbox,
+			Title:       t.Title,
+			Tags:        t.Tags,
+			References:  t.References,
+			ScheduledAt: t.ScheduledAt,
+			DueAt:       t.DueAt,
+			Estimate:    t.Estimate,
+			Assignee:    t.Assignee,
+			Project:     t.Project,
+			Zettel:      t.Zettel,
+			Properties:  t.Properties,
+			IndentLevel: t.IndentLevel,
+			LineNum:     t.LineNum,
+			Parent:      parent,
+			SubLines:    subLines,
+		}
+	}
+	return entry
+}
+
+// materialize rebuilds fresh Task values, including the parent/child links,
+// from a cache entry. Callers are free to modify the returned tasks.
+func (e *indexedFile) materialize(filePath string) []*Task {
+	tasks := make([]*Task, len(e.Tasks))
+	for i, it := range e.Tasks {
+		tasks[i] = &Task{
+			Keyword:        it.Keyword,
+			ID:             it.ID,
+			PriorityCookie: it.Cookie,
+			Checkbox:       it.Checkbox,
+			Title:          it.Title,
+			Tags:           slices.Clone(it.Tags),
+			References:     slices.Clone(it.References),
+			ScheduledAt:    it.ScheduledAt,
+			DueAt:          it.DueAt,
+			Estimate:       it.Estimate,
+			Assignee:       it.Assignee,
+			Project:        it.Project,
+			Zettel:         it.Zettel,
+			Properties:     maps.Clone(it.Properties),
+			FilePath:       filePath,
+			IndentLevel:    it.IndentLevel,
+			LineNum:        it.LineNum,
+			stamp:          e.Stamp,
+			subLines:       it.SubLines,
+			subStamp:       e.Stamp,
+			hasSubLines:    true,
+		}
+	}
+	for i, it := range e.Tasks {
+		if it.Parent < 0 || it.Parent >= len(tasks) {
+			continue
+		}
+		parent := tasks[it.Parent]
+		tasks[i].Parent = parent
+		parent.Children = append(parent.Children, tasks[i])
+	}
+	return tasks
+}
+
+// blockLines returns the task line at lineNum (1-based) plus the following
+// lines that are blank or indented deeper than indent. It mirrors
+// ReadRawBlock for content that is already in memory.
+func blockLines(fileLines []string, lineNum, indent int) []string {
+	if lineNum < 1 || lineNum > len(fileLines) {
+		return nil
+	}
+	lines := []string{fileLines[lineNum-1]}
+	for _, line := range fileLines[lineNum:] {
+		if line == "" {
+			lines = append(lines, line)
+			continue
+		}
+		_, level := StripLinePrefix(line)
+		if level <= indent {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// extractSubLines returns the task's direct sub-items that record clock
+// entries, state transitions or pomodoros (CLOCK, LOG, legacy COMPLETED,
+// POMODOROS). block is the
+// task line followed by its raw block, as returned by ReadRawBlock.
+func extractSubLines(block []string) []string {
+	expectedRawIndent := detectSubItemRawIndent(block)
+	if expectedRawIndent < 0 {
+		return nil
+	}
+	var subLines []string
+	for _, line := range block[1:] {
+		if line == "" || countLeadingSpaces(line) != expectedRawIndent {
+			continue
+		}
+		if clockLineRe.MatchString(line) || logEntryRe.MatchString(line) || completedLineRe.MatchString(line) ||
+			pomodoroLineRe.MatchString(line) {
+			subLines = append(subLines, line)
+		}
+	}
+	return subLines
+}
+
+// taskSubLines returns the task's CLOCK/LOG/COMPLETED/POMODOROS sub-lines. Lines
+// captured by the index are reused while the source file is unchanged;
+// otherwise the task's block is read from disk.
+func taskSubLines(t *Task) ([]string, error) {
+	if t.FilePath == "" || t.LineNum == 0 {
+		return nil, nil
+	}
+	if t.hasSubLines {
+		if info, err := os.Stat(t.FilePath); err == nil && stampOf(info) == t.subStamp {
+			return t.subLines, nil
+		}
+	}
+	raw, err := ReadRawBlock(t)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+	return extractSubLines(strings.Split(raw, "\n")), nil
+}
@@ -0,0 +1,151 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func makeIndexConfig(t *testing.T) (string, func() []*Task) {
+	t.Helper()
+	cfg, tmpDir := makeProcessFileConfig(t)
+	cfg.Directories.Karya = t.TempDir()
+	return tmpDir, func() []*Task {
+		t.Helper()
+		tasks, err := ListTasks(cfg, "", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tasks
+	}
+}
+
+// touch bumps a file's mtime so the index notices same-size rewrites.
+func touch(t *testing.T, path string, offset time.Duration) {
+	t.Helper()
+	ts := time.Now().Add(offset)
+	if err := os.Chtimes(path, ts, ts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListTasks_IndexWritten(t *testing.T) {
+	cfg, tmpDir := makeProcessFileConfig(t)
+	cfg.Directories.Karya = t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "work"), 0755)
+	writeTaskFile(t, tmpDir, "work/tasks.md", "TODO: parent #a\n  - owner:: ops\n  - TODO: child\n")
+
+	if _, err := ListTasks(cfg, "", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(IndexPath(cfg)); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+
+	// A fresh process (no in-memory index) must load from disk with hierarchy intact
+	indexesMu.Lock()
+	delete(indexes, IndexPath(cfg))
+	indexesMu.Unlock()
+
+	tasks, err := ListTasks(cfg, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[1].Parent != tasks[0] || len(tasks[0].Children) != 1 {
+		t.Error("parent/child links not restored from index")
+	}
+	if len(tasks[0].Tags) != 1 || tasks[0].Tags[0] != "a" {
+		t.Errorf("tags = %v, want [a]", tasks[0].Tags)
+	}
+	if tasks[0].Properties["owner"] != "ops" || len(tasks[1].Properties) != 0 {
+		t.Errorf("properties = %v / %v, want owner=ops on the parent only", tasks[0].Properties, tasks[1].Properties)
+	}
+	if tasks[0].Project != "work" || tasks[0].Zettel != "tasks" {
+		t.Errorf("project/zettel = %q/%q", tasks[0].Project, tasks[0].Zettel)
+	}
+}
+
+func TestListTasks_IndexReusesUnchangedFiles(t *testing.T) {
+	tmpDir, list := makeIndexConfig(t)
+	os.MkdirAll(filepath.Join(tmpDir, "work"), 0755)
+	path := writeTaskFile(t, tmpDir, "work/tasks.md", "TODO: original\n")
+	list()
+
+	// Tamper with the cached entry: an unchanged file must be served from it
+	for _, ix := range indexes {
+		if e, ok := ix.files[path]; ok {
+			e.Tasks[0].Title = "cached"
+		}
+	}
+	if got := list()[0].Title; got != "cached" {
+		t.Fatalf("unchanged file re-parsed: title = %q", got)
+	}
+
+	// Rewriting the file (same size, new mtime) must trigger a re-parse
+	writeTaskFile(t, tmpDir, "work/tasks.md", "TODO: modified\n")
+	touch(t, path, time.Minute)
+	if got := list()[0].Title; got != "modified" {
+		t.Errorf("changed file not re-parsed: title = %q", got)
+	}
+}
+
+func TestListTasks_IndexDropsDeletedFiles(t *testing.T) {
+	tmpDir, list := makeIndexConfig(t)
+	os.MkdirAll(filepath.Join(tmpDir, "work"), 0755)
+	writeTaskFile(t, tmpDir, "work/a.md", "TODO: a\n")
+	path := writeTaskFile(t, tmpDir, "work/b.md", "TODO: b\n")
+	if n := len(list()); n != 2 {
+		t.Fatalf("expected 2 tasks, got %d", n)
+	}
+
+	os.Remove(path)
+	if n := len(list()); n != 1 {
+		t.Fatalf("expected 1 task after delete, got %d", n)
+	}
+	for _, ix := range indexes {
+		if _, ok := ix.files[path]; ok {
+			t.Error("deleted file still in index")
+		}
+	}
+}
+
+func TestParseClockEntries_FromIndex(t *testing.T) {
+	tmpDir, list := makeIndexConfig(t)
+	os.MkdirAll(filepath.Join(tmpDir, "work"), 0755)
+	path := writeTaskFile(t, tmpDir, "work/tasks.md", `DOING: Write design doc
+  CLOCK: 2026-06-17T09:30--2026-06-17T11:15
+  LOG(TODO -> DOING): 2026-06-17T09:30
+  Some notes here
+`)
+
+	tasks := list()
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(tasks))
+	}
+	task := tasks[0]
+	if len(task.subLines) != 2 {
+		t.Fatalf("expected 2 cached sub-lines, got %d: %v", len(task.subLines), task.subLines)
+	}
+
+	entries, err := ParseClockEntries(task)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ParseClockEntries = %v, %v", entries, err)
+	}
+	transitions, err := ParseStateTransitions(task)
+	if err != nil || len(transitions) != 1 || transitions[0].To != "DOING" {
+		t.Fatalf("ParseStateTransitions = %v, %v", transitions, err)
+	}
+
+	// Writes through the same Task must not be hidden by the cached sub-lines
+	if err := ClockIn(task); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, path, time.Minute)
+	if !IsClockActive(task) {
+		t.Error("clock-in not visible after file changed")
+	}
+}
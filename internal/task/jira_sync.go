@@ -1,12 +1,11 @@
 package task
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/vinayprograms/karya/internal/config"
@@ -76,7 +75,10 @@ func SyncFromJira(ctx context.Context, cfg *config.Config, client *jira.Client)
 		}
 
 		if existing, ok := existingJira[issue.Key]; ok {
-			if err := updateExistingTask(cfg, existing, issue, issueKeys); err != nil {
+			err := Journaled(existing, "JIRA: sync "+issue.Key, func() error {
+				return updateExistingTask(cfg, existing, issue, issueKeys)
+			})
+			if err != nil {
 				return 0, fmt.Errorf("updating %s: %w", issue.Key, err)
 			}
 		} else {
@@ -118,7 +120,10 @@ func handleDisappearedTicket(ctx context.Context, cfg *config.Config, client *ji
 	}
 	reassigned := issue.Fields.Assignee == nil || issue.Fields.Assignee.AccountID != currentUser
 
-	if isDone || reassigned {
+	if !isDone && !reassigned {
+		return nil
+	}
+	return Journaled(t, "JIRA: close "+t.ID, func() error {
 		oldKW := t.Keyword
 		if err := UpdateTaskStatus(t, "DONE", cfg); err != nil {
 			if errors.Is(err, ErrPendingChildren) {
@@ -135,8 +140,8 @@ func handleDisappearedTicket(ctx context.Context, cfg *config.Config, client *ji
 		if reassigned {
 			return appendLineAfterTask(t, "  Unassigned in JIRA")
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 func updateExistingTask(cfg *config.Config, t *Task, issue *jira.Issue, allIssues map[string]*jira.Issue) error {
@@ -178,34 +183,30 @@ func appendNewTask(cfg *config.Config, issue *jira.Issue, allIssues map[string]*
 	line := renderTaskLine(keyword, issue)
 	content := renderTaskBlock(line, issue, allIssues)
 
-	f, err := os.OpenFile(inboxPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	_, err = f.WriteString("\n" + content)
-	return err
+	return mutateFile(inboxPath, "JIRA: add "+issue.Key, func(data []byte) ([]byte, error) {
+		return append(data, "\n"+content...), nil
+	})
 }
 
 func renderTaskLine(keyword string, issue *jira.Issue) string {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%s: [%s] %s", keyword, issue.Key, issue.Fields.Summary))
-
+	line := fmt.Sprintf("%s: [%s] %s", keyword, issue.Key, issue.Fields.Summary)
+	m, ok := ParseLineModel(line)
+	if !ok {
+		return line
+	}
 	for _, label := range issue.Fields.Labels {
-		sb.WriteString(fmt.Sprintf(" #%s", label))
+		m.AddTag(label)
 	}
-
 	if issue.Fields.DueDate != "" {
-		sb.WriteString(fmt.Sprintf(" @d:%s", issue.Fields.DueDate))
+		m.SetDue(issue.Fields.DueDate)
 	}
 
 	// Add parent reference if parent is not in our issue set
 	if issue.Fields.Parent != nil {
-		sb.WriteString(fmt.Sprintf(" ^%s", issue.Fields.Parent.Key))
+		m.AddRef(issue.Fields.Parent.Key)
 	}
 
-	return sb.String()
+	return m.String()
 }
 
 func renderTaskBlock(taskLine string, issue *jira.Issue, allIssues map[string]*jira.Issue) string {
@@ -239,55 +240,43 @@ func updateTaskContent(t *Task, issue *jira.Issue, allIssues map[string]*jira.Is
 		return nil
 	}
 
-	data, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return err
-	}
-	lines := strings.Split(string(data), "\n")
-	if t.LineNum > len(lines) {
-		return nil
-	}
-
-	// Check if the URL link already exists in the indented block — if so, nothing to do
-	ticketURL := jiraTicketURL(issue.Key)
-	for i := t.LineNum; i < len(lines); i++ {
-		line := lines[i]
-		if line == "" {
-			continue
-		}
-		if len(line) > 0 && (line[0] != ' ' && line[0] != '\t') {
-			break
-		}
-		if strings.Contains(line, ticketURL) {
-			return nil
+	return mutateTask(t, "JIRA: update description: "+t.Title, func(lines []string, taskIdx int) ([]string, error) {
+		// Check if the URL link already exists in the indented block — if so, nothing to do
+		ticketURL := jiraTicketURL(issue.Key)
+		for i := taskIdx + 1; i < len(lines); i++ {
+			line := lines[i]
+			if line == "" {
+				continue
+			}
+			if line[0] != ' ' && line[0] != '\t' {
+				break
+			}
+			if strings.Contains(line, ticketURL) {
+				return lines, nil
+			}
 		}
-	}
 
-	// Find the extent of the current indented block
-	blockEnd := t.LineNum
-	for i := t.LineNum; i < len(lines); i++ {
-		line := lines[i]
-		if line == "" {
-			blockEnd = i + 1
-			continue
-		}
-		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
-			blockEnd = i + 1
-		} else {
-			break
+		// Find the extent of the current indented block
+		blockEnd := taskIdx + 1
+		for i := taskIdx + 1; i < len(lines); i++ {
+			line := lines[i]
+			if line == "" {
+				blockEnd = i + 1
+				continue
+			}
+			if line[0] == ' ' || line[0] == '\t' {
+				blockEnd = i + 1
+			} else {
+				break
+			}
 		}
-	}
 
-	// Replace with just the ticket URL
-	finalBlock := buildFinalBlock(issue)
-
-	// Replace the old block
-	result := make([]string, 0, len(lines))
-	result = append(result, lines[:t.LineNum]...)
-	result = append(result, finalBlock...)
-	result = append(result, lines[blockEnd:]...)
-
-	return os.WriteFile(t.FilePath, []byte(strings.Join(result, "\n")), 0644)
+		// Replace the old block with just the ticket URL
+		result := make([]string, 0, len(lines))
+		result = append(result, lines[:taskIdx+1]...)
+		result = append(result, buildFinalBlock(issue)...)
+		return append(result, lines[blockEnd:]...), nil
+	})
 }
 
 func buildFinalBlock(issue *jira.Issue) []string {
@@ -300,27 +289,10 @@ func setDueDate(t *Task, date string) error {
 		return nil
 	}
 
-	data, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return err
-	}
-	lines := strings.Split(string(data), "\n")
-	if t.LineNum > len(lines) {
-		return nil
-	}
-
-	line := lines[t.LineNum-1]
-
-	// Replace existing @d: or append
-	dueRe := regexp.MustCompile(`@d:[^ ]+`)
-	if dueRe.MatchString(line) {
-		line = dueRe.ReplaceAllString(line, "@d:"+date)
-	} else {
-		line = line + " @d:" + date
-	}
-
-	lines[t.LineNum-1] = line
-	return os.WriteFile(t.FilePath, []byte(strings.Join(lines, "\n")), 0644)
+	_, err := editLine(t, "JIRA: set due date: "+t.Title, func(m *LineModel) {
+		m.SetDue(date)
+	})
+	return err
 }
 
 func appendLineAfterTask(t *Task, text string) error {
@@ -328,36 +300,25 @@ func appendLineAfterTask(t *Task, text string) error {
 		return nil
 	}
 
-	data, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(data), "\n")
-
-	// Find end of task's indented block
-	insertAt := t.LineNum // after the task line (0-indexed)
-	for i := t.LineNum; i < len(lines); i++ {
-		line := lines[i]
-		if line == "" {
-			insertAt = i + 1
-			continue
-		}
-		_, level := StripLinePrefix(line)
-		if level > t.IndentLevel {
-			insertAt = i + 1
-		} else {
-			break
+	return mutateTask(t, "JIRA: add note: "+t.Title, func(lines []string, taskIdx int) ([]string, error) {
+		// Find end of task's indented block
+		_, taskLevel := StripLinePrefix(lines[taskIdx])
+		insertAt := taskIdx + 1
+		for i := taskIdx + 1; i < len(lines); i++ {
+			line := lines[i]
+			if line == "" {
+				insertAt = i + 1
+				continue
+			}
+			_, level := StripLinePrefix(line)
+			if level > taskLevel {
+				insertAt = i + 1
+			} else {
+				break
+			}
 		}
-	}
-
-	// Insert the new line
-	result := make([]string, 0, len(lines)+1)
-	result = append(result, lines[:insertAt]...)
-	result = append(result, text)
-	result = append(result, lines[insertAt:]...)
-
-	return os.WriteFile(t.FilePath, []byte(strings.Join(result, "\n")), 0644)
+		return slices.Insert(lines, insertAt, text), nil
+	})
 }
 
 // UpdateTaskLabels updates the tags on a task's line to match JIRA labels.
@@ -366,28 +327,8 @@ func UpdateTaskLabels(t *Task, labels []string) error {
 		return nil
 	}
 
-	data, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return err
-	}
-
-	scanner := bufio.NewScanner(strings.NewReader(string(data)))
-	var lines []string
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		if lineNum == t.LineNum {
-			// Remove existing tags (boundary-guarded so a URL fragment isn't mistaken for one)
-			tagRe := regexp.MustCompile(`(?:^|\s)#[^ ]+`)
-			line = tagRe.ReplaceAllString(line, "")
-			// Append new tags
-			for _, label := range labels {
-				line += " #" + label
-			}
-		}
-		lines = append(lines, line)
-	}
-
-	return os.WriteFile(t.FilePath, []byte(strings.Join(lines, "\n")), 0644)
+	_, err := editLine(t, "JIRA: update labels: "+t.Title, func(m *LineModel) {
+		m.SetTags(labels)
+	})
+	return err
 }
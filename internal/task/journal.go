@@ -0,0 +1,360 @@
+package task
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// Journal entry kinds.
+const (
+	JournalEdit = "edit" // changes made by a task operation
+	JournalUndo = "undo" // an edit reverted by Undo
+	JournalRedo = "redo" // an undone edit re-applied by Redo
+)
+
+// FileChange is one contiguous hunk of an edit: the lines starting at Line
+// (1-based) read Before before the edit and After afterwards.
+type FileChange struct {
+	Path   string   `json:"path"`
+	Line   int      `json:"line"`
+	Before []string `json:"before"`
+	After  []string `json:"after"`
+}
+
+// JournalEntry is one line of the edit journal. Undo and redo entries name
+// the edit they apply to in Target, its position in the journal.
+type JournalEntry struct {
+	Time    time.Time    `json:"time"`
+	Kind    string       `json:"kind"`
+	Target  int          `json:"target,omitempty"`
+	Summary string       `json:"summary"`
+	Changes []FileChange `json:"changes"`
+}
+
+// Files returns the files the entry changed, in order of first change.
+func (e JournalEntry) Files() []string {
+	var files []string
+	for _, ch := range e.Changes {
+		if !slices.Contains(files, ch.Path) {
+			files = append(files, ch.Path)
+		}
+	}
+	return files
+}
+
+var (
+	journalMu   sync.Mutex
+	journalPath string
+)
+
+// JournalPath returns the location of the edit journal, or "" when no karya
+// directory is configured.
+func JournalPath(c *config.Config) string {
+	if c.Directories.Karya == "" {
+		return ""
+	}
+	return filepath.Join(c.Directories.Karya, ".cache", "journal.jsonl")
+}
+
+// EnableJournal records every task file edit made by this process from now
+// on in the journal at JournalPath, so it can be reverted with Undo.
+func EnableJournal(c *config.Config) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	journalPath = JournalPath(c)
+}
+
+// Journaled runs fn, recording every edit it makes to t's file (status,
+// LOG and CLOCK lines, dates...) as a single journal entry described by
+// summary, so that one undo reverts them together. Calls nest: edits made
+// by an inner Journaled on the same task join the outer entry.
+func Journaled(t *Task, summary string, fn func() error) error {
+	if t.journal != nil {
+		return fn()
+	}
+	entry := &JournalEntry{Kind: JournalEdit, Summary: summary}
+	t.journal = entry
+	err := fn()
+	t.journal = nil
+	// Edits made before a failure are journaled too, so they can be undone
+	if jerr := appendJournal(entry); err == nil {
+		err = jerr
+	}
+	return err
+}
+
+// recordEdit journals the change from before to after in path: as part of
+// the task's Journaled entry when there is one, or as an entry of its own.
+func recordEdit(t *Task, summary, path string, before, after []byte) error {
+	change, ok := diffChange(path, before, after)
+	if !ok {
+		return nil
+	}
+	if t != nil && t.journal != nil {
+		t.journal.Changes = append(t.journal.Changes, change)
+		return nil
+	}
+	return appendJournal(&JournalEntry{Kind: JournalEdit, Summary: summary, Changes: []FileChange{change}})
+}
+
+// diffChange returns the hunk between the common leading and trailing lines
+// of before and after. A pure insertion or deletion is widened by one line
+// of context, so reverting it has something to anchor to.
+func diffChange(path string, before, after []byte) (FileChange, bool) {
+	if string(before) == string(after) {
+		return FileChange{}, false
+	}
+	a := strings.Split(string(before), "\n")
+	b := strings.Split(string(after), "\n")
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	if prefix == len(a)-suffix || prefix == len(b)-suffix {
+		if prefix > 0 {
+			prefix--
+		} else {
+			suffix--
+		}
+	}
+	return FileChange{
+		Path:   path,
+		Line:   prefix + 1,
+		Before: slices.Clone(a[prefix : len(a)-suffix]),
+		After:  slices.Clone(b[prefix : len(b)-suffix]),
+	}, true
+}
+
+// appendJournal appends the entry to the journal if journaling is enabled
+// and the entry changed anything. Each entry is a single O_APPEND write, so
+// processes appending concurrently don't interleave.
+func appendJournal(entry *JournalEntry) error {
+	journalMu.Lock()
+	path := journalPath
+	journalMu.Unlock()
+	if path == "" || len(entry.Changes) == 0 {
+		return nil
+	}
+	return writeJournalEntry(path, entry)
+}
+
+func writeJournalEntry(path string, entry *JournalEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadJournal returns every journal entry, oldest first. A missing journal
+// is empty.
+func ReadJournal(c *config.Config) ([]JournalEntry, error) {
+	path := JournalPath(c)
+	if path == "" {
+		return nil, fmt.Errorf("no karya directory configured for the edit journal")
+	}
+	return readJournal(path)
+}
+
+func readJournal(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// A torn final line from a crash; everything before it is intact
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// journalStacks replays the journal and returns the positions of the edits
+// that can be undone and of those that can be redone, most recent last. A
+// new edit clears the redo stack.
+func journalStacks(entries []JournalEntry) (done, undone []int) {
+	for i, e := range entries {
+		switch e.Kind {
+		case JournalEdit:
+			done = append(done, i)
+			undone = nil
+		case JournalUndo:
+			if j := slices.Index(done, e.Target); j >= 0 {
+				done = slices.Delete(done, j, j+1)
+				undone = append(undone, e.Target)
+			}
+		case JournalRedo:
+			if j := slices.Index(undone, e.Target); j >= 0 {
+				undone = slices.Delete(undone, j, j+1)
+				done = append(done, e.Target)
+			}
+		}
+	}
+	return done, undone
+}
+
+// Undo reverts the n most recent edits that haven't been undone yet, newest
+// first, and returns the entries it recorded. Each hunk is found again even
+// if later edits moved it; if its lines were changed since, Undo stops with
+// a *ConflictError and leaves that edit's files untouched.
+func Undo(c *config.Config, n int) ([]JournalEntry, error) {
+	return revertJournal(c, n, JournalUndo)
+}
+
+// Redo re-applies the n most recently undone edits.
+func Redo(c *config.Config, n int) ([]JournalEntry, error) {
+	return revertJournal(c, n, JournalRedo)
+}
+
+func revertJournal(c *config.Config, n int, kind string) ([]JournalEntry, error) {
+	path := JournalPath(c)
+	if path == "" {
+		return nil, fmt.Errorf("no karya directory configured for the edit journal")
+	}
+	// The journal lock serialises undo/redo across processes; it is always
+	// taken before any task file's lock
+	unlock, err := lockFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := readJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	done, undone := journalStacks(entries)
+	stack := done
+	if kind == JournalRedo {
+		stack = undone
+	}
+
+	var applied []JournalEntry
+	for range n {
+		if len(stack) == 0 {
+			break
+		}
+		target := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		changes, err := applyJournalEntry(entries[target], kind == JournalUndo)
+		if err != nil {
+			return applied, err
+		}
+		entry := JournalEntry{Kind: kind, Target: target, Summary: entries[target].Summary, Changes: changes}
+		if err := writeJournalEntry(path, &entry); err != nil {
+			return applied, err
+		}
+		applied = append(applied, entry)
+	}
+	if len(applied) == 0 {
+		return nil, fmt.Errorf("nothing to %s", kind)
+	}
+	return applied, nil
+}
+
+// applyJournalEntry reverts (or, for redo, re-applies) the entry's changes
+// and returns the changes actually made. All hunks are located before any
+// file is written, so a conflict leaves every file as it was.
+func applyJournalEntry(e JournalEntry, undo bool) ([]FileChange, error) {
+	files := e.Files()
+	unlock, err := lockFiles(files...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	original := make(map[string][]byte, len(files))
+	lines := make(map[string][]string, len(files))
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		original[f] = content
+		lines[f] = strings.Split(string(content), "\n")
+	}
+
+	changes := slices.Clone(e.Changes)
+	if undo {
+		slices.Reverse(changes)
+	}
+	var made []FileChange
+	for _, ch := range changes {
+		from, to := ch.Before, ch.After
+		if undo {
+			from, to = to, from
+		}
+		cur := lines[ch.Path]
+		i := findLines(cur, from, ch.Line-1)
+		if i < 0 {
+			return nil, &ConflictError{Path: ch.Path, Task: e.Summary, Reason: fmt.Sprintf("lines near line %d changed since the edit", ch.Line)}
+		}
+		lines[ch.Path] = slices.Concat(cur[:i], to, cur[i+len(from):])
+		made = append(made, FileChange{Path: ch.Path, Line: i + 1, Before: from, After: to})
+	}
+
+	for _, f := range files {
+		if err := writeIfUnchanged(f, original[f], []byte(strings.Join(lines[f], "\n"))); err != nil {
+			return nil, err
+		}
+	}
+	return made, nil
+}
+
+// findLines returns the index at which want occurs in lines, preferring the
+// occurrence closest to hint, or -1.
+func findLines(lines, want []string, hint int) int {
+	best, bestDist := -1, 0
+	for i := 0; i+len(want) <= len(lines); i++ {
+		if !slices.Equal(lines[i:i+len(want)], want) {
+			continue
+		}
+		d := i - hint
+		if d < 0 {
+			d = -d
+		}
+		if best < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
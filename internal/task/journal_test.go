@@ -0,0 +1,206 @@
+package task
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// journalTestSetup enables the journal in a temporary karya directory and
+// returns the config along with a task read from a fresh notes file.
+func journalTestSetup(t *testing.T, content string) (*config.Config, *Task, string) {
+	t.Helper()
+	cfg := createTestConfig()
+	cfg.Directories.Karya = t.TempDir()
+	EnableJournal(cfg)
+	t.Cleanup(func() { journalPath = "" })
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	os.WriteFile(path, []byte(content), 0644)
+	tasks, err := readInboxFile(path, cfg)
+	if err != nil || len(tasks) == 0 {
+		t.Fatalf("readInboxFile() = %v, %v", tasks, err)
+	}
+	return cfg, tasks[0], path
+}
+
+func TestUndo(t *testing.T) {
+	original := "# Notes\n\nTODO: Write report\nTODO: Review\n"
+
+	tests := []struct {
+		name     string
+		external func(string) string // edit made after the status change, nil for none
+		want     string
+		conflict bool
+	}{
+		{
+			name: "unchanged file",
+			want: original,
+		},
+		{
+			name: "lines inserted above",
+			external: func(s string) string {
+				return strings.Replace(s, "# Notes\n", "# Notes\n\nIntro.\nMore intro.\n", 1)
+			},
+			want: "# Notes\n\nIntro.\nMore intro.\n\nTODO: Write report\nTODO: Review\n",
+		},
+		{
+			name: "neighbouring task edited",
+			external: func(s string) string {
+				return strings.Replace(s, "TODO: Review", "DOING: Review", 1)
+			},
+			want: "# Notes\n\nTODO: Write report\nDOING: Review\n",
+		},
+		{
+			name: "task line edited since",
+			external: func(s string) string {
+				return strings.Replace(s, "DONE: Write report", "DONE: Write the report", 1)
+			},
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, task, path := journalTestSetup(t, original)
+			if err := UpdateTaskStatus(task, "DONE", cfg); err != nil {
+				t.Fatalf("UpdateTaskStatus() error: %v", err)
+			}
+			if tt.external != nil {
+				edited, _ := os.ReadFile(path)
+				os.WriteFile(path, []byte(tt.external(string(edited))), 0644)
+			}
+			before, _ := os.ReadFile(path)
+
+			entries, err := Undo(cfg, 1)
+			got, _ := os.ReadFile(path)
+			if tt.conflict {
+				if !errors.Is(err, ErrConflict) {
+					t.Fatalf("Undo() error = %v, want ErrConflict", err)
+				}
+				if string(got) != string(before) {
+					t.Errorf("file written despite conflict: %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Undo() error: %v", err)
+			}
+			if len(entries) != 1 || entries[0].Summary != "Status TODO -> DONE: Write report" {
+				t.Errorf("Undo() = %+v, want the status change", entries)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUndo_Journaled(t *testing.T) {
+	original := "TODO: Write report\n  - owner:: alice\n"
+	cfg, task, path := journalTestSetup(t, original)
+
+	// The status change and its LOG line are one entry, undone together
+	err := Journaled(task, "Complete report", func() error {
+		if err := UpdateTaskStatus(task, "DONE", cfg); err != nil {
+			return err
+		}
+		return RecordStateTransition(task, "TODO", "DONE")
+	})
+	if err != nil {
+		t.Fatalf("Journaled() error: %v", err)
+	}
+	if err := SetTaskPriority(task, "A"); err != nil {
+		t.Fatalf("SetTaskPriority() error: %v", err)
+	}
+
+	entries, err := Undo(cfg, 2)
+	if err != nil {
+		t.Fatalf("Undo() error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Summary != "Set priority [#A]: Write report" || entries[1].Summary != "Complete report" {
+		t.Errorf("Undo() = %+v, want priority then status entries", entries)
+	}
+	if got, _ := os.ReadFile(path); string(got) != original {
+		t.Errorf("file = %q, want %q", got, original)
+	}
+	if _, err := Undo(cfg, 1); err == nil || err.Error() != "nothing to undo" {
+		t.Errorf("Undo() on empty stack error = %v", err)
+	}
+}
+
+func TestRedo(t *testing.T) {
+	original := "TODO: Write report\n"
+	cfg, task, path := journalTestSetup(t, original)
+
+	if err := SetTaskPriority(task, "B"); err != nil {
+		t.Fatalf("SetTaskPriority() error: %v", err)
+	}
+	edited, _ := os.ReadFile(path)
+	if _, err := Undo(cfg, 1); err != nil {
+		t.Fatalf("Undo() error: %v", err)
+	}
+	if _, err := Redo(cfg, 1); err != nil {
+		t.Fatalf("Redo() error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != string(edited) {
+		t.Errorf("file after redo = %q, want %q", got, edited)
+	}
+
+	// The redone edit can be undone again; a new edit then clears redo
+	if _, err := Undo(cfg, 1); err != nil {
+		t.Fatalf("Undo() after redo error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != original {
+		t.Errorf("file after second undo = %q, want %q", got, original)
+	}
+	tasks, _ := readInboxFile(path, cfg)
+	if err := SetTaskProperty(tasks[0], "owner", "bob"); err != nil {
+		t.Fatalf("SetTaskProperty() error: %v", err)
+	}
+	if _, err := Redo(cfg, 1); err == nil || err.Error() != "nothing to redo" {
+		t.Errorf("Redo() after new edit error = %v, want nothing to redo", err)
+	}
+}
+
+func TestDiffChange(t *testing.T) {
+	tests := []struct {
+		name, before, after string
+		want                FileChange
+	}{
+		{
+			name:   "replaced line",
+			before: "a\nb\nc\n",
+			after:  "a\nB\nc\n",
+			want:   FileChange{Line: 2, Before: []string{"b"}, After: []string{"B"}},
+		},
+		{
+			name:   "inserted line keeps context",
+			before: "a\nc\n",
+			after:  "a\nb\nc\n",
+			want:   FileChange{Line: 1, Before: []string{"a"}, After: []string{"a", "b"}},
+		},
+		{
+			name:   "insert at start",
+			before: "b\n",
+			after:  "a\nb\n",
+			want:   FileChange{Line: 1, Before: []string{"b"}, After: []string{"a", "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := diffChange("f", []byte(tt.before), []byte(tt.after))
+			if !ok {
+				t.Fatal("diffChange() found no change")
+			}
+			if got.Line != tt.want.Line || strings.Join(got.Before, "|") != strings.Join(tt.want.Before, "|") ||
+				strings.Join(got.After, "|") != strings.Join(tt.want.After, "|") {
+				t.Errorf("diffChange() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,621 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SpanKind identifies what a span of a task line holds.
+type SpanKind int
+
+const (
+	SpanIndent    SpanKind = iota // leading whitespace and bullet: "  - "
+	SpanKeyword                   // "TODO:"
+	SpanCheckbox                  // "[ ]" or "[x]" of a checkbox item
+	SpanPriority                  // "[#A]"
+	SpanID                        // "[id]"
+	SpanText                      // a word of the title
+	SpanTag                       // "#tag"
+	SpanRef                       // "^id"
+	SpanScheduled                 // "@s:date" or a bare "@date"
+	SpanDue                       // "@d:date"
+	SpanEstimate                  // "~3h"
+	SpanAssignee                  // ">> name", possibly several words
+	SpanSpace                     // whitespace between the other spans
+)
+
+// Span is a run of a task line, kept exactly as written.
+type Span struct {
+	Kind SpanKind
+	Text string
+}
+
+// Value returns the span's content without its marker: "backend" for
+// "#backend", "2025-01-15" for "@s:2025-01-15", "A" for "[#A]".
+func (s Span) Value() string {
+	switch s.Kind {
+	case SpanKeyword:
+		return strings.TrimSuffix(s.Text, ":")
+	case SpanPriority:
+		return s.Text[2:3]
+	case SpanID:
+		return s.Text[1 : len(s.Text)-1]
+	case SpanTag, SpanRef, SpanEstimate:
+		return s.Text[1:]
+	case SpanScheduled:
+		if strings.HasPrefix(s.Text, "@s:") {
+			return s.Text[3:]
+		}
+		return s.Text[1:]
+	case SpanDue:
+		return s.Text[3:]
+	case SpanAssignee:
+		return strings.TrimSpace(s.Text[2:])
+	}
+	return s.Text
+}
+
+// LineModel is a task line split into typed spans. Joining the spans gives
+// back the line byte for byte, so an edit made through its methods changes
+// only the tokens it touches and leaves the rest of the line as the user
+// wrote it.
+//
+// The tokens are those ParseLine reads: a keyword or checkbox head, then
+// priority cookies, an optional [id] and title words mixed with metadata.
+type LineModel struct {
+	Spans []Span
+}
+
+// keywordHeadRe matches the "KEYWORD:" head of a stripped task line.
+var keywordHeadRe = regexp.MustCompile(`^[A-Z]+:`)
+
+// estimateWordRe matches a whole "~3h" or "~1d4h" estimate word.
+var estimateWordRe = regexp.MustCompile(`^~` + estimatePattern + `$`)
+
+// ParseLineModel tokenizes a task line: "KEYWORD: ..." or, with a bullet, a
+// checkbox item "- [ ] ...". ok is false for any other line. The keyword is
+// not checked against the configuration.
+func ParseLineModel(line string) (m *LineModel, ok bool) {
+	stripped, prefixLen := StripLinePrefix(line)
+	var head Span
+	switch {
+	case checkboxLineRe.MatchString(line):
+		head = Span{SpanCheckbox, stripped[:len("[ ]")]}
+	case keywordHeadRe.MatchString(stripped):
+		head = Span{SpanKeyword, keywordHeadRe.FindString(stripped)}
+	default:
+		return nil, false
+	}
+
+	m = &LineModel{}
+	if prefixLen > 0 {
+		m.Spans = append(m.Spans, Span{SpanIndent, line[:prefixLen]})
+	}
+	m.Spans = append(m.Spans, head)
+	m.tokenize(stripped[len(head.Text):])
+	return m, true
+}
+
+// tokenize appends the spans of the line after its head.
+func (m *LineModel) tokenize(body string) {
+	expectID := true // an [id] may follow the head and any priority cookies
+	for i := 0; i < len(body); {
+		if isBlank(body[i]) {
+			j := i
+			for j < len(body) && isBlank(body[j]) {
+				j++
+			}
+			m.Spans = append(m.Spans, Span{SpanSpace, body[i:j]})
+			i = j
+			continue
+		}
+
+		word := nextWord(body[i:])
+		kind := wordKind(word)
+		if expectID && kind == SpanText && word[0] == '[' {
+			// IDs may contain spaces: "[my id]"
+			if end := strings.IndexByte(body[i:], ']'); end > 1 {
+				m.Spans = append(m.Spans, Span{SpanID, body[i : i+end+1]})
+				i += end + 1
+				expectID = false
+				continue
+			}
+		}
+		if kind != SpanPriority {
+			expectID = false
+		}
+
+		end := i + len(word)
+		if kind == SpanAssignee {
+			// The assignee runs on over title words and dates, as in
+			// ParseLine, up to the next tag, reference or estimate
+			for j := end; j < len(body); {
+				k := j
+				for k < len(body) && isBlank(body[k]) {
+					k++
+				}
+				if k == len(body) {
+					break
+				}
+				next := nextWord(body[k:])
+				switch wordKind(next) {
+				case SpanText, SpanScheduled, SpanDue:
+					end = k + len(next)
+					j = end
+					continue
+				}
+				break
+			}
+		}
+		m.Spans = append(m.Spans, Span{kind, body[i:end]})
+		i = end
+	}
+}
+
+func isBlank(b byte) bool { return b == ' ' || b == '\t' }
+
+// nextWord returns the word starting s. A "^ref" or ">>" inside a word
+// starts a new one, since ParseLine reads those without a space before them.
+func nextWord(s string) string {
+	end := strings.IndexAny(s, " \t")
+	if end < 0 {
+		end = len(s)
+	}
+	word := s[:end]
+	for _, marker := range []string{"^", ">>"} {
+		if k := strings.Index(word[1:], marker); k >= 0 && k+1+len(marker) < len(word) {
+			word = word[:k+1]
+		}
+	}
+	return word
+}
+
+// wordKind classifies a whitespace-delimited word of a task line.
+func wordKind(word string) SpanKind {
+	switch {
+	case word == "[#A]" || word == "[#B]" || word == "[#C]":
+		return SpanPriority
+	case len(word) > 1 && word[0] == '#':
+		return SpanTag
+	case len(word) > 1 && word[0] == '^':
+		return SpanRef
+	case strings.HasPrefix(word, ">>"):
+		return SpanAssignee
+	case strings.HasPrefix(word, "@s:") && len(word) > 3:
+		return SpanScheduled
+	case strings.HasPrefix(word, "@d:") && len(word) > 3:
+		return SpanDue
+	case len(word) > 1 && word[0] == '@' && !strings.Contains(word, ":"):
+		return SpanScheduled
+	case estimateWordRe.MatchString(word):
+		return SpanEstimate
+	}
+	return SpanText
+}
+
+// String returns the line.
+func (m *LineModel) String() string {
+	var sb strings.Builder
+	for _, s := range m.Spans {
+		sb.WriteString(s.Text)
+	}
+	return sb.String()
+}
+
+// first returns the first span of the given kind, or a zero Span.
+func (m *LineModel) first(kind SpanKind) Span {
+	if i := m.index(kind); i >= 0 {
+		return m.Spans[i]
+	}
+	return Span{}
+}
+
+func (m *LineModel) index(kind SpanKind) int {
+	return slices.IndexFunc(m.Spans, func(s Span) bool { return s.Kind == kind })
+}
+
+func (m *LineModel) values(kind SpanKind) []string {
+	var vals []string
+	for _, s := range m.Spans {
+		if s.Kind == kind {
+			vals = append(vals, s.Value())
+		}
+	}
+	return vals
+}
+
+// Keyword returns the line's keyword, or "" for a checkbox item.
+func (m *LineModel) Keyword() string { return m.first(SpanKeyword).Value() }
+
+// Checkbox returns the checkbox as written ("[ ]", "[x]"), or "".
+func (m *LineModel) Checkbox() string { return m.first(SpanCheckbox).Text }
+
+// Priority returns the priority cookie's letter, or "".
+func (m *LineModel) Priority() string { return m.first(SpanPriority).Value() }
+
+// ID returns the task's [id], or "".
+func (m *LineModel) ID() string { return m.first(SpanID).Value() }
+
+// Tags returns the tags, without "#".
+func (m *LineModel) Tags() []string { return m.values(SpanTag) }
+
+// Refs returns the referenced task IDs, without "^".
+func (m *LineModel) Refs() []string { return m.values(SpanRef) }
+
+// Due returns the @d: date, or "".
+func (m *LineModel) Due() string { return m.first(SpanDue).Value() }
+
+// Estimate returns the estimate without "~", or "".
+func (m *LineModel) Estimate() string { return m.first(SpanEstimate).Value() }
+
+// Assignee returns the name after ">>", or "".
+func (m *LineModel) Assignee() string { return m.first(SpanAssignee).Value() }
+
+// Scheduled returns the @s: date, or else the first bare @date.
+func (m *LineModel) Scheduled() string {
+	if i := m.scheduledIndex(); i >= 0 {
+		return m.Spans[i].Value()
+	}
+	return ""
+}
+
+// scheduledIndex returns the span holding the scheduled date: the first
+// @s: token, else the first bare @date (as ParseLine reads it), or -1.
+func (m *LineModel) scheduledIndex() int {
+	bare := -1
+	for i, s := range m.Spans {
+		if s.Kind != SpanScheduled {
+			continue
+		}
+		if strings.HasPrefix(s.Text, "@s:") {
+			return i
+		}
+		if bare < 0 {
+			bare = i
+		}
+	}
+	return bare
+}
+
+// Title returns the title words, keeping the spacing between adjacent ones.
+func (m *LineModel) Title() string {
+	var sb strings.Builder
+	last := -1
+	for i, s := range m.Spans {
+		if s.Kind != SpanText {
+			continue
+		}
+		if last >= 0 {
+			if i == last+2 && m.Spans[last+1].Kind == SpanSpace {
+				sb.WriteString(m.Spans[last+1].Text)
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(s.Text)
+		last = i
+	}
+	return sb.String()
+}
+
+// SetKeyword makes the line a "KEYWORD:" task, replacing its keyword or
+// checkbox.
+func (m *LineModel) SetKeyword(keyword string) {
+	m.Spans[m.headIndex()] = Span{SpanKeyword, keyword + ":"}
+}
+
+// SetCheckbox makes the line a checkbox item with the given mark ("[ ]" or
+// "[x]"), replacing its checkbox or keyword.
+func (m *LineModel) SetCheckbox(mark string) {
+	m.Spans[m.headIndex()] = Span{SpanCheckbox, mark}
+}
+
+// SetPriority sets the priority cookie ("A", "B" or "C") right after the
+// head, org-style, removing any other cookie. An empty cookie removes it.
+func (m *LineModel) SetPriority(cookie string) {
+	m.removeAll(SpanPriority)
+	if cookie != "" {
+		m.insert(m.headIndex()+1, Span{SpanPriority, "[#" + cookie + "]"})
+	}
+}
+
+// SetID sets the task's [id], after the head and priority cookie. An empty
+// id removes it.
+func (m *LineModel) SetID(id string) {
+	if id == "" {
+		m.removeAll(SpanID)
+		return
+	}
+	m.set(SpanID, "["+id+"]", m.afterHead())
+}
+
+// SetTitle replaces the title words with title, written where the title
+// started. Words of title that look like metadata (#tag, @date...) are read
+// back as such.
+func (m *LineModel) SetTitle(title string) {
+	first := m.index(SpanText)
+	for i := len(m.Spans) - 1; i > first; i-- {
+		if m.Spans[i].Kind == SpanText {
+			m.remove(i)
+		}
+	}
+	switch {
+	case first >= 0 && title == "":
+		m.remove(first)
+	case first >= 0:
+		m.Spans[first].Text = title
+	case title != "":
+		m.insert(m.afterHead(), Span{SpanText, title})
+	}
+}
+
+// AddTag adds #tag after the existing tags, unless the line already has it.
+func (m *LineModel) AddTag(tag string) {
+	if slices.Contains(m.Tags(), tag) {
+		return
+	}
+	m.insert(m.metaPos(SpanTag, SpanRef, SpanAssignee), Span{SpanTag, "#" + tag})
+}
+
+// RemoveTag removes every #tag.
+func (m *LineModel) RemoveTag(tag string) {
+	m.removeIf(func(s Span) bool { return s.Kind == SpanTag && s.Value() == tag })
+}
+
+// SetTags replaces the line's tags with tags, keeping those it already has
+// in place.
+func (m *LineModel) SetTags(tags []string) {
+	m.removeIf(func(s Span) bool { return s.Kind == SpanTag && !slices.Contains(tags, s.Value()) })
+	for _, tag := range tags {
+		m.AddTag(tag)
+	}
+}
+
+// AddRef adds a ^ref dependency on the task with the given ID, unless the
+// line already has it.
+func (m *LineModel) AddRef(id string) {
+	if slices.Contains(m.Refs(), id) {
+		return
+	}
+	m.insert(m.metaPos(SpanRef, SpanAssignee), Span{SpanRef, "^" + id})
+}
+
+// RemoveRef removes every ^ref to the given ID.
+func (m *LineModel) RemoveRef(id string) {
+	m.removeIf(func(s Span) bool { return s.Kind == SpanRef && s.Value() == id })
+}
+
+// SetScheduled sets the scheduled date as "@s:date", replacing the current
+// one (explicit or bare). An empty date removes every scheduled date.
+func (m *LineModel) SetScheduled(date string) {
+	if date == "" {
+		m.removeAll(SpanScheduled)
+		return
+	}
+	token := Span{SpanScheduled, "@s:" + date}
+	if i := m.scheduledIndex(); i >= 0 {
+		m.Spans[i] = token
+		return
+	}
+	m.insert(m.metaPos(SpanScheduled, SpanTag, SpanRef, SpanAssignee), token)
+}
+
+// SetDue sets the "@d:date" due date. An empty date removes it.
+func (m *LineModel) SetDue(date string) {
+	if date == "" {
+		m.removeAll(SpanDue)
+		return
+	}
+	m.set(SpanDue, "@d:"+date, m.metaPos(SpanDue, SpanTag, SpanRef, SpanAssignee))
+}
+
+// SetEstimate sets the "~3h" estimate (given without "~"). An empty
+// estimate removes it.
+func (m *LineModel) SetEstimate(estimate string) {
+	if estimate == "" {
+		m.removeAll(SpanEstimate)
+		return
+	}
+	m.set(SpanEstimate, "~"+estimate, m.metaPos(SpanEstimate, SpanTag, SpanRef, SpanAssignee))
+}
+
+// SetAssignee sets the ">> name" assignee at the end of the line. An empty
+// name removes it.
+func (m *LineModel) SetAssignee(name string) {
+	if name == "" {
+		m.removeAll(SpanAssignee)
+		return
+	}
+	m.set(SpanAssignee, ">> "+name, m.end())
+}
+
+// set replaces the first span of the kind with text, or inserts it at pos.
+func (m *LineModel) set(kind SpanKind, text string, pos int) {
+	if i := m.index(kind); i >= 0 {
+		m.Spans[i].Text = text
+		return
+	}
+	m.insert(pos, Span{kind, text})
+}
+
+func (m *LineModel) headIndex() int {
+	if len(m.Spans) > 0 && m.Spans[0].Kind == SpanIndent {
+		return 1
+	}
+	return 0
+}
+
+// afterHead returns the position after the head, priority cookies and [id].
+func (m *LineModel) afterHead() int {
+	pos := m.headIndex() + 1
+	for i := pos; i < len(m.Spans); i++ {
+		switch m.Spans[i].Kind {
+		case SpanPriority, SpanID:
+			pos = i + 1
+		case SpanSpace:
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// end returns the position at the end of the line, before any trailing
+// whitespace.
+func (m *LineModel) end() int {
+	if n := len(m.Spans); m.Spans[n-1].Kind == SpanSpace {
+		return n - 1
+	}
+	return len(m.Spans)
+}
+
+// metaPos returns where a new token of kind goes: after the last span of
+// the same kind, else before the first span of the later kinds, else at the
+// end of the line. Dates thus stay ahead of tags and the assignee, which
+// would otherwise swallow them.
+func (m *LineModel) metaPos(kind SpanKind, later ...SpanKind) int {
+	last := -1
+	for i, s := range m.Spans {
+		if s.Kind == kind {
+			last = i
+		}
+	}
+	if last >= 0 {
+		return last + 1
+	}
+	if i := slices.IndexFunc(m.Spans, func(s Span) bool { return slices.Contains(later, s.Kind) }); i >= 0 {
+		return i
+	}
+	return m.end()
+}
+
+// insert places span at pos, adding a space on either side where it would
+// otherwise touch another token.
+func (m *LineModel) insert(pos int, span Span) {
+	ins := []Span{span}
+	if pos > 0 && m.Spans[pos-1].Kind != SpanSpace {
+		ins = slices.Insert(ins, 0, Span{SpanSpace, " "})
+	}
+	if pos < len(m.Spans) && m.Spans[pos].Kind != SpanSpace {
+		ins = append(ins, Span{SpanSpace, " "})
+	}
+	m.Spans = slices.Insert(m.Spans, pos, ins...)
+}
+
+// remove deletes span i with the whitespace before it (or after it, when it
+// directly follows the head).
+func (m *LineModel) remove(i int) {
+	switch {
+	case m.Spans[i-1].Kind == SpanSpace:
+		m.Spans = slices.Delete(m.Spans, i-1, i+1)
+	case i+1 < len(m.Spans) && m.Spans[i+1].Kind == SpanSpace:
+		m.Spans = slices.Delete(m.Spans, i, i+2)
+	default:
+		m.Spans = slices.Delete(m.Spans, i, i+1)
+	}
+}
+
+func (m *LineModel) removeAll(kind SpanKind) {
+	m.removeIf(func(s Span) bool { return s.Kind == kind })
+}
+
+func (m *LineModel) removeIf(match func(Span) bool) {
+	for i := len(m.Spans) - 1; i > m.headIndex(); i-- {
+		// A removal may take the whitespace after the span too
+		if i < len(m.Spans) && match(m.Spans[i]) {
+			m.remove(i)
+		}
+	}
+}
+
+// EditLine applies edit to the model of t's source line, writes the line
+// back and updates t's keyword, ID, priority, title, tags, references,
+// dates, estimate and assignee from it. The line is located as by the other
+// writers (see mutateTask) and the change is journaled, so it can be undone.
+func EditLine(t *Task, edit func(*LineModel)) error {
+	m, err := editLine(t, "Edit task: "+t.Title, edit)
+	if err != nil {
+		return err
+	}
+	if kw := m.Keyword(); kw != "" {
+		t.Keyword = kw
+	}
+	if t.Checkbox != "" {
+		t.Checkbox = m.Checkbox()
+	}
+	t.ID = m.ID()
+	t.PriorityCookie = m.Priority()
+	t.Title = m.Title()
+	t.Tags = m.Tags()
+	t.References = m.Refs()
+	t.ScheduledAt = m.Scheduled()
+	t.DueAt = m.Due()
+	t.Estimate = m.Estimate()
+	t.Assignee = m.Assignee()
+	return nil
+}
+
+// editLine is EditLine journaled under summary, without updating t's
+// fields. It returns the edited line.
+func editLine(t *Task, summary string, edit func(*LineModel)) (*LineModel, error) {
+	var m *LineModel
+	err := mutateTask(t, summary, func(lines []string, i int) ([]string, error) {
+		var ok bool
+		if m, ok = ParseLineModel(lines[i]); !ok {
+			return nil, fmt.Errorf("not a task line: %q", lines[i])
+		}
+		edit(m)
+		if m.Title() == "" {
+			return nil, fmt.Errorf("task title cannot be empty")
+		}
+		lines[i] = m.String()
+		return lines, nil
+	})
+	return m, err
+}
+
+// LineEdit is a set of changes to a task line's title, tags and assignee,
+// as made by `todo edit`, the TUI and the edit_task MCP tool.
+type LineEdit struct {
+	Title          string   // new title, "" keeps the current one
+	AddTags        []string // tags to add, with or without "#"
+	RemoveTags     []string // tags to remove, with or without "#"
+	Assignee       string   // new assignee, "" keeps the current one
+	RemoveAssignee bool
+}
+
+// Validate checks that the edit changes something and that its values are
+// single tokens where the line syntax needs them to be.
+func (e LineEdit) Validate() error {
+	if e.Title == "" && len(e.AddTags) == 0 && len(e.RemoveTags) == 0 && e.Assignee == "" && !e.RemoveAssignee {
+		return fmt.Errorf("nothing to do")
+	}
+	if e.Assignee != "" && e.RemoveAssignee {
+		return fmt.Errorf("cannot both set and remove the assignee")
+	}
+	for _, tag := range slices.Concat(e.AddTags, e.RemoveTags) {
+		tag = strings.TrimPrefix(tag, "#")
+		if tag == "" || strings.ContainsAny(tag, " \t") {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// Apply makes the edit on m.
+func (e LineEdit) Apply(m *LineModel) {
+	if e.Title != "" {
+		m.SetTitle(e.Title)
+	}
+	for _, tag := range e.RemoveTags {
+		m.RemoveTag(strings.TrimPrefix(tag, "#"))
+	}
+	for _, tag := range e.AddTags {
+		m.AddTag(strings.TrimPrefix(tag, "#"))
+	}
+	if e.Assignee != "" || e.RemoveAssignee {
+		m.SetAssignee(e.Assignee)
+	}
+}
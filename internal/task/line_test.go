@@ -0,0 +1,229 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseLineModel_RoundTrip(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Todo.Checkboxes = true
+
+	lines := []string{
+		"TODO: Write report",
+		"  - DOING: [#A] [api-1] Build API #backend @s:2025-01-15 @d:2025-01-20 ~3h ^db-1 >> alice",
+		"TODO: Write docs [#C] @2025-01-15",
+		"TODO:Fix   spacing\tand tabs  ",
+		"* DONE: [my id] Ship it ^a ^b #x #y",
+		"TODO: Email bob@example.com about page#anchor",
+		"TODO: Fix >> bob smith @d:2025-02-01 #urgent",
+		"TODO: Parse x^2 terms",
+		"- [ ] [t-1] write tests #backend @d:2025-01-20 ^api-02",
+		"  * [x] write tests",
+		"TODO: @ # ^ ~ alone",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			m, ok := ParseLineModel(line)
+			if !ok {
+				t.Fatalf("ParseLineModel(%q) not a task line", line)
+			}
+			if got := m.String(); got != line {
+				t.Errorf("String() = %q, want %q", got, line)
+			}
+
+			// The model reads the line as ParseLine does
+			want := ParseLine(cfg, line, "", "", "")
+			if m.Keyword() != "" && m.Keyword() != want.Keyword {
+				t.Errorf("Keyword() = %q, want %q", m.Keyword(), want.Keyword)
+			}
+			if m.Checkbox() != want.Checkbox || m.Priority() != want.PriorityCookie || m.ID() != want.ID {
+				t.Errorf("Checkbox/Priority/ID = %q/%q/%q, want %q/%q/%q",
+					m.Checkbox(), m.Priority(), m.ID(), want.Checkbox, want.PriorityCookie, want.ID)
+			}
+			if m.Title() != want.Title {
+				t.Errorf("Title() = %q, want %q", m.Title(), want.Title)
+			}
+			if !slices.Equal(m.Tags(), want.Tags) || !slices.Equal(m.Refs(), want.References) {
+				t.Errorf("Tags/Refs = %v/%v, want %v/%v", m.Tags(), m.Refs(), want.Tags, want.References)
+			}
+			if m.Due() != want.DueAt || m.Estimate() != want.Estimate {
+				t.Errorf("Due/Estimate = %q/%q, want %q/%q", m.Due(), m.Estimate(), want.DueAt, want.Estimate)
+			}
+			if want.Assignee == "" && (m.Scheduled() != want.ScheduledAt || m.Assignee() != "") {
+				t.Errorf("Scheduled/Assignee = %q/%q, want %q/%q", m.Scheduled(), m.Assignee(), want.ScheduledAt, want.Assignee)
+			}
+		})
+	}
+
+	for _, line := range []string{"", "Just a note", "todo: lower case", "[ ] no bullet"} {
+		if _, ok := ParseLineModel(line); ok {
+			t.Errorf("ParseLineModel(%q) accepted a non-task line", line)
+		}
+	}
+}
+
+func TestLineModel_Edits(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		edit func(*LineModel)
+		want string
+	}{
+		{
+			name: "set keyword keeps the rest",
+			line: "  - TODO: [#A]  Fix   it #x",
+			edit: func(m *LineModel) { m.SetKeyword("DONE") },
+			want: "  - DONE: [#A]  Fix   it #x",
+		},
+		{
+			name: "check a checkbox",
+			line: "- [ ] write tests",
+			edit: func(m *LineModel) { m.SetCheckbox("[x]") },
+			want: "- [x] write tests",
+		},
+		{
+			name: "priority goes after the head",
+			line: "TODO: [api-1] Build API",
+			edit: func(m *LineModel) { m.SetPriority("B") },
+			want: "TODO: [#B] [api-1] Build API",
+		},
+		{
+			name: "priority moved from mid-line",
+			line: "TODO: Write docs [#C] @2025-01-15",
+			edit: func(m *LineModel) { m.SetPriority("A") },
+			want: "TODO: [#A] Write docs @2025-01-15",
+		},
+		{
+			name: "remove priority",
+			line: "TODO: Write docs [#C] @2025-01-15",
+			edit: func(m *LineModel) { m.SetPriority("") },
+			want: "TODO: Write docs @2025-01-15",
+		},
+		{
+			name: "bare date replaced by @s:",
+			line: "TODO: Call @2025-01-15 #phone",
+			edit: func(m *LineModel) { m.SetScheduled("2025-02-01") },
+			want: "TODO: Call @s:2025-02-01 #phone",
+		},
+		{
+			name: "new dates go before tags and assignee",
+			line: "TODO: Call mom #phone >> me",
+			edit: func(m *LineModel) { m.SetDue("2025-02-01"); m.SetScheduled("2025-01-31") },
+			want: "TODO: Call mom @d:2025-02-01 @s:2025-01-31 #phone >> me",
+		},
+		{
+			name: "remove dates",
+			line: "TODO: Call @s:2025-01-15 mom @d:2025-01-20",
+			edit: func(m *LineModel) { m.SetScheduled(""); m.SetDue("") },
+			want: "TODO: Call mom",
+		},
+		{
+			name: "retitle keeps metadata",
+			line: "TODO: [t1] Old #x title @d:2025-01-20 ^dep",
+			edit: func(m *LineModel) { m.SetTitle("New title") },
+			want: "TODO: [t1] New title #x @d:2025-01-20 ^dep",
+		},
+		{
+			name: "tags added after existing ones",
+			line: "TODO: Fix #a bug ^dep",
+			edit: func(m *LineModel) { m.AddTag("b"); m.AddTag("a"); m.RemoveTag("missing") },
+			want: "TODO: Fix #a #b bug ^dep",
+		},
+		{
+			name: "set tags",
+			line: "TODO: Fix #a #b",
+			edit: func(m *LineModel) { m.SetTags([]string{"b", "c"}) },
+			want: "TODO: Fix #b #c",
+		},
+		{
+			name: "first tag before refs",
+			line: "TODO: Fix bug ^dep  ",
+			edit: func(m *LineModel) { m.AddTag("x"); m.AddRef("other") },
+			want: "TODO: Fix bug #x ^dep ^other  ",
+		},
+		{
+			name: "assignee replaced and removed",
+			line: "TODO: Fix >> bob smith #x",
+			edit: func(m *LineModel) { m.SetAssignee("carol") },
+			want: "TODO: Fix >> carol #x",
+		},
+		{
+			name: "assignee added at the end",
+			line: "TODO: Fix #x",
+			edit: func(m *LineModel) { m.SetAssignee("carol"); m.SetEstimate("2h") },
+			want: "TODO: Fix ~2h #x >> carol",
+		},
+		{
+			name: "id set and removed",
+			line: "TODO: [#A] Fix",
+			edit: func(m *LineModel) { m.SetID("f-1") },
+			want: "TODO: [#A] [f-1] Fix",
+		},
+		{
+			name: "token touching the head",
+			line: "TODO:#x Fix",
+			edit: func(m *LineModel) { m.RemoveTag("x"); m.SetPriority("A") },
+			want: "TODO: [#A] Fix",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ok := ParseLineModel(tt.line)
+			if !ok {
+				t.Fatalf("ParseLineModel(%q) not a task line", tt.line)
+			}
+			tt.edit(m)
+			if got := m.String(); got != tt.want {
+				t.Errorf("edited line = %q, want %q", got, tt.want)
+			}
+			// The edited line tokenizes back to the same line
+			if again, _ := ParseLineModel(m.String()); again.String() != tt.want {
+				t.Errorf("re-parsed line = %q", again.String())
+			}
+		})
+	}
+}
+
+func TestEditLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	os.WriteFile(path, []byte("# Notes\n\n- TODO: [t1] Draft  plan #work @d:2025-07-01 >> bob\n  - owner:: alice\n"), 0644)
+
+	cfg := createTestConfig()
+	tasks, _ := readInboxFile(path, cfg)
+	task := tasks[0]
+
+	edit := LineEdit{Title: "Final plan", AddTags: []string{"#q3"}, RemoveTags: []string{"work"}, RemoveAssignee: true}
+	if err := edit.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if err := EditLine(task, edit.Apply); err != nil {
+		t.Fatalf("EditLine() error: %v", err)
+	}
+	want := "# Notes\n\n- TODO: [t1] Final plan @d:2025-07-01 #q3\n  - owner:: alice\n"
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+	if task.Title != "Final plan" || !slices.Equal(task.Tags, []string{"q3"}) || task.Assignee != "" || task.DueAt != "2025-07-01" {
+		t.Errorf("task = %+v, want its fields updated", task)
+	}
+
+	// Other writers still find the retitled line
+	if err := UpdateTaskStatus(task, "DONE", cfg); err != nil {
+		t.Fatalf("UpdateTaskStatus() after EditLine: %v", err)
+	}
+
+	if err := EditLine(task, func(m *LineModel) { m.SetTitle("") }); err == nil {
+		t.Error("EditLine() accepted an empty title")
+	}
+	if err := (LineEdit{}).Validate(); err == nil {
+		t.Error("Validate() accepted an empty edit")
+	}
+	if err := (LineEdit{AddTags: []string{"two words"}}).Validate(); err == nil {
+		t.Error("Validate() accepted a tag with a space")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package task
+
+// lockProcess is a no-op where flock(2) is unavailable: writers within one
+// process are still serialised by lockFiles, and the content check in
+// writeIfUnchanged catches changes made by other processes.
+func lockProcess(abs string) (func(), error) {
+	return func() {}, nil
+}
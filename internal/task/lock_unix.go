@@ -0,0 +1,36 @@
+//go:build unix
+
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockProcess takes an exclusive flock(2) on a lock file for the absolute
+// path abs, blocking until other karya processes release it. Lock files live
+// in a per-user temp directory rather than next to the notes, so they never
+// show up in a project's git repository.
+func lockProcess(abs string) (func(), error) {
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("karya-locks-%d", os.Getuid()))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	f, err := os.OpenFile(filepath.Join(dir, hex.EncodeToString(sum[:8])+".lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -26,23 +26,29 @@ type ListTasksResult struct {
 }
 
 type TaskInfo struct {
-	Keyword     string   `json:"keyword" jsonschema:"task status keyword (e.g., TODO, DOING, DONE)"`
-	ID          string   `json:"id,omitempty" jsonschema:"task unique identifier"`
-	Title       string   `json:"title" jsonschema:"task title/description"`
-	Tags        []string `json:"tags,omitempty" jsonschema:"task tags (without #)"`
-	References  []string `json:"references,omitempty" jsonschema:"IDs of tasks this task depends on (^id syntax)"`
-	ScheduledAt string   `json:"scheduled_at,omitempty" jsonschema:"scheduled date"`
-	DueAt       string   `json:"due_at,omitempty" jsonschema:"due date"`
-	Assignee    string   `json:"assignee,omitempty" jsonschema:"task assignee"`
-	Project     string   `json:"project" jsonschema:"project name"`
-	Zettel      string   `json:"zettel,omitempty" jsonschema:"zettel ID (if structured mode)"`
-	FilePath    string   `json:"file_path" jsonschema:"file path where task is defined"`
-	Priority    int      `json:"priority" jsonschema:"priority level (1=in_progress, 2=active, 3=someday, 4=completed)"`
-	Status      string   `json:"status" jsonschema:"status category (active, in_progress, completed, someday)"`
-	InCycle    bool   `json:"in_cycle,omitempty" jsonschema:"true if task participates in a circular dependency"`
-	ParentID   string `json:"parent_id,omitempty" jsonschema:"ID of parent task (if this is a sub-task)"`
-	ChildCount int    `json:"child_count,omitempty" jsonschema:"number of direct child tasks"`
-	RawContent string `json:"raw_content,omitempty" jsonschema:"raw file content of task and all indented lines below it"`
+	Keyword        string            `json:"keyword" jsonschema:"task status keyword (e.g., TODO, DOING, DONE)"`
+	ID             string            `json:"id,omitempty" jsonschema:"task unique identifier"`
+	Title          string            `json:"title" jsonschema:"task title/description"`
+	Tags           []string          `json:"tags,omitempty" jsonschema:"task tags (without #)"`
+	References     []string          `json:"references,omitempty" jsonschema:"IDs of tasks this task depends on (^id syntax)"`
+	ScheduledAt    string            `json:"scheduled_at,omitempty" jsonschema:"scheduled date"`
+	DueAt          string            `json:"due_at,omitempty" jsonschema:"due date"`
+	Estimate       string            `json:"estimate,omitempty" jsonschema:"effort estimate from a ~3h token (m, h, d = 8h, w = 5d)"`
+	OverBudget     bool              `json:"over_budget,omitempty" jsonschema:"true if more time has been clocked than estimated"`
+	Assignee       string            `json:"assignee,omitempty" jsonschema:"task assignee"`
+	Project        string            `json:"project" jsonschema:"project name"`
+	Zettel         string            `json:"zettel,omitempty" jsonschema:"zettel ID (if structured mode)"`
+	Properties     map[string]string `json:"properties,omitempty" jsonschema:"task properties from 'key:: value' sub-lines (keys lower-cased)"`
+	FilePath       string            `json:"file_path" jsonschema:"file path where task is defined"`
+	Priority       int               `json:"priority" jsonschema:"priority level (1=in_progress, 2=active, 3=someday, 4=completed)"`
+	PriorityCookie string            `json:"priority_cookie,omitempty" jsonschema:"explicit priority within the status category: A (highest), B or C, from a [#A]-style marker"`
+	Status         string            `json:"status" jsonschema:"status category (active, in_progress, completed, someday)"`
+	InCycle        bool              `json:"in_cycle,omitempty" jsonschema:"true if task participates in a circular dependency"`
+	DuplicateID    bool              `json:"duplicate_id,omitempty" jsonschema:"true if another task has the same ID"`
+	Blocked        bool              `json:"blocked,omitempty" jsonschema:"true while a referenced (^id) task is not completed"`
+	ParentID       string            `json:"parent_id,omitempty" jsonschema:"ID of parent task (if this is a sub-task)"`
+	ChildCount     int               `json:"child_count,omitempty" jsonschema:"number of direct child tasks"`
+	RawContent     string            `json:"raw_content,omitempty" jsonschema:"raw file content of task and all indented lines below it"`
 }
 
 type GetTaskArgs struct {
@@ -76,7 +82,7 @@ type SearchResultInfo struct {
 }
 
 type FilterTasksArgs struct {
-	Filter        string `json:"filter" jsonschema:"filter expression (e.g., '>> alice' for assignee, '#urgent' for tag, '@2025-01-15' for date)"`
+	Filter        string `json:"filter" jsonschema:"filter query: terms like '>>alice', '#urgent', '@d:<2025-07-01', 'project:x', 'category:active', 'priority:A', 'prop:customer=ACME', 'has:deps', 'has:estimate', 'over:budget', 'in:cycle', 'is:ready', 'is:blocked' or free text, combined with AND/OR/NOT and parentheses"`
 	Project       string `json:"project,omitempty" jsonschema:"optional project name to limit filter"`
 	ShowCompleted bool   `json:"show_completed,omitempty" jsonschema:"whether to include completed tasks (default: false)"`
 }
@@ -97,6 +103,7 @@ type UpdateTaskStatusResult struct {
 	Message       string              `json:"message" jsonschema:"status message"`
 	Success       bool                `json:"success" jsonschema:"whether the update succeeded"`
 	ValidKeywords map[string][]string `json:"valid_keywords" jsonschema:"valid keywords grouped by category (Active, InProgress, Completed, Someday)"`
+	Unblocked     []TaskInfo          `json:"unblocked,omitempty" jsonschema:"dependent tasks moved from the waiting keyword to the ready keyword by this completion"`
 }
 
 type GetProjectsArgs struct{}
@@ -134,8 +141,10 @@ type GetTaskByIDArgs struct {
 }
 
 type GetTaskByIDResult struct {
-	Task  *TaskInfo `json:"task,omitempty" jsonschema:"the matching task"`
-	Found bool      `json:"found" jsonschema:"whether a task with this ID was found"`
+	Task       *TaskInfo  `json:"task,omitempty" jsonschema:"the matching task"`
+	Found      bool       `json:"found" jsonschema:"whether a task with this ID was found"`
+	Duplicates []TaskInfo `json:"duplicates,omitempty" jsonschema:"other tasks with the same ID; the ID is ambiguous and should be fixed"`
+	Message    string     `json:"message,omitempty" jsonschema:"warning about the lookup, such as a duplicated ID"`
 }
 
 type GetDependenciesArgs struct {
@@ -160,6 +169,15 @@ type GetDependentsResult struct {
 	Count      int        `json:"count" jsonschema:"number of dependents"`
 }
 
+type GetReadyTasksArgs struct {
+	Project string `json:"project,omitempty" jsonschema:"optional project name to limit search"`
+}
+
+type GetReadyTasksResult struct {
+	Tasks []TaskInfo `json:"tasks" jsonschema:"active and in-progress tasks with no open dependencies, highest priority first"`
+	Count int        `json:"count" jsonschema:"number of ready tasks"`
+}
+
 type GetCycleTasksArgs struct {
 	Project string `json:"project,omitempty" jsonschema:"optional project name to limit search"`
 }
@@ -173,17 +191,65 @@ type ScheduleTaskArgs struct {
 	Project         string `json:"project" jsonschema:"project name"`
 	Keyword         string `json:"keyword" jsonschema:"current task keyword"`
 	Title           string `json:"title" jsonschema:"task title to identify the task"`
-	ScheduledAt     string `json:"scheduled_at,omitempty" jsonschema:"scheduled date to set (e.g. 2026-06-20, 2026-06-20T09:00, 2026-06-20+1w). Omit to leave unchanged."`
-	DueAt           string `json:"due_at,omitempty" jsonschema:"due date to set (same format). Omit to leave unchanged."`
+	ScheduledAt     string `json:"scheduled_at,omitempty" jsonschema:"scheduled date to set, as a token (e.g. 2026-06-20, 2026-06-20T09:00, 2026-06-20+1w) or in words (e.g. tomorrow 3pm, next fri, in 2 weeks, every monday at 9, end of month, +3bd). Omit to leave unchanged."`
+	DueAt           string `json:"due_at,omitempty" jsonschema:"due date to set (same formats). Omit to leave unchanged."`
 	RemoveScheduled bool   `json:"remove_scheduled,omitempty" jsonschema:"if true, removes the existing scheduled date"`
 	RemoveDue       bool   `json:"remove_due,omitempty" jsonschema:"if true, removes the existing due date"`
+	DryRun          bool   `json:"dry_run,omitempty" jsonschema:"if true, only resolves the dates to tokens without changing the task"`
 }
 
 type ScheduleTaskResult struct {
+	Message     string `json:"message" jsonschema:"result message"`
+	Success     bool   `json:"success" jsonschema:"whether the operation succeeded"`
+	ScheduledAt string `json:"scheduled_at,omitempty" jsonschema:"the scheduled date token that was (or, on a dry run, would be) set"`
+	DueAt       string `json:"due_at,omitempty" jsonschema:"the due date token that was (or would be) set"`
+}
+
+type SetPropertyArgs struct {
+	Project string `json:"project" jsonschema:"project name"`
+	Keyword string `json:"keyword" jsonschema:"current task keyword"`
+	Title   string `json:"title" jsonschema:"task title to identify the task"`
+	Key     string `json:"key" jsonschema:"property key (letters, digits, '-' and '_', starting with a letter)"`
+	Value   string `json:"value,omitempty" jsonschema:"property value. Empty removes the property."`
+}
+
+type SetPropertyResult struct {
 	Message string `json:"message" jsonschema:"result message"`
 	Success bool   `json:"success" jsonschema:"whether the operation succeeded"`
 }
 
+type EditTaskArgs struct {
+	Project        string   `json:"project" jsonschema:"project name"`
+	Keyword        string   `json:"keyword" jsonschema:"current task keyword"`
+	Title          string   `json:"title" jsonschema:"task title to identify the task"`
+	NewTitle       string   `json:"new_title,omitempty" jsonschema:"new title for the task. Omit to keep the current title."`
+	AddTags        []string `json:"add_tags,omitempty" jsonschema:"tags to add, without spaces ('#' optional)"`
+	RemoveTags     []string `json:"remove_tags,omitempty" jsonschema:"tags to remove ('#' optional)"`
+	Assignee       string   `json:"assignee,omitempty" jsonschema:"new assignee. Omit to keep the current assignee."`
+	RemoveAssignee bool     `json:"remove_assignee,omitempty" jsonschema:"remove the assignee"`
+}
+
+type EditTaskResult struct {
+	Message string    `json:"message" jsonschema:"result message"`
+	Success bool      `json:"success" jsonschema:"whether the operation succeeded"`
+	Task    *TaskInfo `json:"task,omitempty" jsonschema:"the task after the edit"`
+}
+
+type RefileTaskArgs struct {
+	Project     string `json:"project" jsonschema:"project name"`
+	Keyword     string `json:"keyword" jsonschema:"current task keyword"`
+	Title       string `json:"title" jsonschema:"task title to identify the task"`
+	Destination string `json:"destination" jsonschema:"where to move the task: 'inbox', a project name (its most recent note), or 'project/zettelID'"`
+	Position    int    `json:"position,omitempty" jsonschema:"1-based line in the destination file to insert before. 0 or omitted appends to the end."`
+}
+
+type RefileTaskResult struct {
+	Message  string `json:"message" jsonschema:"result message"`
+	Success  bool   `json:"success" jsonschema:"whether the operation succeeded"`
+	FilePath string `json:"file_path,omitempty" jsonschema:"file the task now lives in"`
+	LineNum  int    `json:"line_num,omitempty" jsonschema:"line number of the task in its new file"`
+}
+
 type ClockInArgs struct {
 	Project string `json:"project" jsonschema:"project name"`
 	Keyword string `json:"keyword" jsonschema:"task keyword"`
@@ -212,15 +278,52 @@ type ClockTableResult struct {
 }
 
 type ClockProjectResult struct {
-	Project string             `json:"project" jsonschema:"project name"`
-	Total   string             `json:"total" jsonschema:"project total time as H:MM"`
-	Tasks   []ClockTaskResult  `json:"tasks" jsonschema:"per-task breakdown"`
+	Project    string            `json:"project" jsonschema:"project name"`
+	Total      string            `json:"total" jsonschema:"project total time as H:MM"`
+	Estimate   string            `json:"estimate,omitempty" jsonschema:"sum of estimates of the project's estimated tasks as H:MM"`
+	Actual     string            `json:"actual,omitempty" jsonschema:"all time clocked on the project's estimated tasks as H:MM"`
+	OverBudget bool              `json:"over_budget,omitempty" jsonschema:"true if actual exceeds estimate"`
+	Pomodoros  int               `json:"pomodoros,omitempty" jsonschema:"completed pomodoros recorded on the project's tasks"`
+	Tasks      []ClockTaskResult `json:"tasks" jsonschema:"per-task breakdown"`
 }
 
 type ClockTaskResult struct {
-	Keyword  string `json:"keyword" jsonschema:"task keyword"`
-	Title    string `json:"title" jsonschema:"task title"`
-	Duration string `json:"duration" jsonschema:"time spent as H:MM"`
+	Keyword    string `json:"keyword" jsonschema:"task keyword"`
+	Title      string `json:"title" jsonschema:"task title"`
+	Duration   string `json:"duration" jsonschema:"time spent in the range as H:MM"`
+	Estimate   string `json:"estimate,omitempty" jsonschema:"effort estimate (~3h) as H:MM"`
+	Actual     string `json:"actual,omitempty" jsonschema:"all time clocked on the task as H:MM (set when the task has an estimate)"`
+	OverBudget bool   `json:"over_budget,omitempty" jsonschema:"true if actual exceeds estimate"`
+	Pomodoros  int    `json:"pomodoros,omitempty" jsonschema:"completed pomodoros recorded on the task"`
+}
+
+type ListViewsArgs struct{}
+
+type ViewInfo struct {
+	Name          string `json:"name" jsonschema:"view name"`
+	Filter        string `json:"filter,omitempty" jsonschema:"filter query"`
+	Sort          string `json:"sort,omitempty" jsonschema:"sort order (priority, project, title, scheduled, due)"`
+	GroupBy       string `json:"group_by,omitempty" jsonschema:"grouping (project, assignee, tag, category)"`
+	ShowCompleted bool   `json:"show_completed" jsonschema:"whether completed tasks are included"`
+}
+
+type ListViewsResult struct {
+	Views []ViewInfo `json:"views" jsonschema:"saved views defined in config.toml"`
+}
+
+type RunViewArgs struct {
+	Name string `json:"name" jsonschema:"name of the saved view to run"`
+}
+
+type ViewGroupResult struct {
+	Name  string     `json:"name,omitempty" jsonschema:"group name (empty when the view is not grouped)"`
+	Tasks []TaskInfo `json:"tasks" jsonschema:"tasks in this group"`
+}
+
+type RunViewResult struct {
+	Name   string            `json:"name" jsonschema:"view name"`
+	Groups []ViewGroupResult `json:"groups" jsonschema:"task groups in display order"`
+	Count  int               `json:"count" jsonschema:"number of distinct tasks in the view"`
 }
 
 // MCPServer wraps the MCP server with task operations
@@ -315,7 +418,7 @@ func (s *MCPServer) registerTools() {
 	// Filter tasks
 	mcp.AddTool(s.server, &mcp.Tool{
 		Name:        "filter_tasks",
-		Description: "PREFERRED: Powerful task filtering with multiple criteria. Use '>> name' for assignee, '#tag' for tags, '@date' or '@s:date' for scheduled, '@d:date' for due dates, or plain text. Essential for focused task views.",
+		Description: "PREFERRED: Powerful task filtering with a boolean query language. Terms: '>>name' for assignee, '#tag' for tags, '@date' or '@s:date' for scheduled, '@d:date' for due dates (dates accept <, <=, >, >=, a..b, overdue), field terms project:, keyword:, category:, tag:, assignee:, id:, scheduled:, due:, priority:, prop:key=value, has:deps, has:estimate, over:budget, in:cycle, is:ready (active or in progress with no open dependencies), is:blocked, or plain text. Combine with AND, OR, NOT and parentheses, e.g. '#urgent AND >>alice AND @d:<2025-07-01 AND NOT project:infra'. Essential for focused task views.",
 	}, s.filterTasks)
 
 	// Update task status
@@ -345,7 +448,7 @@ func (s *MCPServer) registerTools() {
 	// Get task by ID
 	mcp.AddTool(s.server, &mcp.Tool{
 		Name:        "get_task_by_id",
-		Description: "PREFERRED: Get a task directly by its unique ID. Faster than searching by title when you know the task ID. Returns full task details including dependencies.",
+		Description: "PREFERRED: Get a task directly by its unique ID. Faster than searching by title when you know the task ID. Returns full task details including dependencies. If several tasks share the ID, they are listed in duplicates.",
 	}, s.getTaskByID)
 
 	// Get dependencies
@@ -360,6 +463,12 @@ func (s *MCPServer) registerTools() {
 		Description: "PREFERRED: Get all tasks that depend on a given task (tasks that reference it via ^id). Essential for understanding impact when completing or modifying a task.",
 	}, s.getDependents)
 
+	// Get ready tasks
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "get_ready_tasks",
+		Description: "PREFERRED: List the tasks that can be worked on now: active or in progress, with every ^id dependency completed. Blocked tasks are left out. Use this to pick the next task.",
+	}, s.getReadyTasks)
+
 	// Get cycle tasks
 	mcp.AddTool(s.server, &mcp.Tool{
 		Name:        "get_cycle_tasks",
@@ -369,9 +478,27 @@ func (s *MCPServer) registerTools() {
 	// Schedule task
 	mcp.AddTool(s.server, &mcp.Tool{
 		Name:        "schedule_task",
-		Description: "PREFERRED: Set, update, or remove scheduled/due dates on a task. Use scheduled_at/due_at to set dates (YYYY-MM-DD with optional time, recurrence, warning). Use remove_scheduled/remove_due to clear dates.",
+		Description: "PREFERRED: Set, update, or remove scheduled/due dates on a task. Use scheduled_at/due_at to set dates (YYYY-MM-DD with optional time, recurrence, warning, or words such as 'tomorrow 3pm' or 'every monday at 9'). Use remove_scheduled/remove_due to clear dates. Use dry_run to preview the resolved date tokens first.",
 	}, s.scheduleTask)
 
+	// Set property
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "set_property",
+		Description: "PREFERRED: Set or remove an arbitrary key/value property on a task, stored as a 'key:: value' sub-line under the task. Use an empty value to remove the property. Properties are returned by get_task and can be filtered with prop:key=value.",
+	}, s.setProperty)
+
+	// Edit task line
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "edit_task",
+		Description: "PREFERRED: Retitle a task, add or remove its tags, or set or remove its assignee. Only the changed tokens of the task line are rewritten; IDs, dates, references and other metadata stay as written. Use update_task_status, schedule_task and set_property for status, dates and properties.",
+	}, s.editTask)
+
+	// Refile task
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "refile_task",
+		Description: "PREFERRED: Move a task to another project, note or the inbox. The whole task block moves with it: nested child tasks, CLOCK/LOG lines and properties, re-indented for the destination. Never move tasks by editing files by hand.",
+	}, s.refileTask)
+
 	// Clock in
 	mcp.AddTool(s.server, &mcp.Tool{
 		Name:        "clock_in",
@@ -387,9 +514,21 @@ func (s *MCPServer) registerTools() {
 	// Get clock table
 	mcp.AddTool(s.server, &mcp.Tool{
 		Name:        "get_clock_table",
-		Description: "PREFERRED: Get time tracking data aggregated by project and task for a date range. Shows how time was spent.",
+		Description: "PREFERRED: Get time tracking data aggregated by project and task for a date range. Shows how time was spent, and for tasks with an effort estimate (~3h) the estimate, all time clocked so far and whether it is over budget.",
 	}, s.getClockTable)
 
+	// List saved views
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "list_views",
+		Description: "PREFERRED: List the saved task views defined in config.toml ([[views]]), with their filter, sort and grouping.",
+	}, s.listViews)
+
+	// Run a saved view
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        "run_view",
+		Description: "PREFERRED: Run a saved task view by name. Returns the matching tasks sorted and grouped as configured. Use list_views to discover view names.",
+	}, s.runView)
+
 	// Sync JIRA
 	mcp.AddTool(s.server, &mcp.Tool{
 		Name:        "sync_jira",
@@ -415,22 +554,28 @@ func (s *MCPServer) taskToInfo(t *Task) TaskInfo {
 	}
 
 	return TaskInfo{
-		Keyword:     t.Keyword,
-		ID:          t.ID,
-		Title:       t.Title,
-		Tags:        t.Tags,
-		References:  t.References,
-		ScheduledAt: t.ScheduledAt,
-		DueAt:       t.DueAt,
-		Assignee:    t.Assignee,
-		Project:     t.Project,
-		Zettel:      t.Zettel,
-		FilePath:    t.FilePath,
-		Priority:    t.Priority(s.config),
-		Status:      status,
-		InCycle:     t.InCycle,
-		ParentID:    parentID,
-		ChildCount:  len(t.Children),
+		Keyword:        t.Keyword,
+		ID:             t.ID,
+		Title:          t.Title,
+		Tags:           t.Tags,
+		References:     t.References,
+		ScheduledAt:    t.ScheduledAt,
+		DueAt:          t.DueAt,
+		Estimate:       t.Estimate,
+		OverBudget:     IsOverBudget(t),
+		Assignee:       t.Assignee,
+		Project:        t.Project,
+		Zettel:         t.Zettel,
+		Properties:     t.Properties,
+		FilePath:       t.FilePath,
+		Priority:       t.Priority(s.config),
+		PriorityCookie: t.PriorityCookie,
+		Status:         status,
+		InCycle:        t.InCycle,
+		DuplicateID:    t.DuplicateID,
+		Blocked:        t.Blocked,
+		ParentID:       parentID,
+		ChildCount:     len(t.Children),
 	}
 }
 
@@ -448,6 +593,9 @@ func (s *MCPServer) listTasks(ctx context.Context, req *mcp.CallToolRequest, arg
 	// Secondary sort by project, then title, then file path for deterministic order
 	sort.SliceStable(tasks, func(i, j int) bool {
 		if tasks[i].Priority(s.config) == tasks[j].Priority(s.config) {
+			if tasks[i].CookieRank() != tasks[j].CookieRank() {
+				return tasks[i].CookieRank() < tasks[j].CookieRank()
+			}
 			if tasks[i].Project != tasks[j].Project {
 				return tasks[i].Project < tasks[j].Project
 			}
@@ -521,13 +669,19 @@ func (s *MCPServer) filterTasks(ctx context.Context, req *mcp.CallToolRequest, a
 	// Detect circular dependencies before filtering
 	DetectCycles(tasks)
 
-	filtered := FilterTasks(tasks, args.Filter)
+	filtered, err := QueryTasks(s.config, tasks, args.Filter)
+	if err != nil {
+		return nil, FilterTasksResult{}, fmt.Errorf("invalid filter: %w", err)
+	}
 
 	// Sort by priority
 	SortByPriority(filtered, s.config)
 	// Secondary sort by project, then title, then file path for deterministic order
 	sort.SliceStable(filtered, func(i, j int) bool {
 		if filtered[i].Priority(s.config) == filtered[j].Priority(s.config) {
+			if filtered[i].CookieRank() != filtered[j].CookieRank() {
+				return filtered[i].CookieRank() < filtered[j].CookieRank()
+			}
 			if filtered[i].Project != filtered[j].Project {
 				return filtered[i].Project < filtered[j].Project
 			}
@@ -609,28 +763,41 @@ func (s *MCPServer) updateTaskStatus(ctx context.Context, req *mcp.CallToolReque
 		}
 	}
 
-	if err := UpdateTaskStatus(targetTask, args.NewKeyword, s.config); err != nil {
-		return nil, UpdateTaskStatusResult{
-			Success:       false,
-			Message:       fmt.Sprintf("failed to update task: %v", err),
-			ValidKeywords: validKeywords,
-		}, nil
-	}
-
-	// Record state transition
-	if err := RecordStateTransition(targetTask, oldKeyword, args.NewKeyword); err != nil {
+	// Journaled so the status and its LOG line are undone together
+	summary := fmt.Sprintf("Status %s -> %s: %s", oldKeyword, args.NewKeyword, targetTask.Title)
+	var unblocked []*Task
+	err = Journaled(targetTask, summary, func() error {
+		if err := UpdateTaskStatus(targetTask, args.NewKeyword, s.config); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+		// Record state transition
+		if err := RecordStateTransition(targetTask, oldKeyword, args.NewKeyword); err != nil {
+			return fmt.Errorf("status updated but failed to record transition: %w", err)
+		}
+		// Move dependents waiting on this task to the ready keyword
+		var err error
+		if unblocked, err = UnblockDependents(s.config, targetTask); err != nil {
+			return fmt.Errorf("status updated but failed to unblock dependents: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, UpdateTaskStatusResult{
 			Success:       false,
-			Message:       fmt.Sprintf("status updated but failed to record transition: %v", err),
+			Message:       err.Error(),
 			ValidKeywords: validKeywords,
 		}, nil
 	}
 
-	return nil, UpdateTaskStatusResult{
+	result := UpdateTaskStatusResult{
 		Success:       true,
 		Message:       fmt.Sprintf("Updated task status: %s → %s", oldKeyword, args.NewKeyword),
 		ValidKeywords: validKeywords,
-	}, nil
+	}
+	for _, t := range unblocked {
+		result.Unblocked = append(result.Unblocked, s.taskToInfo(t))
+	}
+	return nil, result, nil
 }
 
 func (s *MCPServer) getProjects(ctx context.Context, req *mcp.CallToolRequest, args GetProjectsArgs) (*mcp.CallToolResult, GetProjectsResult, error) {
@@ -712,14 +879,22 @@ func (s *MCPServer) getTaskByID(ctx context.Context, req *mcp.CallToolRequest, a
 
 	DetectCycles(tasks)
 
-	task := GetTaskByID(tasks, args.ID)
-	if task == nil {
+	matches := FindTasksByID(tasks, args.ID)
+	if len(matches) == 0 {
 		return nil, GetTaskByIDResult{Found: false}, nil
 	}
 
+	task := matches[0]
 	info := s.taskToInfo(task)
 	info.RawContent, _ = ReadRawBlock(task)
-	return nil, GetTaskByIDResult{Task: &info, Found: true}, nil
+	result := GetTaskByIDResult{Task: &info, Found: true}
+	if len(matches) > 1 {
+		for _, d := range matches[1:] {
+			result.Duplicates = append(result.Duplicates, s.taskToInfo(d))
+		}
+		result.Message = fmt.Sprintf("ID %q is used by %d tasks; returning the first. Fix with a unique [id] per task.", args.ID, len(matches))
+	}
+	return nil, result, nil
 }
 
 func (s *MCPServer) getDependencies(ctx context.Context, req *mcp.CallToolRequest, args GetDependenciesArgs) (*mcp.CallToolResult, GetDependenciesResult, error) {
@@ -784,6 +959,28 @@ func (s *MCPServer) getDependents(ctx context.Context, req *mcp.CallToolRequest,
 	}, nil
 }
 
+func (s *MCPServer) getReadyTasks(ctx context.Context, req *mcp.CallToolRequest, args GetReadyTasksArgs) (*mcp.CallToolResult, GetReadyTasksResult, error) {
+	tasks, err := ListTasks(s.config, args.Project, false)
+	if err != nil {
+		return nil, GetReadyTasksResult{}, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	DetectCycles(tasks)
+	SortByPriority(tasks, s.config)
+
+	readyTasks := []TaskInfo{}
+	for _, t := range tasks {
+		if IsReady(s.config, t) {
+			readyTasks = append(readyTasks, s.taskToInfo(t))
+		}
+	}
+
+	return nil, GetReadyTasksResult{
+		Tasks: readyTasks,
+		Count: len(readyTasks),
+	}, nil
+}
+
 func (s *MCPServer) getCycleTasks(ctx context.Context, req *mcp.CallToolRequest, args GetCycleTasksArgs) (*mcp.CallToolResult, GetCycleTasksResult, error) {
 	tasks, err := ListTasks(s.config, args.Project, true)
 	if err != nil {
@@ -806,6 +1003,23 @@ func (s *MCPServer) getCycleTasks(ctx context.Context, req *mcp.CallToolRequest,
 }
 
 func (s *MCPServer) scheduleTask(ctx context.Context, req *mcp.CallToolRequest, args ScheduleTaskArgs) (*mcp.CallToolResult, ScheduleTaskResult, error) {
+	// Dates in words are resolved to tokens first, and returned so the
+	// caller sees what was set
+	now := time.Now()
+	for _, date := range []*string{&args.ScheduledAt, &args.DueAt} {
+		if *date == "" {
+			continue
+		}
+		token, err := ParseNaturalDate(*date, now)
+		if err != nil {
+			return nil, ScheduleTaskResult{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+		*date = token
+	}
+
 	tasks, err := ListTasks(s.config, args.Project, true)
 	if err != nil {
 		return nil, ScheduleTaskResult{
@@ -829,11 +1043,13 @@ func (s *MCPServer) scheduleTask(ctx context.Context, req *mcp.CallToolRequest,
 		}, nil
 	}
 
-	if err := SetTaskDate(targetTask, args.ScheduledAt, args.DueAt, args.RemoveScheduled, args.RemoveDue); err != nil {
-		return nil, ScheduleTaskResult{
-			Success: false,
-			Message: fmt.Sprintf("failed to update dates: %v", err),
-		}, nil
+	if !args.DryRun {
+		if err := SetTaskDate(targetTask, args.ScheduledAt, args.DueAt, args.RemoveScheduled, args.RemoveDue); err != nil {
+			return nil, ScheduleTaskResult{
+				Success: false,
+				Message: fmt.Sprintf("failed to update dates: %v", err),
+			}, nil
+		}
 	}
 
 	var changes []string
@@ -850,9 +1066,145 @@ func (s *MCPServer) scheduleTask(ctx context.Context, req *mcp.CallToolRequest,
 		changes = append(changes, "due removed")
 	}
 
+	verb := "Updated"
+	if args.DryRun {
+		verb = "Would update (dry run)"
+	}
 	return nil, ScheduleTaskResult{
-		Success: true,
-		Message: fmt.Sprintf("Updated: %s", strings.Join(changes, ", ")),
+		Success:     true,
+		Message:     fmt.Sprintf("%s: %s", verb, strings.Join(changes, ", ")),
+		ScheduledAt: args.ScheduledAt,
+		DueAt:       args.DueAt,
+	}, nil
+}
+
+func (s *MCPServer) setProperty(ctx context.Context, req *mcp.CallToolRequest, args SetPropertyArgs) (*mcp.CallToolResult, SetPropertyResult, error) {
+	tasks, err := ListTasks(s.config, args.Project, true)
+	if err != nil {
+		return nil, SetPropertyResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to list tasks: %v", err),
+		}, nil
+	}
+
+	var targetTask *Task
+	for _, t := range tasks {
+		if t.Keyword == args.Keyword && (t.Title == args.Title || containsIgnoreCase(t.Title, args.Title)) {
+			targetTask = t
+			break
+		}
+	}
+
+	if targetTask == nil {
+		return nil, SetPropertyResult{
+			Success: false,
+			Message: fmt.Sprintf("task not found: %s: %s", args.Keyword, args.Title),
+		}, nil
+	}
+
+	if err := SetTaskProperty(targetTask, args.Key, args.Value); err != nil {
+		return nil, SetPropertyResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to set property: %v", err),
+		}, nil
+	}
+
+	if strings.TrimSpace(args.Value) == "" {
+		return nil, SetPropertyResult{Success: true, Message: fmt.Sprintf("Removed %s", args.Key)}, nil
+	}
+	return nil, SetPropertyResult{Success: true, Message: fmt.Sprintf("Set %s:: %s", args.Key, strings.TrimSpace(args.Value))}, nil
+}
+
+func (s *MCPServer) editTask(ctx context.Context, req *mcp.CallToolRequest, args EditTaskArgs) (*mcp.CallToolResult, EditTaskResult, error) {
+	edit := LineEdit{
+		Title:          args.NewTitle,
+		AddTags:        args.AddTags,
+		RemoveTags:     args.RemoveTags,
+		Assignee:       args.Assignee,
+		RemoveAssignee: args.RemoveAssignee,
+	}
+	if err := edit.Validate(); err != nil {
+		return nil, EditTaskResult{Success: false, Message: err.Error()}, nil
+	}
+
+	tasks, err := ListTasks(s.config, args.Project, true)
+	if err != nil {
+		return nil, EditTaskResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to list tasks: %v", err),
+		}, nil
+	}
+
+	var targetTask *Task
+	for _, t := range tasks {
+		if t.Keyword == args.Keyword && (t.Title == args.Title || containsIgnoreCase(t.Title, args.Title)) {
+			targetTask = t
+			break
+		}
+	}
+
+	if targetTask == nil {
+		return nil, EditTaskResult{
+			Success: false,
+			Message: fmt.Sprintf("task not found: %s: %s", args.Keyword, args.Title),
+		}, nil
+	}
+
+	if err := EditLine(targetTask, edit.Apply); err != nil {
+		return nil, EditTaskResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to edit task: %v", err),
+		}, nil
+	}
+
+	info := s.taskToInfo(targetTask)
+	return nil, EditTaskResult{Success: true, Message: fmt.Sprintf("Edited %s: %s", targetTask.Keyword, targetTask.Title), Task: &info}, nil
+}
+
+func (s *MCPServer) refileTask(ctx context.Context, req *mcp.CallToolRequest, args RefileTaskArgs) (*mcp.CallToolResult, RefileTaskResult, error) {
+	tasks, err := ListTasks(s.config, args.Project, true)
+	if err != nil {
+		return nil, RefileTaskResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to list tasks: %v", err),
+		}, nil
+	}
+
+	var targetTask *Task
+	for _, t := range tasks {
+		if t.Keyword == args.Keyword && (t.Title == args.Title || containsIgnoreCase(t.Title, args.Title)) {
+			targetTask = t
+			break
+		}
+	}
+
+	if targetTask == nil {
+		return nil, RefileTaskResult{
+			Success: false,
+			Message: fmt.Sprintf("task not found: %s: %s", args.Keyword, args.Title),
+		}, nil
+	}
+
+	dest, _, err := ResolveRefileTarget(s.config, args.Destination)
+	if err != nil {
+		return nil, RefileTaskResult{
+			Success: false,
+			Message: fmt.Sprintf("invalid destination: %v", err),
+		}, nil
+	}
+
+	if err := RefileTask(targetTask, dest, args.Position); err != nil {
+		return nil, RefileTaskResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to refile task: %v", err),
+		}, nil
+	}
+
+	return nil, RefileTaskResult{
+		Success:  true,
+		Message:  fmt.Sprintf("Refiled '%s' to %s", targetTask.Title, args.Destination),
+		FilePath: targetTask.FilePath,
+		LineNum:  targetTask.LineNum,
 	}, nil
 }
 
@@ -913,17 +1265,31 @@ func (s *MCPServer) getClockTable(ctx context.Context, req *mcp.CallToolRequest,
 	for _, p := range table.Projects {
 		var tasks []ClockTaskResult
 		for _, e := range p.Entries {
-			tasks = append(tasks, ClockTaskResult{
-				Keyword:  e.Task.Keyword,
-				Title:    e.Task.Title,
-				Duration: FormatDuration(e.Duration),
-			})
+			result := ClockTaskResult{
+				Keyword:   e.Task.Keyword,
+				Title:     e.Task.Title,
+				Duration:  FormatDuration(e.Duration),
+				Pomodoros: e.Pomodoros,
+			}
+			if e.Estimate > 0 {
+				result.Estimate = FormatDuration(e.Estimate)
+				result.Actual = FormatDuration(e.Actual)
+				result.OverBudget = e.OverBudget()
+			}
+			tasks = append(tasks, result)
 		}
-		projects = append(projects, ClockProjectResult{
-			Project: p.Project,
-			Total:   FormatDuration(p.Total),
-			Tasks:   tasks,
-		})
+		project := ClockProjectResult{
+			Project:   p.Project,
+			Total:     FormatDuration(p.Total),
+			Pomodoros: p.Pomodoros,
+			Tasks:     tasks,
+		}
+		if p.Estimate > 0 {
+			project.Estimate = FormatDuration(p.Estimate)
+			project.Actual = FormatDuration(p.Actual)
+			project.OverBudget = p.OverBudget()
+		}
+		projects = append(projects, project)
 	}
 
 	return nil, ClockTableResult{
@@ -970,6 +1336,45 @@ func equalIgnoreCase(a, b string) bool {
 	return true
 }
 
+func (s *MCPServer) listViews(ctx context.Context, req *mcp.CallToolRequest, args ListViewsArgs) (*mcp.CallToolResult, ListViewsResult, error) {
+	views := make([]ViewInfo, len(s.config.Views))
+	for i, v := range s.config.Views {
+		views[i] = ViewInfo{
+			Name:          v.Name,
+			Filter:        v.Filter,
+			Sort:          v.Sort,
+			GroupBy:       v.GroupBy,
+			ShowCompleted: v.ShowCompleted,
+		}
+	}
+	return nil, ListViewsResult{Views: views}, nil
+}
+
+func (s *MCPServer) runView(ctx context.Context, req *mcp.CallToolRequest, args RunViewArgs) (*mcp.CallToolResult, RunViewResult, error) {
+	v := s.config.FindView(args.Name)
+	if v == nil {
+		return nil, RunViewResult{}, fmt.Errorf("view not found: %s", args.Name)
+	}
+
+	groups, err := RunView(s.config, *v)
+	if err != nil {
+		return nil, RunViewResult{}, err
+	}
+
+	seen := make(map[*Task]bool)
+	result := RunViewResult{Name: v.Name, Groups: make([]ViewGroupResult, len(groups))}
+	for i, g := range groups {
+		infos := make([]TaskInfo, len(g.Tasks))
+		for j, t := range g.Tasks {
+			infos[j] = s.taskToInfo(t)
+			seen[t] = true
+		}
+		result.Groups[i] = ViewGroupResult{Name: g.Name, Tasks: infos}
+	}
+	result.Count = len(seen)
+	return nil, result, nil
+}
+
 type SyncJiraArgs struct{}
 type SyncJiraResult struct {
 	Message string `json:"message"`
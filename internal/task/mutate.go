@@ -0,0 +1,337 @@
+package task
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Task files are edited concurrently by the todo TUI, the MCP server (whose
+// JIRA sync runs on a ticker), CLI commands and the user's editor. Every
+// write goes through this file: writers hold an advisory lock on the file,
+// replace it atomically, and re-locate the task's line when the file changed
+// since the task was read, failing with a *ConflictError instead of writing
+// stale content.
+
+// ErrConflict is matched (via errors.Is) by every *ConflictError.
+var ErrConflict = errors.New("task file changed on disk")
+
+// ConflictError reports that a file changed underneath an edit: the task's
+// line could no longer be found, or the file was rewritten while the edit was
+// in progress. Nothing is written; reload the task and retry.
+type ConflictError struct {
+	Path   string
+	Task   string // "KEYWORD: title" of the task being edited, if any
+	Reason string
+}
+
+func (e *ConflictError) Error() string {
+	if e.Task == "" {
+		return fmt.Sprintf("%s: %s: %s", ErrConflict, e.Path, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", ErrConflict, e.Path, e.Task, e.Reason)
+}
+
+func (e *ConflictError) Unwrap() error { return ErrConflict }
+
+var (
+	fileLocksMu sync.Mutex
+	fileLocks   = make(map[string]*sync.Mutex)
+)
+
+// lockFiles takes the advisory locks for the given files, in a fixed order so
+// that two writers locking the same pair can't deadlock. Goroutines of this
+// process are serialised by a mutex per file, other karya processes by an OS
+// lock where the platform has one (see lockProcess). The returned function
+// releases every lock.
+func lockFiles(paths ...string) (func(), error) {
+	var keys []string
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, abs)
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	var releases []func()
+	unlock := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+	for _, key := range keys {
+		fileLocksMu.Lock()
+		mu, ok := fileLocks[key]
+		if !ok {
+			mu = &sync.Mutex{}
+			fileLocks[key] = mu
+		}
+		fileLocksMu.Unlock()
+
+		mu.Lock()
+		release, err := lockProcess(key)
+		if err != nil {
+			mu.Unlock()
+			unlock()
+			return nil, fmt.Errorf("failed to lock %s: %w", key, err)
+		}
+		releases = append(releases, func() {
+			release()
+			mu.Unlock()
+		})
+	}
+	return unlock, nil
+}
+
+// writeFileAtomic replaces path with data by writing a temporary file in the
+// same directory and renaming it over path, so readers never see a partial
+// write. The file's permissions are kept; new files get 0644. A symlinked
+// path is resolved so the link itself survives.
+func writeFileAtomic(path string, data []byte) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// writeIfUnchanged atomically writes data to path, provided the file still
+// hashes to the content it was read with. Editors don't take karya's lock, so
+// this is checked once more just before the rename.
+func writeIfUnchanged(path string, read, data []byte) error {
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if sha256.Sum256(current) != sha256.Sum256(read) {
+		return &ConflictError{Path: path, Reason: "file was modified while it was being edited"}
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writePairIfUnchanged writes two files like writeIfUnchanged, checking
+// both before writing either so that a conflict leaves them as they were.
+// first is written first; if writing second then fails, first is restored.
+func writePairIfUnchanged(first string, firstRead, firstData []byte, second string, secondRead, secondData []byte) error {
+	for _, f := range []struct {
+		path string
+		read []byte
+	}{{first, firstRead}, {second, secondRead}} {
+		current, err := os.ReadFile(f.path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if sha256.Sum256(current) != sha256.Sum256(f.read) {
+			return &ConflictError{Path: f.path, Reason: "file was modified while it was being edited"}
+		}
+	}
+	_, statErr := os.Stat(first)
+	if err := writeFileAtomic(first, firstData); err != nil {
+		return err
+	}
+	if err := writeIfUnchanged(second, secondRead, secondData); err != nil {
+		if os.IsNotExist(statErr) {
+			os.Remove(first)
+		} else {
+			writeFileAtomic(first, firstRead)
+		}
+		return err
+	}
+	return nil
+}
+
+// mutateFile applies edit to the content of path (nil if the file doesn't
+// exist) while holding the file's lock, atomically writes the result and
+// journals it under summary. Content returned unchanged is not written.
+func mutateFile(path, summary string, edit func(content []byte) ([]byte, error)) error {
+	unlock, err := lockFiles(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated, err := edit(content)
+	if err != nil {
+		return err
+	}
+	if string(updated) == string(content) {
+		return nil
+	}
+	if err := writeIfUnchanged(path, content, updated); err != nil {
+		return err
+	}
+	return recordEdit(nil, summary, path, content, updated)
+}
+
+// mutateTask edits the task's source file under its lock. edit receives the
+// file's lines and the index of the task's line (see locateTask) and returns
+// the new lines, which must keep the task line at the same index. The edit is
+// journaled under summary (see Journaled). On success the task's LineNum,
+// IndentLevel and file stamp describe the written file.
+func mutateTask(t *Task, summary string, edit func(lines []string, idx int) ([]string, error)) error {
+	if t.FilePath == "" {
+		return fmt.Errorf("task has no file path")
+	}
+	unlock, err := lockFiles(t.FilePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(t.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	idx, err := locateTask(lines, t, taskFileChanged(t))
+	if err != nil {
+		return err
+	}
+	newLines, err := edit(lines, idx)
+	if err != nil {
+		return err
+	}
+
+	if updated := strings.Join(newLines, "\n"); updated != string(content) {
+		err := writeIfUnchanged(t.FilePath, content, []byte(updated))
+		var conflict *ConflictError
+		if errors.As(err, &conflict) {
+			conflict.Task = t.Keyword + ": " + t.Title
+			return conflict
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		if err := recordEdit(t, summary, t.FilePath, content, []byte(updated)); err != nil {
+			return fmt.Errorf("failed to journal edit: %w", err)
+		}
+	}
+
+	t.LineNum = idx + 1
+	_, t.IndentLevel = StripLinePrefix(newLines[idx])
+	if info, err := os.Stat(t.FilePath); err == nil {
+		t.stamp = stampOf(info)
+	}
+	return nil
+}
+
+// taskFileChanged reports whether the task's file was modified since the
+// task was read. Tasks that weren't read from disk count as unchanged.
+func taskFileChanged(t *Task) bool {
+	if t.stamp == (fileStamp{}) {
+		return false
+	}
+	info, err := os.Stat(t.FilePath)
+	return err == nil && stampOf(info) != t.stamp
+}
+
+// locateTask returns the index of the task's line in lines. The recorded
+// line number is used while that line still is the task; otherwise the task
+// is looked up by its ID and then by keyword and title, preferring the match
+// closest to the recorded line. When the file changed since the task was
+// read, a task that is gone or matches several lines equally well is a
+// *ConflictError.
+func locateTask(lines []string, t *Task, changed bool) (int, error) {
+	if i := t.LineNum - 1; i >= 0 && i < len(lines) && isTaskLine(lines[i], t) {
+		return i, nil
+	}
+
+	conflict := func(reason string) error {
+		return &ConflictError{Path: t.FilePath, Task: t.Keyword + ": " + t.Title, Reason: reason}
+	}
+
+	matchers := []func(string) bool{
+		func(line string) bool { return isTaskLine(line, t) },
+	}
+	if t.ID != "" {
+		matchers = slices.Insert(matchers, 0, func(line string) bool { return lineTaskID(line) == t.ID })
+	}
+	for _, match := range matchers {
+		idx, ambiguous := nearestLine(lines, t.LineNum, match)
+		if idx < 0 {
+			continue
+		}
+		if ambiguous && changed {
+			return 0, conflict("task matches several lines after the file changed")
+		}
+		return idx, nil
+	}
+
+	if changed {
+		return 0, conflict("task line no longer exists after the file changed")
+	}
+	return 0, fmt.Errorf("task not found in file: %s: %s", t.Keyword, t.Title)
+}
+
+// nearestLine returns the index of the line matching match that is closest
+// to the 1-based lineNum, or -1. ambiguous is set when two matches are
+// equally close.
+func nearestLine(lines []string, lineNum int, match func(string) bool) (idx int, ambiguous bool) {
+	idx, best := -1, 0
+	for i, line := range lines {
+		if !match(line) {
+			continue
+		}
+		d := i + 1 - lineNum
+		if d < 0 {
+			d = -d
+		}
+		switch {
+		case idx < 0 || d < best:
+			idx, best, ambiguous = i, d, false
+		case d == best:
+			ambiguous = true
+		}
+	}
+	return idx, ambiguous
+}
+
+// lineTaskID returns the [id] of a task line, or "".
+func lineTaskID(line string) string {
+	if m, ok := ParseLineModel(line); ok {
+		return m.ID()
+	}
+	return ""
+}
@@ -0,0 +1,235 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMutateTask_Relocate(t *testing.T) {
+	original := "# Notes\n\nTODO: [t1] Write report\nTODO: Review\nTODO: Review\n"
+
+	tests := []struct {
+		name     string
+		external string // file content written after the tasks were read, "" for none
+		title    string // task to complete
+		want     string
+		wantLine int
+		conflict bool
+	}{
+		{
+			name:     "unchanged file",
+			title:    "Write report",
+			want:     "# Notes\n\nDONE: [t1] Write report\nTODO: Review\nTODO: Review\n",
+			wantLine: 3,
+		},
+		{
+			name:     "lines inserted above",
+			external: "# Notes\n\nIntro.\nMore intro.\n\nTODO: [t1] Write report\nTODO: Review\nTODO: Review\n",
+			title:    "Write report",
+			want:     "# Notes\n\nIntro.\nMore intro.\n\nDONE: [t1] Write report\nTODO: Review\nTODO: Review\n",
+			wantLine: 6,
+		},
+		{
+			name:     "found by ID after a keyword change",
+			external: "# Notes\n\nTODO: Review\nDOING: [t1] Write report\nTODO: Review\n",
+			title:    "Write report",
+			want:     "# Notes\n\nTODO: Review\nDONE: [t1] Write report\nTODO: Review\n",
+			wantLine: 4,
+		},
+		{
+			name:     "task removed",
+			external: "# Notes\n\nTODO: Review\nTODO: Review\n",
+			title:    "Write report",
+			conflict: true,
+		},
+		{
+			name:     "ambiguous after change",
+			external: "# Notes\n\nTODO: Review\nTODO: [t1] Write report\nTODO: Review\n",
+			title:    "Review",
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notes.md")
+			os.WriteFile(path, []byte(original), 0644)
+
+			cfg := createTestConfig()
+			tasks, err := readInboxFile(path, cfg)
+			if err != nil {
+				t.Fatalf("readInboxFile() error: %v", err)
+			}
+			var task *Task
+			for _, tk := range tasks {
+				if tk.Title == tt.title && (tt.title != "Review" || tk.LineNum == 4) {
+					task = tk
+					break
+				}
+			}
+
+			if tt.external != "" {
+				os.WriteFile(path, []byte(tt.external), 0644)
+				// Make sure the stamp differs even on coarse-grained clocks
+				later := time.Now().Add(time.Hour)
+				os.Chtimes(path, later, later)
+			}
+
+			err = UpdateTaskStatus(task, "DONE", cfg)
+			got, _ := os.ReadFile(path)
+			if tt.conflict {
+				var conflict *ConflictError
+				if !errors.As(err, &conflict) || !errors.Is(err, ErrConflict) {
+					t.Fatalf("UpdateTaskStatus() error = %v, want a *ConflictError", err)
+				}
+				if string(got) != tt.external {
+					t.Errorf("file written despite conflict: %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateTaskStatus() error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+			if task.LineNum != tt.wantLine {
+				t.Errorf("LineNum = %d, want %d", task.LineNum, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestMutateTask_SequentialEdits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	os.WriteFile(path, []byte("TODO: Write report\n"), 0644)
+
+	cfg := createTestConfig()
+	tasks, _ := readInboxFile(path, cfg)
+	task := tasks[0]
+
+	// Each write refreshes the task's stamp, so later edits see no conflict
+	if err := SetTaskPriority(task, "A"); err != nil {
+		t.Fatalf("SetTaskPriority() error: %v", err)
+	}
+	if err := SetTaskProperty(task, "owner", "alice"); err != nil {
+		t.Fatalf("SetTaskProperty() error: %v", err)
+	}
+	if err := SetTaskDate(task, "", "2025-07-01", false, false); err != nil {
+		t.Fatalf("SetTaskDate() error: %v", err)
+	}
+	want := "TODO: [#A] Write report @d:2025-07-01\n  - owner:: alice\n"
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
+
+func TestMutateTask_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	os.WriteFile(path, []byte("TODO: Write report\n"), 0644)
+
+	cfg := createTestConfig()
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		tasks, _ := readInboxFile(path, cfg)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- SetTaskProperty(tasks[0], fmt.Sprintf("key%d", i), "v")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("SetTaskProperty() error: %v", err)
+		}
+	}
+
+	got, _ := os.ReadFile(path)
+	if n := strings.Count(string(got), ":: v"); n != writers {
+		t.Errorf("%d of %d properties survived:\n%s", n, writers, got)
+	}
+}
+
+func TestWriteIfUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	os.WriteFile(path, []byte("TODO: Edited elsewhere\n"), 0600)
+
+	err := writeIfUnchanged(path, []byte("TODO: Original\n"), []byte("DONE: Original\n"))
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("writeIfUnchanged() error = %v, want ErrConflict", err)
+	}
+
+	if err := writeIfUnchanged(path, []byte("TODO: Edited elsewhere\n"), []byte("DONE: Edited elsewhere\n")); err != nil {
+		t.Fatalf("writeIfUnchanged() error: %v", err)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600 kept", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("temp file left behind: %v", entries)
+	}
+}
+
+func TestWritePairIfUnchanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		dest     string // destination content, "" for a new file
+		external string // source content written after it was read, "" for none
+		conflict bool
+	}{
+		{name: "unchanged", dest: "TODO: Existing\n"},
+		{name: "new destination"},
+		{name: "source changed", dest: "TODO: Existing\n", external: "TODO: Moved\nTODO: Added elsewhere\n", conflict: true},
+		{name: "source changed, new destination", external: "TODO: Moved\nTODO: Added elsewhere\n", conflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src, dest := filepath.Join(dir, "src.md"), filepath.Join(dir, "dest.md")
+			srcRead := []byte("TODO: Moved\n")
+			os.WriteFile(src, srcRead, 0644)
+			var destRead []byte
+			if tt.dest != "" {
+				destRead = []byte(tt.dest)
+				os.WriteFile(dest, destRead, 0644)
+			}
+			if tt.external != "" {
+				os.WriteFile(src, []byte(tt.external), 0644)
+			}
+
+			err := writePairIfUnchanged(dest, destRead, []byte(tt.dest+"TODO: Moved\n"), src, srcRead, nil)
+			gotSrc, _ := os.ReadFile(src)
+			gotDest, destErr := os.ReadFile(dest)
+			if tt.conflict {
+				if !errors.Is(err, ErrConflict) {
+					t.Fatalf("writePairIfUnchanged() error = %v, want ErrConflict", err)
+				}
+				if string(gotSrc) != tt.external {
+					t.Errorf("source = %q, want %q", gotSrc, tt.external)
+				}
+				if tt.dest == "" && !os.IsNotExist(destErr) || string(gotDest) != tt.dest {
+					t.Errorf("destination written despite conflict: %q", gotDest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("writePairIfUnchanged() error: %v", err)
+			}
+			if len(gotSrc) != 0 || string(gotDest) != tt.dest+"TODO: Moved\n" {
+				t.Errorf("source = %q, destination = %q", gotSrc, gotDest)
+			}
+		})
+	}
+}
@@ -0,0 +1,768 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ParseNaturalDate turns a date written in words, relative to now, into a
+// date token (see ParseSchedule). It understands:
+//
+//   - days: today, tomorrow, fri, this fri, next fri (in next week), next
+//     week/month/year (their first day), end of week/month/year, in 2 weeks,
+//     in 3 business days, +3bd, -2d, jul 4, 4th july 2026, the 15th, 2nd
+//     business day, last fri of the month and 2025-07-04
+//   - times: 3pm, 3:30pm, 15:00, at 9, noon, and ranges such as 9-10am or
+//     3pm to 4:30pm; a range past midnight ends the next day
+//   - repeats: daily, weekly, monthly, yearly, weekdays, every 2 weeks,
+//     every other day, every mon and thu, every 2 weeks on fri, every last
+//     fri, every 15th, every month on the last business day; ending 10
+//     times or until a day
+//   - warnings: warn 3 days, !3d
+//
+// A repeat on certain days starts on the first of them from today. A time
+// gets the configured zone (see UseTimeZone); without a day, one already
+// past today is tomorrow's. Input that already is a valid
+// token is returned as it is.
+func ParseNaturalDate(input string, now time.Time) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("empty date")
+	}
+	// ParseSchedule ignores what follows a date, so words after one
+	// aren't a token
+	if !strings.ContainsFunc(input, isDateSeparator) {
+		if _, err := ParseSchedule(input); err == nil {
+			return input, nil
+		}
+	}
+
+	p := &naturalParser{
+		words: strings.FieldsFunc(strings.ToLower(input), isDateSeparator),
+		now:   now,
+		today: truncateToDay(now.In(time.Local)),
+	}
+	if err := p.parse(); err != nil {
+		return "", fmt.Errorf("can't read date %q: %w", input, err)
+	}
+	token := p.token()
+	if _, err := ParseSchedule(token); err != nil {
+		return "", fmt.Errorf("can't read date %q: %w", input, err)
+	}
+	return token, nil
+}
+
+// isDateSeparator reports whether r separates the words of a natural date.
+func isDateSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == ','
+}
+
+// naturalParser reads the words of a natural date, one clause at a time.
+type naturalParser struct {
+	words []string
+	pos   int
+	now   time.Time
+	today time.Time
+
+	date               time.Time // zero until a day is given
+	hasTime, hasEnd    bool
+	hour, minute       int
+	endHour, endMinute int
+	rec                *RecurrenceSpec
+	count              int
+	until              time.Time
+	warning            int
+	dayRule            *MonthDay // the day was given as one, such as the 2nd business day
+}
+
+var (
+	isoDateRe     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	relativeRe    = regexp.MustCompile(`^([+-])(\d+)(d|w|m|y|bd)$`)
+	clockRe       = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a|p)?$`)
+	ordinalRe     = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)$`)
+	warningWordRe = regexp.MustCompile(`^!?(\d+)d$`)
+	countWordRe   = regexp.MustCompile(`^x(\d+)$`)
+)
+
+// naturalNumbers are the numbers that may be written as words.
+var naturalNumbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "twelve": 12,
+}
+
+// naturalOrdinals are the ordinals that may be written as words; last
+// counts from the end of the month.
+var naturalOrdinals = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1,
+}
+
+func (p *naturalParser) peek(i int) string {
+	if p.pos+i < len(p.words) {
+		return p.words[p.pos+i]
+	}
+	return ""
+}
+
+func (p *naturalParser) parse() error {
+	clauses := []func() (bool, error){p.repeatClause, p.limitClause, p.warningClause, p.timeClause, p.dateClause}
+	for p.pos < len(p.words) {
+		switch p.peek(0) {
+		case "on", "the", "and", "from", "starting", "due", "by":
+			p.pos++
+			continue
+		}
+		matched := false
+		for _, clause := range clauses {
+			ok, err := clause()
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("don't know %q", p.peek(0))
+		}
+	}
+	if (p.count > 0 || !p.until.IsZero()) && p.rec == nil {
+		return fmt.Errorf("an end needs a repeat such as \"every week\"")
+	}
+	// every month on the last business day: the day is the repeat's
+	if r := p.rec; p.dayRule != nil && r != nil && r.Unit == 'm' && !r.hasDays() {
+		r.MonthDays = []MonthDay{*p.dayRule}
+		p.date = time.Time{}
+	}
+	return nil
+}
+
+// token builds the date token of everything read.
+func (p *naturalParser) token() string {
+	date := p.date
+	if date.IsZero() {
+		// A time already past today is tomorrow's
+		from := p.today
+		if p.hasTime {
+			loc := time.Local
+			if writeZone != nil {
+				loc = writeZone
+			}
+			if time.Date(from.Year(), from.Month(), from.Day(), p.hour, p.minute, 0, 0, loc).Before(p.now) {
+				from = from.AddDate(0, 0, 1)
+			}
+		}
+		date = from
+		if p.rec != nil && p.rec.hasDays() {
+			for d := from; d.Before(from.AddDate(1, 0, 7)); d = d.AddDate(0, 0, 1) {
+				if p.rec.matches(d) {
+					date = d
+					break
+				}
+			}
+		}
+	}
+
+	s := &Schedule{Date: date, Recurrence: p.rec}
+	if r := p.rec; r != nil {
+		r.Count, r.Until = p.count, p.until
+		// A single day the date is on already is just the plain repeat
+		if r.Unit == 'w' && len(r.Weekdays) == 1 && date.Weekday() == r.Weekdays[0] {
+			r.Weekdays = nil
+		}
+		if r.Unit == 'm' && len(r.MonthDays) == 1 && r.MonthDays[0].Kind == MonthDayDate && r.MonthDays[0].N == date.Day() {
+			r.MonthDays = nil
+		}
+	}
+	if p.hasTime {
+		s.Zone = writeZone
+		loc := s.loc()
+		s.HasTime = true
+		s.Date = time.Date(date.Year(), date.Month(), date.Day(), p.hour, p.minute, 0, 0, loc)
+		if p.hasEnd {
+			end := time.Date(date.Year(), date.Month(), date.Day(), p.endHour, p.endMinute, 0, 0, loc)
+			if end.After(s.Date) {
+				s.HasEnd, s.EndTime = true, end
+			} else {
+				s.EndDate = end.AddDate(0, 0, 1)
+			}
+		}
+	}
+	if p.warning > 0 {
+		s.Warning = &WarningSpec{Days: p.warning}
+	}
+	return s.FormatToken()
+}
+
+// dateClause reads a day.
+func (p *naturalParser) dateClause() (bool, error) {
+	d, ok, err := p.readRuleDate()
+	if !ok && err == nil {
+		d, ok, err = p.readDate()
+	}
+	if !ok || err != nil {
+		return ok, err
+	}
+	if !p.date.IsZero() {
+		return false, fmt.Errorf("more than one day given")
+	}
+	p.date = d
+	return true, nil
+}
+
+// readRuleDate reads the next 2nd business day or, followed by "of the
+// month", the next last fri. "of every month" makes it a monthly repeat.
+func (p *naturalParser) readRuleDate() (time.Time, bool, error) {
+	start := p.pos
+	md, of, ok, err := p.readMonthDay()
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+	if of == "" && md.Kind != MonthDayBusiness {
+		p.pos = start
+		return time.Time{}, false, nil
+	}
+	if of == "each" || of == "every" {
+		if p.rec != nil {
+			return time.Time{}, false, fmt.Errorf("more than one repeat given")
+		}
+		p.rec = &RecurrenceSpec{Interval: 1, Unit: 'm'}
+	}
+	// Every such day turns up within two months
+	for d := p.today; d.Before(p.today.AddDate(0, 2, 0)); d = d.AddDate(0, 0, 1) {
+		if md.matches(d) {
+			p.dayRule = &md
+			return d, true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("no month has a %s", strings.Join(p.words[start:p.pos], " "))
+}
+
+// readDate reads a day at the current word.
+func (p *naturalParser) readDate() (time.Time, bool, error) {
+	w := p.peek(0)
+	today := p.today
+	consume := func(n int, d time.Time) (time.Time, bool, error) {
+		p.pos += n
+		return d, true, nil
+	}
+
+	switch w {
+	case "today", "tonight":
+		return consume(1, today)
+	case "tomorrow", "tmrw":
+		return consume(1, today.AddDate(0, 0, 1))
+	case "yesterday":
+		return consume(1, today.AddDate(0, 0, -1))
+	case "eow":
+		return consume(1, endOfPeriod(today, "week"))
+	case "eom":
+		return consume(1, endOfPeriod(today, "month"))
+	case "eoy":
+		return consume(1, endOfPeriod(today, "year"))
+	case "end":
+		if p.peek(1) == "of" {
+			if d := endOfPeriod(today, p.peek(2)); !d.IsZero() {
+				return consume(3, d)
+			}
+		}
+		return time.Time{}, false, fmt.Errorf("want end of week, month or year")
+	case "this":
+		if wd, ok := naturalWeekday(p.peek(1)); ok {
+			return consume(2, onOrAfter(today, wd))
+		}
+		if p.peek(1) == "weekend" {
+			return consume(2, onOrAfter(today, time.Saturday))
+		}
+		return time.Time{}, false, fmt.Errorf("want a weekday after %q", w)
+	case "next":
+		monday := today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7)
+		if wd, ok := naturalWeekday(p.peek(1)); ok {
+			return consume(2, monday.AddDate(0, 0, (int(wd)+6)%7))
+		}
+		switch p.peek(1) {
+		case "week":
+			return consume(2, monday)
+		case "weekend":
+			return consume(2, monday.AddDate(0, 0, 5))
+		case "month":
+			return consume(2, time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, time.Local))
+		case "year":
+			return consume(2, time.Date(today.Year()+1, 1, 1, 0, 0, 0, 0, time.Local))
+		}
+		return time.Time{}, false, fmt.Errorf("want a weekday, week, month or year after %q", w)
+	case "in":
+		n, ok := naturalNumber(p.peek(1))
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("want a number after %q", w)
+		}
+		unit, k, ok := naturalUnit(p.words[min(p.pos+2, len(p.words)):])
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("want days, weeks, months or years after %q", w+" "+p.peek(1))
+		}
+		return consume(2+k, addInterval(today, n, unit))
+	}
+
+	if isoDateRe.MatchString(w) {
+		d, err := time.ParseInLocation("2006-01-02", w, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", w)
+		}
+		return consume(1, d)
+	}
+	if m := relativeRe.FindStringSubmatch(w); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		if m[1] == "-" {
+			if m[3] == "bd" {
+				return time.Time{}, false, fmt.Errorf("business days only count forward")
+			}
+			n = -n
+		}
+		unit := m[3][0]
+		if m[3] == "bd" {
+			unit = 'b'
+		}
+		return consume(1, addInterval(today, n, unit))
+	}
+	if wd, ok := naturalWeekday(w); ok {
+		return consume(1, onOrAfter(today, wd))
+	}
+
+	// jul 4 [2026], 4 jul [2026], 4th of july, the 15th
+	if month, ok := naturalMonth(w); ok {
+		if day, ok := naturalDay(p.peek(1)); ok {
+			return p.monthDay(2, month, day)
+		}
+		return time.Time{}, false, fmt.Errorf("want a day after %q", w)
+	}
+	if day, ok := naturalDay(w); ok {
+		next := 1
+		if p.peek(next) == "of" {
+			next++
+		}
+		if month, ok := naturalMonth(p.peek(next)); ok {
+			return p.monthDay(next+1, month, day)
+		}
+		if ordinalRe.MatchString(w) {
+			// The next month that has the day, from this one
+			for m := 0; ; m++ {
+				d := time.Date(today.Year(), today.Month()+time.Month(m), day, 0, 0, 0, 0, time.Local)
+				if d.Day() == day && !d.Before(today) {
+					return consume(1, d)
+				}
+			}
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// monthDay returns day of month, after n words, in the year that follows
+// them or else its next occurrence from today.
+func (p *naturalParser) monthDay(n int, month time.Month, day int) (time.Time, bool, error) {
+	year := p.today.Year()
+	explicit := false
+	if y, err := strconv.Atoi(p.peek(n)); err == nil && len(p.peek(n)) == 4 {
+		year, explicit = y, true
+		n++
+	}
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	if d.Day() != day {
+		return time.Time{}, false, fmt.Errorf("%s has no day %d", month, day)
+	}
+	if !explicit && d.Before(p.today) {
+		d = d.AddDate(1, 0, 0)
+	}
+	p.pos += n
+	return d, true, nil
+}
+
+// timeClause reads a time or time range, optionally after "at".
+func (p *naturalParser) timeClause() (bool, error) {
+	at := p.peek(0) == "at" || p.peek(0) == "@"
+	n := 0
+	if at {
+		n = 1
+	}
+	w := p.peek(n)
+
+	var sh, sm, eh, em int
+	var hasEnd, ok bool
+	if w == "noon" || w == "midday" {
+		sh, ok = 12, true
+	} else if from, to, found := strings.Cut(w, "-"); found {
+		eh, em, ok = parseClock(to, "", at)
+		if ok {
+			sh, sm, ok = parseClock(from, clockMeridiem(to), true)
+			hasEnd = true
+		}
+	} else {
+		sh, sm, ok = parseClock(w, "", at)
+	}
+	if !ok {
+		if at {
+			return false, fmt.Errorf("want a time after %q", p.peek(0))
+		}
+		return false, nil
+	}
+	n++
+
+	// 3pm to 4pm, 3pm - 4pm
+	if !hasEnd && (p.peek(n) == "to" || p.peek(n) == "-") {
+		if eh, em, ok = parseClock(p.peek(n+1), "", true); !ok {
+			return false, fmt.Errorf("want an end time after %q", p.peek(n))
+		}
+		hasEnd = true
+		n += 2
+	}
+	if p.hasTime {
+		return false, fmt.Errorf("more than one time given")
+	}
+	p.hasTime, p.hour, p.minute = true, sh, sm
+	p.hasEnd, p.endHour, p.endMinute = hasEnd, eh, em
+	p.pos += n
+	return true, nil
+}
+
+// parseClock parses 3pm, 3:30pm or 15:00; a bare hour (9) only when bare
+// is set. A time without am or pm takes the given meridiem.
+func parseClock(s, meridiem string, bare bool) (hour, minute int, ok bool) {
+	m := clockRe.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[3] == "" && !bare) {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		meridiem = m[3][:1]
+	}
+	switch {
+	case meridiem != "" && (hour < 1 || hour > 12):
+		return 0, 0, false
+	case meridiem == "a" && hour == 12:
+		hour = 0
+	case meridiem == "p" && hour < 12:
+		hour += 12
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// clockMeridiem returns "a" or "p" when a time says am or pm.
+func clockMeridiem(s string) string {
+	if m := clockRe.FindStringSubmatch(s); m != nil && m[3] != "" {
+		return m[3][:1]
+	}
+	return ""
+}
+
+// repeatClause reads a repeat: daily, weekly, monthly, yearly, weekdays,
+// or "every" followed by a period or days.
+func (p *naturalParser) repeatClause() (bool, error) {
+	w := p.peek(0)
+	var r *RecurrenceSpec
+	switch w {
+	case "daily":
+		r = &RecurrenceSpec{Interval: 1, Unit: 'd'}
+	case "weekly":
+		r = &RecurrenceSpec{Interval: 1, Unit: 'w'}
+	case "monthly":
+		r = &RecurrenceSpec{Interval: 1, Unit: 'm'}
+	case "yearly", "annually":
+		r = &RecurrenceSpec{Interval: 1, Unit: 'y'}
+	case "weekdays":
+		r = &RecurrenceSpec{Interval: 1, Unit: 'b'}
+	case "every", "each":
+		p.pos++
+		var err error
+		if r, err = p.readEvery(); err != nil {
+			return false, err
+		}
+		p.pos--
+	default:
+		return false, nil
+	}
+	if p.rec != nil {
+		return false, fmt.Errorf("more than one repeat given")
+	}
+	p.rec = r
+	p.pos++
+	return true, nil
+}
+
+// readEvery reads what follows "every": [N|other] period [on DAYS], DAYS,
+// an Nth weekday (last fri), an Nth business day (first business day) or a
+// day of the month (15th), the last three optionally "of the month".
+func (p *naturalParser) readEvery() (*RecurrenceSpec, error) {
+	r := &RecurrenceSpec{Interval: 1}
+	if p.peek(0) == "other" {
+		r.Interval = 2
+		p.pos++
+	} else if n, ok := naturalNumber(p.peek(0)); ok {
+		if _, _, unit := naturalUnit(p.words[min(p.pos+1, len(p.words)):]); unit {
+			r.Interval = n
+			p.pos++
+		}
+	}
+
+	if unit, k, ok := naturalUnit(p.words[p.pos:]); ok {
+		r.Unit = unit
+		p.pos += k
+		if unit == 'w' {
+			next := 0
+			if p.peek(0) == "on" {
+				next = 1
+			}
+			if _, ok := naturalWeekday(p.peek(next)); ok {
+				p.pos += next
+				r.Weekdays = p.readWeekdays()
+			}
+		}
+		return r, nil
+	}
+	if _, ok := naturalWeekday(p.peek(0)); ok {
+		r.Unit, r.Weekdays = 'w', p.readWeekdays()
+		return r, nil
+	}
+	md, _, ok, err := p.readMonthDay()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		r.Unit, r.MonthDays = 'm', []MonthDay{md}
+		return r, nil
+	}
+	if n, ok := naturalOrdinal(p.peek(0)); ok {
+		if p.peek(1) == "day" {
+			p.pos++
+		}
+		r.Unit, r.MonthDays = 'm', []MonthDay{{Kind: MonthDayDate, N: n}}
+		p.pos++
+		p.readOfMonth()
+		return r, nil
+	}
+	return nil, fmt.Errorf("want a period or days after \"every\"")
+}
+
+// readMonthDay reads an Nth weekday (last fri) or Nth business day (2nd
+// business day) and any "of the month" after it, returning the word
+// before "month" ("the", "each", "every") or "" without one.
+func (p *naturalParser) readMonthDay() (md MonthDay, of string, ok bool, err error) {
+	start := p.pos
+	n, ok := naturalOrdinal(p.peek(0))
+	if !ok {
+		return MonthDay{}, "", false, nil
+	}
+	if wd, ok := naturalWeekday(p.peek(1)); ok {
+		md = MonthDay{Kind: MonthDayWeekday, N: n, Weekday: wd}
+		p.pos += 2
+	} else if unit, k, ok := naturalUnit(p.words[min(p.pos+1, len(p.words)):]); ok && unit == 'b' {
+		md = MonthDay{Kind: MonthDayBusiness, N: n}
+		p.pos += 1 + k
+	} else {
+		return MonthDay{}, "", false, nil
+	}
+	if _, err := parseMonthDay(md.String()); err != nil {
+		return MonthDay{}, "", false, fmt.Errorf("no month has a %s", strings.Join(p.words[start:p.pos], " "))
+	}
+	return md, p.readOfMonth(), true, nil
+}
+
+// readOfMonth reads "of the month", "of each month" or "of every month",
+// returning the word before "month", or "" when there is none.
+func (p *naturalParser) readOfMonth() string {
+	if p.peek(0) != "of" {
+		return ""
+	}
+	switch w := p.peek(1); w {
+	case "the", "each", "every":
+		if p.peek(2) == "month" {
+			p.pos += 3
+			return w
+		}
+	}
+	return ""
+}
+
+// readWeekdays reads a list of weekdays: mon, wed and fri.
+func (p *naturalParser) readWeekdays() []time.Weekday {
+	var days []time.Weekday
+	for {
+		if p.peek(0) == "and" {
+			if _, ok := naturalWeekday(p.peek(1)); ok {
+				p.pos++
+			}
+		}
+		wd, ok := naturalWeekday(p.peek(0))
+		if !ok {
+			return days
+		}
+		days = append(days, wd)
+		p.pos++
+	}
+}
+
+// limitClause reads the end of a repeat: "until DAY", "10 times" or "x10".
+func (p *naturalParser) limitClause() (bool, error) {
+	w := p.peek(0)
+	switch {
+	case w == "until" || w == "till":
+		p.pos++
+		d, ok, err := p.readDate()
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, fmt.Errorf("want a day after %q", w)
+		}
+		p.until = d
+		return true, nil
+	case countWordRe.MatchString(w):
+		p.count, _ = strconv.Atoi(w[1:])
+		p.pos++
+		return true, nil
+	case p.peek(1) == "times":
+		n, ok := naturalNumber(w)
+		if !ok {
+			return false, nil
+		}
+		p.count = n
+		p.pos += 2
+		return true, nil
+	}
+	return false, nil
+}
+
+// warningClause reads "warn 3 days [before]", "warn 3d" or "!3d".
+func (p *naturalParser) warningClause() (bool, error) {
+	w := p.peek(0)
+	if strings.HasPrefix(w, "!") {
+		m := warningWordRe.FindStringSubmatch(w)
+		if m == nil {
+			return false, fmt.Errorf("want a warning such as !3d")
+		}
+		p.warning, _ = strconv.Atoi(m[1])
+		p.pos++
+		return true, nil
+	}
+	if w != "warn" && w != "warning" {
+		return false, nil
+	}
+	if m := warningWordRe.FindStringSubmatch(p.peek(1)); m != nil {
+		p.warning, _ = strconv.Atoi(m[1])
+		p.pos += 2
+	} else if n, ok := naturalNumber(p.peek(1)); ok && (p.peek(2) == "day" || p.peek(2) == "days") {
+		p.warning = n
+		p.pos += 3
+	} else {
+		return false, fmt.Errorf("want a number of days after %q", w)
+	}
+	switch p.peek(0) {
+	case "before", "ahead", "early":
+		p.pos++
+	}
+	return true, nil
+}
+
+// naturalUnit reads a period at the start of words, returning its unit
+// and the number of words it takes.
+func naturalUnit(words []string) (unit byte, n int, ok bool) {
+	if len(words) == 0 {
+		return 0, 0, false
+	}
+	switch words[0] {
+	case "day", "days", "d":
+		return 'd', 1, true
+	case "week", "weeks", "wk", "wks", "w":
+		return 'w', 1, true
+	case "month", "months", "mo", "m":
+		return 'm', 1, true
+	case "year", "years", "yr", "yrs", "y":
+		return 'y', 1, true
+	case "weekday", "weekdays", "bd", "bds":
+		return 'b', 1, true
+	case "business", "working", "work":
+		if len(words) > 1 && (words[1] == "day" || words[1] == "days") {
+			return 'b', 2, true
+		}
+	}
+	return 0, 0, false
+}
+
+func naturalNumber(w string) (int, bool) {
+	if n, ok := naturalNumbers[w]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(w)
+	return n, err == nil && n > 0
+}
+
+// naturalOrdinal reads 1st, 2nd, ..., first, ..., fifth or last.
+func naturalOrdinal(w string) (int, bool) {
+	if n, ok := naturalOrdinals[w]; ok {
+		return n, true
+	}
+	if m := ordinalRe.FindStringSubmatch(w); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n, n >= 1 && n <= 31
+	}
+	return 0, false
+}
+
+// naturalDay reads a day of the month: 4 or 4th.
+func naturalDay(w string) (int, bool) {
+	if m := ordinalRe.FindStringSubmatch(w); m != nil {
+		w = m[1]
+	}
+	n, err := strconv.Atoi(w)
+	return n, err == nil && len(w) <= 2 && n >= 1 && n <= 31
+}
+
+// naturalWeekday reads a weekday by its name, its plural or a prefix of at
+// least three letters: mon, tues, thursday, fridays.
+func naturalWeekday(w string) (time.Weekday, bool) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if w == name+"s" || len(w) >= 3 && strings.HasPrefix(name, w) {
+			return wd, true
+		}
+	}
+	return 0, false
+}
+
+// naturalMonth reads a month by its name or a prefix of at least three
+// letters: jul, sept, december.
+func naturalMonth(w string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		if len(w) >= 3 && strings.HasPrefix(strings.ToLower(m.String()), w) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// onOrAfter returns the first day from d that is a wd.
+func onOrAfter(d time.Time, wd time.Weekday) time.Time {
+	return d.AddDate(0, 0, (int(wd)-int(d.Weekday())+7)%7)
+}
+
+// endOfPeriod returns the last day of d's week (Sunday), month or year, or
+// the zero time for another period.
+func endOfPeriod(d time.Time, period string) time.Time {
+	switch period {
+	case "week":
+		return onOrAfter(d, time.Sunday)
+	case "month":
+		return time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.Local)
+	case "year":
+		return time.Date(d.Year(), 12, 31, 0, 0, 0, 0, time.Local)
+	}
+	return time.Time{}
+}
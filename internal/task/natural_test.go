@@ -0,0 +1,133 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNaturalDate(t *testing.T) {
+	withZones(t, "", "")
+	now := time.Date(2025, 7, 2, 16, 30, 0, 0, time.Local) // a Wednesday
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		// Tokens pass through
+		{"2025-07-04T14:00+1w!3d", "2025-07-04T14:00+1w!3d", false},
+
+		// Days
+		{"today", "2025-07-02", false},
+		{"Tomorrow", "2025-07-03", false},
+		{"yesterday", "2025-07-01", false},
+		{"fri", "2025-07-04", false},
+		{"wed", "2025-07-02", false},
+		{"this friday", "2025-07-04", false},
+		{"next fri", "2025-07-11", false},
+		{"next mon", "2025-07-07", false},
+		{"next week", "2025-07-07", false},
+		{"next month", "2025-08-01", false},
+		{"next year", "2026-01-01", false},
+		{"in 2 weeks", "2025-07-16", false},
+		{"in a month", "2025-08-02", false},
+		{"in 3 business days", "2025-07-07", false},
+		{"+3bd", "2025-07-07", false},
+		{"+2w", "2025-07-16", false},
+		{"-2d", "2025-06-30", false},
+		{"end of week", "2025-07-06", false},
+		{"end of month", "2025-07-31", false},
+		{"eoy", "2025-12-31", false},
+		{"jul 4", "2025-07-04", false},
+		{"4th of july", "2025-07-04", false},
+		{"june 1", "2026-06-01", false},
+		{"1 june 2025", "2025-06-01", false},
+		{"the 15th", "2025-07-15", false},
+		{"1st", "2025-08-01", false},
+		{"31st", "2025-07-31", false},
+		{"2025-09-01", "2025-09-01", false},
+		{"2nd business day of the month", "2025-07-02", false},
+		{"last business day of the month", "2025-07-31", false},
+		{"first business day", "2025-08-01", false},
+		{"last fri of the month", "2025-07-25", false},
+		{"2025-07-04 every fri", "2025-07-04+1w", false},
+
+		// Times
+		{"tomorrow 3pm", "2025-07-03T15:00", false},
+		{"tomorrow\t3pm", "2025-07-03T15:00", false},
+		{"tomorrow at 3:30pm", "2025-07-03T15:30", false},
+		{"fri 15:00", "2025-07-04T15:00", false},
+		{"at 9", "2025-07-03T09:00", false}, // already past today
+		{"at 5pm", "2025-07-02T17:00", false},
+		{"noon", "2025-07-03T12:00", false},
+		{"12am tomorrow", "2025-07-03T00:00", false},
+		{"mon 9-10:30am", "2025-07-07T09:00-10:30", false},
+		{"mon 3pm to 4pm", "2025-07-07T15:00-16:00", false},
+		{"fri 10pm to 2am", "2025-07-04T22:00--2025-07-05T02:00", false},
+
+		// Repeats
+		{"every monday at 9", "2025-07-07T09:00+1w", false},
+		{"every mon and thu", "2025-07-03+1w:mon,thu", false},
+		{"every 2 weeks on fri", "2025-07-04+2w", false},
+		{"every other day", "2025-07-02+2d", false},
+		{"every 3 days", "2025-07-02+3d", false},
+		{"daily at 8am", "2025-07-03T08:00+1d", false},
+		{"daily at 6pm", "2025-07-02T18:00+1d", false},
+		{"weekdays", "2025-07-02+1b", false},
+		{"every weekday", "2025-07-02+1b", false},
+		{"every last fri", "2025-07-25+1m:-1fri", false},
+		{"every 15th", "2025-07-15+1m", false},
+		{"every last business day of the month", "2025-07-31+1m:-1bd", false},
+		{"every first business day", "2025-08-01+1m:1bd", false},
+		{"every last fri of the month", "2025-07-25+1m:-1fri", false},
+		{"every month on the last business day", "2025-07-31+1m:-1bd", false},
+		{"monthly on the 2nd business day", "2025-07-02+1m:2bd", false},
+		{"last business day of the month every month", "2025-07-31+1m:-1bd", false},
+		{"2nd business day of every month", "2025-07-02+1m:2bd", false},
+		{"monthly from next month", "2025-08-01+1m", false},
+		{"every week until aug 1", "2025-07-02+1w..2025-08-01", false},
+		{"weekly 10 times", "2025-07-02+1wx10", false},
+
+		// Warnings
+		{"fri warn 3 days before", "2025-07-04!3d", false},
+		{"end of month !5d", "2025-07-31!5d", false},
+
+		// Errors
+		{"", "", true},
+		{"someday", "", true},
+		{"tomorrow fri", "", true},
+		{"3pm 4pm", "", true},
+		{"10 times", "", true},
+		{"feb 30", "", true},
+		{"at 25", "", true},
+		{"-3bd", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseNaturalDate(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNaturalDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseNaturalDate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNaturalDate_Zone(t *testing.T) {
+	withZones(t, "Europe/Berlin", "America/New_York")
+	now := time.Date(2025, 7, 2, 12, 0, 0, 0, time.Local)
+
+	got, err := ParseNaturalDate("tomorrow 3pm", now)
+	if err != nil {
+		t.Fatalf("ParseNaturalDate() error: %v", err)
+	}
+	if want := "2025-07-03T15:00[America/New_York]"; got != want {
+		t.Errorf("ParseNaturalDate() = %q, want %q", got, want)
+	}
+	got, err = ParseNaturalDate("tomorrow", now)
+	if err != nil || got != "2025-07-03" {
+		t.Errorf("ParseNaturalDate() = %q, %v; want an untimed date", got, err)
+	}
+}
@@ -0,0 +1,445 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// NotifyKind is what a notification is about.
+type NotifyKind string
+
+const (
+	NotifyStart   NotifyKind = "start"   // a timed item starts (or is due) within the lead time
+	NotifyWarning NotifyKind = "warning" // a date's warning window (!Nd) opened
+	NotifyClock   NotifyKind = "clock"   // a clock has been running for clock_hours
+)
+
+// notifyStartGrace is how long after a timed item starts it is still
+// notified, when it has no later end time, so a late check doesn't miss it.
+const notifyStartGrace = 15 * time.Minute
+
+// notifyStateAge is how long fired notifications are remembered.
+const notifyStateAge = 30 * 24 * time.Hour
+
+// Notification is a reminder `agenda notify` sends once. Key identifies it
+// across runs: the item, the kind and the occurrence it is about.
+type Notification struct {
+	Kind    NotifyKind `json:"kind"`
+	Key     string     `json:"key"`
+	Title   string     `json:"title"`
+	Body    string     `json:"body"`
+	At      time.Time  `json:"at"` // start, due date or clock-in time
+	Project string     `json:"project,omitempty"`
+	ID      string     `json:"id,omitempty"`
+	File    string     `json:"file,omitempty"`
+	Line    int        `json:"line,omitempty"`
+}
+
+// DueNotifications returns the notifications due at now, sorted by At:
+//
+//   - timed scheduled and due dates, and timed calendar events, from the
+//     lead time before they start until they end (or notifyStartGrace
+//     after they start)
+//   - dates whose warning window, as the agenda shows it (!Nd, or
+//     default_warning_days for deadlines), has opened and not yet passed
+//   - open clocks that have run for clock_hours
+//
+// Completed tasks are left out.
+func DueNotifications(c *config.Config, tasks []*Task, events []CalendarEvent, now time.Time) []Notification {
+	lead := c.NotifyLeadTime()
+	var ns []Notification
+	for _, t := range tasks {
+		if t.IsCompleted(c) {
+			continue
+		}
+		for _, d := range []struct {
+			token    string
+			deadline bool
+		}{{t.ScheduledAt, false}, {t.DueAt, true}} {
+			if d.token == "" {
+				continue
+			}
+			sched, err := ParseSchedule(d.token)
+			if err != nil {
+				continue
+			}
+			ns = append(ns, startNotifications(t, sched, d.deadline, lead, now)...)
+			ns = append(ns, warningNotifications(c, t, sched, d.deadline, now)...)
+		}
+		ns = append(ns, clockNotifications(c, t, now)...)
+	}
+	for _, ev := range events {
+		if ev.AllDay || !notifyStartDue(ev.Start, ev.End, lead, now) {
+			continue
+		}
+		body := startBody("Starts", ev.Start, now)
+		if ev.Location != "" {
+			body += " · " + ev.Location
+		}
+		ns = append(ns, Notification{
+			Kind:  NotifyStart,
+			Key:   fmt.Sprintf("start|event|%s|%s|%s", ev.Calendar, ev.UID, ev.Start.UTC().Format(time.RFC3339)),
+			Title: ev.Summary,
+			Body:  body,
+			At:    ev.Start,
+		})
+	}
+	sort.SliceStable(ns, func(i, j int) bool { return ns[i].At.Before(ns[j].At) })
+	return ns
+}
+
+// notifyStartDue says whether a start at start, ending at end (zero when
+// it has none), is notified at now.
+func notifyStartDue(start, end time.Time, lead time.Duration, now time.Time) bool {
+	until := start.Add(notifyStartGrace)
+	if end.After(until) {
+		until = end
+	}
+	return !now.Before(start.Add(-lead)) && now.Before(until)
+}
+
+func startNotifications(t *Task, sched *Schedule, deadline bool, lead time.Duration, now time.Time) []Notification {
+	if !sched.HasTime {
+		return nil
+	}
+	field, verb := "scheduled", "Starts"
+	if deadline {
+		field, verb = "due", "Due"
+	}
+	var ns []Notification
+	for _, occ := range sched.ExpandOccurrences(now.AddDate(0, 0, -1), now.Add(lead)) {
+		var end time.Time
+		if sched.HasEnd {
+			end = sched.EndOn(occ)
+		}
+		if !notifyStartDue(occ, end, lead, now) {
+			continue
+		}
+		ns = append(ns, taskNotification(t, NotifyStart,
+			fmt.Sprintf("start|%s|%s|%s", field, notifyTaskID(t), occ.UTC().Format(time.RFC3339)),
+			startBody(verb, occ, now), occ))
+	}
+	return ns
+}
+
+// startBody describes a start relative to now: "Starts at 09:00, in 10 min".
+func startBody(verb string, at, now time.Time) string {
+	when := at.Format("15:04")
+	if !truncateToDay(at).Equal(truncateToDay(now)) {
+		when = at.Format("Mon Jan 02 15:04")
+	}
+	switch d := at.Sub(now).Round(time.Minute); {
+	case d > 0:
+		return fmt.Sprintf("%s at %s, in %s", verb, when, formatLead(d))
+	case d == 0:
+		return fmt.Sprintf("%s now (%s)", verb, when)
+	}
+	if verb == "Due" {
+		return fmt.Sprintf("Was due at %s", when)
+	}
+	return fmt.Sprintf("Started at %s", when)
+}
+
+// formatLead formats a lead time: "10 min", "1h 30m".
+func formatLead(d time.Duration) string {
+	m := int(d.Minutes())
+	if m < 60 {
+		return fmt.Sprintf("%d min", m)
+	}
+	if m%60 == 0 {
+		return fmt.Sprintf("%dh", m/60)
+	}
+	return fmt.Sprintf("%dh %02dm", m/60, m%60)
+}
+
+func warningNotifications(c *config.Config, t *Task, sched *Schedule, deadline bool, now time.Time) []Notification {
+	// The same warning days as the agenda's (see addAgendaEntries)
+	warningDays := 0
+	if sched.Warning != nil {
+		warningDays = sched.Warning.Days
+	} else if deadline {
+		warningDays = c.Schedule.DefaultWarningDays
+	}
+	if warningDays <= 0 {
+		return nil
+	}
+	field, verb := "scheduled", "Scheduled"
+	if deadline {
+		field, verb = "due", "Due"
+	}
+
+	today := truncateToDay(now)
+	var ns []Notification
+	for _, occ := range sched.ExpandOccurrences(today, today.AddDate(0, 0, warningDays)) {
+		day := truncateToDay(occ)
+		if !today.Before(day) || today.Before(day.AddDate(0, 0, -warningDays)) {
+			continue
+		}
+		body := fmt.Sprintf("%s in %d days, on %s", verb, daysBetween(today, day), day.Format("Mon Jan 02"))
+		if daysBetween(today, day) == 1 {
+			body = fmt.Sprintf("%s tomorrow, %s", verb, day.Format("Mon Jan 02"))
+		}
+		ns = append(ns, taskNotification(t, NotifyWarning,
+			fmt.Sprintf("warning|%s|%s|%s", field, notifyTaskID(t), day.Format("2006-01-02")),
+			body, occ))
+	}
+	return ns
+}
+
+func clockNotifications(c *config.Config, t *Task, now time.Time) []Notification {
+	if c.Notify.ClockHours <= 0 {
+		return nil
+	}
+	limit := time.Duration(c.Notify.ClockHours * float64(time.Hour))
+	entries, err := ParseClockEntries(t)
+	if err != nil {
+		return nil
+	}
+	var ns []Notification
+	for _, e := range entries {
+		if !e.Open || now.Sub(e.Start) < limit {
+			continue
+		}
+		ns = append(ns, taskNotification(t, NotifyClock,
+			fmt.Sprintf("clock|%s|%s", notifyTaskID(t), e.Start.UTC().Format(time.RFC3339)),
+			fmt.Sprintf("Clocked in for %s, since %s", FormatDuration(now.Sub(e.Start)), e.Start.Format("Mon Jan 02 15:04")),
+			e.Start))
+	}
+	return ns
+}
+
+func taskNotification(t *Task, kind NotifyKind, key, body string, at time.Time) Notification {
+	n := Notification{
+		Kind:    kind,
+		Key:     key,
+		Title:   t.Keyword + ": " + t.Title,
+		Body:    body,
+		At:      at,
+		Project: t.Project,
+		File:    t.FilePath,
+		Line:    t.LineNum,
+	}
+	if !t.DuplicateID {
+		n.ID = t.ID
+	}
+	return n
+}
+
+// notifyTaskID identifies a task in notification keys: by its ID, or else
+// by its file and title, which unlike its line survive edits around it.
+func notifyTaskID(t *Task) string {
+	if t.ID != "" && !t.DuplicateID {
+		return t.ID
+	}
+	return t.FilePath + ":" + t.Title
+}
+
+// NotifySink delivers notifications.
+type NotifySink interface {
+	Send(n Notification) error
+}
+
+// CommandSink runs Command with the shell for each notification, with the
+// notification in KARYA_NOTIFY_KIND, _TITLE, _BODY, _AT, _PROJECT, _ID,
+// _FILE and _LINE and as a JSON object on stdin.
+type CommandSink struct {
+	Command string
+}
+
+func (s *CommandSink) Send(n Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	cmd.Env = append(os.Environ(),
+		"KARYA_NOTIFY_KIND="+string(n.Kind),
+		"KARYA_NOTIFY_TITLE="+n.Title,
+		"KARYA_NOTIFY_BODY="+n.Body,
+		"KARYA_NOTIFY_AT="+n.At.Format(time.RFC3339),
+		"KARYA_NOTIFY_PROJECT="+n.Project,
+		"KARYA_NOTIFY_ID="+n.ID,
+		"KARYA_NOTIFY_FILE="+n.File,
+		"KARYA_NOTIFY_LINE="+strconv.Itoa(n.Line),
+	)
+	cmd.Stdin = bytes.NewReader(data)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command: %w: %s", err, out)
+	}
+	return nil
+}
+
+// ExecSink runs a notify-send style program: Args, then the title and body.
+type ExecSink struct {
+	Args []string
+}
+
+func (s *ExecSink) Send(n Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	args := append(append([]string{}, s.Args[1:]...), n.Title, n.Body)
+	if out, err := exec.CommandContext(ctx, s.Args[0], args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", s.Args[0], err, out)
+	}
+	return nil
+}
+
+// JSONSink writes each notification as a line of JSON.
+type JSONSink struct {
+	W io.Writer
+}
+
+func (s *JSONSink) Send(n Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.W, "%s\n", data)
+	return err
+}
+
+// NotifySinks returns the sinks listed in notify.sinks, or when none are,
+// the command and exec sinks that are set, or else a JSON sink on stdout.
+func NotifySinks(c *config.Config, stdout io.Writer) ([]NotifySink, error) {
+	names := c.Notify.Sinks
+	if len(names) == 0 {
+		if c.Notify.Command != "" {
+			names = append(names, "command")
+		}
+		if len(c.Notify.Exec) > 0 {
+			names = append(names, "exec")
+		}
+		if len(names) == 0 {
+			names = []string{"stdout"}
+		}
+	}
+	var sinks []NotifySink
+	for _, name := range names {
+		switch name {
+		case "command":
+			if c.Notify.Command == "" {
+				return nil, fmt.Errorf("notify sink %q needs [notify] command", name)
+			}
+			sinks = append(sinks, &CommandSink{Command: c.Notify.Command})
+		case "exec":
+			if len(c.Notify.Exec) == 0 {
+				return nil, fmt.Errorf("notify sink %q needs [notify] exec, such as [\"notify-send\"]", name)
+			}
+			sinks = append(sinks, &ExecSink{Args: c.Notify.Exec})
+		case "stdout":
+			sinks = append(sinks, &JSONSink{W: stdout})
+		default:
+			return nil, fmt.Errorf("unknown notify sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// NotifyStatePath returns where `agenda notify` keeps the notifications it
+// sent, or "" when no karya directory is configured.
+func NotifyStatePath(c *config.Config) string {
+	if c.Directories.Karya == "" {
+		return ""
+	}
+	return filepath.Join(c.Directories.Karya, ".cache", "notify.json")
+}
+
+// Notifier sends due notifications to its sinks, each once: the keys it
+// sent are kept at NotifyStatePath, so a restart doesn't send them again.
+type Notifier struct {
+	config *config.Config
+	sinks  []NotifySink
+	path   string
+	sent   map[string]time.Time // key -> when it was sent
+}
+
+// NewNotifier returns a notifier, with the notifications already sent read
+// from NotifyStatePath. A missing or unreadable state file starts empty.
+func NewNotifier(c *config.Config, sinks []NotifySink) *Notifier {
+	n := &Notifier{config: c, sinks: sinks, path: NotifyStatePath(c), sent: make(map[string]time.Time)}
+	if n.path != "" {
+		if data, err := os.ReadFile(n.path); err == nil {
+			json.Unmarshal(data, &n.sent)
+		}
+	}
+	return n
+}
+
+// Check sends the notifications due at now (see DueNotifications) that
+// weren't sent before, and returns them. A notification counts as sent
+// when at least one sink took it; otherwise the next check retries it.
+func (n *Notifier) Check(now time.Time) ([]Notification, error) {
+	tasks, err := ListTasks(n.config, "", false)
+	if err != nil {
+		return nil, err
+	}
+	// Events from yesterday, which may still run, to tomorrow, which may
+	// start within the lead time
+	today := truncateToDay(now)
+	events, err := CalendarEvents(n.config, today.AddDate(0, 0, -1), today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	var sent []Notification
+	var errs []error
+	changed := false
+	for _, note := range DueNotifications(n.config, tasks, events, now) {
+		if _, ok := n.sent[note.Key]; ok {
+			continue
+		}
+		delivered := false
+		for _, sink := range n.sinks {
+			if err := sink.Send(note); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			delivered = true
+		}
+		if delivered {
+			n.sent[note.Key] = now
+			sent = append(sent, note)
+			changed = true
+		}
+	}
+	for key, at := range n.sent {
+		if now.Sub(at) > notifyStateAge {
+			delete(n.sent, key)
+			changed = true
+		}
+	}
+	if changed {
+		if err := n.save(); err != nil {
+			errs = append(errs, fmt.Errorf("saving notify state: %w", err))
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (n *Notifier) save() error {
+	if n.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(n.sent)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(n.path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(n.path, data)
+}
@@ -0,0 +1,195 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDueNotifications(t *testing.T) {
+	withZones(t, "UTC", "")
+	at := func(d, h, m int) time.Time { return time.Date(2025, 7, d, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		name      string
+		keyword   string
+		scheduled string
+		due       string
+		now       time.Time
+		want      []string // kind: body
+	}{
+		{"before the lead time", "TODO", "2025-07-02T09:00", "", at(2, 8, 49), nil},
+		{"within the lead time", "TODO", "2025-07-02T09:00", "", at(2, 8, 50),
+			[]string{"start: Starts at 09:00, in 10 min"}},
+		{"late", "TODO", "2025-07-02T09:00", "", at(2, 9, 10),
+			[]string{"start: Started at 09:00"}},
+		{"too late", "TODO", "2025-07-02T09:00", "", at(2, 9, 15), nil},
+		{"until its end", "TODO", "2025-07-02T09:00-10:00", "", at(2, 9, 40),
+			[]string{"start: Started at 09:00"}},
+		{"untimed", "TODO", "2025-07-02", "", at(2, 8, 55), nil},
+		{"recurring", "TODO", "2025-06-25T09:00+1w", "", at(2, 8, 55),
+			[]string{"start: Starts at 09:00, in 5 min"}},
+		{"after midnight", "TODO", "2025-07-03T00:05", "", at(2, 23, 58),
+			[]string{"start: Starts at Thu Jul 03 00:05, in 7 min"}},
+		{"timed deadline", "TODO", "", "2025-07-02T17:00", at(2, 16, 50),
+			[]string{"start: Due at 17:00, in 10 min"}},
+		{"deadline warning", "TODO", "", "2025-07-05", at(2, 0, 1),
+			[]string{"warning: Due in 3 days, on Sat Jul 05"}},
+		{"deadline warning not yet", "TODO", "", "2025-07-06", at(2, 23, 59), nil},
+		{"deadline's own warning", "TODO", "", "2025-07-03!1d", at(2, 12, 0),
+			[]string{"warning: Due tomorrow, Thu Jul 03"}},
+		{"scheduled without a warning", "TODO", "2025-07-04", "", at(2, 12, 0), nil},
+		{"scheduled warning", "TODO", "2025-07-04!2d", "", at(2, 12, 0),
+			[]string{"warning: Scheduled in 2 days, on Fri Jul 04"}},
+		{"completed", "DONE", "2025-07-02T09:00", "2025-07-04", at(2, 8, 55), nil},
+	}
+
+	c := createTestConfig()
+	c.Schedule.DefaultWarningDays = 3
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Keyword: tt.keyword, Title: "Review", ScheduledAt: tt.scheduled, DueAt: tt.due}
+			var got []string
+			for _, n := range DueNotifications(c, []*Task{task}, nil, tt.now) {
+				got = append(got, string(n.Kind)+": "+n.Body)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DueNotifications() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDueNotifications_ClocksAndEvents(t *testing.T) {
+	withZones(t, "UTC", "")
+	f := filepath.Join(t.TempDir(), "tasks.md")
+	os.WriteFile(f, []byte("DOING: Migrate\n  CLOCK: 2025-07-02T08:00--\n"), 0644)
+	task := &Task{Keyword: "DOING", Title: "Migrate", FilePath: f, LineNum: 1}
+	events := []CalendarEvent{
+		{Calendar: "work", UID: "standup", Summary: "Standup", Location: "Room 1",
+			Start: time.Date(2025, 7, 2, 12, 5, 0, 0, time.UTC), End: time.Date(2025, 7, 2, 12, 20, 0, 0, time.UTC)},
+		{Calendar: "work", UID: "offsite", Summary: "Offsite", AllDay: true,
+			Start: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	c := createTestConfig()
+	c.Notify.ClockHours = 4
+	now := time.Date(2025, 7, 2, 12, 0, 0, 0, time.UTC)
+	var got []string
+	for _, n := range DueNotifications(c, []*Task{task}, events, now) {
+		got = append(got, n.Title+": "+n.Body)
+	}
+	want := []string{
+		"DOING: Migrate: Clocked in for 4:00, since Wed Jul 02 08:00",
+		"Standup: Starts at 12:05, in 5 min · Room 1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DueNotifications() = %q, want %q", got, want)
+	}
+
+	c.Notify.ClockHours = -1
+	if ns := DueNotifications(c, []*Task{task}, nil, now); len(ns) != 0 {
+		t.Errorf("DueNotifications() with clock notifications off = %v", ns)
+	}
+}
+
+// recordSink records what it is sent, failing while err is set.
+type recordSink struct {
+	sent []string
+	err  error
+}
+
+func (s *recordSink) Send(n Notification) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, n.Key)
+	return nil
+}
+
+func TestNotifier_Check(t *testing.T) {
+	withZones(t, "UTC", "")
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "projects", "web"), 0755)
+	os.WriteFile(filepath.Join(dir, "projects", "web", "tasks.md"),
+		[]byte("TODO: [rel] Release @s:2025-07-02T09:00\nTODO: Retro @s:2025-07-02T09:05\n"), 0644)
+
+	c := createTestConfig()
+	c.Directories.Projects = filepath.Join(dir, "projects")
+	c.Directories.Karya = filepath.Join(dir, "karya")
+	now := time.Date(2025, 7, 2, 8, 55, 0, 0, time.UTC)
+
+	sink := &recordSink{}
+	sent, err := NewNotifier(c, []NotifySink{sink}).Check(now)
+	if err != nil || len(sent) != 2 {
+		t.Fatalf("Check() = %v, %v; want two notifications", sent, err)
+	}
+	if want := "start|scheduled|rel|2025-07-02T09:00:00Z"; sink.sent[0] != want {
+		t.Errorf("first key = %q, want %q", sink.sent[0], want)
+	}
+
+	// A restart doesn't send them again
+	restarted := &recordSink{}
+	if sent, err := NewNotifier(c, []NotifySink{restarted}).Check(now.Add(time.Minute)); err != nil || len(sent) != 0 {
+		t.Errorf("Check() after a restart = %v, %v; want nothing", sent, err)
+	}
+
+	// Undelivered notifications are retried
+	os.Remove(NotifyStatePath(c))
+	failing := &recordSink{err: errors.New("no display")}
+	n := NewNotifier(c, []NotifySink{failing})
+	if _, err := n.Check(now); err == nil {
+		t.Error("Check() with a failing sink returned no error")
+	}
+	failing.err = nil
+	if sent, _ := n.Check(now); len(sent) != 2 {
+		t.Errorf("Check() retry sent %d, want 2", len(sent))
+	}
+}
+
+func TestNotifySinks(t *testing.T) {
+	c := createTestConfig()
+	var out bytes.Buffer
+	sinks, err := NotifySinks(c, &out)
+	if err != nil || len(sinks) != 1 {
+		t.Fatalf("NotifySinks() = %v, %v; want the stdout sink", sinks, err)
+	}
+	n := Notification{Kind: NotifyStart, Key: "k", Title: "TODO: Review", Body: "Starts at 09:00, in 10 min",
+		At: time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC), Project: "web"}
+	if err := sinks[0].Send(n); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	var got Notification
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil || got != n || !strings.HasSuffix(out.String(), "}\n") {
+		t.Errorf("stdout sink wrote %q", out.String())
+	}
+
+	// The command sink passes the notification in the environment and on stdin
+	file := filepath.Join(t.TempDir(), "out")
+	c.Notify.Command = `printf '%s|%s|' "$KARYA_NOTIFY_KIND" "$KARYA_NOTIFY_TITLE" > ` + file + ` && cat >> ` + file
+	c.Notify.Exec = []string{"true"}
+	sinks, err = NotifySinks(c, &out)
+	if err != nil || len(sinks) != 2 {
+		t.Fatalf("NotifySinks() = %v, %v; want command and exec", sinks, err)
+	}
+	for _, s := range sinks {
+		if err := s.Send(n); err != nil {
+			t.Fatalf("Send() error: %v", err)
+		}
+	}
+	data, _ := os.ReadFile(file)
+	if !strings.HasPrefix(string(data), "start|TODO: Review|{") {
+		t.Errorf("command got %q", data)
+	}
+
+	c.Notify.Sinks = []string{"exec"}
+	c.Notify.Exec = nil
+	if _, err := NotifySinks(c, &out); err == nil {
+		t.Error("NotifySinks() without an exec program returned no error")
+	}
+}
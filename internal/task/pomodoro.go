@@ -0,0 +1,263 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// pomodoroLineRe matches the "POMODOROS: N" sub-line counting a task's
+// completed pomodoros.
+var pomodoroLineRe = regexp.MustCompile(`^\s*(?:[-*+]\s*)?POMODOROS:\s*(\d+)\s*$`)
+
+// PomodoroPhase is what a Pomodoro timer is running.
+type PomodoroPhase int
+
+const (
+	PomodoroIdle PomodoroPhase = iota
+	PomodoroWork
+	PomodoroBreak
+)
+
+// Pomodoro runs work intervals on a task, clocking it in for each one and
+// out at its end, followed by a break. Callers drive it with Tick.
+type Pomodoro struct {
+	Task      *Task
+	Phase     PomodoroPhase
+	Started   time.Time // Start of the current interval
+	Ends      time.Time // End of the current interval
+	Completed int       // Work intervals completed in this session
+	LongBreak bool      // The current break is a long one
+
+	work, shortBreak, longBreak time.Duration
+	longBreakAfter              int
+}
+
+// NewPomodoro returns an idle timer for t using the [schedule] intervals.
+func NewPomodoro(c *config.Config, t *Task) *Pomodoro {
+	p := &Pomodoro{Task: t, longBreakAfter: c.Schedule.PomodoroLongBreakAfter}
+	p.work, p.shortBreak, p.longBreak = c.PomodoroIntervals()
+	return p
+}
+
+// Start begins a work interval at now and clocks the task in. A clock
+// already running on the task is kept.
+func (p *Pomodoro) Start(now time.Time) error {
+	if p.Phase == PomodoroWork {
+		return fmt.Errorf("pomodoro already running")
+	}
+	if !IsClockActive(p.Task) {
+		if err := clockInAt(p.Task, now); err != nil {
+			return err
+		}
+	}
+	p.Phase, p.Started, p.Ends, p.LongBreak = PomodoroWork, now, now.Add(p.work), false
+	return nil
+}
+
+// Tick advances the timer to now. A work interval that has ended is clocked
+// out at its end, counted on the task and followed by a break, long after
+// every few pomodoros; a break that has ended leaves the timer idle. It
+// reports whether the phase changed.
+func (p *Pomodoro) Tick(now time.Time) (bool, error) {
+	changed := false
+	if p.Phase == PomodoroWork && !now.Before(p.Ends) {
+		if IsClockActive(p.Task) {
+			if err := clockOutAt(p.Task, p.Ends); err != nil {
+				return false, err
+			}
+		}
+		if err := RecordPomodoro(p.Task); err != nil {
+			return false, err
+		}
+		p.Completed++
+		// Without a count, as in a config not built by config.Load, every
+		// break is short
+		p.LongBreak = p.longBreakAfter > 0 && p.Completed%p.longBreakAfter == 0
+		rest := p.shortBreak
+		if p.LongBreak {
+			rest = p.longBreak
+		}
+		p.Phase, p.Started, p.Ends = PomodoroBreak, p.Ends, p.Ends.Add(rest)
+		changed = true
+	}
+	if p.Phase == PomodoroBreak && !now.Before(p.Ends) {
+		p.Phase = PomodoroIdle
+		changed = true
+	}
+	return changed, nil
+}
+
+// Stop ends the timer at now. A work interval cut short is clocked out but
+// not counted.
+func (p *Pomodoro) Stop(now time.Time) error {
+	phase := p.Phase
+	p.Phase = PomodoroIdle
+	if phase == PomodoroWork && IsClockActive(p.Task) {
+		return clockOutAt(p.Task, now)
+	}
+	return nil
+}
+
+// Remaining returns how long the current interval has left.
+func (p *Pomodoro) Remaining(now time.Time) time.Duration {
+	if p.Phase == PomodoroIdle || !now.Before(p.Ends) {
+		return 0
+	}
+	return p.Ends.Sub(now)
+}
+
+// Status describes the timer for a status line, e.g.
+// "🍅 Work 24:59 · #2 · Write docs".
+func (p *Pomodoro) Status(now time.Time) string {
+	switch p.Phase {
+	case PomodoroWork:
+		return fmt.Sprintf("🍅 Work %s · #%d · %s", formatCountdown(p.Remaining(now)), p.Completed+1, p.Task.Title)
+	case PomodoroBreak:
+		label := "Break"
+		if p.LongBreak {
+			label = "Long break"
+		}
+		return fmt.Sprintf("☕ %s %s · %d done", label, formatCountdown(p.Remaining(now)), p.Completed)
+	}
+	return fmt.Sprintf("🍅 %d done · %s", p.Completed, p.Task.Title)
+}
+
+// PomodoroSession is the pomodoro timer of an interactive view: one timer at
+// a time, driven by ticks tagged with the generation Start returned, so
+// ticks scheduled for a stopped or replaced timer are ignored.
+type PomodoroSession struct {
+	Timer *Pomodoro // nil until one is started
+	gen   int
+}
+
+// Running reports whether a work interval or break is under way.
+func (s *PomodoroSession) Running() bool {
+	return s.Timer != nil && s.Timer.Phase != PomodoroIdle
+}
+
+// Start begins a work interval on t at now, continuing the session's count
+// when t is the task the last one ran on. It returns the generation to tag
+// ticks with.
+func (s *PomodoroSession) Start(c *config.Config, t *Task, now time.Time) (int, error) {
+	p := NewPomodoro(c, t)
+	if s.Timer != nil && s.Timer.Task == t {
+		p = s.Timer
+	}
+	if err := p.Start(now); err != nil {
+		return 0, err
+	}
+	s.Timer = p
+	s.gen++
+	return s.gen, nil
+}
+
+// Stop ends the timer at now, clocking out of an unfinished work interval.
+func (s *PomodoroSession) Stop(now time.Time) error {
+	s.gen++
+	if s.Timer == nil {
+		return nil
+	}
+	return s.Timer.Stop(now)
+}
+
+// Tick advances the timer of generation gen to now. It returns a message
+// announcing the end of a work interval or a break, or an error, and
+// whether to tick again.
+func (s *PomodoroSession) Tick(gen int, now time.Time) (msg string, again bool) {
+	p := s.Timer
+	if p == nil || gen != s.gen {
+		return "", false
+	}
+	changed, err := p.Tick(now)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("Pomodoro: %v", err), true
+	case !changed:
+		return "", true
+	case p.Phase == PomodoroIdle:
+		return "Break over · p: start the next pomodoro", false
+	}
+	return fmt.Sprintf("Pomodoro done (%d on this task) · time for a break", PomodoroCount(p.Task)), true
+}
+
+// Status describes the running timer for a status line, or returns "".
+func (s *PomodoroSession) Status(now time.Time) string {
+	if !s.Running() {
+		return ""
+	}
+	return s.Timer.Status(now)
+}
+
+// formatCountdown formats a duration as MM:SS, rounding up to the second.
+func formatCountdown(d time.Duration) string {
+	s := int((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
+// PomodoroCount returns the number of pomodoros completed on the task.
+func PomodoroCount(t *Task) int {
+	lines, err := taskSubLines(t)
+	if err != nil {
+		return 0
+	}
+	for _, line := range lines {
+		if m := pomodoroLineRe.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return n
+		}
+	}
+	return 0
+}
+
+// RecordPomodoro adds one to the task's POMODOROS counter, inserting the
+// counter after the task line if it has none.
+func RecordPomodoro(t *Task) error {
+	if t.FilePath == "" || t.LineNum == 0 {
+		return fmt.Errorf("task has no file location")
+	}
+
+	return mutateTask(t, "Pomodoro: "+t.Title, func(lines []string, taskIdx int) ([]string, error) {
+		_, taskLevel := StripLinePrefix(lines[taskIdx])
+		// Only a direct sub-item is the task's counter, as in PomodoroCount;
+		// a nested task's counter is its own
+		indent := subItemIndentAt(lines, taskIdx, taskLevel)
+		for i := taskIdx + 1; i < len(lines); i++ {
+			line := lines[i]
+			if line == "" {
+				continue
+			}
+			_, level := StripLinePrefix(line)
+			if level <= taskLevel {
+				break
+			}
+			if countLeadingSpaces(line) != len(indent) {
+				continue
+			}
+			if m := pomodoroLineRe.FindStringSubmatchIndex(line); m != nil {
+				n, _ := strconv.Atoi(line[m[2]:m[3]])
+				lines[i] = line[:m[2]] + strconv.Itoa(n+1) + strings.TrimRight(line[m[3]:], " \t")
+				return lines, nil
+			}
+			if isNestedTaskLine(line) {
+				break
+			}
+		}
+		counter := fmt.Sprintf("%s* POMODOROS: 1", indent)
+		return slices.Insert(lines, taskIdx+1, counter), nil
+	})
+}
+
+// isNestedTaskLine reports whether a line under a task is a task of its
+// own rather than one of its CLOCK, LOG or COMPLETED entries.
+func isNestedTaskLine(line string) bool {
+	if _, ok := ParseLineModel(line); !ok {
+		return false
+	}
+	return !clockLineRe.MatchString(line) && !logEntryRe.MatchString(line) && !completedLineRe.MatchString(line)
+}
@@ -0,0 +1,210 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordPomodoro(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		lineNum   int
+		level     int
+		want      string
+		wantCount int
+	}{
+		{"first", "TODO: Write docs\nTODO: Other\n", 1, 0,
+			"TODO: Write docs\n  * POMODOROS: 1\nTODO: Other\n", 1},
+		{"counts up", "TODO: Write docs\n  * CLOCK: 2025-07-02T09:00--2025-07-02T09:25\n  * POMODOROS: 9\n", 1, 0,
+			"TODO: Write docs\n  * CLOCK: 2025-07-02T09:00--2025-07-02T09:25\n  * POMODOROS: 10\n", 10},
+		{"indented", "- TODO: Parent\n  - TODO: Write docs\n    - note\n", 2, 1,
+			"- TODO: Parent\n  - TODO: Write docs\n    * POMODOROS: 1\n    - note\n", 1},
+		{"child's counter", "TODO: Write docs\n- TODO: Child\n  * POMODOROS: 3\n", 1, 0,
+			"TODO: Write docs\n* POMODOROS: 1\n- TODO: Child\n  * POMODOROS: 3\n", 1},
+		{"after a child", "TODO: Write docs\n- TODO: Child\n* POMODOROS: 3\n", 1, 0,
+			"TODO: Write docs\n* POMODOROS: 1\n- TODO: Child\n* POMODOROS: 3\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filepath.Join(t.TempDir(), "tasks.md")
+			os.WriteFile(f, []byte(tt.content), 0644)
+			task := &Task{Keyword: "TODO", Title: "Write docs", FilePath: f, LineNum: tt.lineNum, IndentLevel: tt.level}
+
+			if err := RecordPomodoro(task); err != nil {
+				t.Fatalf("RecordPomodoro() error: %v", err)
+			}
+			data, _ := os.ReadFile(f)
+			if string(data) != tt.want {
+				t.Errorf("file = %q, want %q", data, tt.want)
+			}
+			if got := PomodoroCount(task); got != tt.wantCount {
+				t.Errorf("PomodoroCount() = %d, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestPomodoro(t *testing.T) {
+	withZones(t, "UTC", "")
+	f := filepath.Join(t.TempDir(), "tasks.md")
+	os.WriteFile(f, []byte("TODO: Write docs\n"), 0644)
+	task := &Task{Keyword: "TODO", Title: "Write docs", FilePath: f, LineNum: 1}
+
+	c := createTestConfig()
+	c.Schedule.PomodoroWork, c.Schedule.PomodoroBreak, c.Schedule.PomodoroLongBreak = "25m", "5m", "15m"
+	c.Schedule.PomodoroLongBreakAfter = 2
+	p := NewPomodoro(c, task)
+	at := func(h, m int) time.Time { return time.Date(2025, 7, 2, h, m, 0, 0, time.UTC) }
+
+	if err := p.Start(at(9, 0)); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	if !IsClockActive(task) || p.Status(at(9, 10)) != "🍅 Work 15:00 · #1 · Write docs" {
+		t.Fatalf("after Start: clocked in %v, status %q", IsClockActive(task), p.Status(at(9, 10)))
+	}
+	if changed, err := p.Tick(at(9, 24)); changed || err != nil {
+		t.Errorf("Tick() before the end = %v, %v", changed, err)
+	}
+
+	// Ticking late still clocks out at the interval's end
+	if changed, err := p.Tick(at(9, 27)); !changed || err != nil {
+		t.Fatalf("Tick() at the end = %v, %v", changed, err)
+	}
+	if p.Phase != PomodoroBreak || p.LongBreak || p.Remaining(at(9, 27)) != 3*time.Minute {
+		t.Errorf("after work: phase %v, long %v, remaining %v", p.Phase, p.LongBreak, p.Remaining(at(9, 27)))
+	}
+	entries, _ := ParseClockEntries(task)
+	if len(entries) != 1 || entries[0].Open || !entries[0].End.Equal(at(9, 25)) {
+		t.Errorf("clock entries = %+v, want one ending at 09:25", entries)
+	}
+	if PomodoroCount(task) != 1 {
+		t.Errorf("PomodoroCount() = %d, want 1", PomodoroCount(task))
+	}
+
+	// The second pomodoro earns a long break
+	p.Tick(at(9, 30))
+	if p.Phase != PomodoroIdle {
+		t.Fatalf("after the break: phase %v, want idle", p.Phase)
+	}
+	p.Start(at(9, 30))
+	p.Tick(at(9, 55))
+	if !p.LongBreak || !p.Ends.Equal(at(10, 10)) || p.Status(at(10, 0)) != "☕ Long break 10:00 · 2 done" {
+		t.Errorf("second break: long %v, ends %v, status %q", p.LongBreak, p.Ends, p.Status(at(10, 0)))
+	}
+
+	// Stopping cuts a pomodoro short without counting it
+	p.Start(at(11, 0))
+	if err := p.Stop(at(11, 10)); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if IsClockActive(task) || PomodoroCount(task) != 2 || p.Phase != PomodoroIdle {
+		t.Errorf("after Stop: clocked in %v, count %d, phase %v", IsClockActive(task), PomodoroCount(task), p.Phase)
+	}
+}
+
+func TestQueryClockTable_Pomodoros(t *testing.T) {
+	cfg, tmpDir := makeProcessFileConfig(t)
+	cfg.Directories.Karya = t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "work"), 0755)
+	writeTaskFile(t, tmpDir, "work/tasks.md", `DOING: Write design doc
+  * POMODOROS: 3
+  * CLOCK: 2026-06-17T09:00--2026-06-17T09:25
+TODO: Review PR
+  * CLOCK: 2026-06-17T14:00--2026-06-17T14:30
+`)
+
+	day := time.Date(2026, 6, 17, 0, 0, 0, 0, time.Local)
+	table, err := QueryClockTable(cfg, day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Projects) != 1 || table.Projects[0].Pomodoros != 3 {
+		t.Fatalf("unexpected table: %+v", table)
+	}
+	for _, e := range table.Projects[0].Entries {
+		want := map[string]int{"Write design doc": 3, "Review PR": 0}[e.Task.Title]
+		if e.Pomodoros != want {
+			t.Errorf("%s: Pomodoros = %d, want %d", e.Task.Title, e.Pomodoros, want)
+		}
+	}
+}
+
+func TestPomodoroSession(t *testing.T) {
+	withZones(t, "UTC", "")
+	f := filepath.Join(t.TempDir(), "tasks.md")
+	os.WriteFile(f, []byte("TODO: Write docs\nTODO: Review docs\n"), 0644)
+	docs := &Task{Keyword: "TODO", Title: "Write docs", FilePath: f, LineNum: 1}
+	review := &Task{Keyword: "TODO", Title: "Review docs", FilePath: f, LineNum: 2}
+
+	c := createTestConfig()
+	c.Schedule.PomodoroWork, c.Schedule.PomodoroBreak, c.Schedule.PomodoroLongBreak = "25m", "5m", "15m"
+	c.Schedule.PomodoroLongBreakAfter = 4
+	at := func(h, m int) time.Time { return time.Date(2025, 7, 2, h, m, 0, 0, time.UTC) }
+
+	var s PomodoroSession
+	if s.Running() || s.Status(at(9, 0)) != "" {
+		t.Fatalf("new session is running")
+	}
+	gen, err := s.Start(c, docs, at(9, 0))
+	if err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	if msg, again := s.Tick(gen, at(9, 10)); msg != "" || !again {
+		t.Errorf("Tick() mid-interval = %q, %v", msg, again)
+	}
+	if msg, again := s.Tick(gen, at(9, 25)); msg != "Pomodoro done (1 on this task) · time for a break" || !again {
+		t.Errorf("Tick() at the end of work = %q, %v", msg, again)
+	}
+	if msg, again := s.Tick(gen, at(9, 30)); msg != "Break over · p: start the next pomodoro" || again {
+		t.Errorf("Tick() at the end of the break = %q, %v", msg, again)
+	}
+
+	// The same task keeps the session's count; ticks of a stopped timer
+	// are ignored
+	old := gen
+	if gen, err = s.Start(c, docs, at(10, 0)); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	if s.Timer.Completed != 1 || gen == old {
+		t.Errorf("restart: completed %d, gen %d (was %d)", s.Timer.Completed, gen, old)
+	}
+	if err := s.Stop(at(10, 5)); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if msg, again := s.Tick(gen, at(10, 30)); msg != "" || again {
+		t.Errorf("Tick() after Stop = %q, %v", msg, again)
+	}
+
+	// Another task starts a new count
+	if _, err := s.Start(c, review, at(11, 0)); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	if s.Timer.Task != review || s.Timer.Completed != 0 || !s.Running() {
+		t.Errorf("new task: task %q, completed %d, running %v", s.Timer.Task.Title, s.Timer.Completed, s.Running())
+	}
+}
+
+func TestPomodoro_NoLongBreakCount(t *testing.T) {
+	withZones(t, "UTC", "")
+	f := filepath.Join(t.TempDir(), "tasks.md")
+	os.WriteFile(f, []byte("TODO: Write docs\n"), 0644)
+	task := &Task{Keyword: "TODO", Title: "Write docs", FilePath: f, LineNum: 1}
+
+	c := createTestConfig()
+	c.Schedule.PomodoroWork, c.Schedule.PomodoroBreak, c.Schedule.PomodoroLongBreak = "25m", "5m", "15m"
+	p := NewPomodoro(c, task)
+	start := time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC)
+	if err := p.Start(start); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	if changed, err := p.Tick(start.Add(25 * time.Minute)); !changed || err != nil {
+		t.Fatalf("Tick() at the end = %v, %v", changed, err)
+	}
+	if p.Phase != PomodoroBreak || p.LongBreak {
+		t.Errorf("after work: phase %v, long %v; want a short break", p.Phase, p.LongBreak)
+	}
+}
@@ -0,0 +1,137 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// propertyLineRe matches a "key:: value" sub-line, with an optional bullet.
+var propertyLineRe = regexp.MustCompile(`^\s*(?:[-*+]\s*)?([A-Za-z][\w-]*)::[ \t]*(.*)$`)
+
+var propertyKeyRe = regexp.MustCompile(`^[A-Za-z][\w-]*$`)
+
+// parsePropertyLine returns the key (lower-cased) and value of a property
+// sub-line such as "- customer:: ACME".
+func parsePropertyLine(line string) (key, value string, ok bool) {
+	m := propertyLineRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	return strings.ToLower(m[1]), strings.TrimSpace(m[2]), true
+}
+
+// setProperty records a parsed property on the task. Later lines for the same
+// key override earlier ones.
+func (t *Task) setProperty(key, value string) {
+	if t.Properties == nil {
+		t.Properties = make(map[string]string)
+	}
+	t.Properties[key] = value
+}
+
+// PropertyKeys returns the task's property keys in sorted order.
+func (t *Task) PropertyKeys() []string {
+	keys := make([]string, 0, len(t.Properties))
+	for k := range t.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SetTaskProperty sets a "key:: value" property sub-line on the task. An
+// existing line for the key is updated in place; otherwise a new line is
+// inserted after the task line at the task's sub-item indent. An empty value
+// removes the property.
+func SetTaskProperty(t *Task, key, value string) error {
+	if !propertyKeyRe.MatchString(key) {
+		return fmt.Errorf("invalid property key %q (letters, digits, '-' and '_', starting with a letter)", key)
+	}
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "\n") {
+		return fmt.Errorf("property value must be a single line")
+	}
+	if t.FilePath == "" || t.LineNum == 0 {
+		return fmt.Errorf("task has no file location")
+	}
+
+	written := false
+	err := mutateTask(t, fmt.Sprintf("Set %s: %s", key, t.Title), func(lines []string, taskIdx int) ([]string, error) {
+		_, taskLevel := StripLinePrefix(lines[taskIdx])
+		// Only the task's direct sub-items are its properties; deeper lines
+		// belong to child tasks
+		indent := subItemIndentAt(lines, taskIdx, taskLevel)
+
+		// Update the first line for this key and drop any duplicates, so the
+		// file agrees with the parser (where the last line wins)
+		newLines := make([]string, 0, len(lines)+1)
+		newLines = append(newLines, lines[:taskIdx+1]...)
+		found := false
+		i := taskIdx + 1
+		for ; i < len(lines); i++ {
+			line := lines[i]
+			if line == "" {
+				newLines = append(newLines, line)
+				continue
+			}
+			_, level := StripLinePrefix(line)
+			if level <= taskLevel {
+				break
+			}
+			if countLeadingSpaces(line) != len(indent) {
+				newLines = append(newLines, line)
+				continue
+			}
+			m := propertyLineRe.FindStringSubmatchIndex(line)
+			if m == nil || !strings.EqualFold(line[m[2]:m[3]], key) {
+				newLines = append(newLines, line)
+				continue
+			}
+			if !found && value != "" {
+				// Keep the original bullet, indent and key spelling
+				newLines = append(newLines, strings.TrimRight(line[:m[4]], " \t")+" "+value)
+			}
+			found = true
+		}
+		newLines = append(newLines, lines[i:]...)
+
+		if !found {
+			if value == "" {
+				return lines, nil
+			}
+			propLine := fmt.Sprintf("%s- %s:: %s", indent, key, value)
+			newLines = slices.Insert(newLines, taskIdx+1, propLine)
+		}
+		written = true
+		return newLines, nil
+	})
+	if err != nil || !written {
+		return err
+	}
+
+	key = strings.ToLower(key)
+	if value == "" {
+		delete(t.Properties, key)
+	} else {
+		t.setProperty(key, value)
+	}
+	return nil
+}
+
+// ParsePropertyAssignment splits "key:: value" (or "key=value") input from a
+// prompt into key and value.
+func ParsePropertyAssignment(input string) (key, value string, err error) {
+	input = strings.TrimSpace(input)
+	var ok bool
+	if key, value, ok = strings.Cut(input, "::"); !ok {
+		key, value, ok = strings.Cut(input, "=")
+	}
+	key = strings.TrimSpace(key)
+	if !ok || !propertyKeyRe.MatchString(key) {
+		return "", "", fmt.Errorf("expected key:: value")
+	}
+	return key, strings.TrimSpace(value), nil
+}
@@ -0,0 +1,164 @@
+package task
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestProcessFile_Properties(t *testing.T) {
+	cfg, tmpDir := makeProcessFileConfig(t)
+	path := writeTaskFile(t, tmpDir, "tasks.md", `- TODO: [inv-7] Send invoice
+  - Customer:: ACME
+  - po-number::   4471  
+  - TODO: Chase payment
+    - customer:: ACME Accounts
+  - notes:: belongs to the parent again
+- TODO: Unrelated
+customer:: not a sub-line
+`)
+
+	tasks, err := ProcessFile(cfg, path)
+	if err != nil || len(tasks) != 3 {
+		t.Fatalf("ProcessFile = %v, %v", tasks, err)
+	}
+
+	tests := []struct {
+		task int
+		want map[string]string
+	}{
+		{0, map[string]string{"customer": "ACME", "po-number": "4471", "notes": "belongs to the parent again"}},
+		{1, map[string]string{"customer": "ACME Accounts"}},
+		{2, nil},
+	}
+	for _, tt := range tests {
+		got := tasks[tt.task].Properties
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: properties = %v, want %v", tasks[tt.task].Title, got, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%s: %s = %q, want %q", tasks[tt.task].Title, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestSetTaskProperty(t *testing.T) {
+	cfg, tmpDir := makeProcessFileConfig(t)
+	path := writeTaskFile(t, tmpDir, "tasks.md", `# Tasks
+
+  - TODO: Send invoice
+    * CLOCK: 2026-06-17T09:30--2026-06-17T10:00
+    - TODO: Chase payment
+      - customer:: Child Co
+      * Customer:: Old
+`)
+
+	tasks, err := ProcessFile(cfg, path)
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("ProcessFile = %v, %v", tasks, err)
+	}
+
+	// Existing key is updated in place, keeping its bullet and spelling, and
+	// the duplicate is dropped
+	child := tasks[1]
+	if err := SetTaskProperty(child, "CUSTOMER", "Globex"); err != nil {
+		t.Fatalf("SetTaskProperty update: %v", err)
+	}
+	// New property goes after the task line at the existing sub-item indent
+	parent := tasks[0]
+	if err := SetTaskProperty(parent, "customer", "ACME"); err != nil {
+		t.Fatalf("SetTaskProperty insert: %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	want := `# Tasks
+
+  - TODO: Send invoice
+    - customer:: ACME
+    * CLOCK: 2026-06-17T09:30--2026-06-17T10:00
+    - TODO: Chase payment
+      - customer:: Globex
+`
+	if string(got) != want {
+		t.Errorf("file content = %q, want %q", string(got), want)
+	}
+	if parent.Properties["customer"] != "ACME" || child.Properties["customer"] != "Globex" {
+		t.Errorf("in-memory properties = %v / %v", parent.Properties, child.Properties)
+	}
+
+	// Re-parse and remove: the child's property must survive
+	tasks, _ = ProcessFile(cfg, path)
+	if err := SetTaskProperty(tasks[0], "customer", ""); err != nil {
+		t.Fatalf("SetTaskProperty remove: %v", err)
+	}
+	tasks, _ = ProcessFile(cfg, path)
+	if _, ok := tasks[0].Properties["customer"]; ok {
+		t.Errorf("parent property not removed: %v", tasks[0].Properties)
+	}
+	if tasks[1].Properties["customer"] != "Globex" {
+		t.Errorf("child property = %v, want Globex", tasks[1].Properties)
+	}
+
+	if err := SetTaskProperty(tasks[0], "bad key", "x"); err == nil {
+		t.Error("expected error for invalid key")
+	}
+}
+
+func TestSetTaskProperty_AfterClockIn(t *testing.T) {
+	cfg, tmpDir := makeProcessFileConfig(t)
+	path := writeTaskFile(t, tmpDir, "tasks.md", `- TODO: Send invoice
+  - TODO: Chase payment
+    - customer:: Child Co
+`)
+	tasks, err := ProcessFile(cfg, path)
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("ProcessFile = %v, %v", tasks, err)
+	}
+
+	// The inserted CLOCK line moves the child down from where it was parsed
+	parent := tasks[0]
+	if err := ClockIn(parent); err != nil {
+		t.Fatalf("ClockIn: %v", err)
+	}
+	if err := SetTaskProperty(parent, "customer", "ACME"); err != nil {
+		t.Fatalf("SetTaskProperty: %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	lines := strings.Split(string(got), "\n")
+	if len(lines) != 6 || lines[1] != "  - customer:: ACME" || lines[4] != "    - customer:: Child Co" {
+		t.Errorf("file content = %q, want the parent's property added and the child's kept", got)
+	}
+}
+
+func TestParsePropertyAssignment(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantKey   string
+		wantValue string
+		wantErr   bool
+	}{
+		{"customer:: ACME", "customer", "ACME", false},
+		{" po-number::4471 ", "po-number", "4471", false},
+		{"customer=ACME Corp", "customer", "ACME Corp", false},
+		{"url:: https://example.com/a=b", "url", "https://example.com/a=b", false},
+		{"customer::", "customer", "", false},
+		{"no separator", "", "", true},
+		{"1st:: x", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			key, value, err := ParsePropertyAssignment(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePropertyAssignment(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if key != tt.wantKey || value != tt.wantValue {
+				t.Errorf("ParsePropertyAssignment(%q) = %q, %q, want %q, %q", tt.input, key, value, tt.wantKey, tt.wantValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,497 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// QueryError reports a problem in a filter query. Pos is the 1-based column
+// of the offending token within the query string.
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos, e.Msg)
+}
+
+// Query is a parsed filter expression. The syntax is a sequence of terms
+// combined with AND, OR, NOT and parentheses; adjacent terms are implicitly
+// ANDed. AND binds tighter than OR. Terms are:
+//
+//	#tag, >>assignee, @date, @s:date, @d:date   single-field filters (see FilterTasks)
+//	project:NAME  keyword:KW  category:CAT  tag:TAG  assignee:NAME  id:ID
+//	scheduled:EXPR  due:EXPR                    EXPR as for @s:/@d: (<, <=, >, >=, a..b, overdue)
+//	priority:A|B|C  prop:KEY  prop:KEY=VALUE    priority cookie, task properties
+//	has:deps  has:estimate  over:budget  in:cycle
+//	is:ready  is:blocked                        see IsReady and MarkBlocked
+//	word, "quoted phrase"                       free text across all fields
+//
+// For example: #urgent AND >>alice AND @d:<2025-07-01 AND NOT project:infra
+type Query struct {
+	root queryNode
+}
+
+// ParseQuery parses a filter query. An empty query matches every task.
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, end: len([]rune(input)) + 1}
+	if len(tokens) == 0 {
+		return &Query{}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		if tok.kind == tokRParen {
+			return nil, &QueryError{Pos: tok.pos, Msg: `unmatched ")"`}
+		}
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return &Query{root: root}, nil
+}
+
+// Filter returns the tasks matching the query, in their original order.
+// in:cycle relies on InCycle, so callers should run DetectCycles first.
+func (q *Query) Filter(c *config.Config, tasks []*Task) []*Task {
+	if q.root == nil {
+		return tasks
+	}
+	matched := q.root.eval(c, tasks)
+	var filtered []*Task
+	for _, t := range tasks {
+		if matched[t] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// QueryTasks parses query and applies it to tasks.
+func QueryTasks(c *config.Config, tasks []*Task, query string) ([]*Task, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.Filter(c, tasks), nil
+}
+
+// queryNode evaluates to the set of matching tasks drawn from tasks.
+type queryNode interface {
+	eval(c *config.Config, tasks []*Task) map[*Task]bool
+}
+
+type andNode struct{ left, right queryNode }
+type orNode struct{ left, right queryNode }
+type notNode struct{ child queryNode }
+
+// termNode is a leaf predicate. filter narrows a task slice, mirroring the
+// slice-based helpers used by FilterTasks.
+type termNode struct {
+	filter func(c *config.Config, tasks []*Task) []*Task
+}
+
+func (n *andNode) eval(c *config.Config, tasks []*Task) map[*Task]bool {
+	left := n.left.eval(c, tasks)
+	var narrowed []*Task
+	for _, t := range tasks {
+		if left[t] {
+			narrowed = append(narrowed, t)
+		}
+	}
+	return n.right.eval(c, narrowed)
+}
+
+func (n *orNode) eval(c *config.Config, tasks []*Task) map[*Task]bool {
+	result := n.left.eval(c, tasks)
+	for t := range n.right.eval(c, tasks) {
+		result[t] = true
+	}
+	return result
+}
+
+func (n *notNode) eval(c *config.Config, tasks []*Task) map[*Task]bool {
+	excluded := n.child.eval(c, tasks)
+	result := make(map[*Task]bool, len(tasks))
+	for _, t := range tasks {
+		if !excluded[t] {
+			result[t] = true
+		}
+	}
+	return result
+}
+
+func (n *termNode) eval(c *config.Config, tasks []*Task) map[*Task]bool {
+	result := make(map[*Task]bool)
+	for _, t := range n.filter(c, tasks) {
+		result[t] = true
+	}
+	return result
+}
+
+type queryTokenKind int
+
+const (
+	tokWord queryTokenKind = iota
+	tokPhrase
+	tokLParen
+	tokRParen
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string // word or phrase with quotes removed
+	pos  int    // 1-based column
+}
+
+// tokenizeQuery splits a query into words, quoted phrases and parentheses.
+// Quotes may also appear inside a word (project:"my project").
+func tokenizeQuery(input string) ([]queryToken, error) {
+	runes := []rune(input)
+	var tokens []queryToken
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, text: "(", pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, text: ")", pos: i + 1})
+			i++
+		default:
+			start := i
+			kind := tokWord
+			if r == '"' {
+				kind = tokPhrase
+			}
+			var b strings.Builder
+			for i < len(runes) {
+				r = runes[i]
+				if r == '"' {
+					closing := i + 1
+					for closing < len(runes) && runes[closing] != '"' {
+						closing++
+					}
+					if closing == len(runes) {
+						return nil, &QueryError{Pos: i + 1, Msg: "unterminated quote"}
+					}
+					b.WriteString(string(runes[i+1 : closing]))
+					i = closing + 1
+					continue
+				}
+				if unicode.IsSpace(r) || r == '(' || r == ')' {
+					break
+				}
+				b.WriteRune(r)
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: kind, text: b.String(), pos: start + 1})
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	end    int // column reported for errors at end of input
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *queryParser) isOperator(tok *queryToken, op string) bool {
+	return tok != nil && tok.kind == tokWord && tok.text == op
+}
+
+// parseOr: and ("OR" and)*
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator(p.peek(), "OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd: unary (["AND"] unary)*
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok == nil || tok.kind == tokRParen || p.isOperator(tok, "OR") {
+			return left, nil
+		}
+		if p.isOperator(tok, "AND") {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+}
+
+// parseUnary: "NOT" unary | primary
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.isOperator(p.peek(), "NOT") {
+		p.pos++
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary: "(" or-expression ")" | term
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.peek()
+	if tok == nil {
+		return nil, &QueryError{Pos: p.end, Msg: "expected a term"}
+	}
+	switch tok.kind {
+	case tokLParen:
+		open := tok.pos
+		p.pos++
+		if next := p.peek(); next != nil && next.kind == tokRParen {
+			return nil, &QueryError{Pos: next.pos, Msg: "empty parentheses"}
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.kind != tokRParen {
+			return nil, &QueryError{Pos: open, Msg: `unclosed "("`}
+		}
+		p.pos++
+		return node, nil
+	case tokRParen:
+		return nil, &QueryError{Pos: tok.pos, Msg: `unexpected ")"`}
+	case tokPhrase:
+		p.pos++
+		return freeTextTerm(tok.text), nil
+	}
+
+	switch tok.text {
+	case "AND", "OR", "NOT":
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("expected a term before %s", tok.text)}
+	}
+	p.pos++
+
+	// ">> alice" (with a space) is accepted as in the single-field filter syntax
+	if tok.text == ">>" {
+		next := p.peek()
+		if next == nil || next.kind == tokLParen || next.kind == tokRParen {
+			return nil, &QueryError{Pos: tok.pos, Msg: "expected an assignee after >>"}
+		}
+		p.pos++
+		return legacyTerm(">>" + next.text), nil
+	}
+	return parseTerm(tok.text, tok.pos)
+}
+
+// parseTerm builds the predicate for a single word.
+func parseTerm(word string, pos int) (queryNode, error) {
+	switch {
+	case strings.HasPrefix(word, ">>"), strings.HasPrefix(word, "#"):
+		return legacyTerm(word), nil
+	case strings.HasPrefix(word, "@s:"), strings.HasPrefix(word, "@d:"):
+		if err := validateDateExpr(word[3:], pos+3); err != nil {
+			return nil, err
+		}
+		return legacyTerm(word), nil
+	case strings.HasPrefix(word, "@"):
+		if err := validateDateExpr(word[1:], pos+1); err != nil {
+			return nil, err
+		}
+		return legacyTerm(word), nil
+	}
+
+	field, value, ok := strings.Cut(word, ":")
+	if !ok || field == "" || strings.IndexFunc(field, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+		return freeTextTerm(word), nil
+	}
+	valuePos := pos + len([]rune(field)) + 1
+	if value == "" {
+		return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("missing value for %s:", field)}
+	}
+
+	switch strings.ToLower(field) {
+	case "project":
+		return fieldTerm(func(t *Task) bool { return strings.EqualFold(t.Project, value) }), nil
+	case "keyword", "kw":
+		return fieldTerm(func(t *Task) bool { return strings.EqualFold(t.Keyword, value) }), nil
+	case "id":
+		return fieldTerm(func(t *Task) bool { return t.ID != "" && strings.EqualFold(t.ID, value) }), nil
+	case "tag":
+		return legacyTerm("#" + value), nil
+	case "assignee":
+		return legacyTerm(">>" + value), nil
+	case "scheduled":
+		if err := validateDateExpr(value, valuePos); err != nil {
+			return nil, err
+		}
+		return legacyTerm("@s:" + value), nil
+	case "due":
+		if err := validateDateExpr(value, valuePos); err != nil {
+			return nil, err
+		}
+		return legacyTerm("@d:" + value), nil
+	case "category", "cat":
+		var inCategory func(t *Task, c *config.Config) bool
+		switch strings.ToLower(value) {
+		case "active":
+			inCategory = (*Task).IsActive
+		case "inprogress":
+			inCategory = (*Task).IsInProgress
+		case "completed":
+			inCategory = (*Task).IsCompleted
+		case "someday":
+			inCategory = (*Task).IsSomeday
+		default:
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown category %q (want active, inprogress, completed or someday)", value)}
+		}
+		return &termNode{filter: func(c *config.Config, tasks []*Task) []*Task {
+			var filtered []*Task
+			for _, t := range tasks {
+				if inCategory(t, c) {
+					filtered = append(filtered, t)
+				}
+			}
+			return filtered
+		}}, nil
+	case "priority", "pri":
+		cookie := strings.ToUpper(value)
+		if cookie != "A" && cookie != "B" && cookie != "C" {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown priority %q (want A, B or C)", value)}
+		}
+		return fieldTerm(func(t *Task) bool { return t.PriorityCookie == cookie }), nil
+	case "prop", "property":
+		key, want, hasValue := strings.Cut(value, "=")
+		key = strings.ToLower(key)
+		if key == "" {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("missing property name in %s:", field)}
+		}
+		return fieldTerm(func(t *Task) bool {
+			got, ok := t.Properties[key]
+			return ok && (!hasValue || strings.EqualFold(got, want))
+		}), nil
+	case "has":
+		switch strings.ToLower(value) {
+		case "deps":
+			return fieldTerm(func(t *Task) bool { return len(t.References) > 0 }), nil
+		case "estimate":
+			return fieldTerm(func(t *Task) bool { return t.Estimate != "" }), nil
+		}
+		return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown has: value %q (want deps or estimate)", value)}
+	case "over":
+		if strings.ToLower(value) != "budget" {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown over: value %q (want budget)", value)}
+		}
+		return fieldTerm(IsOverBudget), nil
+	case "is":
+		switch strings.ToLower(value) {
+		case "ready":
+			return &termNode{filter: func(c *config.Config, tasks []*Task) []*Task {
+				var filtered []*Task
+				for _, t := range tasks {
+					if IsReady(c, t) {
+						filtered = append(filtered, t)
+					}
+				}
+				return filtered
+			}}, nil
+		case "blocked":
+			return fieldTerm(func(t *Task) bool { return t.Blocked }), nil
+		}
+		return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown is: value %q (want ready or blocked)", value)}
+	case "in":
+		if strings.ToLower(value) != "cycle" {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown in: value %q (want cycle)", value)}
+		}
+		return fieldTerm(func(t *Task) bool { return t.InCycle }), nil
+	}
+	return nil, &QueryError{Pos: pos, Msg: fmt.Sprintf("unknown field %q (quote the term to search for it as text)", field)}
+}
+
+// legacyTerm delegates to the single-field filter syntax of FilterTasks.
+func legacyTerm(filter string) queryNode {
+	return &termNode{filter: func(_ *config.Config, tasks []*Task) []*Task {
+		return FilterTasks(tasks, filter)
+	}}
+}
+
+func freeTextTerm(text string) queryNode {
+	return &termNode{filter: func(_ *config.Config, tasks []*Task) []*Task {
+		return filterByAnyField(tasks, text)
+	}}
+}
+
+func fieldTerm(match func(*Task) bool) queryNode {
+	return &termNode{filter: func(_ *config.Config, tasks []*Task) []*Task {
+		var filtered []*Task
+		for _, t := range tasks {
+			if match(t) {
+				filtered = append(filtered, t)
+			}
+		}
+		return filtered
+	}}
+}
+
+// validateDateExpr checks the dates in a comparison expression accepted by
+// compareDateField. Expressions without an operator are substring matches
+// and always valid.
+func validateDateExpr(expr string, pos int) error {
+	var targets []string
+	switch {
+	case expr == "overdue":
+		return nil
+	case strings.HasPrefix(expr, "<="), strings.HasPrefix(expr, ">="):
+		targets = []string{expr[2:]}
+		pos += 2
+	case strings.HasPrefix(expr, "<"), strings.HasPrefix(expr, ">"):
+		targets = []string{expr[1:]}
+		pos++
+	case strings.Contains(expr, ".."):
+		targets = strings.SplitN(expr, "..", 2)
+	default:
+		return nil
+	}
+	for _, target := range targets {
+		if _, err := ParseSchedule(strings.TrimSpace(target)); err != nil {
+			return &QueryError{Pos: pos, Msg: fmt.Sprintf("invalid date %q", target)}
+		}
+		pos += len([]rune(target)) + 2
+	}
+	return nil
+}
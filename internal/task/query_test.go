@@ -0,0 +1,113 @@
+package task
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func queryTestTasks() []*Task {
+	return []*Task{
+		{Keyword: "TODO", ID: "a1", Title: "Fix login bug", Tags: []string{"urgent"}, Assignee: "alice", Project: "web", DueAt: "2025-06-20", Properties: map[string]string{"customer": "ACME"}},
+		{Keyword: "DOING", Title: "Write design doc", Estimate: "3h", Tags: []string{"docs"}, Assignee: "bob", Project: "infra", DueAt: "2025-06-25"},
+		{Keyword: "TODO", PriorityCookie: "A", Title: "Rotate keys", Tags: []string{"urgent"}, Assignee: "alice", Project: "infra", DueAt: "2025-06-10", References: []string{"a1"}, Blocked: true, Properties: map[string]string{"customer": "Globex"}},
+		{Keyword: "DONE", Title: "Ship release", Tags: []string{"urgent"}, Assignee: "alice", Project: "web", DueAt: "2025-08-01"},
+		{Keyword: "SOMEDAY", Title: "Learn Rust", Project: "personal", InCycle: true},
+	}
+}
+
+func queryTitles(tasks []*Task) string {
+	var titles []string
+	for _, t := range tasks {
+		titles = append(titles, t.Title)
+	}
+	return strings.Join(titles, "|")
+}
+
+func TestQueryTasks(t *testing.T) {
+	cfg := createTestConfig()
+	tasks := queryTestTasks()
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"empty", "", "Fix login bug|Write design doc|Rotate keys|Ship release|Learn Rust"},
+		{"legacy tag", "#urgent", "Fix login bug|Rotate keys|Ship release"},
+		{"legacy assignee with space", ">> bob", "Write design doc"},
+		{"implicit and", "#urgent >>alice project:web", "Fix login bug|Ship release"},
+		{"request example", "#urgent AND >>alice AND @d:<2025-07-01 AND NOT project:infra", "Fix login bug"},
+		{"or", "project:personal OR keyword:doing", "Write design doc|Learn Rust"},
+		{"and binds tighter than or", "project:personal OR #urgent AND project:infra", "Rotate keys|Learn Rust"},
+		{"parentheses", "(project:personal OR #urgent) AND NOT category:completed", "Fix login bug|Rotate keys|Learn Rust"},
+		{"category", "category:inprogress", "Write design doc"},
+		{"id", "id:A1", "Fix login bug"},
+		{"has deps", "has:deps", "Rotate keys"},
+		{"priority cookie", "priority:a", "Rotate keys"},
+		{"property value", "prop:Customer=acme", "Fix login bug"},
+		{"property exists", "prop:customer", "Fix login bug|Rotate keys"},
+		{"has estimate", "has:estimate", "Write design doc"},
+		{"in cycle", "in:cycle", "Learn Rust"},
+		{"blocked", "is:blocked", "Rotate keys"},
+		{"ready", "is:ready", "Fix login bug|Write design doc"},
+		{"due range", "due:2025-06-15..2025-06-30", "Fix login bug|Write design doc"},
+		{"free text", "design", "Write design doc"},
+		{"quoted phrase", `"login bug"`, "Fix login bug"},
+		{"quoted operator is text", `"AND"`, ""},
+		{"double negation", "NOT NOT project:web", "Fix login bug|Ship release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryTasks(cfg, tasks, tt.query)
+			if err != nil {
+				t.Fatalf("QueryTasks(%q) error: %v", tt.query, err)
+			}
+			if titles := queryTitles(got); titles != tt.want {
+				t.Errorf("QueryTasks(%q) = %q, want %q", tt.query, titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantPos int
+		wantMsg string
+	}{
+		{"(#a OR #b", 1, `unclosed "("`},
+		{"#a )", 4, `unmatched ")"`},
+		{"#a AND", 7, "expected a term"},
+		{"OR #a", 1, "expected a term before OR"},
+		{"()", 2, "empty parentheses"},
+		{`"open`, 1, "unterminated quote"},
+		{"#a colour:red", 4, `unknown field "colour"`},
+		{"category:blocked", 10, `unknown category "blocked"`},
+		{"has:kids", 5, `unknown has: value "kids"`},
+		{"over:time", 6, `unknown over: value "time"`},
+		{"is:stuck", 4, `unknown is: value "stuck"`},
+		{"priority:D", 10, `unknown priority "D"`},
+		{"project:", 9, "missing value for project:"},
+		{"prop:=x", 6, "missing property name"},
+		{"@d:<2025-13-45", 5, `invalid date "2025-13-45"`},
+		{">>", 1, "expected an assignee after >>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			var qe *QueryError
+			if !errors.As(err, &qe) {
+				t.Fatalf("ParseQuery(%q) error = %v, want *QueryError", tt.query, err)
+			}
+			if qe.Pos != tt.wantPos {
+				t.Errorf("ParseQuery(%q) pos = %d, want %d", tt.query, qe.Pos, tt.wantPos)
+			}
+			if !strings.Contains(qe.Msg, tt.wantMsg) {
+				t.Errorf("ParseQuery(%q) msg = %q, want it to contain %q", tt.query, qe.Msg, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vinayprograms/karya/internal/config"
+	"github.com/vinayprograms/karya/internal/zet"
+)
+
+// RefileAppend as a refile position appends the block to the end of the
+// destination file.
+const RefileAppend = 0
+
+// RefileTask moves the task's whole block (as returned by ReadRawBlock: the
+// task line, its children and sub-lines such as CLOCK and properties) to
+// destFile and removes it from the source file.
+//
+// position is the 1-based line in destFile before which the block is
+// inserted; RefileAppend (0) or a position past the end appends it. The block
+// is re-indented to match the line it is inserted before, or to the left
+// margin when appended. On success the task's FilePath, LineNum and
+// IndentLevel point at the new location; reload to refresh its children.
+func RefileTask(t *Task, destFile string, position int) error {
+	if t.FilePath == "" || t.LineNum == 0 {
+		return fmt.Errorf("task has no file location")
+	}
+	if position < 0 {
+		return fmt.Errorf("invalid refile position %d", position)
+	}
+
+	unlock, err := lockFiles(t.FilePath, destFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	srcContent, err := os.ReadFile(t.FilePath)
+	if err != nil {
+		return err
+	}
+	srcLines := strings.Split(string(srcContent), "\n")
+	start, err := locateTask(srcLines, t, taskFileChanged(t))
+	if err != nil {
+		return err
+	}
+	_, level := StripLinePrefix(srcLines[start])
+	block := trimBlock(blockLines(srcLines, start+1, level))
+	end := start + len(block)
+	srcLines = append(srcLines[:start:start], srcLines[end:]...)
+
+	sameFile := filepath.Clean(destFile) == filepath.Clean(t.FilePath)
+	var destContent []byte
+	var destLines []string
+	if sameFile {
+		destContent, destLines = srcContent, srcLines
+		if position > start+1 && position <= end {
+			return fmt.Errorf("cannot refile a task into its own block")
+		}
+		if position > end {
+			position -= len(block)
+		}
+	} else {
+		destContent, err = os.ReadFile(destFile)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(destContent) > 0 {
+			destLines = strings.Split(string(destContent), "\n")
+		}
+	}
+
+	// Insert before the given line, or after the last non-empty line
+	appending := position == RefileAppend || position > len(destLines)
+	idx, indent := len(destLines), ""
+	if appending {
+		for idx > 0 && strings.TrimSpace(destLines[idx-1]) == "" {
+			idx--
+		}
+	} else {
+		idx = position - 1
+		line := destLines[idx]
+		indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	}
+	block = reindentBlock(block, indent)
+
+	taskIdx := idx
+	if appending && idx > 0 && !taskLikeRe.MatchString(strings.TrimSpace(destLines[idx-1])) {
+		// Keep the block apart from preceding prose
+		block = append([]string{""}, block...)
+		taskIdx++
+	}
+
+	newDest := make([]string, 0, len(destLines)+len(block)+1)
+	newDest = append(newDest, destLines[:idx]...)
+	newDest = append(newDest, block...)
+	if appending {
+		newDest = append(newDest, "")
+	} else {
+		newDest = append(newDest, destLines[idx:]...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destFile), 0755); err != nil {
+		return err
+	}
+	newDestContent := []byte(strings.Join(newDest, "\n"))
+	newSrcContent := []byte(strings.Join(srcLines, "\n"))
+	if sameFile {
+		err = writeIfUnchanged(destFile, destContent, newDestContent)
+	} else {
+		// Writing the destination first means a failure never loses the
+		// task; it is only ever in both files if restoring fails too
+		err = writePairIfUnchanged(destFile, destContent, newDestContent, t.FilePath, srcContent, newSrcContent)
+	}
+	if err != nil {
+		return err
+	}
+	summary := "Refile: " + t.Title
+	err = Journaled(t, summary, func() error {
+		if !sameFile {
+			if err := recordEdit(t, summary, t.FilePath, srcContent, newSrcContent); err != nil {
+				return err
+			}
+		}
+		return recordEdit(t, summary, destFile, destContent, newDestContent)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to journal edit: %w", err)
+	}
+
+	_, t.IndentLevel = StripLinePrefix(newDest[taskIdx])
+	t.FilePath = destFile
+	t.LineNum = taskIdx + 1
+	if info, err := os.Stat(destFile); err == nil {
+		t.stamp = stampOf(info)
+	}
+	return nil
+}
+
+// trimBlock drops the trailing blank lines of a task block.
+func trimBlock(block []string) []string {
+	for len(block) > 1 && strings.TrimSpace(block[len(block)-1]) == "" {
+		block = block[:len(block)-1]
+	}
+	return block
+}
+
+// reindentBlock replaces the block's base indentation (that of its first
+// line) with indent, keeping relative indentation of the lines below.
+func reindentBlock(block []string, indent string) []string {
+	first := block[0]
+	base := first[:len(first)-len(strings.TrimLeft(first, " \t"))]
+	out := make([]string, len(block))
+	for i, line := range block {
+		switch {
+		case strings.TrimSpace(line) == "":
+			out[i] = ""
+		case strings.HasPrefix(line, base):
+			out[i] = indent + line[len(base):]
+		default:
+			out[i] = indent + strings.TrimLeft(line, " \t")
+		}
+	}
+	return out
+}
+
+// RefileTarget is a destination offered by the refile picker.
+type RefileTarget struct {
+	Label   string // "project", "project/zettelID" or "inbox"
+	Detail  string // note title or file name
+	Project string
+	Path    string // empty for a project: resolved to its latest note on use
+}
+
+// ListRefileTargets lists the inbox, every project and every project note.
+func ListRefileTargets(c *config.Config) ([]RefileTarget, error) {
+	targets := []RefileTarget{{Label: "inbox", Detail: "inbox file", Project: "inbox", Path: c.GetInboxFilePath()}}
+
+	projects, err := ListProjectNames(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		targets = append(targets, RefileTarget{Label: p, Detail: "latest note", Project: p})
+	}
+
+	files, err := FindFiles(c, "")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		rel, err := filepath.Rel(c.Directories.Projects, f)
+		if err != nil {
+			continue
+		}
+		parts := strings.Split(rel, string(filepath.Separator))
+		if len(parts) < 2 {
+			continue
+		}
+		target := RefileTarget{Project: parts[0], Path: f}
+		if len(parts) == 4 && parts[1] == "notes" && zet.IsValidZettelID(parts[2]) && parts[3] == "README.md" {
+			target.Label = parts[0] + "/" + parts[2]
+			target.Detail, _ = zet.GetZettelTitle(filepath.Join(c.Directories.Projects, parts[0], "notes"), parts[2])
+		} else {
+			target.Label = filepath.ToSlash(rel)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// ResolveRefileTarget turns a destination into a file path. dest is "inbox",
+// a project name (its latest note, created if needed), "project/zettelID", or
+// a path to a markdown file. Files created on the way are returned for
+// committing.
+func ResolveRefileTarget(c *config.Config, dest string) (string, []string, error) {
+	if dest == "" {
+		return "", nil, fmt.Errorf("destination is required")
+	}
+	if strings.HasSuffix(strings.ToLower(dest), ".md") {
+		if _, err := os.Stat(dest); err != nil {
+			return "", nil, fmt.Errorf("destination %s: %w", dest, err)
+		}
+		return dest, nil, nil
+	}
+	project, noteID, _ := strings.Cut(dest, "/")
+	return addTarget(c, project, noteID)
+}
@@ -0,0 +1,230 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRefileTask(t *testing.T) {
+	src := strings.Join([]string{
+		"# Source",
+		"",
+		"TODO: Keep me",
+		"- TODO: Parent",
+		"  - CLOCK: [2025-06-01 Mon 09:00]--[2025-06-01 Mon 10:00] =>  1:00",
+		"  - owner:: alice",
+		"  - DOING: Child",
+		"    - notes about the child",
+		"",
+		"TODO: After",
+		"",
+	}, "\n")
+	movedBlock := []string{
+		"- TODO: Parent",
+		"  - CLOCK: [2025-06-01 Mon 09:00]--[2025-06-01 Mon 10:00] =>  1:00",
+		"  - owner:: alice",
+		"  - DOING: Child",
+		"    - notes about the child",
+	}
+	srcAfter := "# Source\n\nTODO: Keep me\n\nTODO: After\n"
+
+	tests := []struct {
+		name     string
+		dest     string // "" refiles within the source file
+		position int
+		wantDest string
+		wantSrc  string
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name:     "append after prose",
+			dest:     "# Dest\n\nSome text.\n",
+			wantDest: "# Dest\n\nSome text.\n\n" + strings.Join(movedBlock, "\n") + "\n",
+			wantSrc:  srcAfter,
+			wantLine: 5,
+		},
+		{
+			name:     "append joins task list",
+			dest:     "# Dest\n\nTODO: Existing\n\n\n",
+			wantDest: "# Dest\n\nTODO: Existing\n" + strings.Join(movedBlock, "\n") + "\n",
+			wantSrc:  srcAfter,
+			wantLine: 4,
+		},
+		{
+			name:     "insert re-indents to destination",
+			dest:     "# Dest\n\n- TODO: Outer\n    - TODO: Inner\n",
+			position: 4,
+			wantDest: "# Dest\n\n- TODO: Outer\n" + strings.Join(indentLines(movedBlock, "    "), "\n") + "\n    - TODO: Inner\n",
+			wantSrc:  srcAfter,
+			wantLine: 4,
+		},
+		{
+			name:     "new file",
+			wantDest: strings.Join(movedBlock, "\n") + "\n",
+			wantSrc:  srcAfter,
+			wantLine: 1,
+		},
+		{
+			name:     "within the same file",
+			position: 3,
+			wantSrc:  "# Source\n\n" + strings.Join(movedBlock, "\n") + "\nTODO: Keep me\n\nTODO: After\n",
+			wantLine: 3,
+		},
+		{
+			name:     "same file after the block",
+			position: 10,
+			wantSrc:  "# Source\n\nTODO: Keep me\n\n" + strings.Join(movedBlock, "\n") + "\nTODO: After\n",
+			wantLine: 5,
+		},
+		{name: "into its own block", position: 6, wantErr: "own block"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			srcPath := filepath.Join(dir, "src.md")
+			os.WriteFile(srcPath, []byte(src), 0644)
+			destPath := srcPath
+			if tt.dest != "" || tt.wantDest != "" {
+				destPath = filepath.Join(dir, "dest.md")
+				if tt.dest != "" {
+					os.WriteFile(destPath, []byte(tt.dest), 0644)
+				}
+			}
+
+			cfg := createTestConfig()
+			cfg.Directories.Projects = dir
+			tasks, err := ProcessFile(cfg, srcPath)
+			if err != nil {
+				t.Fatalf("ProcessFile() error: %v", err)
+			}
+			parent := tasks[1]
+
+			err = RefileTask(parent, destPath, tt.position)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RefileTask() error = %v, want %q", err, tt.wantErr)
+				}
+				if got, _ := os.ReadFile(srcPath); string(got) != src {
+					t.Errorf("source changed on error: %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RefileTask() error: %v", err)
+			}
+
+			if got, _ := os.ReadFile(srcPath); string(got) != tt.wantSrc {
+				t.Errorf("source = %q, want %q", got, tt.wantSrc)
+			}
+			if tt.wantDest != "" {
+				if got, _ := os.ReadFile(destPath); string(got) != tt.wantDest {
+					t.Errorf("dest = %q, want %q", got, tt.wantDest)
+				}
+			}
+			if parent.FilePath != destPath || parent.LineNum != tt.wantLine {
+				t.Errorf("task at %s:%d, want %s:%d", parent.FilePath, parent.LineNum, destPath, tt.wantLine)
+			}
+
+			// The moved block parses back with its child and sub-lines
+			moved, _ := ProcessFile(cfg, destPath)
+			var found *Task
+			for _, mt := range moved {
+				if mt.Title == "Parent" {
+					found = mt
+				}
+			}
+			if found == nil || len(found.Children) != 1 || found.Properties["owner"] != "alice" {
+				t.Errorf("refiled task did not keep its children and properties: %+v", found)
+			}
+		})
+	}
+}
+
+func indentLines(lines []string, indent string) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = indent + l
+	}
+	return out
+}
+
+func TestResolveRefileTarget(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "web", "notes", "20250101000000"), 0755)
+	os.WriteFile(filepath.Join(dir, "web", "notes", "20250101000000", "README.md"), []byte("# Old\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "web", "notes", "20250102000000"), 0755)
+	os.WriteFile(filepath.Join(dir, "web", "notes", "20250102000000", "README.md"), []byte("# New\n"), 0644)
+
+	cfg := createTestConfig()
+	cfg.Directories.Projects = dir
+	cfg.Directories.Karya = dir
+	cfg.Todo.Structured = true
+
+	tests := []struct {
+		dest    string
+		want    string
+		wantErr bool
+	}{
+		{"inbox", filepath.Join(dir, "inbox.md"), false},
+		{"web", filepath.Join(dir, "web", "notes", "20250102000000", "README.md"), false},
+		{"web/20250101000000", filepath.Join(dir, "web", "notes", "20250101000000", "README.md"), false},
+		{"web/20240101000000", "", true},
+		{"missing", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dest, func(t *testing.T) {
+			got, _, err := ResolveRefileTarget(cfg, tt.dest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveRefileTarget(%q) error = %v, wantErr %v", tt.dest, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveRefileTarget(%q) = %q, want %q", tt.dest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefilePicker(t *testing.T) {
+	targets := []RefileTarget{
+		{Label: "inbox", Detail: "inbox file", Project: "inbox", Path: "/inbox.md"},
+		{Label: "web", Detail: "latest note", Project: "web"},
+		{Label: "web/20250101000000", Detail: "Launch plan", Project: "web", Path: "/web/a.md"},
+		{Label: "infra/20250101000000", Detail: "Key rotation", Project: "infra", Path: "/infra/b.md"},
+	}
+	current := &Task{Title: "x", FilePath: "/web/a.md"}
+
+	tests := []struct {
+		name string
+		keys []string
+		want string // label of the selected target, "" if none
+	}{
+		{"first match by default", []string{"enter"}, "inbox"},
+		{"own file is excluded", []string{"l", "a", "u", "n", "c", "h", "enter"}, ""},
+		{"fuzzy subsequence", []string{"k", "r", "o", "t", "enter"}, "infra/20250101000000"},
+		{"word starts rank higher", []string{"w", "e", "b", "enter"}, "web"},
+		{"cursor moves", []string{"down", "enter"}, "web"},
+		{"backspace widens", []string{"z", "z", "backspace", "backspace", "up", "enter"}, "infra/20250101000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := NewRefilePicker(current, targets)
+			for _, k := range tt.keys {
+				rp.Update(k)
+			}
+			got := ""
+			if rp.Selected != nil {
+				got = rp.Selected.Label
+			}
+			if got != tt.want {
+				t.Errorf("selected %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// refilePickerRows is the number of matches shown at once.
+const refilePickerRows = 12
+
+// RefilePicker lets the user choose a refile destination by typing a fuzzy
+// query over projects, their notes and the inbox.
+type RefilePicker struct {
+	Task      *Task
+	Targets   []RefileTarget
+	Query     string
+	Matches   []RefileTarget
+	Cursor    int
+	Confirmed bool
+	Cancelled bool
+	Selected  *RefileTarget
+}
+
+func NewRefilePicker(t *Task, targets []RefileTarget) *RefilePicker {
+	rp := &RefilePicker{Task: t, Targets: targets}
+	rp.filter()
+	return rp
+}
+
+// filter recomputes the matches for the current query, best first. The
+// task's own file is left out since refiling there would be a no-op.
+func (rp *RefilePicker) filter() {
+	type scored struct {
+		target RefileTarget
+		score  int
+	}
+	var results []scored
+	for _, target := range rp.Targets {
+		if target.Path != "" && rp.Task != nil && target.Path == rp.Task.FilePath {
+			continue
+		}
+		score, ok := fuzzyScore(rp.Query, target.Label+" "+target.Detail)
+		if ok {
+			results = append(results, scored{target, score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+
+	rp.Matches = rp.Matches[:0]
+	for _, r := range results {
+		rp.Matches = append(rp.Matches, r.target)
+	}
+	if rp.Cursor >= len(rp.Matches) {
+		rp.Cursor = 0
+	}
+}
+
+// fuzzyScore reports whether every character of pattern (ignoring spaces)
+// appears in s in order, case-insensitively, and scores the match:
+// consecutive characters and matches at word starts score higher.
+func fuzzyScore(pattern, s string) (int, bool) {
+	p := []rune(strings.ToLower(strings.ReplaceAll(pattern, " ", "")))
+	r := []rune(strings.ToLower(s))
+	score, pi, prev := 0, 0, -2
+	for i := 0; i < len(r) && pi < len(p); i++ {
+		if r[i] != p[pi] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 2
+		}
+		if i == 0 || strings.ContainsRune(" /-_", r[i-1]) {
+			score += 3
+		}
+		prev = i
+		pi++
+	}
+	return score, pi == len(p)
+}
+
+func (rp *RefilePicker) Update(key string) {
+	if rp.Confirmed || rp.Cancelled {
+		return
+	}
+
+	switch key {
+	case "down", "ctrl+n", "ctrl+j":
+		if len(rp.Matches) > 0 {
+			rp.Cursor = (rp.Cursor + 1) % len(rp.Matches)
+		}
+	case "up", "ctrl+p", "ctrl+k":
+		if len(rp.Matches) > 0 {
+			rp.Cursor = (rp.Cursor - 1 + len(rp.Matches)) % len(rp.Matches)
+		}
+	case "enter":
+		if len(rp.Matches) > 0 {
+			target := rp.Matches[rp.Cursor]
+			rp.Selected = &target
+			rp.Confirmed = true
+		}
+	case "esc":
+		rp.Cancelled = true
+	case "backspace":
+		if runes := []rune(rp.Query); len(runes) > 0 {
+			rp.Query = string(runes[:len(runes)-1])
+			rp.filter()
+		}
+	case "ctrl+u":
+		rp.Query = ""
+		rp.filter()
+	default:
+		if len(key) == 1 && key[0] >= 32 && key[0] <= 126 {
+			rp.Query += key
+			rp.Cursor = 0
+			rp.filter()
+		}
+	}
+}
+
+func (rp *RefilePicker) View(width int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+
+	var view strings.Builder
+	title := "Refile"
+	if rp.Task != nil {
+		title += ": " + rp.Task.Title
+	}
+	view.WriteString(headerStyle.Render(title))
+	view.WriteString("\n\n")
+	view.WriteString("> " + rp.Query + "▓")
+	view.WriteString("\n\n")
+
+	// Scroll so the cursor stays visible
+	first := 0
+	if rp.Cursor >= refilePickerRows {
+		first = rp.Cursor - refilePickerRows + 1
+	}
+	last := min(first+refilePickerRows, len(rp.Matches))
+
+	labelWidth := 0
+	for _, m := range rp.Matches[first:last] {
+		labelWidth = max(labelWidth, len(m.Label))
+	}
+	for i := first; i < last; i++ {
+		m := rp.Matches[i]
+		detail := m.Detail
+		if avail := width - labelWidth - 12; avail > 0 && len([]rune(detail)) > avail {
+			detail = string([]rune(detail)[:avail]) + "…"
+		}
+		if i == rp.Cursor {
+			view.WriteString(fmt.Sprintf("%s %s  %s", cursorStyle.Render("█"),
+				headerStyle.Render(fmt.Sprintf("%-*s", labelWidth, m.Label)), dimStyle.Render(detail)))
+		} else {
+			view.WriteString(fmt.Sprintf("  %-*s  %s", labelWidth, m.Label, dimStyle.Render(detail)))
+		}
+		view.WriteString("\n")
+	}
+	if len(rp.Matches) == 0 {
+		view.WriteString(dimStyle.Render("No matching destinations"))
+		view.WriteString("\n")
+	} else if len(rp.Matches) > refilePickerRows {
+		view.WriteString(dimStyle.Render(fmt.Sprintf("%d/%d", rp.Cursor+1, len(rp.Matches))))
+		view.WriteString("\n")
+	}
+
+	view.WriteString("\n")
+	view.WriteString(dimStyle.Render("type to filter • ↑/↓: select • enter: refile • esc: cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2)
+
+	return boxStyle.Render(view.String())
+}
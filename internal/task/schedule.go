@@ -2,8 +2,8 @@ package task
 
 import (
 	"fmt"
-	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,28 +22,75 @@ const (
 type RecurrenceSpec struct {
 	Mode     RecurrenceMode
 	Interval int
-	Unit     byte // 'd', 'w', 'm', 'y'
+	Unit     byte // 'd', 'w', 'm', 'y', 'b'
+
+	// Weekdays limits a weekly repeat to these days (+1w:mon,wed,fri).
+	Weekdays []time.Weekday
+	// MonthDays limits a monthly repeat to these days (+1m:15, +1m:2tue, +1m:-1bd).
+	MonthDays []MonthDay
+
+	// Count is how many occurrences are left, the stored date included
+	// (x10); 0 means no limit. Completing a task counts one down.
+	Count int
+	// Until is the last day an occurrence may fall on (..2025-12-31); the
+	// zero time means no end.
+	Until time.Time
 }
 
+// MonthDayKind says how a MonthDay picks its day.
+type MonthDayKind int
+
+const (
+	MonthDayDate     MonthDayKind = iota // 15, -1 — day of the month
+	MonthDayWeekday                      // 2tue, -1fri — Nth weekday of the month
+	MonthDayBusiness                     // 1bd, -1bd — Nth Monday-Friday of the month
+)
+
+// MonthDay selects one day of each month. N counts from the start of the
+// month when positive and back from its end when negative, so -1 is the last
+// day, -1fri the last Friday and -1bd the last business day. Months without
+// such a day (the 31st, a 5th Tuesday) are skipped.
+type MonthDay struct {
+	Kind    MonthDayKind
+	N       int
+	Weekday time.Weekday // MonthDayWeekday only
+}
+
+// recurrenceRe matches the repeat at the end of a date token; the DAYS group
+// is lazy so that a trailing xCOUNT isn't read as part of it.
+var recurrenceRe = regexp.MustCompile(`(\.\+|\+\+|\+)(\d+)([dwmyb])(?::([-A-Za-z0-9,]+?))?(?:x(\d+)|\.\.(\d{4}-\d{2}-\d{2}))?$`)
+
 type WarningSpec struct {
 	Days int
 }
 
+// Schedule is a parsed date token. Date, EndTime and EndDate are local
+// times; a timed token written with a zone is converted from it, and keeps it
+// in Zone.
 type Schedule struct {
-	Date       time.Time
-	HasTime    bool
-	EndTime    time.Time
-	HasEnd     bool
+	Date    time.Time
+	HasTime bool
+	EndTime time.Time
+	HasEnd  bool
+	// EndDate is the end of a range spanning days (DATE--DATE): its last
+	// day, or its end time when HasTime. Zero for other tokens.
+	EndDate    time.Time
+	Zone       *time.Location // zone of a timed token's suffix, nil for floating local times
 	Recurrence *RecurrenceSpec
 	Warning    *WarningSpec
 	Raw        string
 }
 
 // ParseSchedule parses a date token (the part after @s: or @d:) into a Schedule.
-// Token grammar: DATE[TTIME][RECURRENCE][WARNING]
+// Token grammar: (DATE[TTIME[-END][ZONE]] | RANGE)[RECURRENCE][WARNING]
+// RANGE: DATE--DATE | DATETTIME--DATETTIME[ZONE], spanning days
 // DATE: YYYY-MM-DD
-// TIME: THH:MM
-// RECURRENCE: +N[dwmyb] | .+N[dwmyb] | ++N[dwmyb]
+// TIME, END: THH:MM, -HH:MM
+// ZONE: Z | +HH:MM | [Area/City] | [±HH:MM] — a bare -HH:MM right after
+// TIME is an END
+// RECURRENCE: (+|.+|++)N[dwmyb][:DAYS][xCOUNT|..UNTIL]
+// DAYS: weekdays for w (mon,wed,fri); for m, days of the month (1,15,-1),
+// Nth weekdays (2tue, -1fri) or Nth business days (1bd, -1bd)
 // WARNING: !Nd
 func ParseSchedule(raw string) (*Schedule, error) {
 	if raw == "" {
@@ -64,14 +111,19 @@ func ParseSchedule(raw string) (*Schedule, error) {
 	// Extract recurrence suffix. Find the recurrence marker after the date portion.
 	// Date is at least 10 chars (YYYY-MM-DD), time adds 6 (THH:MM) = 16.
 	// Look for .+, ++, or + (in that order to avoid prefix conflicts).
-	recurrenceRe := regexp.MustCompile(`(\.\+|\+\+|\+)(\d+)([dwmyb])$`)
-	if m := recurrenceRe.FindStringSubmatch(remaining); len(m) > 0 {
-		matchStart := strings.LastIndex(remaining, m[0])
+	if m := recurrenceRe.FindStringSubmatchIndex(remaining); m != nil {
+		matchStart := m[0]
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return remaining[m[2*i]:m[2*i+1]]
+		}
 		// Only treat as recurrence if it's after the date portion (pos >= 10)
 		if matchStart >= 10 {
-			interval, _ := strconv.Atoi(m[2])
+			interval, _ := strconv.Atoi(group(2))
 			var mode RecurrenceMode
-			switch m[1] {
+			switch group(1) {
 			case ".+":
 				mode = RecurrenceFromDone
 			case "++":
@@ -79,34 +131,74 @@ func ParseSchedule(raw string) (*Schedule, error) {
 			default:
 				mode = RecurrenceFixed
 			}
-			s.Recurrence = &RecurrenceSpec{
+			r := &RecurrenceSpec{
 				Mode:     mode,
 				Interval: interval,
-				Unit:     m[3][0],
+				Unit:     group(3)[0],
 			}
+			if days := group(4); days != "" {
+				if err := r.parseDays(strings.ToLower(days)); err != nil {
+					return nil, fmt.Errorf("invalid repeat %q: %w", remaining[matchStart:], err)
+				}
+			}
+			if count := group(5); count != "" {
+				r.Count, _ = strconv.Atoi(count)
+				if r.Count < 1 {
+					return nil, fmt.Errorf("invalid repeat %q: count must be at least 1", remaining[matchStart:])
+				}
+			}
+			if until := group(6); until != "" {
+				t, err := time.ParseInLocation("2006-01-02", until, time.Local)
+				if err != nil {
+					return nil, fmt.Errorf("invalid repeat end %q: %w", until, err)
+				}
+				r.Until = t
+			}
+			s.Recurrence = r
 			remaining = remaining[:matchStart]
 		}
 	}
 
+	// A range spanning days: both ends are dates, or both have a time
+	if i := strings.Index(remaining, "--"); i >= 0 {
+		if err := s.parseRange(remaining[:i], remaining[i+2:]); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
 	// Parse date and optional time from remaining
-	// Supports: YYYY-MM-DD, YYYY-MM-DDTHH:MM, YYYY-MM-DDTHH:MM-HH:MM (with end time)
+	// Supports: YYYY-MM-DD, YYYY-MM-DDTHH:MM, YYYY-MM-DDTHH:MM-HH:MM (with end time),
+	// each time optionally followed by a zone
 	if len(remaining) >= 16 && remaining[10] == 'T' {
-		// Date + Time: YYYY-MM-DDTHH:MM[-HH:MM]
-		t, err := time.ParseInLocation("2006-01-02T15:04", remaining[:16], time.Local)
+		// Date + Time: YYYY-MM-DDTHH:MM[-HH:MM][ZONE]
+		rest := remaining[16:]
+		var endT time.Time
+		// Check for end time: -HH:MM
+		if len(rest) >= 6 && rest[0] == '-' {
+			if t, err := time.Parse("15:04", rest[1:6]); err == nil {
+				endT = t
+				s.HasEnd = true
+				rest = rest[6:]
+			}
+		}
+		loc := time.Local
+		if zone := zoneRe.FindString(rest); zone != "" {
+			z, err := parseZone(zone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid datetime %q: %w", remaining, err)
+			}
+			s.Zone, loc = z, z
+		}
+
+		t, err := time.ParseInLocation("2006-01-02T15:04", remaining[:16], loc)
 		if err != nil {
 			return nil, fmt.Errorf("invalid datetime %q: %w", remaining, err)
 		}
-		s.Date = t
+		s.Date = t.In(time.Local)
 		s.HasTime = true
-
-		// Check for end time: -HH:MM
-		if len(remaining) >= 22 && remaining[16] == '-' {
-			endStr := remaining[17:22]
-			endT, err := time.ParseInLocation("15:04", endStr, time.Local)
-			if err == nil {
-				s.EndTime = time.Date(t.Year(), t.Month(), t.Day(), endT.Hour(), endT.Minute(), 0, 0, time.Local)
-				s.HasEnd = true
-			}
+		if s.HasEnd {
+			s.EndTime = time.Date(t.Year(), t.Month(), t.Day(), endT.Hour(), endT.Minute(), 0, 0, loc).In(time.Local)
 		}
 	} else if len(remaining) >= 10 {
 		// Date only: YYYY-MM-DD
@@ -123,17 +215,132 @@ func ParseSchedule(raw string) (*Schedule, error) {
 	return s, nil
 }
 
+// parseRange parses the ends of a range token into Date and EndDate. A zone
+// after the end applies to both ends.
+func (s *Schedule) parseRange(from, to string) error {
+	raw := from + "--" + to
+	layout := "2006-01-02"
+	loc := time.Local
+	switch {
+	case len(from) == 16 && from[10] == 'T':
+		if len(to) < 16 || to[10] != 'T' {
+			return fmt.Errorf("invalid range %q: both ends need a time", raw)
+		}
+		if zone := to[16:]; zone != "" {
+			if zoneRe.FindString(zone) != zone {
+				return fmt.Errorf("invalid range %q", raw)
+			}
+			z, err := parseZone(zone)
+			if err != nil {
+				return fmt.Errorf("invalid range %q: %w", raw, err)
+			}
+			s.Zone, loc = z, z
+		}
+		layout, to = "2006-01-02T15:04", to[:16]
+		s.HasTime = true
+	case len(from) != 10 || len(to) != 10:
+		return fmt.Errorf("invalid range %q: want DATE--DATE or DATETTIME--DATETTIME", raw)
+	}
+
+	start, err := time.ParseInLocation(layout, from, loc)
+	if err != nil {
+		return fmt.Errorf("invalid range %q: %w", raw, err)
+	}
+	end, err := time.ParseInLocation(layout, to, loc)
+	if err != nil {
+		return fmt.Errorf("invalid range %q: %w", raw, err)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("invalid range %q: it must end after it starts", raw)
+	}
+	s.Date, s.EndDate = start.In(time.Local), end.In(time.Local)
+	return nil
+}
+
+// IsRange reports whether the token is a range spanning days (DATE--DATE).
+func (s *Schedule) IsRange() bool {
+	return !s.EndDate.IsZero()
+}
+
+// EndDateOn returns the end of the range occurrence starting at occ: as many
+// days after occ as EndDate is after Date, at EndDate's time, in the token's
+// zone, as a local time.
+func (s *Schedule) EndDateOn(occ time.Time) time.Time {
+	loc := s.loc()
+	end, d := s.EndDate.In(loc), occ.In(loc)
+	days := daysBetween(s.Date.In(loc), end)
+	return time.Date(d.Year(), d.Month(), d.Day()+days, end.Hour(), end.Minute(), 0, 0, loc).In(time.Local)
+}
+
+// SpanDays returns the local days the occurrence starting at occ covers: a
+// single day for tokens that aren't ranges. A timed range ending at
+// midnight doesn't cover the day it ends on.
+func (s *Schedule) SpanDays(occ time.Time) []time.Time {
+	first, last := truncateToDay(occ), truncateToDay(occ)
+	if s.IsRange() {
+		end := s.EndDateOn(occ)
+		last = truncateToDay(end)
+		if s.HasTime && end.Equal(last) && last.After(first) {
+			last = last.AddDate(0, 0, -1)
+		}
+	}
+	var days []time.Time
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+// daysBetween returns the number of calendar days from a's date to b's,
+// whatever their times and zones.
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// loc returns the zone the token's wall clock is in: its Zone, else local.
+func (s *Schedule) loc() *time.Location {
+	if s.Zone != nil {
+		return s.Zone
+	}
+	return time.Local
+}
+
+// EndOn returns the end time of the occurrence starting at occ: the token's
+// end clock time on occ's day in the token's zone, as a local time.
+func (s *Schedule) EndOn(occ time.Time) time.Time {
+	d := occ.In(s.loc())
+	end := s.EndTime.In(s.loc())
+	return time.Date(d.Year(), d.Month(), d.Day(), end.Hour(), end.Minute(), 0, 0, s.loc()).In(time.Local)
+}
+
 // FormatToken reconstructs the date token string from the Schedule.
 func (s *Schedule) FormatToken() string {
 	var b strings.Builder
 
-	if s.HasTime {
-		b.WriteString(s.Date.Format("2006-01-02T15:04"))
+	switch {
+	case s.IsRange() && s.HasTime:
+		b.WriteString(s.Date.In(s.loc()).Format("2006-01-02T15:04"))
+		b.WriteString("--")
+		b.WriteString(s.EndDate.In(s.loc()).Format("2006-01-02T15:04"))
+		if s.Zone != nil {
+			b.WriteString(formatZone(s.Zone, true))
+		}
+	case s.IsRange():
+		b.WriteString(s.Date.Format("2006-01-02"))
+		b.WriteString("--")
+		b.WriteString(s.EndDate.Format("2006-01-02"))
+	case s.HasTime:
+		b.WriteString(s.Date.In(s.loc()).Format("2006-01-02T15:04"))
 		if s.HasEnd {
 			b.WriteByte('-')
-			b.WriteString(s.EndTime.Format("15:04"))
+			b.WriteString(s.EndTime.In(s.loc()).Format("15:04"))
 		}
-	} else {
+		if s.Zone != nil {
+			b.WriteString(formatZone(s.Zone, s.HasEnd))
+		}
+	default:
 		b.WriteString(s.Date.Format("2006-01-02"))
 	}
 
@@ -148,6 +355,17 @@ func (s *Schedule) FormatToken() string {
 		}
 		b.WriteString(strconv.Itoa(s.Recurrence.Interval))
 		b.WriteByte(s.Recurrence.Unit)
+		if days := s.Recurrence.formatDays(); days != "" {
+			b.WriteByte(':')
+			b.WriteString(days)
+		}
+		if s.Recurrence.Count > 0 {
+			b.WriteByte('x')
+			b.WriteString(strconv.Itoa(s.Recurrence.Count))
+		} else if !s.Recurrence.Until.IsZero() {
+			b.WriteString("..")
+			b.WriteString(s.Recurrence.Until.Format("2006-01-02"))
+		}
 	}
 
 	if s.Warning != nil {
@@ -159,6 +377,203 @@ func (s *Schedule) FormatToken() string {
 	return b.String()
 }
 
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// parseDays parses the DAYS part of a repeat: weekday names for a weekly
+// repeat, month days for a monthly one.
+func (r *RecurrenceSpec) parseDays(days string) error {
+	for _, item := range strings.Split(days, ",") {
+		switch r.Unit {
+		case 'w':
+			wd, ok := parseWeekday(item)
+			if !ok {
+				return fmt.Errorf("unknown weekday %q", item)
+			}
+			if !slices.Contains(r.Weekdays, wd) {
+				r.Weekdays = append(r.Weekdays, wd)
+			}
+		case 'm':
+			md, err := parseMonthDay(item)
+			if err != nil {
+				return err
+			}
+			r.MonthDays = append(r.MonthDays, md)
+		default:
+			return fmt.Errorf("days can only follow a w or m repeat")
+		}
+	}
+	slices.Sort(r.Weekdays)
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	i := slices.Index(weekdayNames, name)
+	return time.Weekday(i), i >= 0
+}
+
+// parseMonthDay parses 15, -1, 2tue, -1fri, 1bd or -1bd.
+func parseMonthDay(item string) (MonthDay, error) {
+	end := 0
+	if strings.HasPrefix(item, "-") {
+		end = 1
+	}
+	for end < len(item) && item[end] >= '0' && item[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(item[:end])
+	if err != nil || n == 0 {
+		return MonthDay{}, fmt.Errorf("invalid month day %q", item)
+	}
+	limit := 31
+	md := MonthDay{Kind: MonthDayDate, N: n}
+	switch suffix := item[end:]; suffix {
+	case "":
+	case "bd":
+		md.Kind, limit = MonthDayBusiness, 23
+	default:
+		wd, ok := parseWeekday(suffix)
+		if !ok {
+			return MonthDay{}, fmt.Errorf("invalid month day %q", item)
+		}
+		md.Kind, md.Weekday, limit = MonthDayWeekday, wd, 5
+	}
+	if n > limit || n < -limit {
+		return MonthDay{}, fmt.Errorf("month day %q out of range", item)
+	}
+	return md, nil
+}
+
+func (r *RecurrenceSpec) formatDays() string {
+	var items []string
+	for _, wd := range r.Weekdays {
+		items = append(items, weekdayNames[wd])
+	}
+	for _, md := range r.MonthDays {
+		items = append(items, md.String())
+	}
+	return strings.Join(items, ",")
+}
+
+// String returns the day as written in a date token.
+func (md MonthDay) String() string {
+	switch md.Kind {
+	case MonthDayWeekday:
+		return strconv.Itoa(md.N) + weekdayNames[md.Weekday]
+	case MonthDayBusiness:
+		return strconv.Itoa(md.N) + "bd"
+	}
+	return strconv.Itoa(md.N)
+}
+
+// matches reports whether d is the day md selects in d's month.
+func (md MonthDay) matches(d time.Time) bool {
+	last := daysIn(d.Year(), d.Month())
+	switch md.Kind {
+	case MonthDayWeekday:
+		if d.Weekday() != md.Weekday {
+			return false
+		}
+		if md.N > 0 {
+			return (d.Day()-1)/7+1 == md.N
+		}
+		return (last-d.Day())/7+1 == -md.N
+	case MonthDayBusiness:
+		if !isBusinessDay(d) {
+			return false
+		}
+		// Count business days from d to the start (or end) of the month
+		n, step := 0, -1
+		if md.N < 0 {
+			step = 1
+		}
+		for day := d; day.Month() == d.Month(); day = day.AddDate(0, 0, step) {
+			if isBusinessDay(day) {
+				n++
+			}
+		}
+		if md.N < 0 {
+			return -n == md.N
+		}
+		return n == md.N
+	}
+	if md.N > 0 {
+		return d.Day() == md.N
+	}
+	return d.Day() == last+md.N+1
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func isBusinessDay(d time.Time) bool {
+	return d.Weekday() != time.Saturday && d.Weekday() != time.Sunday
+}
+
+// hasDays reports whether the repeat is limited to certain days.
+func (r *RecurrenceSpec) hasDays() bool {
+	return len(r.Weekdays) > 0 || len(r.MonthDays) > 0
+}
+
+// Next returns the first date of the repeat pattern after t, keeping t's
+// time of day, and false when there is none. Without DAYS that is t plus
+// one interval. With them, it is the next matching day in t's week (weeks
+// start on Monday) or month, else in the week or month one interval later;
+// weeks or months with no matching day are skipped. Count and Until are
+// not applied here (see Continues).
+func (r *RecurrenceSpec) Next(t time.Time) (time.Time, bool) {
+	if !r.hasDays() {
+		return addInterval(t, r.Interval, r.Unit), true
+	}
+	day := truncateToDay(t)
+	var period time.Time
+	if r.Unit == 'w' {
+		period = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	} else {
+		period = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	}
+	// A 5th weekday turns up at least every few months; 100 periods is
+	// plenty for any pattern that matches at all.
+	for range 100 {
+		next := r.nextPeriod(period, 1)
+		for d := period; d.Before(next); d = d.AddDate(0, 0, 1) {
+			if d.After(day) && r.matches(d) {
+				return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location()), true
+			}
+		}
+		period = r.nextPeriod(period, r.Interval)
+	}
+	return time.Time{}, false
+}
+
+func (r *RecurrenceSpec) nextPeriod(period time.Time, n int) time.Time {
+	if r.Unit == 'w' {
+		return period.AddDate(0, 0, 7*n)
+	}
+	return period.AddDate(0, n, 0)
+}
+
+func (r *RecurrenceSpec) matches(d time.Time) bool {
+	if r.Unit == 'w' {
+		return slices.Contains(r.Weekdays, d.Weekday())
+	}
+	for _, md := range r.MonthDays {
+		if md.matches(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// Continues reports whether the nth occurrence of the series (the stored
+// date being the first) falls on d within the repeat's count and end date.
+func (r *RecurrenceSpec) Continues(n int, d time.Time) bool {
+	if r.Count > 0 && n > r.Count {
+		return false
+	}
+	return r.Until.IsZero() || !truncateToDay(d).After(r.Until)
+}
+
 // AddInterval adds N units to the given time, handling month overflow.
 func AddInterval(t time.Time, interval int, unit byte) time.Time {
 	return addInterval(t, interval, unit)
@@ -213,35 +628,44 @@ func addMonths(t time.Time, months int) time.Time {
 	return time.Date(targetYear, targetMonth, d, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
 }
 
-// NextOccurrence computes the next occurrence date based on the recurrence mode.
-func (s *Schedule) NextOccurrence(completionDate time.Time) time.Time {
+// NextOccurrence computes the next occurrence date based on the recurrence
+// mode, and returns false when the series ends instead: the current date was
+// the last of its count, or the next one falls after its until date.
+func (s *Schedule) NextOccurrence(completionDate time.Time) (time.Time, bool) {
 	if s.Recurrence == nil {
-		return s.Date
+		return s.Date, false
 	}
 
+	// Step in the token's zone, so a 09:00 [Europe/Berlin] meeting stays at
+	// 09:00 there across DST changes
 	r := s.Recurrence
+	var next time.Time
+	ok := true
 	switch r.Mode {
 	case RecurrenceFixed:
 		// Advance from original date by one interval
-		return addInterval(s.Date, r.Interval, r.Unit)
+		next, ok = r.Next(s.Date.In(s.loc()))
 	case RecurrenceFromDone:
 		// Advance from completion date
-		return addInterval(completionDate, r.Interval, r.Unit)
+		next, ok = r.Next(completionDate.In(s.loc()))
 	case RecurrenceNextFuture:
 		// Keep advancing from original date until we're in the future
 		now := time.Now()
-		next := s.Date
-		for !next.After(now) {
-			next = addInterval(next, r.Interval, r.Unit)
+		next = s.Date.In(s.loc())
+		for ok && !next.After(now) {
+			next, ok = r.Next(next)
 		}
-		return next
+	default:
+		return s.Date, false
 	}
-	return s.Date
+	next = next.In(time.Local)
+	return next, ok && r.Continues(2, next)
 }
 
 // ExpandOccurrences generates all dates where this recurring schedule appears
 // within [rangeStart, rangeEnd]. For non-recurring schedules, returns the single date
 // if it falls within range. For .+ mode, only returns the stored date (cannot predict future).
+// The series stops after its count or until date.
 // Range comparisons use calendar-day granularity so timed tasks are included when
 // their day falls within the range, regardless of the time-of-day component.
 func (s *Schedule) ExpandOccurrences(rangeStart, rangeEnd time.Time) []time.Time {
@@ -265,35 +689,37 @@ func (s *Schedule) ExpandOccurrences(rangeStart, rangeEnd time.Time) []time.Time
 		return nil
 	}
 
-	// + and ++ modes: expand from original date forward
+	// + and ++ modes: expand from original date forward, stepping in the
+	// token's zone
 	var occurrences []time.Time
-	current := s.Date
+	current := s.Date.In(s.loc())
 	r := s.Recurrence
 
-	// Advance past dates before rangeStart
-	for truncateToDay(current).Before(startDay) {
-		current = addInterval(current, r.Interval, r.Unit)
-		if truncateToDay(current).After(endDay) {
-			return nil
+	for n := 1; ; n++ {
+		local := current.In(time.Local)
+		if truncateToDay(local).After(endDay) || !r.Continues(n, local) {
+			break
 		}
-	}
-
-	// Collect occurrences within range
-	for !truncateToDay(current).After(endDay) {
-		occurrences = append(occurrences, current)
-		current = addInterval(current, r.Interval, r.Unit)
-		if len(occurrences) > 366 {
-			break // safety cap
+		if !truncateToDay(local).Before(startDay) {
+			occurrences = append(occurrences, local)
+			if len(occurrences) > 366 {
+				break // safety cap
+			}
+		}
+		next, ok := r.Next(current)
+		if !ok {
+			break
 		}
+		current = next
 	}
 
 	return occurrences
 }
 
-
 // CompleteRecurringTask handles advancing a recurring task's date on completion.
 // Returns advanced=true if the task was recurring and the date was advanced.
-// If not recurring, returns false and the caller should proceed with normal completion.
+// If not recurring, or this was the last occurrence of its series, returns false
+// and the caller should proceed with normal completion.
 // Auto-clocks-out if the task has an active clock, then records a LOG transition entry.
 func CompleteRecurringTask(t *Task, c *config.Config, targetKeyword string) (advanced bool, err error) {
 	// Try scheduled date first, then due date
@@ -311,42 +737,67 @@ func CompleteRecurringTask(t *Task, c *config.Config, targetKeyword string) (adv
 	if err != nil || sched.Recurrence == nil {
 		return false, nil
 	}
+	nextDate, ok := sched.NextOccurrence(time.Now())
+	if !ok {
+		return false, nil
+	}
 
+	err = Journaled(t, fmt.Sprintf("Complete recurring %s -> %s: %s", t.Keyword, targetKeyword, t.Title), func() error {
+		return advanceRecurringTask(t, sched, nextDate, dateField, isScheduled, targetKeyword)
+	})
+	return err == nil, err
+}
+
+// advanceRecurringTask does the file edits of CompleteRecurringTask.
+func advanceRecurringTask(t *Task, sched *Schedule, nextDate time.Time, dateField string, isScheduled bool, targetKeyword string) error {
 	// Auto clock-out if active
 	if IsClockActive(t) {
 		if err := ClockOut(t); err != nil {
-			return false, fmt.Errorf("failed to auto clock-out: %w", err)
+			return fmt.Errorf("failed to auto clock-out: %w", err)
 		}
 	}
 
 	// Record state transition — one entry per scheduled day.
 	schedDay := time.Date(sched.Date.Year(), sched.Date.Month(), sched.Date.Day(), 0, 0, 0, 0, time.Local)
 	if err := recordOrUpdateTransition(t, schedDay, t.Keyword, targetKeyword); err != nil {
-		return false, fmt.Errorf("failed to record transition: %w", err)
+		return fmt.Errorf("failed to record transition: %w", err)
 	}
 
-	// Compute next occurrence
-	now := time.Now()
-	nextDate := sched.NextOccurrence(now)
-
-	// Build new token, preserving end time on the new date
+	// Build new token, preserving end time (or the range's length) on the
+	// new date and counting down the occurrences left
+	recurrence := *sched.Recurrence
+	if recurrence.Count > 0 {
+		recurrence.Count--
+	}
 	newSched := &Schedule{
 		Date:       nextDate,
 		HasTime:    sched.HasTime,
 		HasEnd:     sched.HasEnd,
-		Recurrence: sched.Recurrence,
+		Zone:       sched.Zone,
+		Recurrence: &recurrence,
 		Warning:    sched.Warning,
 	}
 	if sched.HasEnd {
-		newSched.EndTime = time.Date(nextDate.Year(), nextDate.Month(), nextDate.Day(),
-			sched.EndTime.Hour(), sched.EndTime.Minute(), 0, 0, time.Local)
+		newSched.EndTime = sched.EndOn(nextDate)
+	}
+	if sched.IsRange() {
+		newSched.EndDate = sched.EndDateOn(nextDate)
+	}
+	// A floating time gets the configured zone, keeping its clock time
+	if sched.HasTime && sched.Zone == nil && writeZone != nil {
+		newSched.Zone = writeZone
+		newSched.Date = wallClock(newSched.Date, writeZone)
+		newSched.EndTime = wallClock(newSched.EndTime, writeZone)
+		if newSched.IsRange() {
+			newSched.EndDate = wallClock(newSched.EndDate, writeZone)
+		}
 	}
 	newToken := newSched.FormatToken()
 	oldToken := dateField
 
 	// Replace in file
 	if err := advanceDateInFile(t, oldToken, newToken, isScheduled); err != nil {
-		return false, fmt.Errorf("failed to advance date: %w", err)
+		return fmt.Errorf("failed to advance date: %w", err)
 	}
 
 	// Update in memory
@@ -356,57 +807,28 @@ func CompleteRecurringTask(t *Task, c *config.Config, targetKeyword string) (adv
 		t.DueAt = newToken
 	}
 
-	return true, nil
+	return nil
 }
 
 // advanceDateInFile replaces the date token in the task's source file line.
 func advanceDateInFile(t *Task, oldToken, newToken string, isScheduled bool) error {
-	if t.FilePath == "" {
-		return fmt.Errorf("task has no file path")
-	}
-
-	content, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-
-	lines := strings.Split(string(content), "\n")
-
-	// Build search prefix (same logic as UpdateTaskStatus)
-	var searchPrefix string
-	if t.ID != "" {
-		searchPrefix = fmt.Sprintf("%s: [%s] %s", t.Keyword, t.ID, t.Title)
-	} else {
-		searchPrefix = fmt.Sprintf("%s: %s", t.Keyword, t.Title)
-	}
-
-	found := false
-	for i, line := range lines {
-		stripped, _ := StripLinePrefix(line)
-		if strings.HasPrefix(stripped, searchPrefix) {
-			// Found the line — replace the date token
-			if isScheduled {
-				if strings.Contains(line, "@s:"+oldToken) {
-					lines[i] = strings.Replace(line, "@s:"+oldToken, "@s:"+newToken, 1)
-					found = true
-				} else if strings.Contains(line, "@"+oldToken) {
-					lines[i] = strings.Replace(line, "@"+oldToken, "@"+newToken, 1)
-					found = true
-				}
-			} else {
-				if strings.Contains(line, "@d:"+oldToken) {
-					lines[i] = strings.Replace(line, "@d:"+oldToken, "@d:"+newToken, 1)
-					found = true
-				}
+	kind := SpanDue
+	if isScheduled {
+		kind = SpanScheduled
+	}
+	matched := false
+	_, err := editLine(t, "Advance recurring date: "+t.Title, func(m *LineModel) {
+		// Replace the date token on the task line, keeping its @s:/@d:/@ form
+		for i, s := range m.Spans {
+			if s.Kind == kind && s.Value() == oldToken {
+				m.Spans[i].Text = strings.TrimSuffix(s.Text, oldToken) + newToken
+				matched = true
+				return
 			}
-			break
 		}
+	})
+	if err == nil && !matched {
+		err = fmt.Errorf("task line not found or date token not matched")
 	}
-
-	if !found {
-		return fmt.Errorf("task line not found or date token not matched")
-	}
-
-	newContent := strings.Join(lines, "\n")
-	return os.WriteFile(t.FilePath, []byte(newContent), 0644)
+	return err
 }
@@ -3,6 +3,7 @@ package task
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -185,6 +186,21 @@ func TestFormatToken_RoundTrip(t *testing.T) {
 		"2025-03-21!3d",
 		"2025-03-15T14:00+1w!2d",
 		"2025-03-15T14:00-15:30+1w!2d",
+		"2025-06-02+1w:mon,wed,fri",
+		"2025-01-14+1m:2tue",
+		"2025-01-31++1m:-1,-1bdx6!3d",
+		"2025-06-02T09:00-09:15+2w:mon..2025-12-31",
+		"2025-03-15.+1dx3",
+		"2025-07-01T09:00[Europe/Berlin]",
+		"2025-07-01T09:00Z+1d",
+		"2025-07-01T09:00+05:30",
+		"2025-07-01T09:00[-05:00]",
+		"2025-07-01T09:00-10:00-05:00+1w!1d",
+		"2025-07-01--2025-07-05",
+		"2025-07-07--2025-07-13+4w!2d",
+		"2025-07-01T18:00--2025-07-03T09:00",
+		"2025-07-01T18:00--2025-07-03T09:00-05:00",
+		"2025-07-01T18:00--2025-07-03T09:00[Europe/Berlin]+1m",
 	}
 	for _, tok := range tokens {
 		s, err := ParseSchedule(tok)
@@ -201,7 +217,7 @@ func TestFormatToken_RoundTrip(t *testing.T) {
 
 func TestNextOccurrence_Fixed(t *testing.T) {
 	s, _ := ParseSchedule("2025-03-15+1w")
-	next := s.NextOccurrence(time.Now())
+	next, _ := s.NextOccurrence(time.Now())
 	expected := time.Date(2025, 3, 22, 0, 0, 0, 0, time.Local)
 	if !next.Equal(expected) {
 		t.Errorf("next=%v, want %v", next, expected)
@@ -211,7 +227,7 @@ func TestNextOccurrence_Fixed(t *testing.T) {
 func TestNextOccurrence_FromDone(t *testing.T) {
 	s, _ := ParseSchedule("2025-03-15.+3d")
 	completion := time.Date(2025, 6, 10, 0, 0, 0, 0, time.Local)
-	next := s.NextOccurrence(completion)
+	next, _ := s.NextOccurrence(completion)
 	expected := time.Date(2025, 6, 13, 0, 0, 0, 0, time.Local)
 	if !next.Equal(expected) {
 		t.Errorf("next=%v, want %v", next, expected)
@@ -221,7 +237,7 @@ func TestNextOccurrence_FromDone(t *testing.T) {
 func TestNextOccurrence_NextFuture(t *testing.T) {
 	// A task scheduled far in the past with ++1w should jump to a future date
 	s, _ := ParseSchedule("2020-01-06++1w")
-	next := s.NextOccurrence(time.Now())
+	next, _ := s.NextOccurrence(time.Now())
 	if !next.After(time.Now()) {
 		t.Errorf("expected future date, got %v", next)
 	}
@@ -273,6 +289,364 @@ func TestExpandOccurrences_FromDoneOnlyShowsCurrent(t *testing.T) {
 	}
 }
 
+func TestParseSchedule_RecurrenceDays(t *testing.T) {
+	tests := []struct {
+		input     string
+		weekdays  []time.Weekday
+		monthDays []MonthDay
+		count     int
+		until     string
+	}{
+		{"2025-06-02+1w:mon,wed,fri", []time.Weekday{time.Monday, time.Wednesday, time.Friday}, nil, 0, ""},
+		{"2025-06-02+1w:FRI,mon,fri", []time.Weekday{time.Monday, time.Friday}, nil, 0, ""},
+		{"2025-01-14+1m:2tue", nil, []MonthDay{{Kind: MonthDayWeekday, N: 2, Weekday: time.Tuesday}}, 0, ""},
+		{"2025-01-31+1m:-1fri,-1", nil, []MonthDay{{Kind: MonthDayWeekday, N: -1, Weekday: time.Friday}, {Kind: MonthDayDate, N: -1}}, 0, ""},
+		{"2025-01-31+1m:-1bdx12", nil, []MonthDay{{Kind: MonthDayBusiness, N: -1}}, 12, ""},
+		{"2025-06-02+1wx10", nil, nil, 10, ""},
+		{"2025-06-02+1w:monx10", []time.Weekday{time.Monday}, nil, 10, ""},
+		{"2025-06-02T09:00-10:00+1d..2025-12-31!2d", nil, nil, 0, "2025-12-31"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			s, err := ParseSchedule(tc.input)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error: %v", err)
+			}
+			r := s.Recurrence
+			if r == nil {
+				t.Fatal("expected recurrence")
+			}
+			if !slices.Equal(r.Weekdays, tc.weekdays) || !slices.Equal(r.MonthDays, tc.monthDays) {
+				t.Errorf("days = %v %v, want %v %v", r.Weekdays, r.MonthDays, tc.weekdays, tc.monthDays)
+			}
+			if r.Count != tc.count {
+				t.Errorf("count = %d, want %d", r.Count, tc.count)
+			}
+			until := ""
+			if !r.Until.IsZero() {
+				until = r.Until.Format("2006-01-02")
+			}
+			if until != tc.until {
+				t.Errorf("until = %q, want %q", until, tc.until)
+			}
+		})
+	}
+}
+
+func TestParseSchedule_InvalidRecurrenceDays(t *testing.T) {
+	tests := []string{
+		"2025-03-15+1d:mon",
+		"2025-03-15+1w:2tue",
+		"2025-03-15+1w:mo",
+		"2025-03-15+1m:6tue",
+		"2025-03-15+1m:0",
+		"2025-03-15+1m:32",
+		"2025-03-15+1m:-24bd",
+		"2025-03-15+1wx0",
+		"2025-03-15+1w..2025-13-01",
+	}
+	for _, tc := range tests {
+		if _, err := ParseSchedule(tc); err == nil {
+			t.Errorf("ParseSchedule(%q) expected error, got nil", tc)
+		}
+	}
+}
+
+func TestRecurrenceSpec_Next(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		// Last day of the month
+		{"2025-01-31+1m:-1", "2025-02-28"},
+		{"2024-01-31+1m:-1", "2024-02-29"},
+		{"2025-02-28+1m:-1", "2025-03-31"},
+		// Days missing from a month are skipped, not capped as in addMonths
+		{"2025-01-30+1m:30", "2025-03-30"},
+		{"2025-01-31+1m:31", "2025-03-31"},
+		{"2024-02-29+12m:29", "2028-02-29"},
+		// Last business day when the month ends on a weekend
+		{"2025-05-30+1m:-1bd", "2025-06-30"},
+		{"2025-08-29+1m:-1bd", "2025-09-30"},
+		{"2025-02-28+1m:-1bd", "2025-03-31"},
+		// First business day, from an anchor that isn't one
+		{"2025-03-01+1m:1bd", "2025-03-03"},
+		{"2025-03-03+1m:1bd", "2025-04-01"},
+		// Nth and last weekday
+		{"2025-01-14+1m:2tue", "2025-02-11"},
+		{"2025-01-31+1m:-1fri", "2025-02-28"},
+		{"2025-01-31+1m:5fri", "2025-05-30"},
+		{"2025-01-15+1m:1,15", "2025-02-01"},
+		{"2025-01-15+2m:1,15", "2025-03-01"},
+		// Weekdays
+		{"2025-06-02+1w:mon,wed,fri", "2025-06-04"},
+		{"2025-06-06+1w:mon,wed,fri", "2025-06-09"},
+		{"2025-06-06+2w:mon,fri", "2025-06-16"},
+		{"2025-06-08+1w:sun", "2025-06-15"},
+		{"2025-06-03T09:30+1w:mon,thu", "2025-06-05T09:30"},
+		// Without days, the interval as before
+		{"2025-01-31+1m", "2025-02-28"},
+		{"2025-06-06+1b", "2025-06-09"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			s, err := ParseSchedule(tc.token)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error: %v", err)
+			}
+			next, ok := s.Recurrence.Next(s.Date)
+			got := next.Format("2006-01-02")
+			if s.HasTime {
+				got = next.Format("2006-01-02T15:04")
+			}
+			if !ok || got != tc.want {
+				t.Errorf("Next() = %s, %v, want %s", got, ok, tc.want)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence_Limits(t *testing.T) {
+	tests := []struct {
+		token  string
+		want   string
+		wantOK bool
+	}{
+		{"2025-06-02+1wx2", "2025-06-09", true},
+		{"2025-06-02+1wx1", "2025-06-09", false},
+		{"2025-06-02+1w..2025-06-09", "2025-06-09", true},
+		{"2025-06-02+1w..2025-06-08", "2025-06-09", false},
+		{"2025-06-02.+1dx1", "2025-06-11", false},
+	}
+
+	completion := time.Date(2025, 6, 10, 0, 0, 0, 0, time.Local)
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			s, _ := ParseSchedule(tc.token)
+			next, ok := s.NextOccurrence(completion)
+			if got := next.Format("2006-01-02"); got != tc.want || ok != tc.wantOK {
+				t.Errorf("NextOccurrence() = %s, %v, want %s, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestExpandOccurrences_Days(t *testing.T) {
+	tests := []struct {
+		token string
+		want  []string
+	}{
+		{"2025-06-02+1w:mon,wed,fri", []string{"2025-06-02", "2025-06-04", "2025-06-06", "2025-06-09", "2025-06-11", "2025-06-13"}},
+		{"2025-06-02+1w:mon,wed,frix4", []string{"2025-06-02", "2025-06-04", "2025-06-06", "2025-06-09"}},
+		{"2025-06-02+1d..2025-06-04", []string{"2025-06-02", "2025-06-03", "2025-06-04"}},
+		{"2025-05-30+1m:-1bd", []string{"2025-05-30"}},
+		{"2025-05-20+1w:tuex2", []string{"2025-05-27"}},
+	}
+
+	start := time.Date(2025, 5, 25, 0, 0, 0, 0, time.Local)
+	end := time.Date(2025, 6, 13, 0, 0, 0, 0, time.Local)
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			s, _ := ParseSchedule(tc.token)
+			var got []string
+			for _, occ := range s.ExpandOccurrences(start, end) {
+				got = append(got, occ.Format("2006-01-02"))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("ExpandOccurrences() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseSchedule_Zone(t *testing.T) {
+	tests := []struct {
+		token    string
+		wantZone string // "" for a floating time
+		wantUTC  string
+		wantEnd  string // end time in UTC, "" for none
+		wantErr  bool
+	}{
+		{"2025-07-01T09:00[Europe/Berlin]", "Europe/Berlin", "2025-07-01T07:00", "", false},
+		{"2025-01-15T09:00[Europe/Berlin]", "Europe/Berlin", "2025-01-15T08:00", "", false},
+		{"2025-07-01T09:00Z", "UTC", "2025-07-01T09:00", "", false},
+		{"2025-07-01T09:00+02:00+1w", "+02:00", "2025-07-01T07:00", "", false},
+		{"2025-07-01T09:00-10:00[America/New_York]", "America/New_York", "2025-07-01T13:00", "2025-07-01T14:00", false},
+		{"2025-07-01T09:00-10:00-05:00", "-05:00", "2025-07-01T14:00", "2025-07-01T15:00", false},
+		{"2025-07-01T09:00-05:00", "", "", "", false}, // an end time, not an offset
+		{"2025-07-01T09:00[Mars/Base]", "", "", "", true},
+		{"2025-07-01T09:00+23:00", "", "", "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			s, err := ParseSchedule(tc.token)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseSchedule() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tc.wantZone == "" {
+				if s.Zone != nil || !s.HasEnd {
+					t.Errorf("expected a floating time with an end, got zone %v, HasEnd %v", s.Zone, s.HasEnd)
+				}
+				return
+			}
+			if s.Zone == nil || s.Zone.String() != tc.wantZone {
+				t.Fatalf("Zone = %v, want %s", s.Zone, tc.wantZone)
+			}
+			if s.Date.Location() != time.Local {
+				t.Errorf("Date location = %s, want Local", s.Date.Location())
+			}
+			if got := s.Date.UTC().Format(stampLayout); got != tc.wantUTC {
+				t.Errorf("Date = %s UTC, want %s", got, tc.wantUTC)
+			}
+			if tc.wantEnd != "" {
+				if got := s.EndTime.UTC().Format(stampLayout); got != tc.wantEnd {
+					t.Errorf("EndTime = %s UTC, want %s", got, tc.wantEnd)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSchedule_Range(t *testing.T) {
+	withZones(t, "Europe/Berlin", "")
+	tests := []struct {
+		token     string
+		wantStart string // local
+		wantEnd   string
+		wantTime  bool
+		wantErr   bool
+	}{
+		{"2025-07-01--2025-07-05", "2025-07-01 00:00", "2025-07-05 00:00", false, false},
+		{"2025-07-07--2025-07-13+4w!2d", "2025-07-07 00:00", "2025-07-13 00:00", false, false},
+		{"2025-07-01T18:00--2025-07-03T09:00", "2025-07-01 18:00", "2025-07-03 09:00", true, false},
+		{"2025-07-01T18:00--2025-07-03T09:00[America/New_York]", "2025-07-02 00:00", "2025-07-03 15:00", true, false},
+		{"2025-07-05--2025-07-01", "", "", false, true},
+		{"2025-07-01--2025-07-01", "", "", false, true},
+		{"2025-07-01T09:00--2025-07-03", "", "", false, true},
+		{"2025-07-01--2025-07-03T09:00", "", "", false, true},
+		{"2025-07-01T09:00-10:00--2025-07-03T09:00", "", "", false, true},
+		{"2025-07-01T09:00--2025-07-03T09:00 later", "", "", false, true},
+		{"2025-07-01--2025-13-01", "", "", false, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			s, err := ParseSchedule(tc.token)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseSchedule() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !s.IsRange() || s.HasTime != tc.wantTime || s.HasEnd {
+				t.Fatalf("IsRange() = %v, HasTime = %v, HasEnd = %v", s.IsRange(), s.HasTime, s.HasEnd)
+			}
+			if got := s.Date.Format("2006-01-02 15:04"); got != tc.wantStart {
+				t.Errorf("Date = %s, want %s", got, tc.wantStart)
+			}
+			if got := s.EndDate.Format("2006-01-02 15:04"); got != tc.wantEnd {
+				t.Errorf("EndDate = %s, want %s", got, tc.wantEnd)
+			}
+		})
+	}
+}
+
+func TestSchedule_SpanDays(t *testing.T) {
+	tests := []struct {
+		token string
+		occ   string // occurrence start, "" for the stored date
+		want  []string
+	}{
+		{"2025-07-01", "", []string{"07-01"}},
+		{"2025-07-01T09:00-10:00", "", []string{"07-01"}},
+		{"2025-07-01--2025-07-03", "", []string{"07-01", "07-02", "07-03"}},
+		{"2025-07-01T18:00--2025-07-03T09:00", "", []string{"07-01", "07-02", "07-03"}},
+		{"2025-07-01T22:00--2025-07-02T00:00", "", []string{"07-01"}},
+		{"2025-07-01T22:00--2025-07-03T00:00", "", []string{"07-01", "07-02"}},
+		{"2025-06-28--2025-07-01+1m", "2025-07-28", []string{"07-28", "07-29", "07-30", "07-31"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			s, err := ParseSchedule(tc.token)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error: %v", err)
+			}
+			occ := s.Date
+			if tc.occ != "" {
+				occ, _ = time.ParseInLocation("2006-01-02", tc.occ, time.Local)
+			}
+			var got []string
+			for _, d := range s.SpanDays(occ) {
+				got = append(got, d.Format("01-02"))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("SpanDays() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpandOccurrences_Zone(t *testing.T) {
+	// New York moves its clocks on Mar 9, Berlin on Mar 30: for the three
+	// weeks in between a 09:00 New York meeting is an hour earlier in Berlin
+	withZones(t, "Europe/Berlin", "")
+	s, err := ParseSchedule("2025-03-03T09:00-10:00[America/New_York]+1w")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error: %v", err)
+	}
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)
+	var got, gotEnd []string
+	for _, occ := range s.ExpandOccurrences(start, end) {
+		got = append(got, occ.Format("01-02 15:04"))
+		gotEnd = append(gotEnd, s.EndOn(occ).Format("15:04"))
+	}
+	want := []string{"03-03 15:00", "03-10 14:00", "03-17 14:00", "03-24 14:00", "03-31 15:00"}
+	wantEnd := []string{"16:00", "15:00", "15:00", "15:00", "16:00"}
+	if !slices.Equal(got, want) || !slices.Equal(gotEnd, wantEnd) {
+		t.Errorf("ExpandOccurrences() = %v ending %v, want %v ending %v", got, gotEnd, want, wantEnd)
+	}
+}
+
+func TestCompleteRecurringTask_Zone(t *testing.T) {
+	tests := []struct {
+		name      string
+		writeZone string
+		token     string
+		want      string
+	}{
+		{"keeps its zone", "", "2025-03-03T09:00-10:00[America/New_York]+1w", "2025-03-10T09:00-10:00[America/New_York]+1w"},
+		{"keeps an offset", "Asia/Tokyo", "2025-03-03T09:00[-05:00]+1w", "2025-03-10T09:00[-05:00]+1w"},
+		{"floating gets the configured zone", "America/New_York", "2025-03-03T09:00-10:00+1w", "2025-03-10T09:00-10:00[America/New_York]+1w"},
+		{"dates stay floating", "America/New_York", "2025-03-03+1w", "2025-03-10+1w"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			withZones(t, "Europe/Berlin", tc.writeZone)
+			fp := filepath.Join(t.TempDir(), "test.md")
+			os.WriteFile(fp, []byte("TODO: Standup @s:"+tc.token+"\n"), 0644)
+			task := &Task{Keyword: "TODO", Title: "Standup", ScheduledAt: tc.token, FilePath: fp, LineNum: 1}
+
+			advanced, err := CompleteRecurringTask(task, createTestConfig(), "DONE")
+			if err != nil || !advanced {
+				t.Fatalf("CompleteRecurringTask() = %v, %v", advanced, err)
+			}
+			if task.ScheduledAt != tc.want {
+				t.Errorf("ScheduledAt = %q, want %q", task.ScheduledAt, tc.want)
+			}
+		})
+	}
+}
+
 func TestAddMonths_Overflow(t *testing.T) {
 	// Jan 31 + 1 month should be Feb 28 (non-leap year)
 	jan31 := time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local)
@@ -376,6 +750,63 @@ func TestCompleteRecurringTask_AutoClockOut(t *testing.T) {
 	}
 }
 
+func TestCompleteRecurringTask_Count(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, "test.md")
+	os.WriteFile(fp, []byte("TODO: Physio @s:2025-03-17+1w:mon,thux2\n"), 0644)
+
+	task := &Task{
+		Keyword:     "TODO",
+		Title:       "Physio",
+		ScheduledAt: "2025-03-17+1w:mon,thux2",
+		FilePath:    fp,
+		LineNum:     1,
+	}
+
+	cfg := createTestConfig()
+	advanced, err := CompleteRecurringTask(task, cfg, "DONE")
+	if err != nil || !advanced {
+		t.Fatalf("first completion: advanced=%v, err=%v", advanced, err)
+	}
+	data, _ := os.ReadFile(fp)
+	if !strings.Contains(string(data), "@s:2025-03-20+1w:mon,thux1") {
+		t.Errorf("expected date advanced to Thursday with one left, got: %s", data)
+	}
+
+	// The last occurrence completes normally
+	advanced, err = CompleteRecurringTask(task, cfg, "DONE")
+	if err != nil || advanced {
+		t.Fatalf("last completion: advanced=%v, err=%v", advanced, err)
+	}
+}
+
+func TestCompleteRecurringTask_Range(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"2025-07-07--2025-07-13+4w", "2025-08-04--2025-08-10+4w"},
+		{"2025-01-31--2025-02-02+1m", "2025-02-28--2025-03-02+1m"},
+		{"2025-07-04T18:00--2025-07-07T09:00+1w", "2025-07-11T18:00--2025-07-14T09:00+1w"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "test.md")
+			os.WriteFile(fp, []byte("TODO: On call @s:"+tc.token+"\n"), 0644)
+			task := &Task{Keyword: "TODO", Title: "On call", ScheduledAt: tc.token, FilePath: fp, LineNum: 1}
+
+			advanced, err := CompleteRecurringTask(task, createTestConfig(), "DONE")
+			if err != nil || !advanced {
+				t.Fatalf("CompleteRecurringTask() = %v, %v", advanced, err)
+			}
+			if task.ScheduledAt != tc.want {
+				t.Errorf("ScheduledAt = %q, want %q", task.ScheduledAt, tc.want)
+			}
+		})
+	}
+}
+
 func TestCompleteRecurringTask_NonRecurring(t *testing.T) {
 	dir := t.TempDir()
 	fp := filepath.Join(dir, "test.md")
@@ -43,6 +43,14 @@ func NewStatusPicker(t *Task, c *config.Config) *StatusPicker {
 		{"Completed", c.Colors.CompletedColor, c.Todo.Completed},
 		{"Someday", c.Colors.SomedayColor, c.Todo.Someday},
 	}
+	if t.Checkbox != "" {
+		// A checkbox is either checked or not
+		active, completed := c.CheckboxKeywords()
+		cats = []catDef{
+			{"Active", c.Colors.ActiveColor, []string{active}},
+			{"Completed", c.Colors.CompletedColor, []string{completed}},
+		}
+	}
 
 	for _, cat := range cats {
 		if len(cat.keywords) == 0 {
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,24 +20,40 @@ import (
 // marked completed because it still has active or in-progress children.
 var ErrPendingChildren = errors.New("cannot complete: active child tasks pending")
 
+// priorityCookieRe matches an org-style priority cookie ([#A], [#B] or [#C]).
+// Like tags, the cookie must follow a space or start-of-string.
+var priorityCookieRe = regexp.MustCompile(`(?:^|\s)\[#([ABC])\]`)
+
 // Task represents a parsed task from a line
 type Task struct {
-	Keyword     string
-	ID          string   // Optional unique identifier [id]
-	Title       string
-	Tags        []string
-	References  []string // IDs of tasks this task depends on (^id syntax)
-	ScheduledAt string   // @date or @s:date (scheduled date)
-	DueAt       string   // @d:date (due date)
-	Assignee    string
-	Project     string
-	Zettel      string
-	FilePath    string  // Original file path where this task was found
-	InCycle     bool    // True if this task participates in a circular dependency
-	IndentLevel int     // Byte offset of first non-whitespace character in source line
-	LineNum     int     // 1-based line number in source file
-	Parent      *Task   // Parent task, nil for root tasks
-	Children    []*Task // Child tasks nested under this task in the source file
+	Keyword        string
+	ID             string // Optional unique identifier [id]
+	PriorityCookie string // Optional priority cookie: "A", "B", "C" ([#A] syntax), empty if none
+	Checkbox       string // "[ ]" or "[x]" as written for checkbox list items (todo.checkboxes), empty for KEYWORD: tasks
+	Title          string
+	Tags           []string
+	References     []string // IDs of tasks this task depends on (^id syntax)
+	ScheduledAt    string   // @date or @s:date (scheduled date)
+	DueAt          string   // @d:date (due date)
+	Estimate       string   // ~3h effort estimate, without the "~" (see ParseEstimate)
+	Assignee       string
+	Project        string
+	Zettel         string
+	Properties     map[string]string // "key:: value" sub-lines, keyed by lower-cased key
+	FilePath       string            // Original file path where this task was found
+	InCycle        bool              // True if this task participates in a circular dependency
+	DuplicateID    bool              // True if another listed task has the same ID (see DuplicateIDs)
+	Blocked        bool              // True while a referenced task is not completed (see MarkBlocked)
+	IndentLevel    int               // Byte offset of first non-whitespace character in source line
+	LineNum        int               // 1-based line number in source file
+	Parent         *Task             // Parent task, nil for root tasks
+	Children       []*Task           // Child tasks nested under this task in the source file
+
+	stamp       fileStamp     // State of FilePath when the task was read, zero if unknown (see mutateTask)
+	journal     *JournalEntry // Entry collecting this task's edits inside Journaled
+	subLines    []string      // CLOCK/LOG/COMPLETED sub-lines captured when the file was parsed
+	subStamp    fileStamp     // State of FilePath when subLines were captured
+	hasSubLines bool          // True if subLines were captured (see taskSubLines)
 }
 
 // IsActive returns true if the task is active (not completed)
@@ -111,17 +128,45 @@ func (t *Task) Priority(c *config.Config) int {
 	return 5
 }
 
+// CookieRank returns the rank of the task's priority cookie for sorting.
+// Lower numbers indicate higher priority: 0 = [#A], 1 = [#B], 2 = [#C].
+// Tasks without a cookie rank the same as [#B], as in org-mode.
+func (t *Task) CookieRank() int {
+	switch t.PriorityCookie {
+	case "A":
+		return 0
+	case "C":
+		return 2
+	}
+	return 1
+}
+
 // FindFiles finds README.md files in project directories (structured mode)
-// or all .md files in the project tree (unstructured mode)
+// or all .md files in the project tree (unstructured mode). Archive files are
+// skipped unless Todo.IncludeArchive is set.
 func FindFiles(c *config.Config, project string) ([]string, error) {
 	if c.Todo.Structured {
 		// Structured mode: look for specific zettelkasten directory structure
-		pattern := filepath.Join(c.Directories.Projects, project, "notes", "??????????????", "README.md")
+		prjPattern := project
 		if project == "" || project == "*" {
-			pattern = filepath.Join(c.Directories.Projects, "*", "notes", "??????????????", "README.md")
+			prjPattern = "*"
+		}
+		matches, err := filepath.Glob(filepath.Join(c.Directories.Projects, prjPattern, "notes", "??????????????", "README.md"))
+		if err != nil || !c.Todo.IncludeArchive {
+			return matches, err
+		}
+		for _, pattern := range []string{
+			filepath.Join(c.Directories.Projects, prjPattern, "ARCHIVE*.md"),
+			filepath.Join(c.Directories.Projects, prjPattern, "notes", "ARCHIVE*.md"),
+		} {
+			archives, _ := filepath.Glob(pattern)
+			for _, a := range archives {
+				if IsArchiveFile(a) {
+					matches = append(matches, a)
+				}
+			}
 		}
-		matches, err := filepath.Glob(pattern)
-		return matches, err
+		return matches, nil
 	} else {
 		// Unstructured mode: find all .md files in project directory tree
 		return findUnstructuredFiles(c, project)
@@ -147,6 +192,9 @@ func findUnstructuredFiles(c *config.Config, project string) ([]string, error) {
 		if info.IsDir() || !strings.HasSuffix(strings.ToLower(info.Name()), ".md") {
 			return nil
 		}
+		if !c.Todo.IncludeArchive && IsArchiveFile(path) {
+			return nil
+		}
 
 		files = append(files, path)
 		return nil
@@ -162,6 +210,10 @@ func ProcessFile(c *config.Config, filePath string) ([]*Task, error) {
 		return nil, err
 	}
 	defer file.Close()
+	var stamp fileStamp
+	if info, err := file.Stat(); err == nil {
+		stamp = stampOf(info)
+	}
 
 	// Extract project and zettel from path
 	var project, zettel string
@@ -174,6 +226,14 @@ func ProcessFile(c *config.Config, filePath string) ([]*Task, error) {
 		}
 		zettel = parts[len(parts)-2]
 		project = parts[len(parts)-4]
+		if IsArchiveFile(filePath) {
+			// PRJDIR/project/ARCHIVE.md or PRJDIR/project/notes/ARCHIVE.md
+			zettel = strings.TrimSuffix(parts[len(parts)-1], ".md")
+			project = parts[len(parts)-2]
+			if project == "notes" {
+				project = parts[len(parts)-3]
+			}
+		}
 	} else {
 		// Unstructured mode: derive project and zettel from file path
 		relPath, err := filepath.Rel(c.Directories.Projects, filePath)
@@ -199,11 +259,6 @@ func ProcessFile(c *config.Config, filePath string) ([]*Task, error) {
 		}
 	}
 
-	type stackFrame struct {
-		level int
-		task  *Task
-	}
-
 	var tasks []*Task
 	var stack []stackFrame
 	lineNum := 0
@@ -214,10 +269,12 @@ func ProcessFile(c *config.Config, filePath string) ([]*Task, error) {
 		_, level := StripLinePrefix(line)
 		t := ParseLine(c, line, project, zettel, filePath)
 		if t == nil {
+			attachProperty(stack, line, level)
 			continue
 		}
 		t.IndentLevel = level
 		t.LineNum = lineNum
+		t.stamp = stamp
 		// Pop frames at the same or deeper indent — they are siblings or closed subtrees.
 		for len(stack) > 0 && stack[len(stack)-1].level >= level {
 			stack = stack[:len(stack)-1]
@@ -233,6 +290,27 @@ func ProcessFile(c *config.Config, filePath string) ([]*Task, error) {
 	return tasks, scanner.Err()
 }
 
+// stackFrame tracks an open task while nesting is resolved from indentation.
+type stackFrame struct {
+	level int
+	task  *Task
+}
+
+// attachProperty records a "key:: value" line on the innermost task in stack
+// that is indented less than the line. Other lines are ignored.
+func attachProperty(stack []stackFrame, line string, level int) {
+	key, value, ok := parsePropertyLine(line)
+	if !ok {
+		return
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].level < level {
+			stack[i].task.setProperty(key, value)
+			return
+		}
+	}
+}
+
 // StripLinePrefix strips leading whitespace and an optional bullet marker (-, *, +)
 // followed by at least one space. Returns the content after the prefix and the
 // byte offset where that content begins in the original line.
@@ -257,24 +335,44 @@ func StripLinePrefix(line string) (string, int) {
 
 // ParseLine parses a task line and returns a Task
 func ParseLine(c *config.Config, line, project, zettel, filePath string) *Task {
-	// Strip leading whitespace and optional bullet before matching keyword
-	stripped, _ := StripLinePrefix(line)
+	var keyword, title, checkbox string
 
-	// First check for basic structure: KEYWORD: title
-	basicRe := regexp.MustCompile(`^([A-Z]+):\s*(.+)$`)
-	basicMatches := basicRe.FindStringSubmatch(stripped)
-	if len(basicMatches) == 0 {
-		return nil
-	}
+	if m := checkboxLineRe.FindStringSubmatch(line); m != nil && c != nil && c.Todo.Checkboxes {
+		// Checkbox list item: "- [ ] title" or "- [x] title"
+		active, completed := c.CheckboxKeywords()
+		keyword, checkbox = active, "[ ]"
+		if m[1] != " " {
+			keyword, checkbox = completed, "["+m[1]+"]"
+		}
+		title = m[2]
+	} else {
+		// Strip leading whitespace and optional bullet before matching keyword
+		stripped, _ := StripLinePrefix(line)
 
-	keyword := basicMatches[1]
-	// Check if keyword is valid
-	if !isValidKeyword(c, keyword) {
-		return nil
+		// First check for basic structure: KEYWORD: title
+		basicRe := regexp.MustCompile(`^([A-Z]+):\s*(.+)$`)
+		basicMatches := basicRe.FindStringSubmatch(stripped)
+		if len(basicMatches) == 0 {
+			return nil
+		}
+
+		keyword = basicMatches[1]
+		// Check if keyword is valid
+		if !isValidKeyword(c, keyword) {
+			return nil
+		}
+
+		// Parse the rest of the line for metadata
+		title = basicMatches[2]
 	}
 
-	// Parse the rest of the line for metadata
-	title := basicMatches[2]
+	// Extract priority cookie [#A] before the ID, since a leading cookie
+	// would otherwise be taken for an [id]
+	var cookie string
+	if cookieMatch := priorityCookieRe.FindStringSubmatch(title); len(cookieMatch) > 1 {
+		cookie = cookieMatch[1]
+		title = strings.TrimLeft(priorityCookieRe.ReplaceAllString(title, ""), " \t")
+	}
 
 	// Extract task ID [id] - must be at the start of title
 	var id string
@@ -295,6 +393,10 @@ func ParseLine(c *config.Config, line, project, zettel, filePath string) *Task {
 	}
 	title = refRe.ReplaceAllString(title, "") // Remove all references from title
 
+	// Extract effort estimate (~3h). Done before the assignee, which would
+	// otherwise swallow a trailing estimate.
+	title, estimate := cutEstimate(title)
+
 	// Extract tags (#tag) - supports multiple tags. Require a space or
 	// start-of-string before '#' so a URL fragment like "...page#section"
 	// isn't mistaken for a tag.
@@ -345,18 +447,42 @@ func ParseLine(c *config.Config, line, project, zettel, filePath string) *Task {
 	}
 
 	return &Task{
-		Keyword:     keyword,
-		ID:          id,
-		Title:       strings.TrimSpace(title),
-		Tags:        tags,
-		References:  references,
-		ScheduledAt: scheduledAt,
-		DueAt:       dueAt,
-		Assignee:    assignee,
-		Project:     project,
-		Zettel:      zettel,
-		FilePath:    filePath,
+		Keyword:        keyword,
+		ID:             id,
+		PriorityCookie: cookie,
+		Checkbox:       checkbox,
+		Title:          strings.TrimSpace(title),
+		Tags:           tags,
+		References:     references,
+		ScheduledAt:    scheduledAt,
+		DueAt:          dueAt,
+		Estimate:       estimate,
+		Assignee:       assignee,
+		Project:        project,
+		Zettel:         zettel,
+		FilePath:       filePath,
+	}
+}
+
+// checkboxLineRe matches a GitHub-style checkbox list item. A bullet is
+// required, as in GitHub-flavored markdown.
+var checkboxLineRe = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(\S.*)$`)
+
+// isTaskLine reports whether line is the source line of t: a task line with
+// t's keyword (or checkbox), ID and title, whatever its other tokens.
+func isTaskLine(line string, t *Task) bool {
+	m, ok := ParseLineModel(line)
+	if !ok {
+		return false
+	}
+	if t.Checkbox != "" {
+		if m.Checkbox() != t.Checkbox {
+			return false
+		}
+	} else if m.Keyword() != t.Keyword {
+		return false
 	}
+	return m.ID() == t.ID && m.Title() == t.Title
 }
 
 // IsKeywordValid returns true if the keyword is in any configured category.
@@ -391,37 +517,30 @@ func isValidKeyword(c *config.Config, keyword string) bool {
 // ListTasks lists tasks for a project, filtering by showCompleted
 // This includes tasks from both project files and the inbox file
 func ListTasks(c *config.Config, project string, showCompleted bool) ([]*Task, error) {
-	// Get tasks from project files
-	files, err := FindFiles(c, project)
+	regularTasks, inboxTasks, err := readTasks(c, project)
 	if err != nil {
 		return nil, err
 	}
 
-	var regularTasks []*Task
-	if len(files) > 0 {
-		// Use parallel processing with the shared parallel package
-		taskSlices := parallel.Process(files, func(file string) *[]*Task {
-			tasks, err := ProcessFile(c, file)
-			if err != nil {
-				return nil
-			}
-			return &tasks
-		})
-
-		// Flatten results
-		for _, tasksPtr := range taskSlices {
-			regularTasks = append(regularTasks, *tasksPtr...)
-		}
-	}
-
-	// Load tasks from inbox file (only when listing all projects, not a specific one)
-	var inboxTasks []*Task
-	if project == "" || project == "*" {
-		inboxFilePath := c.GetInboxFilePath()
-		inboxTasks, err = readInboxFile(inboxFilePath, c)
-		if err != nil && !os.IsNotExist(err) {
+	// Flag duplicate IDs and blocked tasks among all tasks, completed ones
+	// included, since those can be referenced too
+	listed := append(slices.Clone(regularTasks), inboxTasks...)
+	if project != "" && project != "*" {
+		// IDs may be shared with, and dependencies open in, other projects
+		// or the inbox
+		others, otherInbox, err := readTasks(c, "")
+		if err != nil {
 			return nil, err
 		}
+		workspace := append(others, otherInbox...)
+		dups := DuplicateIDs(workspace)
+		for _, t := range listed {
+			_, t.DuplicateID = dups[t.ID]
+		}
+		markBlocked(c, listed, openIDs(c, workspace))
+	} else {
+		DuplicateIDs(listed)
+		MarkBlocked(c, listed)
 	}
 
 	// Filter inbox tasks if showCompleted is false
@@ -451,6 +570,67 @@ func ListTasks(c *config.Config, project string, showCompleted bool) ([]*Task, e
 	return allTasks, nil
 }
 
+// readTasks reads every task, completed ones included, of a project's files
+// and, when listing all projects, of the inbox.
+func readTasks(c *config.Config, project string) (regularTasks, inboxTasks []*Task, err error) {
+	// Get tasks from project files
+	files, err := FindFiles(c, project)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reuse cached parses for unchanged files (nil when caching is unavailable)
+	ix := openIndex(c)
+
+	if len(files) > 0 {
+		// Use parallel processing with the shared parallel package
+		taskSlices := parallel.Process(files, func(file string) *[]*Task {
+			parse := func() ([]*Task, error) { return ProcessFile(c, file) }
+			var tasks []*Task
+			var err error
+			if ix != nil {
+				tasks, err = ix.load(file, parse)
+			} else {
+				tasks, err = parse()
+			}
+			if err != nil {
+				return nil
+			}
+			return &tasks
+		})
+
+		// Flatten results
+		for _, tasksPtr := range taskSlices {
+			regularTasks = append(regularTasks, *tasksPtr...)
+		}
+	}
+
+	// Load tasks from inbox file (only when listing all projects, not a specific one)
+	if project == "" || project == "*" {
+		inboxFilePath := c.GetInboxFilePath()
+		parse := func() ([]*Task, error) { return readInboxFile(inboxFilePath, c) }
+		if ix != nil {
+			inboxTasks, err = ix.load(inboxFilePath, parse)
+		} else {
+			inboxTasks, err = parse()
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+	}
+
+	if ix != nil {
+		root := c.Directories.Projects
+		if project != "" && project != "*" {
+			root = filepath.Join(c.Directories.Projects, project)
+		}
+		ix.prune(root, files, c.GetInboxFilePath())
+		// The index is only a cache; failing to persist it must not fail the listing.
+		_ = ix.save()
+	}
+	return regularTasks, inboxTasks, nil
+}
+
 // readInboxFile reads tasks from the inbox file, preserving parent/child hierarchy
 func readInboxFile(inboxPath string, c *config.Config) ([]*Task, error) {
 	file, err := os.Open(inboxPath)
@@ -458,10 +638,9 @@ func readInboxFile(inboxPath string, c *config.Config) ([]*Task, error) {
 		return nil, err
 	}
 	defer file.Close()
-
-	type stackFrame struct {
-		level int
-		task  *Task
+	var stamp fileStamp
+	if info, err := file.Stat(); err == nil {
+		stamp = stampOf(info)
 	}
 
 	var tasks []*Task
@@ -474,10 +653,12 @@ func readInboxFile(inboxPath string, c *config.Config) ([]*Task, error) {
 		_, level := StripLinePrefix(line)
 		t := ParseLine(c, line, "inbox", "inbox", inboxPath)
 		if t == nil {
+			attachProperty(stack, line, level)
 			continue
 		}
 		t.IndentLevel = level
 		t.LineNum = lineNum
+		t.stamp = stamp
 		for len(stack) > 0 && stack[len(stack)-1].level >= level {
 			stack = stack[:len(stack)-1]
 		}
@@ -494,11 +675,15 @@ func readInboxFile(inboxPath string, c *config.Config) ([]*Task, error) {
 }
 
 // SortByPriority sorts tasks by their priority order
-// Order: In Progress (1) -> Active (2) -> Someday (3) -> Completed (4)
+// Order: In Progress (1) -> Active (2) -> Someday (3) -> Completed (4),
+// then by priority cookie ([#A] -> [#B]/none -> [#C]) within each category.
 // Uses stable sort to preserve relative order of equal-priority tasks
 func SortByPriority(tasks []*Task, c *config.Config) {
 	sort.SliceStable(tasks, func(i, j int) bool {
-		return tasks[i].Priority(c) < tasks[j].Priority(c)
+		if tasks[i].Priority(c) != tasks[j].Priority(c) {
+			return tasks[i].Priority(c) < tasks[j].Priority(c)
+		}
+		return tasks[i].CookieRank() < tasks[j].CookieRank()
 	})
 }
 
@@ -516,6 +701,10 @@ func SummarizeProjects(c *config.Config) (map[string]int, error) {
 
 	// Use parallel processing with the shared parallel package
 	results := parallel.Collect(files, func(file string) (projectCount, bool) {
+		if IsArchiveFile(file) {
+			// Archives only hold completed tasks
+			return projectCount{}, false
+		}
 		tasks, err := ProcessFile(c, file)
 		if err != nil {
 			return projectCount{}, false
@@ -870,7 +1059,7 @@ func SearchTasks(c *config.Config, project string, searchTerm string) ([]SearchR
 						results[i].Project = parts[0]
 					}
 				}
-				
+
 				// Get title if possible
 				if c.Todo.Structured {
 					// For structured mode, try to get the zettel title
@@ -962,53 +1151,55 @@ func UpdateTaskStatus(t *Task, newKeyword string, cfg *config.Config) error {
 		}
 	}
 
-	if t.FilePath == "" {
-		return fmt.Errorf("task has no file path")
-	}
-
-	// Read the file
-	content, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-
-	lines := strings.Split(string(content), "\n")
-	found := false
-
-	// Build the search pattern: "KEYWORD: [id] title" or "KEYWORD: title" (with optional metadata after title)
-	var searchPrefix string
-	if t.ID != "" {
-		searchPrefix = fmt.Sprintf("%s: [%s] %s", t.Keyword, t.ID, t.Title)
-	} else {
-		searchPrefix = fmt.Sprintf("%s: %s", t.Keyword, t.Title)
-	}
-
-	for i, line := range lines {
-		stripped, prefixLen := StripLinePrefix(line)
-		if strings.HasPrefix(stripped, searchPrefix) {
-			newLine := line[:prefixLen] + newKeyword + line[prefixLen+len(t.Keyword):]
-			lines[i] = newLine
-			found = true
-			break
+	// Checkbox items are toggled rather than given a keyword
+	mark := ""
+	if t.Checkbox != "" {
+		if cfg == nil {
+			return fmt.Errorf("checkbox task needs the keyword configuration")
+		}
+		var err error
+		if mark, err = checkboxFor(cfg, newKeyword); err != nil {
+			return err
 		}
 	}
 
-	if !found {
-		return fmt.Errorf("task not found in file: %s: %s", t.Keyword, t.Title)
-	}
-
-	// Write the file back
-	newContent := strings.Join(lines, "\n")
-	if err := os.WriteFile(t.FilePath, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	// A line found by ID may carry a different keyword than t; the head is
+	// replaced whatever it is
+	summary := fmt.Sprintf("Status %s -> %s: %s", t.Keyword, newKeyword, t.Title)
+	_, err := editLine(t, summary, func(m *LineModel) {
+		if mark != "" {
+			m.SetCheckbox(mark)
+		} else {
+			m.SetKeyword(newKeyword)
+		}
+	})
+	if err != nil {
+		return err
 	}
 
 	// Update the task's keyword in memory
 	t.Keyword = newKeyword
+	if t.Checkbox != "" {
+		t.Checkbox = mark
+	}
 
 	return nil
 }
 
+// checkboxFor returns the checkbox mark for a status change to keyword: the
+// checkbox completed keyword checks the box and the checkbox active keyword
+// clears it. A box can't hold any other status.
+func checkboxFor(c *config.Config, keyword string) (string, error) {
+	active, completed := c.CheckboxKeywords()
+	switch keyword {
+	case completed:
+		return "[x]", nil
+	case active:
+		return "[ ]", nil
+	}
+	return "", fmt.Errorf("checkbox item can only be %s or %s, not %s", active, completed, keyword)
+}
+
 // GetAllKeywords returns all configured keywords grouped by category
 func GetAllKeywords(c *config.Config) map[string][]string {
 	return map[string][]string{
@@ -1153,7 +1344,8 @@ func findCycleParticipants(graph map[string][]string) map[string]bool {
 	return cycleNodes
 }
 
-// GetTaskByID returns a task by its ID from a slice of tasks.
+// GetTaskByID returns a task by its ID from a slice of tasks. When the ID is
+// duplicated the first match is returned; see FindTasksByID.
 // Returns nil if id is empty or not found.
 func GetTaskByID(tasks []*Task, id string) *Task {
 	if id == "" {
@@ -1306,40 +1498,16 @@ func SetTaskDate(t *Task, scheduledAt, dueAt string, removeScheduled, removeDue
 		}
 	}
 
-	if t.FilePath == "" {
-		return fmt.Errorf("task has no file path")
-	}
-
-	content, err := os.ReadFile(t.FilePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-
-	lines := strings.Split(string(content), "\n")
-
-	var searchPrefix string
-	if t.ID != "" {
-		searchPrefix = fmt.Sprintf("%s: [%s] %s", t.Keyword, t.ID, t.Title)
-	} else {
-		searchPrefix = fmt.Sprintf("%s: %s", t.Keyword, t.Title)
-	}
-
-	found := false
-	for i, line := range lines {
-		stripped, _ := StripLinePrefix(line)
-		if strings.HasPrefix(stripped, searchPrefix) {
-			lines[i] = applyDateChanges(line, scheduledAt, dueAt, removeScheduled, removeDue)
-			found = true
-			break
+	_, err := editLine(t, "Set dates: "+t.Title, func(m *LineModel) {
+		if scheduledAt != "" || removeScheduled {
+			m.SetScheduled(scheduledAt)
 		}
-	}
-
-	if !found {
-		return fmt.Errorf("task not found in file: %s: %s", t.Keyword, t.Title)
-	}
-
-	if err := os.WriteFile(t.FilePath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		if dueAt != "" || removeDue {
+			m.SetDue(dueAt)
+		}
+	})
+	if err != nil {
+		return err
 	}
 
 	if scheduledAt != "" {
@@ -1356,73 +1524,36 @@ func SetTaskDate(t *Task, scheduledAt, dueAt string, removeScheduled, removeDue
 	return nil
 }
 
-// applyDateChanges modifies date tokens on a task line.
-func applyDateChanges(line, scheduledAt, dueAt string, removeScheduled, removeDue bool) string {
-	scheduledExplicitRe := regexp.MustCompile(`\s*@s:[^\s]+`)
-	dueRe := regexp.MustCompile(`\s*@d:[^\s]+`)
-
-	if removeScheduled {
-		line = scheduledExplicitRe.ReplaceAllString(line, "")
-		// Remove bare @date only if no @s: was found (already removed above)
-		if !scheduledExplicitRe.MatchString(line) {
-			line = removeBareScheduledDate(line)
-		}
-	} else if scheduledAt != "" {
-		newToken := " @s:" + scheduledAt
-		if loc := scheduledExplicitRe.FindStringIndex(line); loc != nil {
-			line = line[:loc[0]] + newToken + line[loc[1]:]
-		} else if loc := findBareScheduledDate(line); loc != nil {
-			line = line[:loc[0]] + newToken + line[loc[1]:]
-		} else {
-			line = insertToken(line, newToken)
-		}
+// SetTaskPriority sets or removes the priority cookie on a task's source line.
+// cookie must be "A", "B" or "C"; an empty cookie removes it. The cookie is
+// written org-style, directly after the keyword: "TODO: [#A] title".
+func SetTaskPriority(t *Task, cookie string) error {
+	switch cookie {
+	case "", "A", "B", "C":
+	default:
+		return fmt.Errorf("invalid priority %q (want A, B or C)", cookie)
 	}
 
-	if removeDue {
-		line = dueRe.ReplaceAllString(line, "")
-	} else if dueAt != "" {
-		newToken := " @d:" + dueAt
-		if loc := dueRe.FindStringIndex(line); loc != nil {
-			line = line[:loc[0]] + newToken + line[loc[1]:]
-		} else {
-			line = insertToken(line, newToken)
-		}
+	_, err := editLine(t, fmt.Sprintf("Set priority [#%s]: %s", cookie, t.Title), func(m *LineModel) {
+		m.SetPriority(cookie)
+	})
+	if err != nil {
+		return err
 	}
 
-	return line
-}
-
-// findBareScheduledDate finds a bare @DATE token (not @s: or @d:) in the line.
-func findBareScheduledDate(line string) []int {
-	re := regexp.MustCompile(`\s*@([^\s:][^\s]*)`)
-	matches := re.FindAllStringIndex(line, -1)
-	for _, loc := range matches {
-		substr := line[loc[0]:loc[1]]
-		// Skip if it's @s: or @d:
-		trimmed := strings.TrimLeft(substr, " \t")
-		if strings.HasPrefix(trimmed, "@s:") || strings.HasPrefix(trimmed, "@d:") {
-			continue
-		}
-		return loc
-	}
+	t.PriorityCookie = cookie
 	return nil
 }
 
-// removeBareScheduledDate removes a bare @DATE token from the line.
-func removeBareScheduledDate(line string) string {
-	loc := findBareScheduledDate(line)
-	if loc == nil {
-		return line
-	}
-	return line[:loc[0]] + line[loc[1]:]
-}
-
-// insertToken inserts a date token before trailing metadata (#tag, >> assignee, ^ref).
-func insertToken(line, token string) string {
-	// Find the first trailing metadata marker
-	metaRe := regexp.MustCompile(`\s+(#[^\s]|>>\s|(\^[^\s]))`)
-	if loc := metaRe.FindStringIndex(line); loc != nil {
-		return line[:loc[0]] + token + line[loc[0]:]
-	}
-	return line + token
+// ShiftPriority returns the cookie one step above (raise) or below the given
+// one, clamped to A..C. An empty cookie is treated as B.
+func ShiftPriority(cookie string, raise bool) string {
+	levels := []string{"A", "B", "C"}
+	rank := (&Task{PriorityCookie: cookie}).CookieRank()
+	if raise && rank > 0 {
+		rank--
+	} else if !raise && rank < len(levels)-1 {
+		rank++
+	}
+	return levels[rank]
 }
@@ -1168,4 +1168,246 @@ func TestProcessFile_SiblingsDontNestIntoEachOther(t *testing.T) {
 	if len(p2.Children) != 1 || p2.Children[0].Title != "child of two" {
 		t.Errorf("parent two children wrong: %v", p2.Children)
 	}
-}
\ No newline at end of file
+}
+func TestParseLineWithPriorityCookie(t *testing.T) {
+	cfg := createTestConfig()
+
+	tests := []struct {
+		name       string
+		line       string
+		wantCookie string
+		wantID     string
+		wantTitle  string
+	}{
+		{"org-style after keyword", "TODO: [#A] Patch security hole", "A", "", "Patch security hole"},
+		{"cookie before ID", "TODO: [#C] [sec-01] Patch security hole #backend", "C", "sec-01", "Patch security hole"},
+		{"cookie after ID", "TODO: [sec-01] [#B] Patch security hole", "B", "sec-01", "Patch security hole"},
+		{"cookie at end", "- TODO: Patch security hole [#A] @2025-01-15", "A", "", "Patch security hole"},
+		{"no cookie", "TODO: Patch security hole", "", "", "Patch security hole"},
+		{"unknown level is not a cookie", "TODO: [#D] Patch", "", "#D", "Patch"},
+		{"embedded text is not a cookie", "TODO: Read docs[#A]", "", "", "Read docs[#A]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := ParseLine(cfg, tt.line, "test", "20250101000000", "test.md")
+			if task == nil {
+				t.Fatal("ParseLine returned nil")
+			}
+			if task.PriorityCookie != tt.wantCookie {
+				t.Errorf("PriorityCookie = %q, want %q", task.PriorityCookie, tt.wantCookie)
+			}
+			if task.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", task.ID, tt.wantID)
+			}
+			if task.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", task.Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestTaskSorting_PriorityCookie(t *testing.T) {
+	cfg := createTestConfig()
+
+	tasks := []*Task{
+		{Keyword: "TODO", Title: "low", PriorityCookie: "C"},
+		{Keyword: "DONE", Title: "done", PriorityCookie: "A"},
+		{Keyword: "TODO", Title: "none"},
+		{Keyword: "TODO", Title: "high", PriorityCookie: "A"},
+		{Keyword: "DOING", Title: "doing", PriorityCookie: "C"},
+		{Keyword: "TODO", Title: "medium", PriorityCookie: "B"},
+	}
+
+	SortByPriority(tasks, cfg)
+
+	// Category first, then cookie; no cookie ranks as [#B] and keeps its place
+	expected := []string{"doing", "high", "none", "medium", "low", "done"}
+	for i, want := range expected {
+		if tasks[i].Title != want {
+			t.Errorf("After sorting, task %d should be %q, got %q", i, want, tasks[i].Title)
+		}
+	}
+}
+
+func TestShiftPriority(t *testing.T) {
+	tests := []struct {
+		cookie string
+		raise  bool
+		want   string
+	}{
+		{"", true, "A"},
+		{"", false, "C"},
+		{"B", true, "A"},
+		{"A", true, "A"},
+		{"A", false, "B"},
+		{"C", false, "C"},
+	}
+
+	for _, tt := range tests {
+		if got := ShiftPriority(tt.cookie, tt.raise); got != tt.want {
+			t.Errorf("ShiftPriority(%q, %v) = %q, want %q", tt.cookie, tt.raise, got, tt.want)
+		}
+	}
+}
+
+func TestSetTaskPriority(t *testing.T) {
+	cfg, tmpDir := makeProcessFileConfig(t)
+	path := writeTaskFile(t, tmpDir, "tasks.md", `# Tasks
+
+  - TODO: [api-1] Build API #backend
+TODO: Write docs [#C] @2025-01-15
+`)
+
+	tasks, err := ProcessFile(cfg, path)
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("ProcessFile = %v, %v", tasks, err)
+	}
+
+	if err := SetTaskPriority(tasks[0], "A"); err != nil {
+		t.Fatalf("SetTaskPriority error: %v", err)
+	}
+	if err := SetTaskPriority(tasks[1], "B"); err != nil {
+		t.Fatalf("SetTaskPriority error: %v", err)
+	}
+	if tasks[0].PriorityCookie != "A" || tasks[1].PriorityCookie != "B" {
+		t.Errorf("in-memory cookies = %q, %q", tasks[0].PriorityCookie, tasks[1].PriorityCookie)
+	}
+
+	// Other writers must still find a line once it carries a cookie
+	if err := UpdateTaskStatus(tasks[1], "DOING", cfg); err != nil {
+		t.Fatalf("UpdateTaskStatus after SetTaskPriority: %v", err)
+	}
+	if err := SetTaskPriority(tasks[1], ""); err != nil {
+		t.Fatalf("SetTaskPriority remove error: %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	want := `# Tasks
+
+  - TODO: [#A] [api-1] Build API #backend
+DOING: Write docs @2025-01-15
+`
+	if string(got) != want {
+		t.Errorf("file content = %q, want %q", string(got), want)
+	}
+
+	if err := SetTaskPriority(tasks[0], "Z"); err == nil {
+		t.Error("expected error for invalid priority")
+	}
+}
+
+func TestParseLineCheckboxes(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Todo.Checkboxes = true
+
+	tests := []struct {
+		name         string
+		line         string
+		wantNil      bool
+		wantKeyword  string
+		wantCheckbox string
+		wantID       string
+		wantTitle    string
+		wantTags     []string
+		wantDue      string
+	}{
+		{"unchecked", "- [ ] write tests", false, "TODO", "[ ]", "", "write tests", nil, ""},
+		{"checked", "  * [x] write tests", false, "DONE", "[x]", "", "write tests", nil, ""},
+		{"upper-case X", "- [X] write tests", false, "DONE", "[X]", "", "write tests", nil, ""},
+		{"metadata", "- [ ] [t-1] write tests #backend @d:2025-01-20 ^api-02", false, "TODO", "[ ]", "t-1", "write tests", []string{"backend"}, "2025-01-20"},
+		{"keyword line unaffected", "- TODO: write tests", false, "TODO", "", "", "write tests", nil, ""},
+		{"no bullet", "[ ] write tests", true, "", "", "", "", nil, ""},
+		{"empty item", "- [ ] ", true, "", "", "", "", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := ParseLine(cfg, tt.line, "test", "20250101000000", "test.md")
+			if tt.wantNil {
+				if task != nil {
+					t.Fatalf("ParseLine(%q) = %+v, want nil", tt.line, task)
+				}
+				return
+			}
+			if task == nil {
+				t.Fatalf("ParseLine(%q) returned nil", tt.line)
+			}
+			if task.Keyword != tt.wantKeyword || task.Checkbox != tt.wantCheckbox {
+				t.Errorf("Keyword/Checkbox = %q/%q, want %q/%q", task.Keyword, task.Checkbox, tt.wantKeyword, tt.wantCheckbox)
+			}
+			if task.ID != tt.wantID || task.Title != tt.wantTitle || task.DueAt != tt.wantDue {
+				t.Errorf("ID/Title/DueAt = %q/%q/%q, want %q/%q/%q", task.ID, task.Title, task.DueAt, tt.wantID, tt.wantTitle, tt.wantDue)
+			}
+			if len(task.Tags) != len(tt.wantTags) {
+				t.Errorf("Tags = %v, want %v", task.Tags, tt.wantTags)
+			}
+		})
+	}
+
+	// Checkboxes are plain list items unless enabled
+	cfg.Todo.Checkboxes = false
+	if task := ParseLine(cfg, "- [ ] write tests", "test", "20250101000000", "test.md"); task != nil {
+		t.Errorf("checkbox parsed with checkboxes disabled: %+v", task)
+	}
+}
+
+func TestUpdateTaskStatus_Checkbox(t *testing.T) {
+	cfg, tmpDir := makeProcessFileConfig(t)
+	cfg.Todo.Checkboxes = true
+	cfg.Todo.CheckboxCompleted = "CLOSED"
+	path := writeTaskFile(t, tmpDir, "tasks.md", `# Notes
+
+- [ ] [t-1] write tests #backend
+  - [X] review fixtures
+`)
+
+	tasks, err := ProcessFile(cfg, path)
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("ProcessFile = %v, %v", tasks, err)
+	}
+	if tasks[1].Keyword != "CLOSED" {
+		t.Errorf("checked item keyword = %q, want CLOSED", tasks[1].Keyword)
+	}
+
+	if err := UpdateTaskStatus(tasks[0], "CLOSED", cfg); err != nil {
+		t.Fatalf("UpdateTaskStatus check: %v", err)
+	}
+	if tasks[0].Keyword != "CLOSED" || tasks[0].Checkbox != "[x]" {
+		t.Errorf("after check: Keyword/Checkbox = %q/%q, want CLOSED/[x]", tasks[0].Keyword, tasks[0].Checkbox)
+	}
+	if err := UpdateTaskStatus(tasks[1], "TODO", cfg); err != nil {
+		t.Fatalf("UpdateTaskStatus uncheck: %v", err)
+	}
+	if err := SetTaskPriority(tasks[1], "A"); err != nil {
+		t.Fatalf("SetTaskPriority on checkbox: %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	want := `# Notes
+
+- [x] [t-1] write tests #backend
+  - [ ] [#A] review fixtures
+`
+	if string(got) != want {
+		t.Errorf("file content = %q, want %q", string(got), want)
+	}
+
+	// The rewritten line must still be found by other writers
+	if err := UpdateTaskStatus(tasks[1], "CLOSED", cfg); err != nil {
+		t.Fatalf("UpdateTaskStatus after SetTaskPriority: %v", err)
+	}
+
+	// A box holds no other status, even one of the same category
+	for _, kw := range []string{"DOING", "DONE", "SOMEDAY"} {
+		if err := UpdateTaskStatus(tasks[0], kw, cfg); err == nil {
+			t.Errorf("UpdateTaskStatus(%s) on a checkbox item: expected error", kw)
+		}
+	}
+	if tasks[0].Keyword != "CLOSED" {
+		t.Errorf("keyword after rejected updates = %q, want CLOSED", tasks[0].Keyword)
+	}
+	if err := UpdateTaskStatus(tasks[0], "TODO", nil); err == nil {
+		t.Error("expected error updating a checkbox task without config")
+	}
+}
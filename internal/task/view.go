@@ -0,0 +1,198 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// ViewGroup is one group of tasks produced by a saved view. Views without
+// grouping produce a single group with an empty name.
+type ViewGroup struct {
+	Name  string
+	Tasks []*Task
+}
+
+// ValidateView checks a view's sort and group_by settings and filter syntax.
+func ValidateView(v config.View) error {
+	switch strings.ToLower(v.Sort) {
+	case "", "priority", "project", "title", "scheduled", "due":
+	default:
+		return fmt.Errorf("view %q: unknown sort %q (want priority, project, title, scheduled or due)", v.Name, v.Sort)
+	}
+	switch strings.ToLower(v.GroupBy) {
+	case "", "project", "assignee", "tag", "category":
+	default:
+		return fmt.Errorf("view %q: unknown group_by %q (want project, assignee, tag or category)", v.Name, v.GroupBy)
+	}
+	if _, err := ParseQuery(v.Filter); err != nil {
+		return fmt.Errorf("view %q: invalid filter: %w", v.Name, err)
+	}
+	return nil
+}
+
+// RunView loads all tasks and applies the view to them.
+func RunView(c *config.Config, v config.View) ([]ViewGroup, error) {
+	if err := ValidateView(v); err != nil {
+		return nil, err
+	}
+	tasks, err := ListTasks(c, "", v.ShowCompleted)
+	if err != nil {
+		return nil, err
+	}
+	DetectCycles(tasks)
+	return ApplyView(c, v, tasks)
+}
+
+// ApplyView filters, sorts and groups tasks according to the view. Tasks
+// should already have been through DetectCycles if the filter uses in:cycle.
+func ApplyView(c *config.Config, v config.View, tasks []*Task) ([]ViewGroup, error) {
+	if err := ValidateView(v); err != nil {
+		return nil, err
+	}
+	filtered, err := QueryTasks(c, tasks, v.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if !v.ShowCompleted {
+		var open []*Task
+		for _, t := range filtered {
+			if !t.IsCompleted(c) {
+				open = append(open, t)
+			}
+		}
+		filtered = open
+	}
+	SortView(c, v, filtered)
+	return groupView(c, v, filtered), nil
+}
+
+// SortView sorts tasks by the view's sort order. Ties fall back to the
+// default order used by the todo TUI: priority, priority cookie, project,
+// title, file path.
+func SortView(c *config.Config, v config.View, tasks []*Task) {
+	defaultLess := func(a, b *Task) bool {
+		if a.Priority(c) != b.Priority(c) {
+			return a.Priority(c) < b.Priority(c)
+		}
+		if a.CookieRank() != b.CookieRank() {
+			return a.CookieRank() < b.CookieRank()
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.FilePath < b.FilePath
+	}
+	// Empty values sort after set ones
+	compareField := func(a, b string) int {
+		switch {
+		case a == b:
+			return 0
+		case a == "":
+			return 1
+		case b == "":
+			return -1
+		}
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	var field func(*Task) string
+	switch strings.ToLower(v.Sort) {
+	case "project":
+		field = func(t *Task) string { return t.Project }
+	case "title":
+		field = func(t *Task) string { return t.Title }
+	case "scheduled":
+		field = func(t *Task) string { return t.ScheduledAt }
+	case "due":
+		field = func(t *Task) string { return t.DueAt }
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if field != nil {
+			if cmp := compareField(field(tasks[i]), field(tasks[j])); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return defaultLess(tasks[i], tasks[j])
+	})
+}
+
+// groupView splits sorted tasks into groups, preserving their order within
+// each group. With group_by = "tag" a task appears under each of its tags.
+func groupView(c *config.Config, v config.View, tasks []*Task) []ViewGroup {
+	var keys func(*Task) []string
+	switch strings.ToLower(v.GroupBy) {
+	case "project":
+		keys = func(t *Task) []string { return []string{t.Project} }
+	case "assignee":
+		keys = func(t *Task) []string {
+			if t.Assignee == "" {
+				return []string{"(unassigned)"}
+			}
+			return []string{t.Assignee}
+		}
+	case "tag":
+		keys = func(t *Task) []string {
+			if len(t.Tags) == 0 {
+				return []string{"(untagged)"}
+			}
+			return t.Tags
+		}
+	case "category":
+		keys = func(t *Task) []string { return []string{categoryName(c, t)} }
+	default:
+		return []ViewGroup{{Tasks: tasks}}
+	}
+
+	index := make(map[string]int)
+	var groups []ViewGroup
+	for _, t := range tasks {
+		for _, k := range keys(t) {
+			i, ok := index[k]
+			if !ok {
+				i = len(groups)
+				index[k] = i
+				groups = append(groups, ViewGroup{Name: k})
+			}
+			groups[i].Tasks = append(groups[i].Tasks, t)
+		}
+	}
+
+	if strings.ToLower(v.GroupBy) == "category" {
+		// Categories follow task priority order
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i].Tasks[0].Priority(c) < groups[j].Tasks[0].Priority(c)
+		})
+	} else {
+		// Placeholder groups such as "(unassigned)" go last
+		sort.SliceStable(groups, func(i, j int) bool {
+			pi, pj := strings.HasPrefix(groups[i].Name, "("), strings.HasPrefix(groups[j].Name, "(")
+			if pi != pj {
+				return pj
+			}
+			return strings.ToLower(groups[i].Name) < strings.ToLower(groups[j].Name)
+		})
+	}
+	return groups
+}
+
+// categoryName returns the display name of the task's keyword category.
+func categoryName(c *config.Config, t *Task) string {
+	switch {
+	case t.IsInProgress(c):
+		return "In Progress"
+	case t.IsActive(c):
+		return "Active"
+	case t.IsSomeday(c):
+		return "Someday"
+	case t.IsCompleted(c):
+		return "Completed"
+	}
+	return "Other"
+}
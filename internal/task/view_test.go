@@ -0,0 +1,67 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+func viewSummary(groups []ViewGroup) string {
+	var parts []string
+	for _, g := range groups {
+		parts = append(parts, g.Name+"="+queryTitles(g.Tasks))
+	}
+	return strings.Join(parts, " ; ")
+}
+
+func TestApplyView(t *testing.T) {
+	cfg := createTestConfig()
+
+	tests := []struct {
+		name string
+		view config.View
+		want string
+	}{
+		{"filter only", config.View{Filter: "#urgent"}, "=Rotate keys|Fix login bug"},
+		{"show completed", config.View{Filter: "#urgent", ShowCompleted: true}, "=Rotate keys|Fix login bug|Ship release"},
+		{"sort by due", config.View{Filter: ">>alice", Sort: "due", ShowCompleted: true}, "=Rotate keys|Fix login bug|Ship release"},
+		{"empty sort values last", config.View{Sort: "due"}, "=Rotate keys|Fix login bug|Write design doc|Learn Rust"},
+		{"group by project", config.View{GroupBy: "project"}, "infra=Write design doc|Rotate keys ; personal=Learn Rust ; web=Fix login bug"},
+		{"group by assignee", config.View{GroupBy: "assignee"}, "alice=Rotate keys|Fix login bug ; bob=Write design doc ; (unassigned)=Learn Rust"},
+		{"group by tag", config.View{GroupBy: "tag"}, "docs=Write design doc ; urgent=Rotate keys|Fix login bug ; (untagged)=Learn Rust"},
+		{"group by category", config.View{GroupBy: "category", ShowCompleted: true}, "In Progress=Write design doc ; Active=Rotate keys|Fix login bug ; Someday=Learn Rust ; Completed=Ship release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := ApplyView(cfg, tt.view, queryTestTasks())
+			if err != nil {
+				t.Fatalf("ApplyView error: %v", err)
+			}
+			if got := viewSummary(groups); got != tt.want {
+				t.Errorf("ApplyView = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateView_Errors(t *testing.T) {
+	tests := []struct {
+		view    config.View
+		wantMsg string
+	}{
+		{config.View{Name: "v", Sort: "size"}, `unknown sort "size"`},
+		{config.View{Name: "v", GroupBy: "zettel"}, `unknown group_by "zettel"`},
+		{config.View{Name: "v", Filter: "(#a"}, `invalid filter: column 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantMsg, func(t *testing.T) {
+			err := ValidateView(tt.view)
+			if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("ValidateView(%+v) = %v, want error containing %q", tt.view, err, tt.wantMsg)
+			}
+		})
+	}
+}
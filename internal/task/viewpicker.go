@@ -0,0 +1,111 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// ViewPicker lets the user switch between saved views. The first entry
+// clears the active view and shows all tasks.
+type ViewPicker struct {
+	Views     []config.View
+	Cursor    int
+	Confirmed bool
+	Cancelled bool
+	Selected  *config.View // nil when "All tasks" is chosen
+}
+
+func NewViewPicker(c *config.Config, active *config.View) *ViewPicker {
+	vp := &ViewPicker{Views: c.Views}
+
+	// Pre-select the active view
+	if active != nil {
+		for i, v := range vp.Views {
+			if strings.EqualFold(v.Name, active.Name) {
+				vp.Cursor = i + 1
+				break
+			}
+		}
+	}
+
+	return vp
+}
+
+func (vp *ViewPicker) Update(key string) {
+	if vp.Confirmed || vp.Cancelled {
+		return
+	}
+
+	count := len(vp.Views) + 1
+	switch key {
+	case "j", "down":
+		vp.Cursor = (vp.Cursor + 1) % count
+	case "k", "up":
+		vp.Cursor = (vp.Cursor - 1 + count) % count
+	case "enter":
+		if vp.Cursor > 0 {
+			v := vp.Views[vp.Cursor-1]
+			vp.Selected = &v
+		}
+		vp.Confirmed = true
+	case "esc", "q":
+		vp.Cancelled = true
+	}
+}
+
+func (vp *ViewPicker) View(width int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+
+	var view strings.Builder
+	view.WriteString(headerStyle.Render("Views"))
+	view.WriteString("\n\n")
+
+	names := []string{"All tasks"}
+	details := []string{""}
+	for _, v := range vp.Views {
+		names = append(names, v.Name)
+		details = append(details, v.Filter)
+	}
+
+	nameWidth := 0
+	for _, n := range names {
+		if len(n) > nameWidth {
+			nameWidth = len(n)
+		}
+	}
+
+	for i, name := range names {
+		detail := details[i]
+		if avail := width - nameWidth - 12; avail > 0 && len(detail) > avail {
+			detail = detail[:avail] + "…"
+		}
+		if i == vp.Cursor {
+			view.WriteString(fmt.Sprintf("%s %s  %s", cursorStyle.Render("█"),
+				headerStyle.Render(fmt.Sprintf("%-*s", nameWidth, name)), dimStyle.Render(detail)))
+		} else {
+			view.WriteString(fmt.Sprintf("  %s  %s", fmt.Sprintf("%-*s", nameWidth, name), dimStyle.Render(detail)))
+		}
+		view.WriteString("\n")
+	}
+
+	if len(vp.Views) == 0 {
+		view.WriteString("\n")
+		view.WriteString(dimStyle.Render("No views defined. Add [[views]] to config.toml."))
+		view.WriteString("\n")
+	}
+
+	view.WriteString("\n")
+	view.WriteString(dimStyle.Render("j/k: select • enter: switch • esc: cancel"))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2)
+
+	return boxStyle.Render(view.String())
+}
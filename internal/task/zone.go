@@ -0,0 +1,141 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vinayprograms/karya/internal/config"
+)
+
+// writeZone is the zone karya writes timed dates and CLOCK/LOG stamps in,
+// with a zone suffix; nil writes floating local times as before.
+var writeZone *time.Location
+
+// UseTimeZone makes this process write timed dates and CLOCK/LOG stamps in
+// the [schedule] timezone from now on, or floating local times when it is
+// unset. Reading is unaffected: times without a zone are always local.
+func UseTimeZone(c *config.Config) {
+	writeZone = nil
+	if c.Schedule.TimeZone == "" {
+		return
+	}
+	if loc, err := parseZone("[" + c.Schedule.TimeZone + "]"); err == nil {
+		writeZone = loc
+	}
+}
+
+// zonePattern is the zone suffix of a time: Z, an offset (+02:00) or a
+// bracketed zone name or offset ([Europe/Berlin], [-05:00]).
+const zonePattern = `(?:Z|[+-]\d{2}:\d{2}|\[[^\]\s]+\])`
+
+// stampZonePattern is the zone suffix of a CLOCK or LOG stamp: a zone
+// suffix, or an offset followed by the zone name it was taken in
+// (+01:00[Europe/Berlin]). The offset tells apart the two times a clock
+// shows twice when daylight saving ends.
+const stampZonePattern = `(?:[+-]\d{2}:\d{2}\[[^\]\s]+\]|` + zonePattern + `)`
+
+var (
+	zoneRe      = regexp.MustCompile(`^` + zonePattern)
+	stampZoneRe = regexp.MustCompile(`^` + stampZonePattern + `$`)
+	offsetRe    = regexp.MustCompile(`^([+-])(\d{2}):(\d{2})$`)
+)
+
+// parseZone parses a zone suffix matched by zoneRe.
+func parseZone(s string) (*time.Location, error) {
+	name := strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if name == "Z" || name == "UTC" {
+		return time.UTC, nil
+	}
+	if m := offsetRe.FindStringSubmatch(name); m != nil {
+		h, _ := strconv.Atoi(m[2])
+		mins, _ := strconv.Atoi(m[3])
+		if h > 14 || mins > 59 {
+			return nil, fmt.Errorf("invalid UTC offset %q", name)
+		}
+		secs := (h*60 + mins) * 60
+		if m[1] == "-" {
+			secs = -secs
+		}
+		return time.FixedZone(name, secs), nil
+	}
+	// "Local" and "" would make the meaning depend on the machine again
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// formatZone returns the suffix parseZone reads back as loc. A negative
+// offset right after a start time would read as an end time, so it is
+// bracketed unless bare says there is no such ambiguity.
+func formatZone(loc *time.Location, bare bool) string {
+	name := loc.String()
+	switch {
+	case loc == time.UTC:
+		return "Z"
+	case offsetRe.MatchString(name) && (bare || name[0] == '+'):
+		return name
+	}
+	return "[" + name + "]"
+}
+
+// wallClock returns the time showing t's date and clock in loc.
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+}
+
+const stampLayout = "2006-01-02T15:04"
+
+// parseStamp parses a CLOCK or LOG timestamp, YYYY-MM-DDTHH:MM with an
+// optional zone suffix, into local time. Without a suffix it is local time,
+// so durations across a DST change follow the local zone's rules. When the
+// suffix has both an offset and a zone name, the offset is used.
+func parseStamp(s string) (time.Time, error) {
+	if len(s) < len(stampLayout) {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	loc := time.Local
+	if suffix := s[len(stampLayout):]; suffix != "" {
+		if !stampZoneRe.MatchString(suffix) {
+			return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+		}
+		offset, name, named := strings.Cut(suffix, "[")
+		if named && offset != "" {
+			if _, err := parseZone("[" + name); err != nil {
+				return time.Time{}, err
+			}
+			suffix = offset
+		}
+		var err error
+		if loc, err = parseZone(suffix); err != nil {
+			return time.Time{}, err
+		}
+	}
+	t, err := time.ParseInLocation(stampLayout, s[:len(stampLayout)], loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(time.Local), nil
+}
+
+// formatStamp formats a CLOCK or LOG timestamp, in the configured zone
+// with its suffix when one is set (see UseTimeZone). A zone name follows
+// the offset in effect, so the stamp stays exact when clocks go back.
+func formatStamp(t time.Time) string {
+	if writeZone == nil {
+		return t.In(time.Local).Format(stampLayout)
+	}
+	t = t.In(writeZone)
+	zone := formatZone(writeZone, true)
+	if strings.HasPrefix(zone, "[") {
+		zone = t.Format("-07:00") + zone
+	}
+	return t.Format(stampLayout) + zone
+}
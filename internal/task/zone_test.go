@@ -0,0 +1,165 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// withZones sets the local zone and the configured write zone for a test.
+func withZones(t *testing.T, local, write string) {
+	t.Helper()
+	oldLocal, oldWrite := time.Local, writeZone
+	t.Cleanup(func() { time.Local, writeZone = oldLocal, oldWrite })
+	load := func(name string) *time.Location {
+		if name == "" {
+			return nil
+		}
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatalf("LoadLocation(%q) error: %v", name, err)
+		}
+		return loc
+	}
+	if local != "" {
+		time.Local = load(local)
+	}
+	writeZone = load(write)
+}
+
+func TestParseZone(t *testing.T) {
+	tests := []struct {
+		suffix     string
+		wantName   string
+		wantOffset int // seconds east of UTC on 2025-07-01
+		wantErr    bool
+	}{
+		{"Z", "UTC", 0, false},
+		{"+02:00", "+02:00", 7200, false},
+		{"[-05:30]", "-05:30", -19800, false},
+		{"[Europe/Berlin]", "Europe/Berlin", 7200, false},
+		{"[UTC]", "UTC", 0, false},
+		{"[Mars/Olympus]", "", 0, true},
+		{"+15:00", "", 0, true},
+		{"[Local]", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.suffix, func(t *testing.T) {
+			loc, err := parseZone(tt.suffix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseZone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			_, offset := time.Date(2025, 7, 1, 12, 0, 0, 0, loc).Zone()
+			if loc.String() != tt.wantName || offset != tt.wantOffset {
+				t.Errorf("parseZone() = %s (%+d), want %s (%+d)", loc, offset, tt.wantName, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestFormatZone(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	tests := []struct {
+		loc  *time.Location
+		bare bool
+		want string
+	}{
+		{time.UTC, false, "Z"},
+		{time.FixedZone("+02:00", 7200), false, "+02:00"},
+		{time.FixedZone("-05:00", -18000), false, "[-05:00]"},
+		{time.FixedZone("-05:00", -18000), true, "-05:00"},
+		{berlin, true, "[Europe/Berlin]"},
+	}
+
+	for _, tt := range tests {
+		if got := formatZone(tt.loc, tt.bare); got != tt.want {
+			t.Errorf("formatZone(%s, %v) = %q, want %q", tt.loc, tt.bare, got, tt.want)
+		}
+	}
+}
+
+func TestParseStamp(t *testing.T) {
+	withZones(t, "Europe/Berlin", "")
+	tests := []struct {
+		stamp   string
+		wantUTC string
+		wantErr bool
+	}{
+		{"2025-07-01T09:00", "2025-07-01T07:00", false},
+		{"2025-01-15T09:00", "2025-01-15T08:00", false},
+		{"2025-07-01T09:00Z", "2025-07-01T09:00", false},
+		{"2025-07-01T09:00-04:00", "2025-07-01T13:00", false},
+		{"2025-07-01T09:00[Asia/Kolkata]", "2025-07-01T03:30", false},
+		{"2025-10-26T02:30+02:00[Europe/Berlin]", "2025-10-26T00:30", false},
+		{"2025-10-26T02:30+01:00[Europe/Berlin]", "2025-10-26T01:30", false},
+		{"2025-07-01T09:00+02:00[Mars/Olympus]", "", true},
+		{"2025-07-01T09:00 later", "", true},
+		{"2025-07-01", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stamp, func(t *testing.T) {
+			got, err := parseStamp(tt.stamp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStamp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Location() != time.Local {
+				t.Errorf("parseStamp() location = %s, want Local", got.Location())
+			}
+			if s := got.UTC().Format(stampLayout); s != tt.wantUTC {
+				t.Errorf("parseStamp() = %s UTC, want %s", s, tt.wantUTC)
+			}
+		})
+	}
+}
+
+func TestFormatStamp(t *testing.T) {
+	at := time.Date(2025, 7, 1, 7, 0, 0, 0, time.UTC)
+
+	withZones(t, "Europe/Berlin", "")
+	if got := formatStamp(at); got != "2025-07-01T09:00" {
+		t.Errorf("floating formatStamp() = %q", got)
+	}
+	withZones(t, "Europe/Berlin", "America/New_York")
+	if got := formatStamp(at); got != "2025-07-01T03:00-04:00[America/New_York]" {
+		t.Errorf("zoned formatStamp() = %q", got)
+	}
+}
+
+func TestClock_AcrossFallBack(t *testing.T) {
+	withZones(t, "UTC", "Europe/Berlin")
+	f := filepath.Join(t.TempDir(), "tasks.md")
+	os.WriteFile(f, []byte("TODO: Night shift\n"), 0644)
+	task := &Task{Keyword: "TODO", Title: "Night shift", FilePath: f, LineNum: 1}
+
+	// Berlin's clocks go back from 03:00 to 02:00 at 01:00 UTC
+	in := time.Date(2025, 10, 26, 0, 30, 0, 0, time.UTC)
+	out := time.Date(2025, 10, 26, 1, 15, 0, 0, time.UTC)
+	if err := clockInAt(task, in); err != nil {
+		t.Fatalf("clockInAt() error: %v", err)
+	}
+	if err := clockOutAt(task, out); err != nil {
+		t.Fatalf("clockOutAt() error: %v", err)
+	}
+
+	data, _ := os.ReadFile(f)
+	want := "TODO: Night shift\n  * CLOCK: 2025-10-26T02:30+02:00[Europe/Berlin]--2025-10-26T02:15+01:00[Europe/Berlin]\n"
+	if string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+	entries, err := ParseClockEntries(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].Start.Equal(in) || !entries[0].End.Equal(out) {
+		t.Errorf("clock entries = %+v, want %v--%v", entries, in, out)
+	}
+}